@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN elimtiebreakers VARCHAR(255) NOT NULL DEFAULT 'fouls,auto,ownership,parkclimb';
+ALTER TABLE matches ADD COLUMN tiebreakcriterion VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN elimtiebreakers;
+ALTER TABLE matches DROP COLUMN tiebreakcriterion;
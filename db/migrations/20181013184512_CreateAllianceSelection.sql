@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE alliance_selection (
+  id INTEGER PRIMARY KEY,
+  alliancesjson text,
+  rankedteamsjson text,
+  invitedteamid int,
+  picktimerstartedat datetime
+);
+
+-- +goose Down
+DROP TABLE alliance_selection;
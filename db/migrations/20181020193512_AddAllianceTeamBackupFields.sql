@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE alliance_teams ADD COLUMN replacedteamid int NOT NULL DEFAULT 0;
+ALTER TABLE alliance_teams ADD COLUMN backupfrommatchid int NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE alliance_teams DROP COLUMN replacedteamid;
+ALTER TABLE alliance_teams DROP COLUMN backupfrommatchid;
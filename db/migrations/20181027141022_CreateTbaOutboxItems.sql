@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE tba_outbox_items (
+  id INTEGER PRIMARY KEY,
+  action VARCHAR(255),
+  status VARCHAR(255),
+  attempts int,
+  lasterror text,
+  queuedat datetime,
+  nextattemptat datetime
+);
+CREATE UNIQUE INDEX tba_outbox_action ON tba_outbox_items(action);
+
+-- +goose Down
+DROP TABLE tba_outbox_items;
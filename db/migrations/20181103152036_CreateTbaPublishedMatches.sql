@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE tba_published_matches (
+  id INTEGER PRIMARY KEY,
+  matchkey VARCHAR(255),
+  hash VARCHAR(255)
+);
+CREATE UNIQUE INDEX tba_published_match_key ON tba_published_matches(matchkey);
+
+-- +goose Down
+DROP TABLE tba_published_matches;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN frceventsusername VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE event_settings ADD COLUMN frceventsauthtoken VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE event_settings ADD COLUMN frceventseventcode VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN frceventsusername;
+ALTER TABLE event_settings DROP COLUMN frceventsauthtoken;
+ALTER TABLE event_settings DROP COLUMN frceventseventcode;
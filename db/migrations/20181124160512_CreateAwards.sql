@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE awards (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  presenterscript text,
+  displayorder int
+);
+CREATE TABLE award_winners (
+  id INTEGER PRIMARY KEY,
+  awardid int,
+  teamid int,
+  personname VARCHAR(255)
+);
+CREATE INDEX award_winners_awardid ON award_winners(awardid);
+ALTER TABLE lower_thirds ADD COLUMN awardid int NOT NULL DEFAULT 0;
+
+-- +goose Down
+DROP TABLE awards;
+DROP TABLE award_winners;
+ALTER TABLE lower_thirds DROP COLUMN awardid;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN standbyenabled bool NOT NULL DEFAULT 0;
+ALTER TABLE event_settings ADD COLUMN standbyprimaryaddress VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE event_settings ADD COLUMN standbyprimarypassword VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN standbyenabled;
+ALTER TABLE event_settings DROP COLUMN standbyprimaryaddress;
+ALTER TABLE event_settings DROP COLUMN standbyprimarypassword;
@@ -0,0 +1,352 @@
+-- +goose Up
+-- Counts writes to the event data tables and records the revision at which each table was last written, so that a
+-- standby instance can tell when and what to resync. Any new table that holds event data needs the same triggers.
+CREATE TABLE replication_revision (
+  id INTEGER PRIMARY KEY,
+  revision int
+);
+INSERT INTO replication_revision (id, revision) VALUES (0, 0);
+CREATE TABLE replication_changes (
+  tablename VARCHAR(255) PRIMARY KEY,
+  revision int
+);
+-- +goose StatementBegin
+CREATE TRIGGER event_settings_insert_revision AFTER INSERT ON event_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'event_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER event_settings_update_revision AFTER UPDATE ON event_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'event_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER event_settings_delete_revision AFTER DELETE ON event_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'event_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER teams_insert_revision AFTER INSERT ON teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER teams_update_revision AFTER UPDATE ON teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER teams_delete_revision AFTER DELETE ON teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER matches_insert_revision AFTER INSERT ON matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER matches_update_revision AFTER UPDATE ON matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER matches_delete_revision AFTER DELETE ON matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER match_results_insert_revision AFTER INSERT ON match_results BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'match_results', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER match_results_update_revision AFTER UPDATE ON match_results BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'match_results', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER match_results_delete_revision AFTER DELETE ON match_results BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'match_results', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER rankings_insert_revision AFTER INSERT ON rankings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'rankings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER rankings_update_revision AFTER UPDATE ON rankings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'rankings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER rankings_delete_revision AFTER DELETE ON rankings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'rankings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_teams_insert_revision AFTER INSERT ON alliance_teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_teams_update_revision AFTER UPDATE ON alliance_teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_teams_delete_revision AFTER DELETE ON alliance_teams BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_teams', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_selection_insert_revision AFTER INSERT ON alliance_selection BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_selection', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_selection_update_revision AFTER UPDATE ON alliance_selection BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_selection', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER alliance_selection_delete_revision AFTER DELETE ON alliance_selection BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'alliance_selection', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lower_thirds_insert_revision AFTER INSERT ON lower_thirds BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lower_thirds', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lower_thirds_update_revision AFTER UPDATE ON lower_thirds BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lower_thirds', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lower_thirds_delete_revision AFTER DELETE ON lower_thirds BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lower_thirds', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sponsor_slides_insert_revision AFTER INSERT ON sponsor_slides BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sponsor_slides', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sponsor_slides_update_revision AFTER UPDATE ON sponsor_slides BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sponsor_slides', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sponsor_slides_delete_revision AFTER DELETE ON sponsor_slides BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sponsor_slides', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER schedule_blocks_insert_revision AFTER INSERT ON schedule_blocks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'schedule_blocks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER schedule_blocks_update_revision AFTER UPDATE ON schedule_blocks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'schedule_blocks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER schedule_blocks_delete_revision AFTER DELETE ON schedule_blocks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'schedule_blocks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER awards_insert_revision AFTER INSERT ON awards BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'awards', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER awards_update_revision AFTER UPDATE ON awards BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'awards', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER awards_delete_revision AFTER DELETE ON awards BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'awards', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER award_winners_insert_revision AFTER INSERT ON award_winners BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'award_winners', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER award_winners_update_revision AFTER UPDATE ON award_winners BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'award_winners', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER award_winners_delete_revision AFTER DELETE ON award_winners BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'award_winners', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_outbox_items_insert_revision AFTER INSERT ON tba_outbox_items BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_outbox_items', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_outbox_items_update_revision AFTER UPDATE ON tba_outbox_items BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_outbox_items', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_outbox_items_delete_revision AFTER DELETE ON tba_outbox_items BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_outbox_items', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_published_matches_insert_revision AFTER INSERT ON tba_published_matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_published_matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_published_matches_update_revision AFTER UPDATE ON tba_published_matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_published_matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER tba_published_matches_delete_revision AFTER DELETE ON tba_published_matches BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'tba_published_matches', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER event_settings_insert_revision;
+DROP TRIGGER event_settings_update_revision;
+DROP TRIGGER event_settings_delete_revision;
+DROP TRIGGER teams_insert_revision;
+DROP TRIGGER teams_update_revision;
+DROP TRIGGER teams_delete_revision;
+DROP TRIGGER matches_insert_revision;
+DROP TRIGGER matches_update_revision;
+DROP TRIGGER matches_delete_revision;
+DROP TRIGGER match_results_insert_revision;
+DROP TRIGGER match_results_update_revision;
+DROP TRIGGER match_results_delete_revision;
+DROP TRIGGER rankings_insert_revision;
+DROP TRIGGER rankings_update_revision;
+DROP TRIGGER rankings_delete_revision;
+DROP TRIGGER alliance_teams_insert_revision;
+DROP TRIGGER alliance_teams_update_revision;
+DROP TRIGGER alliance_teams_delete_revision;
+DROP TRIGGER alliance_selection_insert_revision;
+DROP TRIGGER alliance_selection_update_revision;
+DROP TRIGGER alliance_selection_delete_revision;
+DROP TRIGGER lower_thirds_insert_revision;
+DROP TRIGGER lower_thirds_update_revision;
+DROP TRIGGER lower_thirds_delete_revision;
+DROP TRIGGER sponsor_slides_insert_revision;
+DROP TRIGGER sponsor_slides_update_revision;
+DROP TRIGGER sponsor_slides_delete_revision;
+DROP TRIGGER schedule_blocks_insert_revision;
+DROP TRIGGER schedule_blocks_update_revision;
+DROP TRIGGER schedule_blocks_delete_revision;
+DROP TRIGGER awards_insert_revision;
+DROP TRIGGER awards_update_revision;
+DROP TRIGGER awards_delete_revision;
+DROP TRIGGER award_winners_insert_revision;
+DROP TRIGGER award_winners_update_revision;
+DROP TRIGGER award_winners_delete_revision;
+DROP TRIGGER tba_outbox_items_insert_revision;
+DROP TRIGGER tba_outbox_items_update_revision;
+DROP TRIGGER tba_outbox_items_delete_revision;
+DROP TRIGGER tba_published_matches_insert_revision;
+DROP TRIGGER tba_published_matches_update_revision;
+DROP TRIGGER tba_published_matches_delete_revision;
+DROP TABLE replication_changes;
+DROP TABLE replication_revision;
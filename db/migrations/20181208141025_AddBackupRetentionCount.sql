@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN backupretentioncount int NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN backupretentioncount;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE displays (
+  id VARCHAR(255) PRIMARY KEY,
+  nickname VARCHAR(255),
+  type int,
+  configurationjson text,
+  ipaddress VARCHAR(255),
+  lastseentime datetime
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER displays_insert_revision AFTER INSERT ON displays BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'displays', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER displays_update_revision AFTER UPDATE ON displays BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'displays', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER displays_delete_revision AFTER DELETE ON displays BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'displays', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE displays;
@@ -0,0 +1,62 @@
+-- +goose Up
+CREATE TABLE display_groups (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  type int,
+  configurationjson text,
+  playlistid int
+);
+CREATE TABLE display_playlists (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  stepsjson text
+);
+ALTER TABLE displays ADD COLUMN groupid int NOT NULL DEFAULT 0;
+
+-- +goose StatementBegin
+CREATE TRIGGER display_groups_insert_revision AFTER INSERT ON display_groups BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_groups', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER display_groups_update_revision AFTER UPDATE ON display_groups BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_groups', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER display_groups_delete_revision AFTER DELETE ON display_groups BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_groups', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER display_playlists_insert_revision AFTER INSERT ON display_playlists BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_playlists', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER display_playlists_update_revision AFTER UPDATE ON display_playlists BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_playlists', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER display_playlists_delete_revision AFTER DELETE ON display_playlists BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'display_playlists', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE display_groups;
+DROP TABLE display_playlists;
+ALTER TABLE displays DROP COLUMN groupid;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE api_keys (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  key VARCHAR(255),
+  requestsperminute int
+);
+CREATE UNIQUE INDEX api_key ON api_keys(key);
+
+-- +goose StatementBegin
+CREATE TRIGGER api_keys_insert_revision AFTER INSERT ON api_keys BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'api_keys', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER api_keys_update_revision AFTER UPDATE ON api_keys BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'api_keys', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER api_keys_delete_revision AFTER DELETE ON api_keys BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'api_keys', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE api_keys;
@@ -0,0 +1,48 @@
+-- +goose Up
+CREATE TABLE webhooks (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  url VARCHAR(1024),
+  secret VARCHAR(255),
+  events text,
+  enabled bool
+);
+CREATE TABLE webhook_deliveries (
+  id INTEGER PRIMARY KEY,
+  webhookid int,
+  event VARCHAR(255),
+  payload text,
+  status VARCHAR(255),
+  attempts int,
+  responsecode int,
+  lasterror text,
+  createdat datetime,
+  nextattemptat datetime
+);
+CREATE INDEX webhook_delivery_status ON webhook_deliveries(status);
+
+-- +goose StatementBegin
+CREATE TRIGGER webhooks_insert_revision AFTER INSERT ON webhooks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'webhooks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER webhooks_update_revision AFTER UPDATE ON webhooks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'webhooks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER webhooks_delete_revision AFTER DELETE ON webhooks BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'webhooks', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE webhooks;
+DROP TABLE webhook_deliveries;
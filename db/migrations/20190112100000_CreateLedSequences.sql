@@ -0,0 +1,60 @@
+-- +goose Up
+CREATE TABLE led_sequences (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  loop bool,
+  keyframesjson text
+);
+CREATE UNIQUE INDEX led_sequence_name ON led_sequences(name);
+CREATE TABLE led_settings (
+  id INTEGER PRIMARY KEY,
+  controllersjson text,
+  eventsequencesjson text
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER led_sequences_insert_revision AFTER INSERT ON led_sequences BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_sequences', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER led_sequences_update_revision AFTER UPDATE ON led_sequences BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_sequences', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER led_sequences_delete_revision AFTER DELETE ON led_sequences BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_sequences', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER led_settings_insert_revision AFTER INSERT ON led_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER led_settings_update_revision AFTER UPDATE ON led_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER led_settings_delete_revision AFTER DELETE ON led_settings BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'led_settings', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE led_sequences;
+DROP TABLE led_settings;
@@ -0,0 +1,66 @@
+-- +goose Up
+CREATE TABLE lighting_fixtures (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  groupname VARCHAR(255),
+  profile VARCHAR(255),
+  channelsjson text,
+  universe int,
+  address int
+);
+CREATE UNIQUE INDEX lighting_fixture_name ON lighting_fixtures(name);
+CREATE TABLE lighting_cues (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  trigger VARCHAR(255),
+  fadems int,
+  looksjson text
+);
+CREATE UNIQUE INDEX lighting_cue_name ON lighting_cues(name);
+
+-- +goose StatementBegin
+CREATE TRIGGER lighting_fixtures_insert_revision AFTER INSERT ON lighting_fixtures BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_fixtures', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lighting_fixtures_update_revision AFTER UPDATE ON lighting_fixtures BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_fixtures', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lighting_fixtures_delete_revision AFTER DELETE ON lighting_fixtures BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_fixtures', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lighting_cues_insert_revision AFTER INSERT ON lighting_cues BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_cues', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lighting_cues_update_revision AFTER UPDATE ON lighting_cues BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_cues', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER lighting_cues_delete_revision AFTER DELETE ON lighting_cues BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'lighting_cues', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE lighting_fixtures;
+DROP TABLE lighting_cues;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN lightingprotocol VARCHAR(255) NOT NULL DEFAULT 'e131';
+ALTER TABLE event_settings ADD COLUMN lightingaddress VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE event_settings ADD COLUMN lightingpriority int NOT NULL DEFAULT 100;
+ALTER TABLE event_settings ADD COLUMN lightingsyncuniverse int NOT NULL DEFAULT 0;
+ALTER TABLE event_settings ADD COLUMN lightingcomponentid VARCHAR(255) NOT NULL DEFAULT '';
+UPDATE event_settings SET lightingcomponentid = lower(hex(randomblob(16)));
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN lightingprotocol;
+ALTER TABLE event_settings DROP COLUMN lightingaddress;
+ALTER TABLE event_settings DROP COLUMN lightingpriority;
+ALTER TABLE event_settings DROP COLUMN lightingsyncuniverse;
+ALTER TABLE event_settings DROP COLUMN lightingcomponentid;
@@ -0,0 +1,61 @@
+-- +goose Up
+CREATE TABLE sound_packs (
+  id INTEGER PRIMARY KEY,
+  name VARCHAR(255),
+  season int
+);
+CREATE TABLE sound_pack_files (
+  id INTEGER PRIMARY KEY,
+  soundpackid int,
+  cue VARCHAR(255),
+  filename VARCHAR(255),
+  data blob
+);
+CREATE UNIQUE INDEX sound_pack_file_cue ON sound_pack_files(soundpackid, cue);
+
+-- +goose StatementBegin
+CREATE TRIGGER sound_packs_insert_revision AFTER INSERT ON sound_packs BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_packs', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sound_packs_update_revision AFTER UPDATE ON sound_packs BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_packs', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sound_packs_delete_revision AFTER DELETE ON sound_packs BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_packs', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sound_pack_files_insert_revision AFTER INSERT ON sound_pack_files BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_pack_files', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sound_pack_files_update_revision AFTER UPDATE ON sound_pack_files BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_pack_files', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER sound_pack_files_delete_revision AFTER DELETE ON sound_pack_files BEGIN
+  UPDATE replication_revision SET revision = revision + 1;
+  INSERT OR REPLACE INTO replication_changes (tablename, revision)
+    SELECT 'sound_pack_files', revision FROM replication_revision;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE sound_packs;
+DROP TABLE sound_pack_files;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE event_settings ADD COLUMN soundpackid int NOT NULL DEFAULT 0;
+ALTER TABLE event_settings ADD COLUMN soundplayercommand VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE event_settings DROP COLUMN soundpackid;
+ALTER TABLE event_settings DROP COLUMN soundplayercommand;
@@ -9,12 +9,15 @@ import (
 	"fmt"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/lighting"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/Team254/cheesy-arena/partner"
 	"github.com/Team254/cheesy-arena/plc"
+	"github.com/Team254/cheesy-arena/sound"
 	"github.com/Team254/cheesy-arena/vaultled"
 	"log"
-	"math/rand"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,6 +51,7 @@ type Arena struct {
 	networkSwitch    *NetworkSwitch
 	Plc              plc.Plc
 	TbaClient        *partner.TbaClient
+	PartnerSource    partner.Source
 	AllianceStations map[string]*AllianceStation
 	Displays         map[string]*Display
 	ArenaNotifiers
@@ -65,8 +69,10 @@ type Arena struct {
 	SavedMatch                 *model.Match
 	SavedMatchResult           *model.MatchResult
 	AllianceStationDisplayMode string
-	AllianceSelectionAlliances [][]model.AllianceTeam
 	LowerThird                 *model.LowerThird
+	Award                      *model.Award
+	AwardWinnersRevealed       bool
+	StandbyStatus              StandbyStatus
 	MuteMatchSounds            bool
 	matchAborted               bool
 	Scale                      *game.Seesaw
@@ -79,9 +85,29 @@ type Arena struct {
 	BlueSwitchLeds             led.Controller
 	RedVaultLeds               vaultled.Controller
 	BlueVaultLeds              vaultled.Controller
-	warmupLedMode              led.Mode
+	LedSettings                *model.LedSettings
+	ledSequences               map[string]*led.Sequence
+	ledEventSequences          map[string]*led.Sequence
+	Lighting                   lighting.Controller
+	lightingCues               []model.LightingCue
+	lightingCuesMutex          sync.Mutex
+	lastLightingTrigger        string
+	SoundPlayer                sound.Player
+	soundSources               map[string]soundSource
+	lastFieldVisualization     FieldVisualizationMessage
+	lastFieldVisualizationTime time.Time
 	lastRedAllianceReady       bool
 	lastBlueAllianceReady      bool
+	tbaOutboxMutex             sync.Mutex
+	tbaOutboxWakeup            chan struct{}
+	webhookWakeup              chan struct{}
+	webhookEvents              chan webhookEvent
+	webhookRetries             chan int
+	loopTasks                  chan func()
+	standbyMutex               sync.Mutex
+	displayPlaylistStates      map[int]*displayPlaylistState
+	displayHealths             map[string]*DisplayHealth
+	DisplayPageVersion         string // Identifies the current version of the display pages, to detect outdated ones.
 }
 
 type AllianceStation struct {
@@ -115,8 +141,18 @@ func NewArena(dbPath string) (*Arena, error) {
 	arena.AllianceStations["B3"] = new(AllianceStation)
 
 	arena.Displays = make(map[string]*Display)
+	arena.displayPlaylistStates = make(map[int]*displayPlaylistState)
+	arena.displayHealths = make(map[string]*DisplayHealth)
+	arena.tbaOutboxWakeup = make(chan struct{}, 1)
+	arena.webhookWakeup = make(chan struct{}, 1)
+	arena.webhookEvents = make(chan webhookEvent, webhookQueueSize)
+	arena.webhookRetries = make(chan int, webhookQueueSize)
+	arena.loopTasks = make(chan func())
 
 	arena.configureNotifiers()
+	if err = arena.loadDisplays(); err != nil {
+		return nil, err
+	}
 
 	// Load empty match as current.
 	arena.MatchState = PreMatch
@@ -147,6 +183,12 @@ func (arena *Arena) LoadSettings() error {
 	arena.networkSwitch = NewNetworkSwitch(settings.SwitchAddress, settings.SwitchPassword)
 	arena.Plc.SetAddress(settings.PlcAddress)
 	arena.TbaClient = partner.NewTbaClient(settings.TbaEventCode, settings.TbaSecretId, settings.TbaSecret)
+	partnerSources := []partner.Source{arena.TbaClient}
+	if settings.FrcEventsUsername != "" && settings.FrcEventsAuthToken != "" {
+		partnerSources = append(partnerSources, partner.NewFrcEventsClient(settings.FrcEventsUsername,
+			settings.FrcEventsAuthToken, settings.FrcEventsEventCode, time.Now().Year()))
+	}
+	arena.PartnerSource = partner.NewFallbackSource(partnerSources...)
 
 	if arena.EventSettings.NetworkSecurityEnabled {
 		if err = arena.accessPoint.ConfigureAdminWifi(); err != nil {
@@ -171,7 +213,15 @@ func (arena *Arena) LoadSettings() error {
 		return err
 	}
 
-	return nil
+	if err = arena.LoadLedSettings(); err != nil {
+		return err
+	}
+
+	if err = arena.LoadLighting(); err != nil {
+		return err
+	}
+
+	return arena.LoadSounds()
 }
 
 // Sets up the arena for the given match.
@@ -235,14 +285,19 @@ func (arena *Arena) LoadMatch(match *model.Match) error {
 	arena.AllianceStationDisplayModeNotifier.Notify()
 
 	// Set the initial state of the lights.
-	arena.ScaleLeds.SetMode(led.OffMode, led.OffMode)
-	arena.RedSwitchLeds.SetMode(led.RedMode, led.RedMode)
-	arena.BlueSwitchLeds.SetMode(led.BlueMode, led.BlueMode)
+	preMatchSequence := arena.ledEventSequence(LedEventPreMatch)
+	arena.ScaleLeds.SetSequence(preMatchSequence, preMatchSequence)
+	redNotReadySequence := arena.ledEventSequence(LedEventRedAllianceNotReady)
+	arena.RedSwitchLeds.SetSequence(redNotReadySequence, redNotReadySequence)
+	blueNotReadySequence := arena.ledEventSequence(LedEventBlueAllianceNotReady)
+	arena.BlueSwitchLeds.SetSequence(blueNotReadySequence, blueNotReadySequence)
 	arena.RedVaultLeds.SetAllModes(vaultled.OffMode)
 	arena.BlueVaultLeds.SetAllModes(vaultled.OffMode)
 	arena.lastRedAllianceReady = false
 	arena.lastBlueAllianceReady = false
 
+	arena.queueMatchWebhookEvent(model.WebhookMatchLoaded)
+
 	return nil
 }
 
@@ -279,6 +334,11 @@ func (arena *Arena) SubstituteTeam(teamId int, station string) error {
 	if arena.CurrentMatch.Type == "qualification" {
 		return fmt.Errorf("Can't substitute teams for qualification matches.")
 	}
+	if arena.CurrentMatch.Type == "elimination" && teamId != 0 {
+		if err := arena.checkSubstituteAlliance(teamId, station); err != nil {
+			return err
+		}
+	}
 	err := arena.assignTeam(teamId, station)
 	if err != nil {
 		return err
@@ -306,6 +366,39 @@ func (arena *Arena) SubstituteTeam(teamId int, station string) error {
 	return nil
 }
 
+// Returns an error if the given team is a member of an alliance other than the one playing in the given station in
+// the current elimination match, to prevent a team from playing for more than one alliance.
+func (arena *Arena) checkSubstituteAlliance(teamId int, station string) error {
+	alliances, err := arena.Database.GetAllAlliances()
+	if err != nil {
+		return err
+	}
+	teamAllianceIds := make(map[int]int)
+	for _, alliance := range alliances {
+		for _, allianceTeam := range alliance {
+			teamAllianceIds[allianceTeam.TeamId] = allianceTeam.AllianceId
+		}
+	}
+	allianceId, ok := teamAllianceIds[teamId]
+	if !ok {
+		return nil
+	}
+
+	var stationTeams []int
+	if strings.HasPrefix(station, "R") {
+		stationTeams = []int{arena.CurrentMatch.Red1, arena.CurrentMatch.Red2, arena.CurrentMatch.Red3}
+	} else {
+		stationTeams = []int{arena.CurrentMatch.Blue1, arena.CurrentMatch.Blue2, arena.CurrentMatch.Blue3}
+	}
+	for _, stationTeam := range stationTeams {
+		if stationAllianceId, ok := teamAllianceIds[stationTeam]; ok && stationAllianceId != allianceId {
+			return fmt.Errorf("Team %d is a member of alliance %d and can't play for alliance %d.", teamId,
+				allianceId, stationAllianceId)
+		}
+	}
+	return nil
+}
+
 // Starts the match if all conditions are met.
 func (arena *Arena) StartMatch() error {
 	err := arena.checkCanStartMatch()
@@ -366,7 +459,7 @@ func (arena *Arena) AbortMatch() error {
 	}
 
 	if !arena.MuteMatchSounds && arena.MatchState != WarmupPeriod {
-		arena.PlaySoundNotifier.NotifyWithMessage("match-abort")
+		arena.PlaySound("match-abort")
 	}
 	arena.MatchState = PostMatch
 	arena.matchAborted = true
@@ -407,6 +500,7 @@ func (arena *Arena) StartTimeout(durationSec int) error {
 	arena.LastMatchTimeSec = -1
 	arena.AudienceDisplayMode = "timeout"
 	arena.AudienceDisplayModeNotifier.Notify()
+	arena.QueueWebhookEvent(model.WebhookTimeoutStarted, struct{ DurationSec int }{durationSec})
 
 	return nil
 }
@@ -423,6 +517,8 @@ func (arena *Arena) MatchTimeSec() float64 {
 // Performs a single iteration of checking inputs and timers and setting outputs accordingly to control the
 // flow of a match.
 func (arena *Arena) Update() {
+	arena.runLoopTasks()
+
 	// Decide what state the robots need to be in, depending on where we are in the match.
 	auto := false
 	enabled := false
@@ -444,11 +540,10 @@ func (arena *Arena) Update() {
 		arena.AllianceStationDisplayModeNotifier.Notify()
 		arena.sendGameSpecificDataPacket()
 		if !arena.MuteMatchSounds {
-			arena.PlaySoundNotifier.NotifyWithMessage("match-warmup")
+			arena.PlaySound("match-warmup")
 		}
-		// Pick an LED warmup mode at random to keep things interesting.
-		allWarmupModes := []led.Mode{led.WarmupMode, led.Warmup2Mode, led.Warmup3Mode, led.Warmup4Mode}
-		arena.warmupLedMode = allWarmupModes[rand.Intn(len(allWarmupModes))]
+		// Pick new LED sequences at random for the events having several, to keep things interesting.
+		arena.chooseLedEventSequences(true)
 	case WarmupPeriod:
 		auto = true
 		enabled = false
@@ -458,8 +553,9 @@ func (arena *Arena) Update() {
 			enabled = true
 			sendDsPacket = true
 			if !arena.MuteMatchSounds {
-				arena.PlaySoundNotifier.NotifyWithMessage("match-start")
+				arena.PlaySound("match-start")
 			}
+			arena.queueMatchWebhookEvent(model.WebhookMatchStarted)
 		}
 	case AutoPeriod:
 		auto = true
@@ -470,7 +566,7 @@ func (arena *Arena) Update() {
 			enabled = false
 			sendDsPacket = true
 			if !arena.MuteMatchSounds {
-				arena.PlaySoundNotifier.NotifyWithMessage("match-end")
+				arena.PlaySound("match-end")
 			}
 		}
 	case PausePeriod:
@@ -483,7 +579,7 @@ func (arena *Arena) Update() {
 			enabled = true
 			sendDsPacket = true
 			if !arena.MuteMatchSounds {
-				arena.PlaySoundNotifier.NotifyWithMessage("match-resume")
+				arena.PlaySound("match-resume")
 			}
 		}
 	case TeleopPeriod:
@@ -494,7 +590,7 @@ func (arena *Arena) Update() {
 			arena.MatchState = EndgamePeriod
 			sendDsPacket = false
 			if !arena.MuteMatchSounds {
-				arena.PlaySoundNotifier.NotifyWithMessage("match-endgame")
+				arena.PlaySound("match-endgame")
 			}
 		}
 	case EndgamePeriod:
@@ -515,13 +611,14 @@ func (arena *Arena) Update() {
 				arena.AllianceStationDisplayModeNotifier.Notify()
 			}()
 			if !arena.MuteMatchSounds {
-				arena.PlaySoundNotifier.NotifyWithMessage("match-end")
+				arena.PlaySound("match-end")
 			}
+			arena.queueMatchWebhookEvent(model.WebhookMatchEnded)
 		}
 	case TimeoutActive:
 		if matchTimeSec >= float64(game.MatchTiming.TimeoutDurationSec) {
 			arena.MatchState = PostTimeout
-			arena.PlaySoundNotifier.NotifyWithMessage("match-end")
+			arena.PlaySound("match-end")
 			go func() {
 				// Leave the timer on the screen briefly at the end of the timeout period.
 				time.Sleep(time.Second * matchEndScoreDwellSec)
@@ -551,6 +648,8 @@ func (arena *Arena) Update() {
 	// Handle field sensors/lights/motors.
 	arena.handlePlcInput()
 	arena.handleLeds()
+	arena.handleLighting()
+	arena.handleFieldVisualization()
 }
 
 // Loops indefinitely to track and update the arena components.
@@ -559,6 +658,12 @@ func (arena *Arena) Run() {
 	go arena.listenForDriverStations()
 	go arena.listenForDsUdpPackets()
 	go arena.Plc.Run()
+	go arena.runTbaOutbox()
+	go arena.runWebhookDeliveries()
+	go arena.runTbaMirror()
+	go arena.runStandbySync()
+	go arena.runDisplayPlaylists()
+	go arena.runDisplayHealthChecks()
 
 	for {
 		arena.Update()
@@ -566,6 +671,28 @@ func (arena *Arena) Run() {
 	}
 }
 
+// Runs the given function on the arena loop and returns its result, so that background goroutines can change the
+// match state without racing with the loop.
+func (arena *Arena) runOnArenaLoop(task func() error) error {
+	result := make(chan error)
+	arena.loopTasks <- func() {
+		result <- task()
+	}
+	return <-result
+}
+
+// Runs the tasks that other goroutines are waiting to have run on the arena loop.
+func (arena *Arena) runLoopTasks() {
+	for {
+		select {
+		case task := <-arena.loopTasks:
+			task()
+		default:
+			return
+		}
+	}
+}
+
 // Calculates the red alliance score summary for the given realtime snapshot.
 func (arena *Arena) RedScoreSummary() *game.ScoreSummary {
 	return arena.RedRealtimeScore.CurrentScore.Summarize(arena.BlueRealtimeScore.CurrentScore.Fouls)
@@ -641,6 +768,14 @@ func (arena *Arena) checkCanStartMatch() error {
 		return fmt.Errorf("Cannot start match while there is a match still in progress or with results pending.")
 	}
 
+	if arena.EventSettings.TbaMirrorEnabled {
+		return fmt.Errorf("Cannot start match while the event is being mirrored from TBA.")
+	}
+
+	if arena.EventSettings.StandbyEnabled {
+		return fmt.Errorf("Cannot start match while in standby mode; promote this instance first.")
+	}
+
 	err := arena.checkAllianceStationsReady("R1", "R2", "R3", "B1", "B2", "B3")
 	if err != nil {
 		return err
@@ -784,11 +919,11 @@ func (arena *Arena) handlePlcInput() {
 	// Check if a power up has been newly played and trigger the accompanying sound effect if so.
 	newRedPowerUp := arena.RedVault.CheckForNewlyPlayedPowerUp()
 	if newRedPowerUp != "" && !arena.MuteMatchSounds {
-		arena.PlaySoundNotifier.NotifyWithMessage("match-" + newRedPowerUp)
+		arena.PlaySound("match-" + newRedPowerUp)
 	}
 	newBluePowerUp := arena.BlueVault.CheckForNewlyPlayedPowerUp()
 	if newBluePowerUp != "" && !arena.MuteMatchSounds {
-		arena.PlaySoundNotifier.NotifyWithMessage("match-" + newBluePowerUp)
+		arena.PlaySound("match-" + newBluePowerUp)
 	}
 
 	if !oldRedScore.Equals(redScore) || !oldBlueScore.Equals(blueScore) || ownershipChanged {
@@ -810,49 +945,47 @@ func (arena *Arena) handleLeds() {
 		arena.Plc.SetStackLights(!redAllianceReady, !blueAllianceReady, greenStackLight)
 		arena.Plc.SetStackBuzzer(redAllianceReady && blueAllianceReady)
 
-		// Turn off each alliance switch if all teams become ready.
-		if redAllianceReady && !arena.lastRedAllianceReady {
-			arena.RedSwitchLeds.SetMode(led.OffMode, led.OffMode)
-		} else if !redAllianceReady && arena.lastRedAllianceReady {
-			arena.RedSwitchLeds.SetMode(led.RedMode, led.RedMode)
+		// Change each alliance switch to the ready sequence if all teams become ready.
+		if redAllianceReady != arena.lastRedAllianceReady {
+			sequence := arena.ledEventSequence(LedEventRedAllianceNotReady)
+			if redAllianceReady {
+				sequence = arena.ledEventSequence(LedEventAllianceReady)
+			}
+			arena.RedSwitchLeds.SetSequence(sequence, sequence)
 		}
 		arena.lastRedAllianceReady = redAllianceReady
-		if blueAllianceReady && !arena.lastBlueAllianceReady {
-			arena.BlueSwitchLeds.SetMode(led.OffMode, led.OffMode)
-		} else if !blueAllianceReady && arena.lastBlueAllianceReady {
-			arena.BlueSwitchLeds.SetMode(led.BlueMode, led.BlueMode)
+		if blueAllianceReady != arena.lastBlueAllianceReady {
+			sequence := arena.ledEventSequence(LedEventBlueAllianceNotReady)
+			if blueAllianceReady {
+				sequence = arena.ledEventSequence(LedEventAllianceReady)
+			}
+			arena.BlueSwitchLeds.SetSequence(sequence, sequence)
 		}
 		arena.lastBlueAllianceReady = blueAllianceReady
 	case WarmupPeriod:
 		arena.Plc.SetStackLights(false, false, true)
-		arena.ScaleLeds.SetMode(arena.warmupLedMode, arena.warmupLedMode)
-		arena.RedSwitchLeds.SetMode(arena.warmupLedMode, arena.warmupLedMode)
-		arena.BlueSwitchLeds.SetMode(arena.warmupLedMode, arena.warmupLedMode)
+		arena.setAllSeesawLedSequences(arena.ledEventSequence(LedEventWarmup))
 	case AutoPeriod:
 		fallthrough
 	case TeleopPeriod:
 		fallthrough
 	case EndgamePeriod:
-		handleSeesawTeleopLeds(arena.Scale, &arena.ScaleLeds)
-		handleSeesawTeleopLeds(arena.RedSwitch, &arena.RedSwitchLeds)
-		handleSeesawTeleopLeds(arena.BlueSwitch, &arena.BlueSwitchLeds)
+		arena.handleSeesawTeleopLeds(arena.Scale, &arena.ScaleLeds)
+		arena.handleSeesawTeleopLeds(arena.RedSwitch, &arena.RedSwitchLeds)
+		arena.handleSeesawTeleopLeds(arena.BlueSwitch, &arena.BlueSwitchLeds)
 		handleVaultTeleopLeds(arena.RedVault, &arena.RedVaultLeds)
 		handleVaultTeleopLeds(arena.BlueVault, &arena.BlueVaultLeds)
 	case PausePeriod:
-		arena.ScaleLeds.SetMode(led.OffMode, led.OffMode)
-		arena.RedSwitchLeds.SetMode(led.OffMode, led.OffMode)
-		arena.BlueSwitchLeds.SetMode(led.OffMode, led.OffMode)
+		arena.setAllSeesawLedSequences(arena.ledEventSequence(LedEventPause))
 	case PostMatch:
 		arena.Plc.SetStackLights(false, false, false)
-		mode := led.FadeSingleMode
+		event := LedEventPostMatch
 		if arena.FieldReset {
-			mode = led.GreenMode
+			event = LedEventFieldReset
 		} else if arena.FieldVolunteers {
-			mode = led.PurpleMode
+			event = LedEventFieldVolunteers
 		}
-		arena.ScaleLeds.SetMode(mode, mode)
-		arena.RedSwitchLeds.SetMode(mode, mode)
-		arena.BlueSwitchLeds.SetMode(mode, mode)
+		arena.setAllSeesawLedSequences(arena.ledEventSequence(event))
 		arena.RedVaultLeds.SetAllModes(vaultled.OffMode)
 		arena.BlueVaultLeds.SetAllModes(vaultled.OffMode)
 	}
@@ -864,41 +997,50 @@ func (arena *Arena) handleLeds() {
 	arena.BlueVaultLeds.Update()
 }
 
-func handleSeesawTeleopLeds(seesaw *game.Seesaw, leds *led.Controller) {
-	// Assume the simplest mode to start and consider others in order of increasing complexity.
-	redMode := led.NotOwnedMode
-	blueMode := led.NotOwnedMode
+// Sets the scale and both switches to show the given sequence on both sides.
+func (arena *Arena) setAllSeesawLedSequences(sequence *led.Sequence) {
+	arena.ScaleLeds.SetSequence(sequence, sequence)
+	arena.RedSwitchLeds.SetSequence(sequence, sequence)
+	arena.BlueSwitchLeds.SetSequence(sequence, sequence)
+}
+
+func (arena *Arena) handleSeesawTeleopLeds(seesaw *game.Seesaw, leds *led.Controller) {
+	// Assume the simplest event to start and consider others in order of increasing complexity.
+	redEvent := LedEventNotOwned
+	blueEvent := LedEventNotOwned
 
-	// Upgrade the mode to ownership based on the physical state of the switch or scale.
+	// Upgrade the event to ownership based on the physical state of the switch or scale.
 	if seesaw.GetOwnedBy() == game.RedAlliance && seesaw.Kind != game.BlueAlliance {
-		redMode = led.OwnedMode
+		redEvent = LedEventOwned
 	} else if seesaw.GetOwnedBy() == game.BlueAlliance && seesaw.Kind != game.RedAlliance {
-		blueMode = led.OwnedMode
+		blueEvent = LedEventOwned
 	}
 
-	// Upgrade the mode if there is an applicable power up.
+	// Upgrade the event if there is an applicable power up.
 	powerUp := game.GetActivePowerUp(time.Now())
 	if powerUp != nil && (seesaw.Kind == game.NeitherAlliance && powerUp.Level >= 2 ||
 		seesaw.Kind == powerUp.Alliance && (powerUp.Level == 1 || powerUp.Level == 3)) {
 		if powerUp.Effect == game.Boost {
 			if powerUp.Alliance == game.RedAlliance {
-				redMode = led.BoostMode
+				redEvent = LedEventBoost
 			} else {
-				blueMode = led.BoostMode
+				blueEvent = LedEventBoost
 			}
 		} else {
 			if powerUp.Alliance == game.RedAlliance {
-				redMode = led.ForceMode
+				redEvent = LedEventForce
 			} else {
-				blueMode = led.ForceMode
+				blueEvent = LedEventForce
 			}
 		}
 	}
 
+	redSequence := arena.ledEventSequence(redEvent)
+	blueSequence := arena.ledEventSequence(blueEvent)
 	if seesaw.NearIsRed {
-		leds.SetMode(redMode, blueMode)
+		leds.SetSequence(redSequence, blueSequence)
 	} else {
-		leds.SetMode(blueMode, redMode)
+		leds.SetSequence(blueSequence, redSequence)
 	}
 }
 
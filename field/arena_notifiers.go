@@ -8,10 +8,11 @@ package field
 import (
 	"fmt"
 	"github.com/Team254/cheesy-arena/game"
-	"github.com/Team254/cheesy-arena/led"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/Team254/cheesy-arena/vaultled"
 	"github.com/Team254/cheesy-arena/websocket"
+	"log"
 	"strconv"
 	"time"
 )
@@ -21,15 +22,21 @@ type ArenaNotifiers struct {
 	AllianceStationDisplayModeNotifier *websocket.Notifier
 	ArenaStatusNotifier                *websocket.Notifier
 	AudienceDisplayModeNotifier        *websocket.Notifier
+	AwardNotifier                      *websocket.Notifier
+	BracketNotifier                    *websocket.Notifier
 	DisplayConfigurationNotifier       *websocket.Notifier
+	DisplayHealthNotifier              *websocket.Notifier
+	FieldVisualizationNotifier         *websocket.Notifier
 	LedModeNotifier                    *websocket.Notifier
 	LowerThirdNotifier                 *websocket.Notifier
 	MatchLoadNotifier                  *websocket.Notifier
 	MatchTimeNotifier                  *websocket.Notifier
 	MatchTimingNotifier                *websocket.Notifier
 	PlaySoundNotifier                  *websocket.Notifier
+	RankingsNotifier                   *websocket.Notifier
 	RealtimeScoreNotifier              *websocket.Notifier
 	ReloadDisplaysNotifier             *websocket.Notifier
+	ScheduleNotifier                   *websocket.Notifier
 	ScorePostedNotifier                *websocket.Notifier
 	ScoringStatusNotifier              *websocket.Notifier
 }
@@ -40,7 +47,7 @@ type DisplayConfigurationMessage struct {
 }
 
 type LedModeMessage struct {
-	LedMode      led.Mode
+	LedSequence  string
 	VaultLedMode vaultled.Mode
 }
 
@@ -66,22 +73,44 @@ func (arena *Arena) configureNotifiers() {
 	arena.ArenaStatusNotifier = websocket.NewNotifier("arenaStatus", arena.generateArenaStatusMessage)
 	arena.AudienceDisplayModeNotifier = websocket.NewNotifier("audienceDisplayMode",
 		arena.generateAudienceDisplayModeMessage)
+	arena.AwardNotifier = websocket.NewNotifier("award", arena.generateAwardMessage)
+	arena.BracketNotifier = websocket.NewNotifier("bracket", arena.generateBracketMessage)
 	arena.DisplayConfigurationNotifier = websocket.NewNotifier("displayConfiguration",
 		arena.generateDisplayConfigurationMessage)
+	arena.DisplayHealthNotifier = websocket.NewNotifier("displayHealth", arena.generateDisplayHealthMessage)
+	arena.FieldVisualizationNotifier = websocket.NewNotifier("fieldVisualization",
+		arena.generateFieldVisualizationMessage)
 	arena.LedModeNotifier = websocket.NewNotifier("ledMode", arena.generateLedModeMessage)
 	arena.LowerThirdNotifier = websocket.NewNotifier("lowerThird", arena.generateLowerThirdMessage)
 	arena.MatchLoadNotifier = websocket.NewNotifier("matchLoad", arena.generateMatchLoadMessage)
 	arena.MatchTimeNotifier = websocket.NewNotifier("matchTime", arena.generateMatchTimeMessage)
 	arena.MatchTimingNotifier = websocket.NewNotifier("matchTiming", arena.generateMatchTimingMessage)
 	arena.PlaySoundNotifier = websocket.NewNotifier("playSound", nil)
+	arena.RankingsNotifier = websocket.NewNotifier("rankings", arena.generateRankingsMessage)
 	arena.RealtimeScoreNotifier = websocket.NewNotifier("realtimeScore", arena.generateRealtimeScoreMessage)
 	arena.ReloadDisplaysNotifier = websocket.NewNotifier("reload", nil)
+	arena.ScheduleNotifier = websocket.NewNotifier("schedule", arena.generateScheduleMessage)
 	arena.ScorePostedNotifier = websocket.NewNotifier("scorePosted", arena.generateScorePostedMessage)
 	arena.ScoringStatusNotifier = websocket.NewNotifier("scoringStatus", arena.generateScoringStatusMessage)
 }
 
 func (arena *Arena) generateAllianceSelectionMessage() interface{} {
-	return &arena.AllianceSelectionAlliances
+	message := struct {
+		Alliances            [][]model.AllianceTeam
+		InvitedTeamId        int
+		PickTimeRemainingSec int
+		PickTimerRunning     bool
+	}{Alliances: [][]model.AllianceTeam{}}
+	allianceSelection, err := arena.Database.GetAllianceSelection()
+	if err != nil {
+		log.Printf("Failed to load alliance selection: %s", err.Error())
+	} else if allianceSelection != nil {
+		message.Alliances = allianceSelection.Alliances
+		message.InvitedTeamId = allianceSelection.InvitedTeamId
+		message.PickTimeRemainingSec = allianceSelection.PickTimeRemainingSec(time.Now())
+		message.PickTimerRunning = !allianceSelection.PickTimerStartedAt.IsZero()
+	}
+	return &message
 }
 
 func (arena *Arena) generateAllianceStationDisplayModeMessage() interface{} {
@@ -104,6 +133,32 @@ func (arena *Arena) generateAudienceDisplayModeMessage() interface{} {
 	return arena.AudienceDisplayMode
 }
 
+func (arena *Arena) generateAwardMessage() interface{} {
+	message := struct {
+		Award           *model.Award
+		Winners         []tournament.AwardWinnerPresentation
+		WinnersRevealed bool
+	}{arena.Award, []tournament.AwardWinnerPresentation{}, arena.AwardWinnersRevealed}
+	if arena.Award != nil {
+		winners, err := tournament.GetAwardWinnerPresentations(arena.Database, arena.Award.Id)
+		if err != nil {
+			log.Printf("Failed to load award winners: %s", err.Error())
+		} else {
+			message.Winners = winners
+		}
+	}
+	return &message
+}
+
+func (arena *Arena) generateBracketMessage() interface{} {
+	bracket, err := tournament.BuildBracket(arena.Database)
+	if err != nil {
+		log.Printf("Failed to build elimination bracket: %s", err.Error())
+		return &tournament.Bracket{Rounds: []tournament.BracketRound{}}
+	}
+	return bracket
+}
+
 func (arena *Arena) generateDisplayConfigurationMessage() interface{} {
 	displayUrls := make(map[string]string)
 	for displayId, display := range arena.Displays {
@@ -113,7 +168,7 @@ func (arena *Arena) generateDisplayConfigurationMessage() interface{} {
 }
 
 func (arena *Arena) generateLedModeMessage() interface{} {
-	return &LedModeMessage{arena.ScaleLeds.GetCurrentMode(), arena.RedVaultLeds.CurrentForceMode}
+	return &LedModeMessage{arena.ScaleLeds.GetCurrentSequence(), arena.RedVaultLeds.CurrentForceMode}
 }
 
 func (arena *Arena) generateLowerThirdMessage() interface{} {
@@ -150,6 +205,17 @@ func (arena *Arena) generateMatchTimingMessage() interface{} {
 	return &game.MatchTiming
 }
 
+func (arena *Arena) generateRankingsMessage() interface{} {
+	rankings, err := arena.Database.GetAllRankings()
+	if err != nil {
+		log.Printf("Failed to load rankings: %s", err.Error())
+	}
+	if rankings == nil {
+		rankings = []game.Ranking{}
+	}
+	return rankings
+}
+
 func (arena *Arena) generateRealtimeScoreMessage() interface{} {
 	fields := struct {
 		Red          *audienceAllianceScoreFields
@@ -164,6 +230,21 @@ func (arena *Arena) generateRealtimeScoreMessage() interface{} {
 	return &fields
 }
 
+func (arena *Arena) generateScheduleMessage() interface{} {
+	schedule := make(map[string][]model.Match)
+	for _, matchType := range []string{"practice", "qualification", "elimination"} {
+		matches, err := arena.Database.GetMatchesByType(matchType)
+		if err != nil {
+			log.Printf("Failed to load %s matches: %s", matchType, err.Error())
+		}
+		if matches == nil {
+			matches = []model.Match{}
+		}
+		schedule[matchType] = matches
+	}
+	return schedule
+}
+
 func (arena *Arena) generateScorePostedMessage() interface{} {
 	// For elimination matches, summarize the state of the series.
 	var seriesStatus, seriesLeader string
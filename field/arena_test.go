@@ -5,6 +5,7 @@ package field
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/stretchr/testify/assert"
@@ -466,6 +467,20 @@ func TestSubstituteTeam(t *testing.T) {
 	arena.Database.CreateMatch(&match)
 	arena.LoadMatch(&match)
 	assert.Nil(t, arena.SubstituteTeam(107, "R1"))
+
+	// Check that a member of one alliance can't be substituted into another alliance's station.
+	arena.Database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 1, PickPosition: 0, TeamId: 102})
+	arena.Database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 1, PickPosition: 1, TeamId: 103})
+	arena.Database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 2, PickPosition: 0, TeamId: 104})
+	arena.Database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 2, PickPosition: 1, TeamId: 105})
+	arena.Database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 2, PickPosition: 2, TeamId: 101})
+	err = arena.SubstituteTeam(101, "R1")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Team 101 is a member of alliance 2 and can't play for alliance 1.")
+	}
+	assert.Equal(t, 107, arena.CurrentMatch.Red1)
+	assert.Nil(t, arena.SubstituteTeam(101, "B3"))
+	assert.Equal(t, 101, arena.CurrentMatch.Blue3)
 }
 
 func TestSetupNetwork(t *testing.T) {
@@ -632,3 +647,23 @@ func TestSaveTeamHasConnected(t *testing.T) {
 		assert.Equal(t, "San Jose", teams[5].City)
 	}
 }
+
+func TestRunOnArenaLoop(t *testing.T) {
+	arena := setupTestArena(t)
+
+	result := make(chan error)
+	go func() {
+		result <- arena.runOnArenaLoop(func() error {
+			arena.AudienceDisplayMode = "score"
+			return fmt.Errorf("oops")
+		})
+	}()
+	for arena.AudienceDisplayMode != "score" {
+		arena.Update()
+		time.Sleep(time.Millisecond)
+	}
+	err := <-result
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "oops", err.Error())
+	}
+}
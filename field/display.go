@@ -7,6 +7,8 @@ package field
 
 import (
 	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
 	"math/rand"
 	"net/url"
 	"reflect"
@@ -14,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -33,6 +36,8 @@ const (
 	PitDisplay
 	QueueingDisplay
 	TwitchStreamDisplay
+	BracketDisplay
+	FieldVisualizerDisplay
 )
 
 var DisplayTypeNames = map[DisplayType]string{
@@ -44,6 +49,8 @@ var DisplayTypeNames = map[DisplayType]string{
 	PitDisplay:             "Pit",
 	QueueingDisplay:        "Queueing",
 	TwitchStreamDisplay:    "Twitch Stream",
+	BracketDisplay:         "Bracket",
+	FieldVisualizerDisplay: "Field Visualizer",
 }
 
 var displayTypePaths = map[DisplayType]string{
@@ -55,6 +62,8 @@ var displayTypePaths = map[DisplayType]string{
 	PitDisplay:             "/displays/pit",
 	QueueingDisplay:        "/displays/queueing",
 	TwitchStreamDisplay:    "/displays/twitch",
+	BracketDisplay:         "/displays/bracket",
+	FieldVisualizerDisplay: "/displays/field_visualizer",
 }
 
 var displayRegistryMutex sync.Mutex
@@ -65,6 +74,8 @@ type Display struct {
 	Type            DisplayType
 	Configuration   map[string]string
 	IpAddress       string
+	LastSeenTime    time.Time
+	GroupId         int // Zero if the display isn't part of a group.
 	ConnectionCount int
 }
 
@@ -141,8 +152,8 @@ func (arena *Arena) NextDisplayId() string {
 	}
 }
 
-// Adds the given display to the arena registry and triggers a notification.
-func (arena *Arena) RegisterDisplay(display *Display) {
+// Adds the given display to the arena registry, saves it to the database and triggers a notification.
+func (arena *Arena) RegisterDisplay(display *Display) error {
 	displayRegistryMutex.Lock()
 	defer displayRegistryMutex.Unlock()
 
@@ -150,19 +161,27 @@ func (arena *Arena) RegisterDisplay(display *Display) {
 	if ok && display.Type == PlaceholderDisplay {
 		// Don't rewrite the registered configuration if the new one is a placeholder -- if it is reconnecting after a
 		// restart, it should adopt the existing configuration.
-		arena.Displays[display.Id].ConnectionCount++
+		existingDisplay.ConnectionCount++
+		existingDisplay.IpAddress = display.IpAddress
+		existingDisplay.LastSeenTime = time.Now()
+		display = existingDisplay
 	} else {
 		if ok {
 			display.ConnectionCount = existingDisplay.ConnectionCount + 1
+			display.GroupId = existingDisplay.GroupId
 		} else {
 			display.ConnectionCount = 1
 		}
+		display.LastSeenTime = time.Now()
 		arena.Displays[display.Id] = display
 	}
+	err := arena.saveDisplay(display)
 	arena.DisplayConfigurationNotifier.Notify()
+	return err
 }
 
-// Updates the given display in the arena registry. Triggers a notification if the display configuration changed.
+// Updates the given display in the arena registry and the database. Triggers a notification if the display
+// configuration changed.
 func (arena *Arena) UpdateDisplay(display *Display) error {
 	displayRegistryMutex.Lock()
 	defer displayRegistryMutex.Unlock()
@@ -171,28 +190,95 @@ func (arena *Arena) UpdateDisplay(display *Display) error {
 	if !ok {
 		return fmt.Errorf("Display %s doesn't exist.", display.Id)
 	}
+	display.IpAddress = existingDisplay.IpAddress
+	display.LastSeenTime = existingDisplay.LastSeenTime
 	display.ConnectionCount = existingDisplay.ConnectionCount
 	if !reflect.DeepEqual(existingDisplay, display) {
 		arena.Displays[display.Id] = display
+		err := arena.saveDisplay(display)
 		arena.DisplayConfigurationNotifier.Notify()
+		return err
 	}
 	return nil
 }
 
+// Removes the given display from the arena registry and the database and triggers a notification. Only displays that
+// aren't currently connected can be removed.
+func (arena *Arena) DeleteDisplay(displayId string) error {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	existingDisplay, ok := arena.Displays[displayId]
+	if !ok {
+		return fmt.Errorf("Display %s doesn't exist.", displayId)
+	}
+	if existingDisplay.ConnectionCount > 0 {
+		return fmt.Errorf("Display %s can't be removed while it is connected.", displayId)
+	}
+	if err := arena.Database.DeleteDisplay(existingDisplay.toModel()); err != nil {
+		return err
+	}
+	delete(arena.Displays, displayId)
+	arena.DisplayConfigurationNotifier.Notify()
+	return nil
+}
+
 // Marks the given display as having disconnected in the arena registry and triggers a notification.
 func (arena *Arena) MarkDisplayDisconnected(display *Display) {
 	displayRegistryMutex.Lock()
 	defer displayRegistryMutex.Unlock()
 
 	if existingDisplay, ok := arena.Displays[display.Id]; ok {
+		var err error
 		if existingDisplay.Type == PlaceholderDisplay && existingDisplay.Nickname == "" &&
 			len(existingDisplay.Configuration) == 0 {
 			// If the display is an unconfigured placeholder, just remove it entirely to prevent clutter.
 			delete(arena.Displays, existingDisplay.Id)
+			err = arena.Database.DeleteDisplay(existingDisplay.toModel())
 		} else {
 			existingDisplay.ConnectionCount -= 1
+			existingDisplay.LastSeenTime = time.Now()
+			err = arena.saveDisplay(existingDisplay)
 			arena.DisplayConfigurationNotifier.Notify()
-
+		}
+		if err != nil {
+			log.Printf("Failed to save display %s: %s", display.Id, err.Error())
 		}
 	}
 }
+
+// Populates the arena registry with the displays saved in the database, so that they show up as disconnected and
+// adopt their previous configuration when they reconnect.
+func (arena *Arena) loadDisplays() error {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	displays, err := arena.Database.GetAllDisplays()
+	if err != nil {
+		return err
+	}
+	for _, display := range displays {
+		arena.Displays[display.Id] = &Display{Id: display.Id, Nickname: display.Nickname,
+			Type: DisplayType(display.Type), Configuration: display.Configuration, IpAddress: display.IpAddress,
+			LastSeenTime: display.LastSeenTime, GroupId: display.GroupId}
+	}
+	return nil
+}
+
+// Creates or updates the database record for the given display.
+func (arena *Arena) saveDisplay(display *Display) error {
+	existingDisplay, err := arena.Database.GetDisplayById(display.Id)
+	if err != nil {
+		return err
+	}
+	if existingDisplay == nil {
+		return arena.Database.CreateDisplay(display.toModel())
+	}
+	return arena.Database.SaveDisplay(display.toModel())
+}
+
+func (display *Display) toModel() *model.Display {
+	return &model.Display{Id: display.Id, Nickname: display.Nickname, Type: int(display.Type),
+		Configuration: display.Configuration, IpAddress: display.IpAddress, LastSeenTime: display.LastSeenTime,
+		GroupId: display.GroupId}
+}
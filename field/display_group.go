@@ -0,0 +1,157 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Methods for configuring groups of displays together and rotating them through playlists.
+
+package field
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"reflect"
+	"time"
+)
+
+const displayPlaylistPeriodMs = 1000
+
+// Tracks the progress of a display group through its playlist.
+type displayPlaylistState struct {
+	playlistId    int
+	stepIndex     int
+	stepStartTime time.Time
+}
+
+// Pushes the type and configuration of the given group out to all of its displays. If the group has a playlist, the
+// playlist is restarted from its first step instead.
+func (arena *Arena) ApplyDisplayGroup(groupId int) error {
+	group, err := arena.Database.GetDisplayGroupById(groupId)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return fmt.Errorf("Display group %d doesn't exist.", groupId)
+	}
+
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	delete(arena.displayPlaylistStates, group.Id)
+	if group.PlaylistId != 0 {
+		return arena.advanceDisplayPlaylist(group, time.Now())
+	}
+	return arena.configureGroupDisplays(group.Id, DisplayType(group.Type), group.Configuration)
+}
+
+// Deletes the given group and removes all of its displays from it, leaving them with their current configuration.
+func (arena *Arena) DeleteDisplayGroup(group *model.DisplayGroup) error {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	if err := arena.Database.DeleteDisplayGroup(group); err != nil {
+		return err
+	}
+	delete(arena.displayPlaylistStates, group.Id)
+	for _, display := range arena.Displays {
+		if display.GroupId == group.Id {
+			display.GroupId = 0
+			if err := arena.saveDisplay(display); err != nil {
+				return err
+			}
+		}
+	}
+	arena.DisplayConfigurationNotifier.Notify()
+	return nil
+}
+
+// Loops indefinitely to rotate the display groups that have playlists.
+func (arena *Arena) runDisplayPlaylists() {
+	for {
+		if err := arena.updateDisplayPlaylists(time.Now()); err != nil {
+			log.Printf("Failed to update display playlists: %s", err.Error())
+		}
+		time.Sleep(time.Millisecond * displayPlaylistPeriodMs)
+	}
+}
+
+// Moves each display group that has a playlist on to the next step if the current one has been shown for long enough,
+// and makes sure that all of the group's displays are showing the current step.
+func (arena *Arena) updateDisplayPlaylists(now time.Time) error {
+	groups, err := arena.Database.GetAllDisplayGroups()
+	if err != nil {
+		return err
+	}
+
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	groupsWithPlaylists := make(map[int]bool)
+	for i := range groups {
+		if groups[i].PlaylistId == 0 {
+			continue
+		}
+		groupsWithPlaylists[groups[i].Id] = true
+		if err = arena.advanceDisplayPlaylist(&groups[i], now); err != nil {
+			return err
+		}
+	}
+
+	// Forget the progress of any groups that have been deleted or taken off of their playlist.
+	for groupId := range arena.displayPlaylistStates {
+		if !groupsWithPlaylists[groupId] {
+			delete(arena.displayPlaylistStates, groupId)
+		}
+	}
+	return nil
+}
+
+// Must be called with the display registry mutex held.
+func (arena *Arena) advanceDisplayPlaylist(group *model.DisplayGroup, now time.Time) error {
+	playlist, err := arena.Database.GetDisplayPlaylistById(group.PlaylistId)
+	if err != nil {
+		return err
+	}
+	if playlist == nil || len(playlist.Steps) == 0 {
+		delete(arena.displayPlaylistStates, group.Id)
+		return nil
+	}
+
+	state, ok := arena.displayPlaylistStates[group.Id]
+	if !ok || state.playlistId != playlist.Id || state.stepIndex >= len(playlist.Steps) {
+		state = &displayPlaylistState{playlistId: playlist.Id, stepStartTime: now}
+		arena.displayPlaylistStates[group.Id] = state
+	} else if now.Sub(state.stepStartTime) >= time.Duration(playlist.Steps[state.stepIndex].DwellTimeSec)*time.Second {
+		state.stepIndex = (state.stepIndex + 1) % len(playlist.Steps)
+		state.stepStartTime = now
+	}
+	step := playlist.Steps[state.stepIndex]
+	return arena.configureGroupDisplays(group.Id, DisplayType(step.Type), step.Configuration)
+}
+
+// Sets the type and configuration of every display in the given group, triggering a notification if any of them
+// changed. Must be called with the display registry mutex held.
+func (arena *Arena) configureGroupDisplays(groupId int, displayType DisplayType,
+	configuration map[string]string) error {
+	changed := false
+	for _, display := range arena.Displays {
+		if display.GroupId != groupId {
+			continue
+		}
+		if display.Type == displayType && reflect.DeepEqual(display.Configuration, configuration) {
+			continue
+		}
+		display.Type = displayType
+		display.Configuration = make(map[string]string)
+		for key, value := range configuration {
+			display.Configuration[key] = value
+		}
+		if err := arena.saveDisplay(display); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if changed {
+		arena.DisplayConfigurationNotifier.Notify()
+	}
+	return nil
+}
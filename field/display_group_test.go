@@ -0,0 +1,86 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestApplyDisplayGroup(t *testing.T) {
+	arena := setupTestArena(t)
+
+	group := model.DisplayGroup{Name: "Pit TVs", Type: int(PitDisplay),
+		Configuration: map[string]string{"scrollMsPerRow": "1000"}}
+	arena.Database.CreateDisplayGroup(&group)
+	arena.RegisterDisplay(&Display{Id: "100", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+	arena.RegisterDisplay(&Display{Id: "101", Type: AudienceDisplay, Configuration: map[string]string{}})
+	arena.UpdateDisplay(&Display{Id: "100", Nickname: "Pit 1", Type: PlaceholderDisplay, GroupId: group.Id,
+		Configuration: map[string]string{}})
+
+	assert.Nil(t, arena.ApplyDisplayGroup(group.Id))
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+	assert.Equal(t, "Pit 1", arena.Displays["100"].Nickname)
+	assert.Equal(t, "1000", arena.Displays["100"].Configuration["scrollMsPerRow"])
+	assert.Equal(t, AudienceDisplay, arena.Displays["101"].Type)
+	savedDisplay, _ := arena.Database.GetDisplayById("100")
+	assert.Equal(t, int(PitDisplay), savedDisplay.Type)
+
+	// Check that the display stays in its group when it reconnects.
+	arena.RegisterDisplay(&Display{Id: "100", Type: PitDisplay, Configuration: map[string]string{}})
+	assert.Equal(t, group.Id, arena.Displays["100"].GroupId)
+
+	err := arena.ApplyDisplayGroup(group.Id + 1)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "doesn't exist")
+	}
+
+	assert.Nil(t, arena.DeleteDisplayGroup(&group))
+	assert.Equal(t, 0, arena.Displays["100"].GroupId)
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+}
+
+func TestDisplayPlaylist(t *testing.T) {
+	arena := setupTestArena(t)
+
+	playlist := model.DisplayPlaylist{Name: "Pit Rotation", Steps: []model.DisplayPlaylistStep{
+		{Type: int(PitDisplay), Configuration: map[string]string{}, DwellTimeSec: 60},
+		{Type: int(BracketDisplay), Configuration: map[string]string{}, DwellTimeSec: 30},
+	}}
+	arena.Database.CreateDisplayPlaylist(&playlist)
+	group := model.DisplayGroup{Name: "Pit TVs", Type: int(AudienceDisplay), PlaylistId: playlist.Id}
+	arena.Database.CreateDisplayGroup(&group)
+	arena.RegisterDisplay(&Display{Id: "100", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+	arena.UpdateDisplay(&Display{Id: "100", Type: PlaceholderDisplay, GroupId: group.Id,
+		Configuration: map[string]string{}})
+
+	startTime := time.Now()
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime))
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime.Add(59*time.Second)))
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime.Add(60*time.Second)))
+	assert.Equal(t, BracketDisplay, arena.Displays["100"].Type)
+
+	// A display joining the group mid-step should pick up the current step.
+	arena.RegisterDisplay(&Display{Id: "101", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+	arena.UpdateDisplay(&Display{Id: "101", Type: PlaceholderDisplay, GroupId: group.Id,
+		Configuration: map[string]string{}})
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime.Add(70*time.Second)))
+	assert.Equal(t, BracketDisplay, arena.Displays["101"].Type)
+
+	// Check that the playlist wraps around to the beginning.
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime.Add(90*time.Second)))
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+	assert.Equal(t, PitDisplay, arena.Displays["101"].Type)
+
+	// Check that taking the group off of the playlist stops the rotation.
+	group.PlaylistId = 0
+	arena.Database.SaveDisplayGroup(&group)
+	assert.Nil(t, arena.updateDisplayPlaylists(startTime.Add(200*time.Second)))
+	assert.Equal(t, PitDisplay, arena.Displays["100"].Type)
+	assert.Empty(t, arena.displayPlaylistStates)
+}
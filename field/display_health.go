@@ -0,0 +1,186 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Methods for tracking the heartbeats sent by the display clients and flagging displays that appear to be frozen or
+// running an old version of their page.
+
+package field
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	displayHealthCheckPeriodMs = 1000
+	displayHeartbeatTimeoutSec = 30    // Display clients send a heartbeat every 10 seconds.
+	displayMaxRenderLagMs      = 15000 // Browsers stop rendering tabs that are hidden or hung.
+)
+
+const (
+	DisplayHealthy  = "healthy"
+	DisplayStale    = "stale"
+	DisplayOutdated = "outdated"
+	DisplayOffline  = "offline"
+)
+
+// Represents a heartbeat message sent periodically by a display client. Times are according to the client's clock.
+type DisplayHeartbeat struct {
+	RenderTime   int64 // Time in milliseconds at which the page last rendered a frame.
+	SentTime     int64 // Time in milliseconds at which the heartbeat was sent.
+	PageVersion  string
+	ScreenWidth  int
+	ScreenHeight int
+}
+
+// Guards the display health records separately from the registry so that the notifier can read them while the registry
+// mutex is held.
+var displayHealthMutex sync.Mutex
+
+type DisplayHealth struct {
+	Status            string
+	PageVersion       string
+	ScreenWidth       int
+	ScreenHeight      int
+	RenderLagMs       int64  // How long before the last heartbeat the page last rendered a frame.
+	LatencyMs         int64  // Websocket round-trip time measured by the server following the last heartbeat.
+	AudioError        string // Why the display failed to play the last sound, or blank if it played successfully.
+	LastHeartbeatTime time.Time
+}
+
+// Records the given heartbeat from the given display and triggers a notification.
+func (arena *Arena) RecordDisplayHeartbeat(displayId string, heartbeat *DisplayHeartbeat) {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	display, ok := arena.Displays[displayId]
+	if !ok {
+		return
+	}
+	displayHealthMutex.Lock()
+	health := arena.getDisplayHealth(displayId)
+	health.PageVersion = heartbeat.PageVersion
+	health.ScreenWidth = heartbeat.ScreenWidth
+	health.ScreenHeight = heartbeat.ScreenHeight
+	health.RenderLagMs = heartbeat.SentTime - heartbeat.RenderTime
+	if health.RenderLagMs < 0 {
+		health.RenderLagMs = 0
+	}
+	health.LastHeartbeatTime = time.Now()
+	health.Status = arena.displayHealthStatus(display, health, health.LastHeartbeatTime)
+	displayHealthMutex.Unlock()
+	arena.DisplayHealthNotifier.Notify()
+}
+
+// Records the websocket round-trip time measured for the given display and triggers a notification.
+func (arena *Arena) RecordDisplayLatency(displayId string, latency time.Duration) {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	if _, ok := arena.Displays[displayId]; !ok {
+		return
+	}
+	displayHealthMutex.Lock()
+	arena.getDisplayHealth(displayId).LatencyMs = int64(latency / time.Millisecond)
+	displayHealthMutex.Unlock()
+	arena.DisplayHealthNotifier.Notify()
+}
+
+// Records whether the given display succeeded in playing the last sound and triggers a notification if that changed,
+// since browsers can refuse to play audio without any visible sign.
+func (arena *Arena) RecordDisplayAudioStatus(displayId string, audioError string) {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	if _, ok := arena.Displays[displayId]; !ok {
+		return
+	}
+	displayHealthMutex.Lock()
+	health := arena.getDisplayHealth(displayId)
+	changed := health.AudioError != audioError
+	health.AudioError = audioError
+	displayHealthMutex.Unlock()
+	if changed {
+		arena.DisplayHealthNotifier.Notify()
+	}
+}
+
+// Loops indefinitely to flag displays that have stopped sending heartbeats.
+func (arena *Arena) runDisplayHealthChecks() {
+	for {
+		arena.checkDisplayHealth(time.Now())
+		time.Sleep(time.Millisecond * displayHealthCheckPeriodMs)
+	}
+}
+
+// Re-evaluates the status of every display and triggers a notification if any of them changed.
+func (arena *Arena) checkDisplayHealth(now time.Time) {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	displayHealthMutex.Lock()
+	changed := false
+	for displayId, display := range arena.Displays {
+		health := arena.getDisplayHealth(displayId)
+		if status := arena.displayHealthStatus(display, health, now); status != health.Status {
+			health.Status = status
+			changed = true
+		}
+	}
+	for displayId := range arena.displayHealths {
+		if _, ok := arena.Displays[displayId]; !ok {
+			delete(arena.displayHealths, displayId)
+			changed = true
+		}
+	}
+	displayHealthMutex.Unlock()
+	if changed {
+		arena.DisplayHealthNotifier.Notify()
+	}
+}
+
+// Returns the health record for the given display, creating it if necessary. Must be called with the display health
+// mutex held.
+func (arena *Arena) getDisplayHealth(displayId string) *DisplayHealth {
+	health, ok := arena.displayHealths[displayId]
+	if !ok {
+		health = &DisplayHealth{}
+		arena.displayHealths[displayId] = health
+	}
+	return health
+}
+
+func (arena *Arena) displayHealthStatus(display *Display, health *DisplayHealth, now time.Time) string {
+	if display.ConnectionCount <= 0 {
+		return DisplayOffline
+	}
+
+	// Measure the heartbeat timeout from when the display connected if it hasn't sent one since.
+	lastHeartbeatTime := health.LastHeartbeatTime
+	if display.LastSeenTime.After(lastHeartbeatTime) {
+		lastHeartbeatTime = display.LastSeenTime
+	}
+	if now.Sub(lastHeartbeatTime) > displayHeartbeatTimeoutSec*time.Second ||
+		health.RenderLagMs > displayMaxRenderLagMs {
+		return DisplayStale
+	}
+	if health.LastHeartbeatTime.IsZero() {
+		// Give a newly connected display the chance to send its first heartbeat.
+		return DisplayHealthy
+	}
+	if health.PageVersion != arena.DisplayPageVersion {
+		return DisplayOutdated
+	}
+	return DisplayHealthy
+}
+
+func (arena *Arena) generateDisplayHealthMessage() interface{} {
+	displayHealthMutex.Lock()
+	defer displayHealthMutex.Unlock()
+
+	displayHealths := make(map[string]DisplayHealth)
+	for displayId, health := range arena.displayHealths {
+		displayHealths[displayId] = *health
+	}
+	return displayHealths
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDisplayHeartbeat(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.DisplayPageVersion = "abc123"
+	arena.RegisterDisplay(&Display{Id: "254", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+
+	heartbeat := DisplayHeartbeat{RenderTime: 1000, SentTime: 1200, PageVersion: "abc123", ScreenWidth: 1920,
+		ScreenHeight: 1080}
+	arena.RecordDisplayHeartbeat("254", &heartbeat)
+	arena.RecordDisplayLatency("254", 25*time.Millisecond)
+	health := arena.generateDisplayHealthMessage().(map[string]DisplayHealth)["254"]
+	assert.Equal(t, DisplayHealthy, health.Status)
+	assert.Equal(t, "abc123", health.PageVersion)
+	assert.Equal(t, 1920, health.ScreenWidth)
+	assert.Equal(t, 1080, health.ScreenHeight)
+	assert.Equal(t, int64(200), health.RenderLagMs)
+	assert.Equal(t, int64(25), health.LatencyMs)
+	assert.Equal(t, "", health.AudioError)
+
+	arena.RecordDisplayAudioStatus("254", "NotAllowedError: play() failed because the user didn't interact")
+	health = arena.generateDisplayHealthMessage().(map[string]DisplayHealth)["254"]
+	assert.Contains(t, health.AudioError, "NotAllowedError")
+	assert.Equal(t, DisplayHealthy, health.Status)
+	arena.RecordDisplayAudioStatus("254", "")
+	assert.Equal(t, "", arena.generateDisplayHealthMessage().(map[string]DisplayHealth)["254"].AudioError)
+
+	// Check that heartbeats from unknown displays are ignored.
+	arena.RecordDisplayHeartbeat("1114", &DisplayHeartbeat{PageVersion: "abc123"})
+	arena.RecordDisplayLatency("1114", time.Millisecond)
+	arena.RecordDisplayAudioStatus("1114", "Failed")
+	assert.NotContains(t, arena.generateDisplayHealthMessage(), "1114")
+}
+
+func TestDisplayHealthStatus(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.DisplayPageVersion = "abc123"
+	arena.RegisterDisplay(&Display{Id: "254", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+	getStatus := func() string {
+		return arena.generateDisplayHealthMessage().(map[string]DisplayHealth)["254"].Status
+	}
+
+	// A newly connected display gets some time to send its first heartbeat.
+	arena.checkDisplayHealth(time.Now())
+	assert.Equal(t, DisplayHealthy, getStatus())
+	arena.checkDisplayHealth(time.Now().Add(displayHeartbeatTimeoutSec*time.Second + time.Second))
+	assert.Equal(t, DisplayStale, getStatus())
+
+	arena.RecordDisplayHeartbeat("254", &DisplayHeartbeat{RenderTime: 1000, SentTime: 1000, PageVersion: "abc123"})
+	assert.Equal(t, DisplayHealthy, getStatus())
+	arena.checkDisplayHealth(time.Now().Add(displayHeartbeatTimeoutSec*time.Second - time.Second))
+	assert.Equal(t, DisplayHealthy, getStatus())
+	arena.checkDisplayHealth(time.Now().Add(displayHeartbeatTimeoutSec*time.Second + time.Second))
+	assert.Equal(t, DisplayStale, getStatus())
+
+	// Check that a display which is still connected but no longer rendering is flagged.
+	arena.RecordDisplayHeartbeat("254",
+		&DisplayHeartbeat{RenderTime: 1000, SentTime: 1000 + displayMaxRenderLagMs + 1, PageVersion: "abc123"})
+	assert.Equal(t, DisplayStale, getStatus())
+
+	arena.RecordDisplayHeartbeat("254", &DisplayHeartbeat{RenderTime: 1000, SentTime: 1000, PageVersion: "old"})
+	assert.Equal(t, DisplayOutdated, getStatus())
+
+	arena.MarkDisplayDisconnected(&Display{Id: "254"})
+	arena.checkDisplayHealth(time.Now())
+	assert.NotContains(t, arena.generateDisplayHealthMessage(), "254")
+
+	// Check that a configured display remains listed as offline after it disconnects.
+	arena.RegisterDisplay(&Display{Id: "254", Type: PlaceholderDisplay, Configuration: map[string]string{}})
+	arena.UpdateDisplay(&Display{Id: "254", Nickname: "Pit 1", Type: PitDisplay, Configuration: map[string]string{}})
+	arena.MarkDisplayDisconnected(&Display{Id: "254"})
+	arena.checkDisplayHealth(time.Now())
+	assert.Equal(t, DisplayOffline, getStatus())
+}
@@ -124,3 +124,59 @@ func TestDisplayUpdateError(t *testing.T) {
 		assert.Contains(t, err.Error(), "doesn't exist")
 	}
 }
+
+func TestDisplayPersistence(t *testing.T) {
+	arena := setupTestArena(t)
+
+	display := &Display{Id: "254", Type: PitDisplay, IpAddress: "10.0.100.20",
+		Configuration: map[string]string{"scrollMsPerRow": "1000"}}
+	assert.Nil(t, arena.RegisterDisplay(display))
+	assert.Nil(t, arena.UpdateDisplay(&Display{Id: "254", Nickname: "Pit Left", Type: PitDisplay,
+		Configuration: map[string]string{"scrollMsPerRow": "500"}}))
+	assert.Equal(t, "10.0.100.20", arena.Displays["254"].IpAddress)
+	arena.MarkDisplayDisconnected(display)
+
+	// An unconfigured placeholder shouldn't be kept around once it disconnects.
+	placeholder := &Display{Id: "148", Type: PlaceholderDisplay, Configuration: map[string]string{}}
+	assert.Nil(t, arena.RegisterDisplay(placeholder))
+	savedDisplay, _ := arena.Database.GetDisplayById("148")
+	assert.NotNil(t, savedDisplay)
+	arena.MarkDisplayDisconnected(placeholder)
+	savedDisplay, _ = arena.Database.GetDisplayById("148")
+	assert.Nil(t, savedDisplay)
+
+	// Simulate a restart and check that the display is still known and keeps its configuration when it reconnects.
+	arena, err := NewArena(arena.Database.Path)
+	assert.Nil(t, err)
+	if assert.Contains(t, arena.Displays, "254") {
+		assert.Equal(t, "Pit Left", arena.Displays["254"].Nickname)
+		assert.Equal(t, PitDisplay, arena.Displays["254"].Type)
+		assert.Equal(t, "500", arena.Displays["254"].Configuration["scrollMsPerRow"])
+		assert.Equal(t, "10.0.100.20", arena.Displays["254"].IpAddress)
+		assert.False(t, arena.Displays["254"].LastSeenTime.IsZero())
+		assert.Equal(t, 0, arena.Displays["254"].ConnectionCount)
+	}
+	assert.NotContains(t, arena.Displays, "148")
+	display = &Display{Id: "254", Type: PlaceholderDisplay, IpAddress: "10.0.100.21",
+		Configuration: map[string]string{}}
+	assert.Nil(t, arena.RegisterDisplay(display))
+	assert.Equal(t, PitDisplay, arena.Displays["254"].Type)
+	assert.Equal(t, "/displays/pit?displayId=254&nickname=Pit+Left&scrollMsPerRow=500", arena.Displays["254"].ToUrl())
+	assert.Equal(t, "10.0.100.21", arena.Displays["254"].IpAddress)
+	assert.Equal(t, 1, arena.Displays["254"].ConnectionCount)
+
+	// Check that a display can only be forgotten once it has disconnected.
+	err = arena.DeleteDisplay("254")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "while it is connected")
+	}
+	arena.MarkDisplayDisconnected(display)
+	assert.Nil(t, arena.DeleteDisplay("254"))
+	assert.NotContains(t, arena.Displays, "254")
+	savedDisplay, _ = arena.Database.GetDisplayById("254")
+	assert.Nil(t, savedDisplay)
+	err = arena.DeleteDisplay("254")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "doesn't exist")
+	}
+}
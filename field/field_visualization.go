@@ -0,0 +1,99 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Publishing of the live LED pixel values and field element states for the virtual field visualizer.
+
+package field
+
+import (
+	"encoding/hex"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/vaultled"
+	"time"
+)
+
+// The minimum time between field visualization updates, to limit the load on the clients from animated LED sequences.
+const fieldVisualizationPeriodMs = 100
+
+type FieldVisualizationMessage struct {
+	MatchState
+	Scale      SeesawVisualization
+	RedSwitch  SeesawVisualization
+	BlueSwitch SeesawVisualization
+	RedVault   VaultVisualization
+	BlueVault  VaultVisualization
+}
+
+// Pixel values are encoded as strings of six hex digits per pixel, to keep the messages small.
+type SeesawVisualization struct {
+	OwnedBy     game.Alliance
+	NearIsRed   bool
+	LedSequence string
+	NearPixels  string
+	FarPixels   string
+}
+
+type VaultVisualization struct {
+	ForceCubes     int
+	LevitateCubes  int
+	BoostCubes     int
+	LevitatePlayed bool
+	ForceMode      vaultled.Mode
+	LevitateMode   vaultled.Mode
+	BoostMode      vaultled.Mode
+	Pixels         string
+}
+
+func (arena *Arena) generateFieldVisualizationMessage() interface{} {
+	message := arena.getFieldVisualization()
+	return &message
+}
+
+// Checks the field visualization at most once per update period and sends it to the clients if it has changed.
+func (arena *Arena) handleFieldVisualization() {
+	now := time.Now()
+	if now.Sub(arena.lastFieldVisualizationTime).Seconds()*1000 < fieldVisualizationPeriodMs {
+		return
+	}
+	arena.lastFieldVisualizationTime = now
+	message := arena.getFieldVisualization()
+	if message != arena.lastFieldVisualization {
+		arena.lastFieldVisualization = message
+		arena.FieldVisualizationNotifier.NotifyWithMessage(&message)
+	}
+}
+
+// Returns a snapshot of the current state of the scale, switches and vaults along with their LED pixel values.
+func (arena *Arena) getFieldVisualization() FieldVisualizationMessage {
+	return FieldVisualizationMessage{
+		MatchState: arena.MatchState,
+		Scale:      getSeesawVisualization(arena.Scale, &arena.ScaleLeds),
+		RedSwitch:  getSeesawVisualization(arena.RedSwitch, &arena.RedSwitchLeds),
+		BlueSwitch: getSeesawVisualization(arena.BlueSwitch, &arena.BlueSwitchLeds),
+		RedVault:   getVaultVisualization(arena.RedVault, &arena.RedVaultLeds),
+		BlueVault:  getVaultVisualization(arena.BlueVault, &arena.BlueVaultLeds),
+	}
+}
+
+func getSeesawVisualization(seesaw *game.Seesaw, leds *led.Controller) SeesawVisualization {
+	nearPixels, farPixels := leds.GetPixels()
+	return SeesawVisualization{OwnedBy: seesaw.GetOwnedBy(), NearIsRed: seesaw.NearIsRed,
+		LedSequence: leds.GetCurrentSequence(), NearPixels: encodePixels(nearPixels),
+		FarPixels: encodePixels(farPixels)}
+}
+
+func getVaultVisualization(vault *game.Vault, leds *vaultled.Controller) VaultVisualization {
+	return VaultVisualization{ForceCubes: vault.ForceCubes, LevitateCubes: vault.LevitateCubes,
+		BoostCubes: vault.BoostCubes, LevitatePlayed: vault.LevitatePlayed, ForceMode: leds.CurrentForceMode,
+		LevitateMode: leds.CurrentLevitateMode, BoostMode: leds.CurrentBoostMode,
+		Pixels: encodePixels(leds.GetPixels())}
+}
+
+func encodePixels(pixels [][3]byte) string {
+	data := make([]byte, 0, 3*len(pixels))
+	for _, pixel := range pixels {
+		data = append(data, pixel[:]...)
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/vaultled"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldVisualization(t *testing.T) {
+	arena := setupTestArena(t)
+
+	// Check that the pixels are published even though no LED hardware is configured.
+	arena.handleLeds()
+	arena.handleFieldVisualization()
+	message := arena.lastFieldVisualization
+	assert.Equal(t, PreMatch, message.MatchState)
+	assert.Equal(t, 6*led.DefaultNumPixels, len(message.Scale.NearPixels))
+	assert.Equal(t, 6*led.DefaultNumPixels, len(message.RedSwitch.FarPixels))
+	assert.Equal(t, strings.Repeat("000000", 17), message.RedVault.Pixels)
+	assert.Equal(t, game.NeitherAlliance, message.Scale.OwnedBy)
+
+	// Check that changes are held back until the update period has elapsed.
+	arena.RedVault.ForceCubes = 2
+	arena.RedVaultLeds.SetForceMode(vaultled.TwoCubeMode)
+	arena.Scale.NearIsRed = true
+	arena.Scale.UpdateState([2]bool{true, false}, time.Now())
+	arena.handleFieldVisualization()
+	assert.Equal(t, message, arena.lastFieldVisualization)
+	arena.lastFieldVisualizationTime = time.Now().Add(-fieldVisualizationPeriodMs * time.Millisecond)
+	arena.handleFieldVisualization()
+	message = arena.lastFieldVisualization
+	assert.Equal(t, 2, message.RedVault.ForceCubes)
+	assert.Equal(t, vaultled.TwoCubeMode, message.RedVault.ForceMode)
+	assert.Equal(t, "000000ffff00ffff00", message.RedVault.Pixels[0:18])
+	assert.Equal(t, game.RedAlliance, message.Scale.OwnedBy)
+	assert.True(t, message.Scale.NearIsRed)
+	assert.Equal(t, message, *arena.generateFieldVisualizationMessage().(*FieldVisualizationMessage))
+
+	// Check that an unchanged field still waits out the update period before being checked again.
+	checkTime := time.Now().Add(-fieldVisualizationPeriodMs * time.Millisecond)
+	arena.lastFieldVisualizationTime = checkTime
+	arena.handleFieldVisualization()
+	assert.True(t, arena.lastFieldVisualizationTime.After(checkTime))
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Configuration of the field LED controllers and of the sequences that they show in response to arena and game
+// events.
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Arena and game events that can each be mapped to LED sequences.
+const (
+	LedEventPreMatch             = "preMatch"
+	LedEventRedAllianceNotReady  = "redAllianceNotReady"
+	LedEventBlueAllianceNotReady = "blueAllianceNotReady"
+	LedEventAllianceReady        = "allianceReady"
+	LedEventWarmup               = "warmup"
+	LedEventPause                = "pause"
+	LedEventNotOwned             = "notOwned"
+	LedEventOwned                = "owned"
+	LedEventForce                = "force"
+	LedEventBoost                = "boost"
+	LedEventPostMatch            = "postMatch"
+	LedEventFieldVolunteers      = "fieldVolunteers"
+	LedEventFieldReset           = "fieldReset"
+)
+
+var LedEvents = []string{LedEventPreMatch, LedEventRedAllianceNotReady, LedEventBlueAllianceNotReady,
+	LedEventAllianceReady, LedEventWarmup, LedEventPause, LedEventNotOwned, LedEventOwned, LedEventForce,
+	LedEventBoost, LedEventPostMatch, LedEventFieldVolunteers, LedEventFieldReset}
+
+// The sequences shown for each event unless configured otherwise. Where several are given, one is chosen at random
+// for each match.
+var DefaultLedEventSequences = map[string]string{
+	LedEventPreMatch:             led.OffSequence,
+	LedEventRedAllianceNotReady:  "Red",
+	LedEventBlueAllianceNotReady: "Blue",
+	LedEventAllianceReady:        led.OffSequence,
+	LedEventWarmup:               "Warmup,Warmup Purple,Warmup Sneaky,Warmup Gradient",
+	LedEventPause:                led.OffSequence,
+	LedEventNotOwned:             "Not Owned",
+	LedEventOwned:                "Owned",
+	LedEventForce:                "Force",
+	LedEventBoost:                "Boost",
+	LedEventPostMatch:            "Fade Single",
+	LedEventFieldVolunteers:      "Purple",
+	LedEventFieldReset:           "Green",
+}
+
+// Names of the field elements having LED controllers.
+const (
+	ScaleLedController      = "scale"
+	RedSwitchLedController  = "redSwitch"
+	BlueSwitchLedController = "blueSwitch"
+)
+
+var LedControllers = []string{ScaleLedController, RedSwitchLedController, BlueSwitchLedController}
+
+// Loads or reloads the LED controller configuration, the custom sequences and the mapping of events to sequences.
+func (arena *Arena) LoadLedSettings() error {
+	ledSettings, err := arena.Database.GetLedSettings()
+	if err != nil {
+		return err
+	}
+	customSequences, err := arena.Database.GetAllLedSequences()
+	if err != nil {
+		return err
+	}
+	arena.LedSettings = ledSettings
+
+	// Start with the built-in sequences and let the custom ones override them by name.
+	sequences := make(map[string]*led.Sequence)
+	for i := range led.DefaultSequences {
+		sequence := led.DefaultSequences[i]
+		sequences[sequence.Name] = &sequence
+	}
+	for _, customSequence := range customSequences {
+		sequences[customSequence.Name] = customSequence.Sequence()
+	}
+	arena.ledSequences = sequences
+
+	for name, controller := range arena.ledControllers() {
+		controllerSettings := arena.GetLedControllerSettings(name)
+		if err = controller.Configure(controllerSettings.NumPixels, controllerSettings.NearUniverse,
+			controllerSettings.FarUniverse); err != nil {
+			return err
+		}
+	}
+
+	arena.chooseLedEventSequences(false)
+	return nil
+}
+
+// Reloads the LED settings as above on the arena loop, since the loop is driving the controllers being reconfigured.
+func (arena *Arena) ReloadLedSettings() error {
+	return arena.runOnArenaLoop(arena.LoadLedSettings)
+}
+
+// Returns the configuration of the given LED controller, filling in the defaults for anything that isn't set.
+func (arena *Arena) GetLedControllerSettings(name string) model.LedControllerSettings {
+	controllerSettings := arena.LedSettings.Controllers[name]
+	if controllerSettings.NumPixels == 0 {
+		controllerSettings.NumPixels = led.DefaultNumPixels
+	}
+	if controllerSettings.NearUniverse == 0 {
+		controllerSettings.NearUniverse = led.DefaultNearStripUniverse
+	}
+	if controllerSettings.FarUniverse == 0 {
+		controllerSettings.FarUniverse = led.DefaultFarStripUniverse
+	}
+	return controllerSettings
+}
+
+// Returns the comma-separated names of the sequences mapped to the given event.
+func (arena *Arena) GetLedEventSequenceNames(event string) string {
+	if names, ok := arena.LedSettings.EventSequences[event]; ok {
+		return names
+	}
+	return DefaultLedEventSequences[event]
+}
+
+// Returns the sequence having the given name, or nil if there is none.
+func (arena *Arena) GetLedSequence(name string) *led.Sequence {
+	return arena.ledSequences[name]
+}
+
+// Returns the names of all the available sequences in alphabetical order.
+func (arena *Arena) GetLedSequenceNames() []string {
+	var names []string
+	for name := range arena.ledSequences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the events mapped to the custom sequence having the given name, which would be left referring to a sequence
+// that doesn't exist if it were deleted or renamed. There are none if the custom sequence overrides a built-in one,
+// since the events then fall back to the built-in one.
+func (arena *Arena) GetLedSequenceEvents(name string) []string {
+	for _, sequence := range led.DefaultSequences {
+		if sequence.Name == name {
+			return nil
+		}
+	}
+	var events []string
+	for _, event := range LedEvents {
+		for _, mappedName := range strings.Split(arena.GetLedEventSequenceNames(event), ",") {
+			if strings.TrimSpace(mappedName) == name {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// Updates the events mapped to the given custom sequence to refer to it by its new name, and saves the mapping. Runs
+// on the arena loop since the loop reads the mapping when choosing the sequences for a match.
+func (arena *Arena) RenameLedEventSequence(oldName, newName string) error {
+	return arena.runOnArenaLoop(func() error {
+		for _, event := range arena.GetLedSequenceEvents(oldName) {
+			names := strings.Split(arena.GetLedEventSequenceNames(event), ",")
+			for i, name := range names {
+				if name = strings.TrimSpace(name); name == oldName {
+					name = newName
+				}
+				names[i] = name
+			}
+			arena.LedSettings.EventSequences[event] = strings.Join(names, ",")
+		}
+		return arena.Database.SaveLedSettings(arena.LedSettings)
+	})
+}
+
+// Resolves the sequence to show for each event. Events that are mapped to several sequences get a random one if
+// randomize is true, or the first one otherwise.
+func (arena *Arena) chooseLedEventSequences(randomize bool) {
+	eventSequences := make(map[string]*led.Sequence)
+	for _, event := range LedEvents {
+		var candidates []*led.Sequence
+		for _, name := range strings.Split(arena.GetLedEventSequenceNames(event), ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if sequence, ok := arena.ledSequences[name]; ok {
+				candidates = append(candidates, sequence)
+			} else {
+				log.Printf("Unknown LED sequence '%s' mapped to event %s.", name, event)
+			}
+		}
+		if len(candidates) > 1 && randomize {
+			eventSequences[event] = candidates[rand.Intn(len(candidates))]
+		} else if len(candidates) > 0 {
+			eventSequences[event] = candidates[0]
+		}
+	}
+	arena.ledEventSequences = eventSequences
+}
+
+// Returns the sequence to show for the given event, or nil to turn the LEDs off.
+func (arena *Arena) ledEventSequence(event string) *led.Sequence {
+	return arena.ledEventSequences[event]
+}
+
+func (arena *Arena) ledControllers() map[string]*led.Controller {
+	return map[string]*led.Controller{ScaleLedController: &arena.ScaleLeds,
+		RedSwitchLedController: &arena.RedSwitchLeds, BlueSwitchLedController: &arena.BlueSwitchLeds}
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLedSettingsDefaults(t *testing.T) {
+	arena := setupTestArena(t)
+
+	assert.Equal(t, model.LedControllerSettings{NumPixels: led.DefaultNumPixels,
+		NearUniverse: led.DefaultNearStripUniverse, FarUniverse: led.DefaultFarStripUniverse},
+		arena.GetLedControllerSettings(ScaleLedController))
+	for _, event := range LedEvents {
+		assert.NotNil(t, arena.ledEventSequence(event), event)
+	}
+	assert.Equal(t, len(led.DefaultSequences), len(arena.GetLedSequenceNames()))
+	assert.Equal(t, "Red", arena.RedSwitchLeds.GetCurrentSequence())
+	assert.Equal(t, "Blue", arena.BlueSwitchLeds.GetCurrentSequence())
+}
+
+func TestLedEventSequences(t *testing.T) {
+	arena := setupTestArena(t)
+
+	// Override a built-in sequence and map events to custom ones.
+	arena.Database.CreateLedSequence(&model.LedSequence{Name: "Owned", Keyframes: []led.Keyframe{
+		{Effect: led.SolidEffect, Colors: []string{"yellow"}}}})
+	arena.Database.CreateLedSequence(&model.LedSequence{Name: "Sparkle", Keyframes: []led.Keyframe{
+		{Effect: led.RandomEffect, Colors: []string{"white", "black"}, PeriodMs: 50}}})
+	ledSettings, _ := arena.Database.GetLedSettings()
+	ledSettings.Controllers[ScaleLedController] = model.LedControllerSettings{NumPixels: 60, NearUniverse: 3,
+		FarUniverse: 4}
+	ledSettings.EventSequences[LedEventPause] = "Sparkle"
+	ledSettings.EventSequences[LedEventPostMatch] = ""
+	arena.Database.SaveLedSettings(ledSettings)
+	assert.Nil(t, arena.LoadLedSettings())
+
+	assert.Equal(t, 60, arena.GetLedControllerSettings(ScaleLedController).NumPixels)
+	assert.Equal(t, "Sparkle", arena.ledEventSequence(LedEventPause).Name)
+	assert.Nil(t, arena.ledEventSequence(LedEventPostMatch))
+	assert.Equal(t, []led.Keyframe{{Effect: led.SolidEffect, Colors: []string{"yellow"}}},
+		arena.ledEventSequence(LedEventOwned).Keyframes)
+
+	arena.MatchState = PausePeriod
+	arena.handleLeds()
+	assert.Equal(t, "Sparkle", arena.ScaleLeds.GetCurrentSequence())
+	arena.MatchState = PostMatch
+	arena.handleLeds()
+	assert.Equal(t, led.OffSequence, arena.ScaleLeds.GetCurrentSequence())
+	arena.FieldReset = true
+	arena.handleLeds()
+	assert.Equal(t, "Green", arena.ScaleLeds.GetCurrentSequence())
+
+	// Check that teleop sequences follow ownership of the scale.
+	arena.MatchState = TeleopPeriod
+	arena.handleSeesawTeleopLeds(&game.Seesaw{Kind: game.NeitherAlliance}, &arena.ScaleLeds)
+	assert.Equal(t, "Not Owned", arena.ScaleLeds.GetCurrentSequence())
+}
+
+func TestReloadLedSettingsWhileLoopRunning(t *testing.T) {
+	arena := setupTestArena(t)
+	assert.Nil(t, arena.ScaleLeds.SetAddress("127.0.0.1"))
+
+	// Run the arena loop while the number of pixels is changed back and forth.
+	done := make(chan struct{})
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				arena.Update()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	ledSettings, _ := arena.Database.GetLedSettings()
+	for i := 0; i < 10; i++ {
+		numPixels := led.MaxNumPixels
+		if i%2 == 1 {
+			numPixels = 60
+		}
+		ledSettings.Controllers[ScaleLedController] = model.LedControllerSettings{NumPixels: numPixels,
+			NearUniverse: 1, FarUniverse: 2}
+		arena.Database.SaveLedSettings(ledSettings)
+		assert.Nil(t, arena.ReloadLedSettings())
+	}
+
+	// Check that the strips pick up the final number of pixels once the loop has updated them.
+	var nearPixels, farPixels [][3]byte
+	for i := 0; i < 100 && len(nearPixels) != 60; i++ {
+		time.Sleep(time.Millisecond)
+		arena.runOnArenaLoop(func() error {
+			nearPixels, farPixels = arena.ScaleLeds.GetPixels()
+			return nil
+		})
+	}
+	assert.Equal(t, 60, len(nearPixels))
+	assert.Equal(t, 60, len(farPixels))
+	close(done)
+	<-loopDone
+}
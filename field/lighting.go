@@ -0,0 +1,148 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Control of the venue lighting fixtures through cues fired automatically by match events or by hand.
+
+package field
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/Team254/cheesy-arena/model"
+)
+
+// Match events that can each automatically fire a lighting cue.
+const (
+	LightingTriggerPreMatch   = "preMatch"
+	LightingTriggerWarmup     = "warmup"
+	LightingTriggerAuto       = "auto"
+	LightingTriggerPause      = "pause"
+	LightingTriggerTeleop     = "teleop"
+	LightingTriggerEndgame    = "endgame"
+	LightingTriggerPostMatch  = "postMatch"
+	LightingTriggerTimeout    = "timeout"
+	LightingTriggerFinalScore = "finalScore"
+)
+
+var LightingTriggers = []string{LightingTriggerPreMatch, LightingTriggerWarmup, LightingTriggerAuto,
+	LightingTriggerPause, LightingTriggerTeleop, LightingTriggerEndgame, LightingTriggerPostMatch,
+	LightingTriggerTimeout, LightingTriggerFinalScore}
+
+// Loads the lighting fixtures and cues from the database and configures the output from the event settings.
+func (arena *Arena) LoadLighting() error {
+	fixtures, err := arena.Database.GetAllLightingFixtures()
+	if err != nil {
+		return err
+	}
+	cues, err := arena.Database.GetAllLightingCues()
+	if err != nil {
+		return err
+	}
+
+	settings := arena.EventSettings
+	if err = arena.Lighting.Configure(settings.LightingProtocol, settings.LightingAddress,
+		settings.LightingComponentId, settings.LightingPriority, settings.LightingSyncUniverse); err != nil {
+		return err
+	}
+	currentCue := arena.Lighting.GetCurrentCue()
+	var lightingFixtures []lighting.Fixture
+	for _, fixture := range fixtures {
+		lightingFixtures = append(lightingFixtures, fixture.Fixture())
+	}
+	arena.Lighting.SetFixtures(lightingFixtures)
+	arena.lightingCuesMutex.Lock()
+	arena.lightingCues = cues
+	arena.lightingCuesMutex.Unlock()
+
+	// Restore the cue that was showing before the reload, if it still exists.
+	if cue := arena.getLightingCue(currentCue); cue != nil {
+		arena.Lighting.FireCue(cue.Cue(), arena.getLightingWinner())
+	}
+	return nil
+}
+
+// Reloads the lighting as above on the arena loop, since the loop fires the cues for each match event.
+func (arena *Arena) ReloadLighting() error {
+	return arena.runOnArenaLoop(arena.LoadLighting)
+}
+
+// Fires the cue having the given name, which stays in effect until the next match event that has a cue.
+func (arena *Arena) FireLightingCue(name string) error {
+	return arena.runOnArenaLoop(func() error {
+		cue := arena.getLightingCue(name)
+		if cue == nil {
+			return fmt.Errorf("Unknown lighting cue '%s'.", name)
+		}
+		arena.Lighting.FireCue(cue.Cue(), arena.getLightingWinner())
+		return nil
+	})
+}
+
+// Fires the cue for the current match event if the event has changed, and sends the fixture states to the output.
+func (arena *Arena) handleLighting() {
+	trigger := arena.getLightingTrigger()
+	if trigger != arena.lastLightingTrigger {
+		arena.lastLightingTrigger = trigger
+		for _, cue := range arena.getLightingCues() {
+			if cue.Trigger == trigger {
+				arena.Lighting.FireCue(cue.Cue(), arena.getLightingWinner())
+				break
+			}
+		}
+	}
+	if !arena.EventSettings.StandbyEnabled {
+		// Leave the lighting to the primary while this instance is a standby.
+		arena.Lighting.Update()
+	}
+}
+
+// Returns the match event corresponding to the current state of the arena.
+func (arena *Arena) getLightingTrigger() string {
+	if arena.AudienceDisplayMode == "score" {
+		return LightingTriggerFinalScore
+	}
+	switch arena.MatchState {
+	case StartMatch, WarmupPeriod:
+		return LightingTriggerWarmup
+	case AutoPeriod:
+		return LightingTriggerAuto
+	case PausePeriod:
+		return LightingTriggerPause
+	case TeleopPeriod:
+		return LightingTriggerTeleop
+	case EndgamePeriod:
+		return LightingTriggerEndgame
+	case PostMatch:
+		return LightingTriggerPostMatch
+	case TimeoutActive, PostTimeout:
+		return LightingTriggerTimeout
+	default:
+		return LightingTriggerPreMatch
+	}
+}
+
+// Returns the winner of the most recently committed match, for cues that show the winning alliance's color.
+func (arena *Arena) getLightingWinner() string {
+	if arena.SavedMatch == nil {
+		return ""
+	}
+	return arena.SavedMatch.Winner
+}
+
+// Returns the loaded cues, which are replaced rather than modified in place when the settings page reloads them.
+func (arena *Arena) getLightingCues() []model.LightingCue {
+	arena.lightingCuesMutex.Lock()
+	defer arena.lightingCuesMutex.Unlock()
+	return arena.lightingCues
+}
+
+// Returns the cue having the given name, or nil if there is none.
+func (arena *Arena) getLightingCue(name string) *model.LightingCue {
+	cues := arena.getLightingCues()
+	for i, cue := range cues {
+		if cue.Name == name {
+			return &cues[i]
+		}
+	}
+	return nil
+}
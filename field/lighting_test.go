@@ -0,0 +1,111 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLightingTriggers(t *testing.T) {
+	arena := setupTestArena(t)
+
+	full := 100.0
+	arena.Database.CreateLightingFixture(&model.LightingFixture{Name: "Wash", Profile: "RGB Par", Universe: 1,
+		Address: 1})
+	arena.Database.CreateLightingCue(&model.LightingCue{Name: "Walk-in", Trigger: LightingTriggerPreMatch,
+		Looks: []lighting.Look{{Target: lighting.AllFixtures, Color: "white", Dimmer: &full}}})
+	arena.Database.CreateLightingCue(&model.LightingCue{Name: "Match", Trigger: LightingTriggerAuto,
+		Looks: []lighting.Look{{Target: lighting.AllFixtures, Color: "purple"}}})
+	arena.Database.CreateLightingCue(&model.LightingCue{Name: "Final Score", Trigger: LightingTriggerFinalScore,
+		Looks: []lighting.Look{{Target: "Wash", Color: lighting.WinnerColor}}})
+	arena.Database.CreateLightingCue(&model.LightingCue{Name: "Blackout",
+		Looks: []lighting.Look{{Target: lighting.AllFixtures, Color: "off"}}})
+	assert.Nil(t, arena.LoadLighting())
+
+	arena.handleLighting()
+	assert.Equal(t, "Walk-in", arena.Lighting.GetCurrentCue())
+	assert.Equal(t, [3]byte{255, 255, 255}, arena.Lighting.GetFixtureStates()[0].Color)
+
+	// Check that events without a cue leave the previous one in effect.
+	arena.MatchState = WarmupPeriod
+	arena.handleLighting()
+	assert.Equal(t, "Walk-in", arena.Lighting.GetCurrentCue())
+	arena.MatchState = AutoPeriod
+	arena.handleLighting()
+	assert.Equal(t, "Match", arena.Lighting.GetCurrentCue())
+	arena.MatchState = TeleopPeriod
+	arena.handleLighting()
+	arena.MatchState = PostMatch
+	arena.handleLighting()
+	assert.Equal(t, "Match", arena.Lighting.GetCurrentCue())
+
+	// Check that the final score cue shows the winning alliance's color.
+	arena.MatchState = PreMatch
+	arena.SavedMatch = &model.Match{Winner: "B"}
+	arena.AudienceDisplayMode = "score"
+	arena.handleLighting()
+	assert.Equal(t, "Final Score", arena.Lighting.GetCurrentCue())
+	assert.Equal(t, [3]byte{0, 0, 255}, arena.Lighting.GetFixtureStates()[0].Color)
+
+	// Check that a cue fired by hand stays in effect until the next event, and survives a reload.
+	assert.Nil(t, RunWithTestArenaLoop(arena, func() error { return arena.FireLightingCue("Blackout") }))
+	err := RunWithTestArenaLoop(arena, func() error { return arena.FireLightingCue("Confetti") })
+	assert.EqualError(t, err, "Unknown lighting cue 'Confetti'.")
+	arena.handleLighting()
+	assert.Equal(t, "Blackout", arena.Lighting.GetCurrentCue())
+	assert.Nil(t, arena.LoadLighting())
+	assert.Equal(t, "Blackout", arena.Lighting.GetCurrentCue())
+	arena.AudienceDisplayMode = "blank"
+	arena.handleLighting()
+	assert.Equal(t, "Walk-in", arena.Lighting.GetCurrentCue())
+}
+
+func TestLoadLightingWhileLoopRunning(t *testing.T) {
+	arena := setupTestArena(t)
+
+	// Run the arena loop while the cues are reloaded off the loop, as when the event settings are saved, having it
+	// look up the cue for the current match event on every update.
+	done := make(chan struct{})
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				arena.lastLightingTrigger = ""
+				arena.Update()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		arena.Database.CreateLightingCue(&model.LightingCue{Name: fmt.Sprintf("Cue %d", i),
+			Looks: []lighting.Look{{Target: lighting.AllFixtures, Color: "white"}}})
+		assert.Nil(t, arena.LoadLighting())
+	}
+	assert.Nil(t, arena.FireLightingCue("Cue 9"))
+	close(done)
+	<-loopDone
+}
+
+func TestLightingTriggerForState(t *testing.T) {
+	arena := setupTestArena(t)
+
+	expectedTriggers := map[MatchState]string{PreMatch: LightingTriggerPreMatch, StartMatch: LightingTriggerWarmup,
+		WarmupPeriod: LightingTriggerWarmup, AutoPeriod: LightingTriggerAuto, PausePeriod: LightingTriggerPause,
+		TeleopPeriod: LightingTriggerTeleop, EndgamePeriod: LightingTriggerEndgame,
+		PostMatch: LightingTriggerPostMatch, TimeoutActive: LightingTriggerTimeout,
+		PostTimeout: LightingTriggerTimeout}
+	for matchState, trigger := range expectedTriggers {
+		arena.MatchState = matchState
+		assert.Equal(t, trigger, arena.getLightingTrigger())
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Registry of the sounds played on arena events, which can be replaced by an uploaded sound pack and played both by the
+// displays and through the server's own audio device.
+
+package field
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	soundsDir       = "static/audio"
+	soundsUrlPrefix = "/static/audio/"
+)
+
+// Represents an arena event that plays a sound, along with the built-in file it plays if the sound pack lacks one.
+type SoundCue struct {
+	Name        string
+	Description string
+	DefaultFile string
+}
+
+var SoundCues = []SoundCue{
+	{"match-warmup", "Match warmup period starts", "match_warmup.wav"},
+	{"match-start", "Autonomous period starts", "match_start.wav"},
+	{"match-end", "Autonomous period, match or timeout ends", "match_end.wav"},
+	{"match-resume", "Teleoperated period starts", "match_resume.wav"},
+	{"match-endgame", "Endgame starts", "match_endgame.wav"},
+	{"match-abort", "Match is aborted", "match_abort.mp3"},
+	{"match-force", "Force power up is played", "match_force.wav"},
+	{"match-levitate", "Levitate power up is played", "match_levitate.wav"},
+	{"match-boost", "Boost power up is played", "match_boost.wav"},
+}
+
+// File extensions of the audio formats that can be played by all the supported browsers.
+var SoundFileExtensions = []string{".wav", ".mp3", ".ogg"}
+
+type PlaySoundMessage struct {
+	Name string
+	Url  string
+}
+
+// Where the file for a sound cue can be fetched from by the displays and played from by the server.
+type soundSource struct {
+	url  string
+	path string
+}
+
+// Loads the files of the current sound pack from the database and configures the server-side sound player.
+func (arena *Arena) LoadSounds() error {
+	settings := arena.EventSettings
+	if err := arena.SoundPlayer.Configure(settings.SoundPlayerCommand); err != nil {
+		// Don't let a player that is missing from this machine keep the arena from loading; playback is left disabled
+		// and the problem is shown on the sounds page.
+		log.Printf("Server-side sound playback is disabled: %s", err.Error())
+	}
+	var soundPackFiles []model.SoundPackFile
+	if settings.SoundPackId != 0 {
+		var err error
+		if soundPackFiles, err = arena.Database.GetSoundPackFiles(settings.SoundPackId); err != nil {
+			return err
+		}
+	}
+
+	soundSources := make(map[string]soundSource)
+	for _, cue := range SoundCues {
+		soundSources[cue.Name] = soundSource{soundsUrlPrefix + cue.DefaultFile,
+			filepath.Join(model.BaseDir, soundsDir, cue.DefaultFile)}
+	}
+	for _, soundPackFile := range soundPackFiles {
+		source := soundSource{url: fmt.Sprintf("/sound_packs/%d/%s", soundPackFile.SoundPackId, soundPackFile.Cue)}
+		if settings.SoundPlayerCommand != "" {
+			// The player needs the sound on disk, so extract it from the database.
+			dir := filepath.Join(os.TempDir(), "cheesy-arena-sounds")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			source.path = filepath.Join(dir, fmt.Sprintf("%d_%s%s", soundPackFile.SoundPackId, soundPackFile.Cue,
+				filepath.Ext(soundPackFile.Filename)))
+			if err := ioutil.WriteFile(source.path, soundPackFile.Data, 0644); err != nil {
+				return err
+			}
+		}
+		soundSources[soundPackFile.Cue] = source
+	}
+	arena.soundSources = soundSources
+	return nil
+}
+
+// Plays the sound for the given cue on the displays that have audio enabled and through the server's audio device if
+// one is configured.
+func (arena *Arena) PlaySound(name string) {
+	source, ok := arena.soundSources[name]
+	if !ok {
+		log.Printf("Unknown sound cue '%s'.", name)
+		return
+	}
+	arena.PlaySoundNotifier.NotifyWithMessage(&PlaySoundMessage{name, source.url})
+
+	// Start the player process off the arena loop since doing so can take a while.
+	go func() {
+		if err := arena.SoundPlayer.Play(source.path); err != nil {
+			log.Printf("Failed to play sound '%s' on the server: %s", name, err.Error())
+		}
+	}()
+}
+
+// Returns the URL from which the displays can fetch the current file for each sound cue.
+func (arena *Arena) GetSoundUrls() map[string]string {
+	soundUrls := make(map[string]string)
+	for name, source := range arena.soundSources {
+		soundUrls[name] = source.url
+	}
+	return soundUrls
+}
+
+// Returns the sound cue having the given name, or nil if there is none.
+func GetSoundCue(name string) *SoundCue {
+	for i, cue := range SoundCues {
+		if cue.Name == name {
+			return &SoundCues[i]
+		}
+	}
+	return nil
+}
+
+// Extracts the sound files from the given zip archive of a sound pack. Each file is named after the sound cue it is
+// for, with underscores in place of hyphens (e.g. match_start.wav); other kinds of files are ignored.
+func SoundPackFilesFromZip(zipData []byte) ([]model.SoundPackFile, error) {
+	reader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, fmt.Errorf("The sound pack is not a valid zip file: %s", err.Error())
+	}
+
+	var soundPackFiles []model.SoundPackFile
+	for _, file := range reader.File {
+		filename := filepath.Base(file.Name)
+		if file.FileInfo().IsDir() || strings.HasPrefix(filename, ".") || strings.HasPrefix(file.Name, "__MACOSX") {
+			continue
+		}
+		extension := strings.ToLower(filepath.Ext(filename))
+		validExtension := false
+		for _, soundFileExtension := range SoundFileExtensions {
+			validExtension = validExtension || extension == soundFileExtension
+		}
+		if !validExtension {
+			continue
+		}
+
+		cueName := strings.Replace(strings.TrimSuffix(strings.ToLower(filename), extension), "_", "-", -1)
+		if GetSoundCue(cueName) == nil {
+			return nil, fmt.Errorf("Sound file '%s' isn't named after any sound cue.", filename)
+		}
+		for _, soundPackFile := range soundPackFiles {
+			if soundPackFile.Cue == cueName {
+				return nil, fmt.Errorf("The sound pack has more than one file for sound cue '%s'.", cueName)
+			}
+		}
+		contents, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(contents)
+		contents.Close()
+		if err != nil {
+			return nil, err
+		}
+		soundPackFiles = append(soundPackFiles, model.SoundPackFile{Cue: cueName, Filename: filename, Data: data})
+	}
+	if len(soundPackFiles) == 0 {
+		return nil, fmt.Errorf("The sound pack doesn't contain any sound files named after a sound cue.")
+	}
+	return soundPackFiles, nil
+}
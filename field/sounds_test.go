@@ -0,0 +1,90 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestSoundCueDefaultFilesExist(t *testing.T) {
+	model.BaseDir = ".."
+	for _, cue := range SoundCues {
+		_, err := ioutil.ReadFile("../static/audio/" + cue.DefaultFile)
+		assert.Nil(t, err, cue.Name)
+	}
+}
+
+func TestLoadSounds(t *testing.T) {
+	arena := setupTestArena(t)
+
+	assert.Equal(t, "/static/audio/match_start.wav", arena.GetSoundUrls()["match-start"])
+	assert.Equal(t, len(SoundCues), len(arena.GetSoundUrls()))
+
+	// Check that the files in the sound pack replace the built-in ones and that they are extracted for the player.
+	soundPack := model.SoundPack{Name: "Power Up", Season: 2018}
+	arena.Database.CreateSoundPack(&soundPack)
+	arena.Database.CreateSoundPackFile(&model.SoundPackFile{SoundPackId: soundPack.Id, Cue: "match-start",
+		Filename: "charge.mp3", Data: []byte("charge")})
+	arena.EventSettings.SoundPackId = soundPack.Id
+	arena.EventSettings.SoundPlayerCommand = "true"
+	assert.Nil(t, arena.LoadSounds())
+	assert.Equal(t, "/sound_packs/1/match-start", arena.GetSoundUrls()["match-start"])
+	assert.Equal(t, "/static/audio/match_end.wav", arena.GetSoundUrls()["match-end"])
+	data, err := ioutil.ReadFile(arena.soundSources["match-start"].path)
+	assert.Nil(t, err)
+	assert.Equal(t, "charge", string(data))
+	arena.PlaySound("match-start")
+	assert.Equal(t, "", arena.SoundPlayer.GetLastError())
+
+	// Check that a missing player disables server-side playback rather than failing to load.
+	arena.EventSettings.SoundPlayerCommand = "blorpy-player"
+	arena.Database.SaveEventSettings(arena.EventSettings)
+	assert.Nil(t, arena.LoadSettings())
+	assert.Equal(t, "/sound_packs/1/match-start", arena.GetSoundUrls()["match-start"])
+	assert.Contains(t, arena.SoundPlayer.GetLastError(), "'blorpy-player' can't be found")
+	arena.PlaySound("match-start")
+}
+
+func TestSoundPackFilesFromZip(t *testing.T) {
+	soundPackFiles, err := SoundPackFilesFromZip(createTestZip(t, map[string]string{
+		"power_up/match_start.wav": "start", "power_up/MATCH_END.MP3": "end", "power_up/README.txt": "readme",
+		"__MACOSX/power_up/._match_start.wav": "junk", "power_up/.match_boost.ogg": "hidden"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(soundPackFiles))
+	assert.Contains(t, soundPackFiles,
+		model.SoundPackFile{Cue: "match-start", Filename: "match_start.wav", Data: []byte("start")})
+	assert.Contains(t, soundPackFiles,
+		model.SoundPackFile{Cue: "match-end", Filename: "MATCH_END.MP3", Data: []byte("end")})
+
+	soundPackFiles, err = SoundPackFilesFromZip(createTestZip(t, map[string]string{"match_end.mp3": "end",
+		"match-levitate.ogg": "levitate"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(soundPackFiles))
+
+	_, err = SoundPackFilesFromZip(createTestZip(t, map[string]string{"match_begin.wav": "start"}))
+	assert.EqualError(t, err, "Sound file 'match_begin.wav' isn't named after any sound cue.")
+	_, err = SoundPackFilesFromZip(createTestZip(t, map[string]string{"match_end.wav": "end", "match_end.mp3": "end"}))
+	assert.EqualError(t, err, "The sound pack has more than one file for sound cue 'match-end'.")
+	_, err = SoundPackFilesFromZip(createTestZip(t, map[string]string{"README.txt": "readme"}))
+	assert.EqualError(t, err, "The sound pack doesn't contain any sound files named after a sound cue.")
+	_, err = SoundPackFilesFromZip([]byte("blorpy"))
+	assert.Contains(t, err.Error(), "not a valid zip file")
+}
+
+func createTestZip(t *testing.T, files map[string]string) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, contents := range files {
+		file, err := writer.Create(name)
+		assert.Nil(t, err)
+		file.Write([]byte(contents))
+	}
+	assert.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
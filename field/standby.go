@@ -0,0 +1,186 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Standby mode, in which a second instance continuously replicates the event data from the primary scorekeeping
+// machine so that it can take over if the primary fails.
+
+package field
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	standbySyncPeriodMs     = 1000
+	standbyRequestTimeoutMs = 5000
+	StandbyAdminUser        = "admin"
+)
+
+// Event data sent from the primary to a standby instance, tagged with the revision it reflects.
+type StandbySnapshot struct {
+	Revision string
+	Tables   []string // The tables in the bundle, which are those changed since the standby's last revision.
+	Bundle   *model.EventBundle
+}
+
+type StandbyStatus struct {
+	Revision     string
+	LastSyncTime time.Time
+	LastError    string
+}
+
+// Loops indefinitely to pull the event data from the primary whenever standby mode is enabled.
+func (arena *Arena) runStandbySync() {
+	for {
+		if arena.EventSettings.StandbyEnabled {
+			if err := arena.SyncFromPrimary(); err != nil {
+				log.Printf("Failed to sync from primary: %s", err.Error())
+			}
+		}
+		time.Sleep(time.Millisecond * standbySyncPeriodMs)
+	}
+}
+
+// Fetches the tables that have changed on the primary since the last sync and replaces the local copies with them.
+func (arena *Arena) SyncFromPrimary() error {
+	arena.standbyMutex.Lock()
+	defer arena.standbyMutex.Unlock()
+	return arena.syncFromPrimary()
+}
+
+// Saves the standby configuration and resets the replication status so that the next sync starts afresh.
+func (arena *Arena) ConfigureStandby(enabled bool, primaryAddress, primaryPassword string) error {
+	arena.standbyMutex.Lock()
+	defer arena.standbyMutex.Unlock()
+
+	return arena.runOnArenaLoop(func() error {
+		arena.EventSettings.StandbyEnabled = enabled
+		arena.EventSettings.StandbyPrimaryAddress = primaryAddress
+		arena.EventSettings.StandbyPrimaryPassword = primaryPassword
+		arena.StandbyStatus = StandbyStatus{}
+		return arena.Database.SaveEventSettings(arena.EventSettings)
+	})
+}
+
+// Takes over from the primary by doing a final sync if it is still reachable and then leaving standby mode, so that
+// matches can be run and the displays are driven from this instance.
+func (arena *Arena) PromoteStandby() error {
+	arena.standbyMutex.Lock()
+	defer arena.standbyMutex.Unlock()
+
+	if !arena.EventSettings.StandbyEnabled {
+		return fmt.Errorf("This instance is not in standby mode.")
+	}
+	if err := arena.syncFromPrimary(); err != nil {
+		log.Printf("Promoting standby without a final sync from the primary: %s", err.Error())
+	}
+
+	return arena.runOnArenaLoop(func() error {
+		arena.EventSettings.StandbyEnabled = false
+		if err := arena.Database.SaveEventSettings(arena.EventSettings); err != nil {
+			return err
+		}
+		if err := arena.LoadSettings(); err != nil {
+			return err
+		}
+		if err := arena.syncImportedMatches(); err != nil {
+			return err
+		}
+		arena.StandbyStatus = StandbyStatus{}
+		arena.ReloadDisplaysNotifier.Notify()
+		return nil
+	})
+}
+
+// Fetches the changes from the primary on the calling goroutine and then applies them on the arena loop, since they
+// replace the event settings and may load a different match. Must be called with the standby mutex held.
+func (arena *Arena) syncFromPrimary() error {
+	snapshot, err := arena.fetchStandbySnapshot()
+	return arena.runOnArenaLoop(func() error {
+		if err == nil && snapshot != nil {
+			err = arena.applyStandbySnapshot(snapshot)
+		}
+		if err != nil {
+			arena.StandbyStatus.LastError = err.Error()
+		} else {
+			arena.StandbyStatus.LastError = ""
+			arena.StandbyStatus.LastSyncTime = time.Now()
+		}
+		return err
+	})
+}
+
+// Returns the changes since the last sync from the primary, or nil if there aren't any.
+func (arena *Arena) fetchStandbySnapshot() (*StandbySnapshot, error) {
+	address := arena.EventSettings.StandbyPrimaryAddress
+	if address == "" {
+		return nil, fmt.Errorf("No primary address is configured.")
+	}
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		address = "http://" + address
+	}
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/api/standby/snapshot?revision=%s",
+		strings.TrimSuffix(address, "/"), url.QueryEscape(arena.StandbyStatus.Revision)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if arena.EventSettings.StandbyPrimaryPassword != "" {
+		request.SetBasicAuth(StandbyAdminUser, arena.EventSettings.StandbyPrimaryPassword)
+	}
+	client := &http.Client{Timeout: time.Millisecond * standbyRequestTimeoutMs}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("Primary returned status %d.", response.StatusCode)
+	}
+	var snapshot StandbySnapshot
+	if err = json.NewDecoder(response.Body).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Replaces the local copies of the tables in the given snapshot and brings the arena in line with them.
+func (arena *Arena) applyStandbySnapshot(snapshot *StandbySnapshot) error {
+	eventSettingsChanged := false
+	for _, table := range snapshot.Tables {
+		eventSettingsChanged = eventSettingsChanged || table == "event_settings"
+	}
+	if snapshot.Bundle == nil || eventSettingsChanged && snapshot.Bundle.EventSettings == nil {
+		return fmt.Errorf("Primary returned an incomplete snapshot.")
+	}
+
+	if eventSettingsChanged {
+		// Keep this instance's own standby configuration rather than taking the primary's.
+		snapshot.Bundle.EventSettings.StandbyEnabled = arena.EventSettings.StandbyEnabled
+		snapshot.Bundle.EventSettings.StandbyPrimaryAddress = arena.EventSettings.StandbyPrimaryAddress
+		snapshot.Bundle.EventSettings.StandbyPrimaryPassword = arena.EventSettings.StandbyPrimaryPassword
+	}
+	if err := arena.Database.RestoreEventBundleTables(snapshot.Bundle, snapshot.Tables); err != nil {
+		return err
+	}
+	if eventSettingsChanged {
+		eventSettings, err := arena.Database.GetEventSettings()
+		if err != nil {
+			return err
+		}
+		arena.EventSettings = eventSettings
+	}
+	arena.StandbyStatus.Revision = snapshot.Revision
+	return arena.syncImportedMatches()
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSyncFromPrimary(t *testing.T) {
+	arena := setupTestArena(t)
+	primaryDb := model.SetupTestDb(t, "standby_primary")
+	defer primaryDb.Close()
+
+	// Mock a primary that serves the tables changed since the standby's revision, if there are any.
+	requestCount := 0
+	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		username, password, _ := r.BasicAuth()
+		if username != StandbyAdminUser || password != "secret" {
+			w.WriteHeader(401)
+			return
+		}
+		revision, _ := primaryDb.GetRevision()
+		tables, _ := primaryDb.GetChangedTables(r.URL.Query().Get("revision"))
+		if len(tables) == 0 {
+			w.WriteHeader(304)
+			return
+		}
+		bundle, _ := primaryDb.BuildEventBundleTables(tables)
+		json.NewEncoder(w).Encode(StandbySnapshot{Revision: revision, Tables: tables, Bundle: bundle})
+	}))
+	defer primaryServer.Close()
+
+	eventSettings, _ := primaryDb.GetEventSettings()
+	eventSettings.Name = "Chezy Champs"
+	eventSettings.AdminPassword = "secret"
+	primaryDb.SaveEventSettings(eventSettings)
+	primaryDb.CreateTeam(&model.Team{Id: 254})
+	match1 := model.Match{Type: "qualification", DisplayName: "1", Status: "complete", Red1: 254}
+	primaryDb.CreateMatch(&match1)
+	primaryDb.CreateMatchResult(model.BuildTestMatchResult(match1.Id, 1))
+	primaryDb.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", Red1: 254})
+
+	arena.EventSettings.StandbyEnabled = true
+	arena.EventSettings.StandbyPrimaryAddress = primaryServer.URL
+	err := RunWithTestArenaLoop(arena, arena.SyncFromPrimary)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Primary returned status 401.", err.Error())
+		assert.Equal(t, err.Error(), arena.StandbyStatus.LastError)
+	}
+
+	arena.EventSettings.StandbyPrimaryPassword = "secret"
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.SyncFromPrimary))
+	assert.Equal(t, "", arena.StandbyStatus.LastError)
+	assert.Equal(t, "Chezy Champs", arena.EventSettings.Name)
+	assert.True(t, arena.EventSettings.StandbyEnabled)
+	assert.Equal(t, primaryServer.URL, arena.EventSettings.StandbyPrimaryAddress)
+	team, _ := arena.Database.GetTeamById(254)
+	assert.NotNil(t, team)
+	assert.Equal(t, "1", arena.SavedMatch.DisplayName)
+	assert.Equal(t, "2", arena.CurrentMatch.DisplayName)
+
+	// Check that an unchanged primary doesn't cause the data to be replaced.
+	arena.Database.CreateTeam(&model.Team{Id: 1114})
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.SyncFromPrimary))
+	team, _ = arena.Database.GetTeamById(1114)
+	assert.NotNil(t, team)
+
+	// Check that only the tables changed on the primary are replaced.
+	arena.Database.CreateLowerThird(&model.LowerThird{TopText: "Standby"})
+	primaryDb.CreateTeam(&model.Team{Id: 148})
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.SyncFromPrimary))
+	team, _ = arena.Database.GetTeamById(148)
+	assert.NotNil(t, team)
+	team, _ = arena.Database.GetTeamById(1114)
+	assert.Nil(t, team)
+	lowerThirds, _ := arena.Database.GetAllLowerThirds()
+	assert.Equal(t, 1, len(lowerThirds))
+	assert.Equal(t, 4, requestCount)
+
+	// Check that matches can't be run while in standby mode.
+	err = arena.StartMatch()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "standby mode")
+	}
+}
+
+func TestPromoteStandby(t *testing.T) {
+	arena := setupTestArena(t)
+
+	err := arena.PromoteStandby()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "This instance is not in standby mode.", err.Error())
+	}
+
+	// Check that the standby can be promoted even when the primary is unreachable.
+	arena.EventSettings.StandbyEnabled = true
+	arena.EventSettings.StandbyPrimaryAddress = "127.0.0.1:1"
+	arena.Database.SaveEventSettings(arena.EventSettings)
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", Time: time.Now()})
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.PromoteStandby))
+	assert.False(t, arena.EventSettings.StandbyEnabled)
+	eventSettings, _ := arena.Database.GetEventSettings()
+	assert.False(t, eventSettings.StandbyEnabled)
+	assert.Equal(t, "1", arena.CurrentMatch.DisplayName)
+	assert.Equal(t, StandbyStatus{}, arena.StandbyStatus)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Mirror mode, in which an event run by another system is imported from The Blue Alliance to drive the displays.
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"reflect"
+	"time"
+)
+
+const tbaMirrorPeriodSec = 30
+
+// Loops indefinitely to import the event data from TBA whenever mirror mode is enabled.
+func (arena *Arena) runTbaMirror() {
+	for {
+		if arena.EventSettings.TbaMirrorEnabled {
+			if err := arena.MirrorTbaEvent(); err != nil {
+				log.Printf("Failed to mirror event from TBA: %s", err.Error())
+			}
+		}
+		time.Sleep(time.Second * tbaMirrorPeriodSec)
+	}
+}
+
+// Imports the latest teams, schedule, results and rankings from TBA and updates the displays to reflect them. The
+// import runs on the calling goroutine but the saved and current matches are only changed from the arena loop.
+func (arena *Arena) MirrorTbaEvent() error {
+	if err := arena.TbaClient.MirrorEvent(arena.Database); err != nil {
+		return err
+	}
+	return arena.runOnArenaLoop(arena.syncImportedMatches)
+}
+
+// Brings the saved and current matches in line with match data that was written to the database by something other
+// than the arena itself, so that the displays reflect it.
+func (arena *Arena) syncImportedMatches() error {
+	qualMatches, err := arena.Database.GetMatchesByType("qualification")
+	if err != nil {
+		return err
+	}
+	elimMatches, err := arena.Database.GetMatchesByType("elimination")
+	if err != nil {
+		return err
+	}
+	matches := append(qualMatches, elimMatches...)
+	var lastCompleteMatch, nextMatch *model.Match
+	for i, match := range matches {
+		if match.Status == "complete" {
+			lastCompleteMatch = &matches[i]
+			nextMatch = nil
+		} else if nextMatch == nil {
+			nextMatch = &matches[i]
+		}
+	}
+
+	// Post the score of the most recently completed match if it is new or has been changed.
+	if lastCompleteMatch != nil {
+		matchResult, err := arena.Database.GetMatchResultForMatch(lastCompleteMatch.Id)
+		if err != nil {
+			return err
+		}
+		if matchResult != nil && (lastCompleteMatch.Id != arena.SavedMatch.Id ||
+			!reflect.DeepEqual(matchResult, arena.SavedMatchResult)) {
+			arena.SavedMatch = lastCompleteMatch
+			arena.SavedMatchResult = matchResult
+			arena.ScorePostedNotifier.Notify()
+		}
+	}
+
+	arena.ScheduleNotifier.Notify()
+	arena.RankingsNotifier.Notify()
+
+	// Load the next match to be played so that the displays show its teams.
+	if nextMatch != nil && arena.MatchState == PreMatch && !reflect.DeepEqual(nextMatch, arena.CurrentMatch) {
+		return arena.LoadMatch(nextMatch)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMirrorTbaEvent(t *testing.T) {
+	arena := setupTestArena(t)
+
+	// Mock a TBA server with one completed match and one upcoming match.
+	matchesJson := `[
+	  {"comp_level": "qm", "match_number": 1, "time": 1000, "winning_alliance": "blue",
+	   "alliances": {"red": {"team_keys": ["frc1", "frc2", "frc3"], "score": 10},
+	                 "blue": {"team_keys": ["frc4", "frc5", "frc6"], "score": 15}},
+	   "score_breakdown": {"red": {"autoRobot1": "AutoRun", "autoRobot2": "AutoRun"},
+	                       "blue": {"autoRobot1": "AutoRun", "autoRobot2": "AutoRun", "autoRobot3": "AutoRun"}}},
+	  {"comp_level": "qm", "match_number": 2, "time": 1420,
+	   "alliances": {"red": {"team_keys": ["frc7", "frc8", "frc9"], "score": -1},
+	                 "blue": {"team_keys": ["frc10", "frc11", "frc12"], "score": -1}}}
+	]`
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/matches"):
+			w.Write([]byte(matchesJson))
+		case strings.HasSuffix(r.URL.Path, "/rankings"):
+			w.Write([]byte(`{"rankings": []}`))
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer tbaServer.Close()
+	arena.TbaClient.BaseUrl = tbaServer.URL
+	arena.EventSettings.TbaMirrorEnabled = true
+
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.MirrorTbaEvent))
+	assert.Equal(t, "1", arena.SavedMatch.DisplayName)
+	assert.Equal(t, 15, arena.SavedMatchResult.BlueScoreSummary().Score)
+	assert.Equal(t, "2", arena.CurrentMatch.DisplayName)
+	assert.Equal(t, 7, arena.CurrentMatch.Red1)
+
+	// Check that matches can't be run from the arena while mirroring.
+	err := arena.StartMatch()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Cannot start match while the event is being mirrored from TBA.", err.Error())
+	}
+
+	// Check that the saved and current matches are brought back in line with the mirrored data.
+	arena.SavedMatch = &model.Match{}
+	arena.CurrentMatch.Red1 = 254
+	assert.Nil(t, RunWithTestArenaLoop(arena, arena.MirrorTbaEvent))
+	assert.Equal(t, "1", arena.SavedMatch.DisplayName)
+	assert.Equal(t, 7, arena.CurrentMatch.Red1)
+}
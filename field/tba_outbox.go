@@ -0,0 +1,142 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Persistent queue of data to publish to The Blue Alliance, with retries for when the network is unavailable.
+
+package field
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	tbaOutboxPeriodSec      = 5
+	tbaOutboxBaseBackoffSec = 5
+	tbaOutboxMaxBackoffSec  = 300
+	TbaOutboxMaxAttempts    = 10
+)
+
+// Queues a publish of the given type of data to The Blue Alliance. Any pending or failed publish of the same type is
+// coalesced into this one, since every publish sends whatever has changed in the latest data.
+func (arena *Arena) QueueTbaPublish(action string) error {
+	arena.tbaOutboxMutex.Lock()
+	defer arena.tbaOutboxMutex.Unlock()
+
+	now := time.Now()
+	item, err := arena.Database.GetTbaOutboxItemByAction(action)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		item = &model.TbaOutboxItem{Action: action, Status: model.TbaOutboxPending, QueuedAt: now,
+			NextAttemptAt: now}
+		err = arena.Database.CreateTbaOutboxItem(item)
+	} else {
+		item.Status = model.TbaOutboxPending
+		item.Attempts = 0
+		item.QueuedAt = now
+		item.NextAttemptAt = now
+		err = arena.Database.SaveTbaOutboxItem(item)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Wake up the worker without blocking if it has already been signaled.
+	select {
+	case arena.tbaOutboxWakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Loops indefinitely to send the queued publishes to The Blue Alliance, including any left over from before a restart.
+func (arena *Arena) runTbaOutbox() {
+	for {
+		arena.processTbaOutbox(time.Now())
+		select {
+		case <-arena.tbaOutboxWakeup:
+		case <-time.After(time.Second * tbaOutboxPeriodSec):
+		}
+	}
+}
+
+// Attempts to send each pending publish that is due at the given time.
+func (arena *Arena) processTbaOutbox(currentTime time.Time) {
+	if !arena.EventSettings.TbaPublishingEnabled || arena.EventSettings.StandbyEnabled {
+		// Leave everything in the queue until publishing is enabled or this standby instance takes over.
+		return
+	}
+	items, err := arena.Database.GetAllTbaOutboxItems()
+	if err != nil {
+		log.Printf("Failed to load TBA outbox: %s", err.Error())
+		return
+	}
+	for _, item := range items {
+		if item.Status != model.TbaOutboxPending || item.NextAttemptAt.After(currentTime) {
+			continue
+		}
+		err = arena.publishToTba(item.Action)
+		if err = arena.recordTbaOutboxAttempt(&item, currentTime, err); err != nil {
+			log.Printf("Failed to update TBA outbox: %s", err.Error())
+		}
+	}
+}
+
+// Removes the given item from the outbox if the publish succeeded, or schedules its retry if not.
+func (arena *Arena) recordTbaOutboxAttempt(item *model.TbaOutboxItem, currentTime time.Time, publishErr error) error {
+	arena.tbaOutboxMutex.Lock()
+	defer arena.tbaOutboxMutex.Unlock()
+
+	// Re-read the item in case another publish was requested while this one was in progress.
+	latestItem, err := arena.Database.GetTbaOutboxItemById(item.Id)
+	if err != nil || latestItem == nil {
+		return err
+	}
+	if !latestItem.QueuedAt.Equal(item.QueuedAt) {
+		// Leave the newer request to be sent on the next pass.
+		return nil
+	}
+
+	if publishErr == nil {
+		return arena.Database.DeleteTbaOutboxItem(latestItem)
+	}
+	log.Printf("Failed to publish %s to TBA: %s", item.Action, publishErr.Error())
+	latestItem.Attempts++
+	latestItem.LastError = publishErr.Error()
+	if latestItem.Attempts >= TbaOutboxMaxAttempts {
+		latestItem.Status = model.TbaOutboxFailed
+	} else {
+		backoffSec := math.Min(tbaOutboxBaseBackoffSec*math.Pow(2, float64(latestItem.Attempts-1)),
+			tbaOutboxMaxBackoffSec)
+		latestItem.NextAttemptAt = currentTime.Add(time.Duration(backoffSec) * time.Second)
+	}
+	return arena.Database.SaveTbaOutboxItem(latestItem)
+}
+
+// Sends the given type of data to The Blue Alliance.
+func (arena *Arena) publishToTba(action string) error {
+	switch action {
+	case model.TbaPublishAlliances:
+		return arena.TbaClient.PublishAlliances(arena.Database)
+	case model.TbaPublishAwards:
+		return arena.TbaClient.PublishAwards(arena.Database)
+	case model.TbaPublishMatches:
+		return arena.TbaClient.PublishMatches(arena.Database)
+	case model.TbaPublishRankings:
+		return arena.TbaClient.PublishRankings(arena.Database)
+	case model.TbaPublishTeams:
+		return arena.TbaClient.PublishTeams(arena.Database)
+	case model.TbaRepublishSchedule:
+		if err := arena.TbaClient.DeletePublishedMatches(arena.Database); err != nil {
+			return err
+		}
+		return arena.TbaClient.PublishMatches(arena.Database)
+	default:
+		return fmt.Errorf("Unknown TBA publish action '%s'.", action)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTbaOutboxCoalescing(t *testing.T) {
+	arena := setupTestArena(t)
+
+	assert.Nil(t, arena.QueueTbaPublish(model.TbaPublishMatches))
+	assert.Nil(t, arena.QueueTbaPublish(model.TbaPublishRankings))
+	assert.Nil(t, arena.QueueTbaPublish(model.TbaPublishMatches))
+	items, _ := arena.Database.GetAllTbaOutboxItems()
+	if assert.Equal(t, 2, len(items)) {
+		assert.Equal(t, model.TbaPublishMatches, items[0].Action)
+		assert.Equal(t, model.TbaOutboxPending, items[0].Status)
+		assert.Equal(t, model.TbaPublishRankings, items[1].Action)
+	}
+
+	// Check that nothing is sent while publishing is disabled.
+	arena.processTbaOutbox(time.Now().Add(time.Hour))
+	items, _ = arena.Database.GetAllTbaOutboxItems()
+	assert.Equal(t, 2, len(items))
+}
+
+func TestTbaOutboxRetries(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1"})
+
+	// Mock a TBA server that fails the first requests for each type of data.
+	var requestPaths []string
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPaths = append(requestPaths, r.URL.Path)
+		if len(requestPaths) <= 2 {
+			http.Error(w, "oops", 500)
+		}
+	}))
+	defer tbaServer.Close()
+	arena.TbaClient.BaseUrl = tbaServer.URL
+	arena.EventSettings.TbaPublishingEnabled = true
+
+	arena.QueueTbaPublish(model.TbaPublishMatches)
+	arena.QueueTbaPublish(model.TbaPublishRankings)
+	startTime := time.Now()
+	arena.processTbaOutbox(startTime)
+	items, _ := arena.Database.GetAllTbaOutboxItems()
+	if assert.Equal(t, 2, len(items)) {
+		assert.Equal(t, 1, items[0].Attempts)
+		assert.Contains(t, items[0].LastError, "Got status code 500")
+		assert.Equal(t, model.TbaOutboxPending, items[0].Status)
+		assert.Equal(t, startTime.Add(tbaOutboxBaseBackoffSec*time.Second).Unix(), items[0].NextAttemptAt.Unix())
+	}
+
+	// Check that nothing is retried before the backoff time has elapsed.
+	arena.processTbaOutbox(startTime.Add(time.Second))
+	assert.Equal(t, 2, len(requestPaths))
+
+	arena.processTbaOutbox(startTime.Add(tbaOutboxBaseBackoffSec * time.Second))
+	items, _ = arena.Database.GetAllTbaOutboxItems()
+	assert.Empty(t, items)
+	if assert.Equal(t, 4, len(requestPaths)) {
+		assert.True(t, strings.HasSuffix(requestPaths[2], "/matches/update"))
+		assert.True(t, strings.HasSuffix(requestPaths[3], "/rankings/update"))
+	}
+}
+
+func TestTbaOutboxRepublishSchedule(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1"})
+
+	var requestPaths []string
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPaths = append(requestPaths, r.URL.Path)
+	}))
+	defer tbaServer.Close()
+	arena.TbaClient.BaseUrl = tbaServer.URL
+	arena.EventSettings.TbaPublishingEnabled = true
+
+	arena.QueueTbaPublish(model.TbaRepublishSchedule)
+	arena.processTbaOutbox(time.Now())
+	items, _ := arena.Database.GetAllTbaOutboxItems()
+	assert.Empty(t, items)
+	if assert.Equal(t, 2, len(requestPaths)) {
+		assert.True(t, strings.HasSuffix(requestPaths[0], "/matches/delete_all"))
+		assert.True(t, strings.HasSuffix(requestPaths[1], "/matches/update"))
+	}
+}
+
+func TestTbaOutboxFailure(t *testing.T) {
+	arena := setupTestArena(t)
+
+	arena.TbaClient.BaseUrl = "fakeurl"
+	arena.EventSettings.TbaPublishingEnabled = true
+	arena.QueueTbaPublish(model.TbaPublishAlliances)
+	currentTime := time.Now()
+	for i := 0; i < TbaOutboxMaxAttempts; i++ {
+		arena.processTbaOutbox(currentTime)
+		currentTime = currentTime.Add(tbaOutboxMaxBackoffSec * time.Second)
+	}
+	item, _ := arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAlliances)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, model.TbaOutboxFailed, item.Status)
+		assert.Equal(t, TbaOutboxMaxAttempts, item.Attempts)
+	}
+
+	// Check that a failed item is no longer retried until it is queued again.
+	arena.processTbaOutbox(currentTime)
+	item, _ = arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAlliances)
+	assert.Equal(t, TbaOutboxMaxAttempts, item.Attempts)
+	arena.QueueTbaPublish(model.TbaPublishAlliances)
+	item, _ = arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAlliances)
+	assert.Equal(t, model.TbaOutboxPending, item.Status)
+	assert.Equal(t, 0, item.Attempts)
+}
@@ -14,7 +14,7 @@ import (
 	"time"
 )
 
-const logsDir = "static/logs"
+const LogsDir = "static/logs"
 
 type TeamMatchLog struct {
 	logger  *log.Logger
@@ -23,12 +23,12 @@ type TeamMatchLog struct {
 
 // Creates a file to log to for the given match and team.
 func NewTeamMatchLog(teamId int, match *model.Match) (*TeamMatchLog, error) {
-	err := os.MkdirAll(filepath.Join(model.BaseDir, logsDir), 0755)
+	err := os.MkdirAll(filepath.Join(model.BaseDir, LogsDir), 0755)
 	if err != nil {
 		return nil, err
 	}
 
-	filename := fmt.Sprintf("%s/%s_%s_Match_%s_%d.csv", filepath.Join(model.BaseDir, logsDir),
+	filename := fmt.Sprintf("%s/%s_%s_Match_%s_%d.csv", filepath.Join(model.BaseDir, LogsDir),
 		time.Now().Format("20060102150405"), match.CapitalizedType(), match.DisplayName, teamId)
 	logFile, err := os.Create(filename)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func SetupTestArena(t *testing.T, uniqueName string) *Arena {
@@ -25,6 +26,27 @@ func SetupTestArena(t *testing.T, uniqueName string) *Arena {
 	return arena
 }
 
+// Calls the given function while running the tasks it posts to the arena loop, in place of the real loop.
+func RunWithTestArenaLoop(arena *Arena, function func() error) error {
+	result := make(chan error)
+	go func() {
+		result <- function()
+	}()
+	for {
+		select {
+		case err := <-result:
+			return err
+		case task := <-arena.loopTasks:
+			task()
+		}
+	}
+}
+
+// Creates the deliveries for the webhook events queued so far, in place of the real worker.
+func ProcessTestWebhookQueue(arena *Arena) {
+	arena.processWebhookQueue(time.Now())
+}
+
 func setupTestArena(t *testing.T) *Arena {
 	return SetupTestArena(t, "field")
 }
@@ -0,0 +1,257 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Delivery of signed event lifecycle notifications to external webhook URLs, with retries for when they are
+// unreachable.
+
+package field
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookPeriodSec          = 5
+	webhookTimeoutSec         = 5
+	webhookBaseBackoffSec     = 5
+	webhookMaxBackoffSec      = 300
+	WebhookMaxAttempts        = 10
+	webhookDeliveryRetainSize = 500
+	webhookQueueSize          = 100
+)
+
+var webhookClient = &http.Client{Timeout: time.Second * webhookTimeoutSec}
+
+// The body of each webhook request.
+type WebhookPayload struct {
+	Event     string
+	Timestamp time.Time
+	Data      interface{}
+}
+
+// Summary of a match that is included in the webhook payloads for match events.
+type WebhookMatch struct {
+	Id          int
+	Type        string
+	DisplayName string
+	RedTeams    []int
+	BlueTeams   []int
+}
+
+// An event waiting for the worker to create a delivery of it for each subscribed webhook.
+type webhookEvent struct {
+	event     string
+	payload   string
+	createdAt time.Time
+}
+
+// Queues a delivery of the given event and data to every webhook that is subscribed to it. The deliveries are created
+// by the worker so that the database isn't touched from the arena loop. Errors are logged rather than returned since a
+// webhook problem shouldn't interfere with running the event.
+func (arena *Arena) QueueWebhookEvent(event string, data interface{}) {
+	if arena.EventSettings.StandbyEnabled {
+		// The primary sends the webhooks while this instance is a standby.
+		return
+	}
+
+	// Encode the payload now since the data may be changed by the time the worker gets to it.
+	now := time.Now()
+	payload, err := json.Marshal(WebhookPayload{event, now, data})
+	if err != nil {
+		log.Printf("Failed to queue webhook event %s: %s", event, err.Error())
+		return
+	}
+	select {
+	case arena.webhookEvents <- webhookEvent{event, string(payload), now}:
+		arena.wakeWebhookWorker()
+	default:
+		log.Printf("Failed to queue webhook event %s: too many events are already queued.", event)
+	}
+}
+
+// Queues a delivery of the given event for the current match, unless it is a test match that isn't part of the event.
+func (arena *Arena) queueMatchWebhookEvent(event string) {
+	if arena.CurrentMatch.Type != "test" {
+		arena.QueueWebhookEvent(event, NewWebhookMatch(arena.CurrentMatch))
+	}
+}
+
+// Requests that the given failed delivery be attempted again from scratch. The reset is done by the worker so that it
+// can't be overwritten by an attempt that is already in progress.
+func (arena *Arena) RetryWebhookDelivery(delivery *model.WebhookDelivery) error {
+	select {
+	case arena.webhookRetries <- delivery.Id:
+		arena.wakeWebhookWorker()
+		return nil
+	default:
+		return fmt.Errorf("Too many webhook retries are already queued; try again shortly.")
+	}
+}
+
+// Wakes up the worker without blocking if it has already been signaled.
+func (arena *Arena) wakeWebhookWorker() {
+	select {
+	case arena.webhookWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Loops indefinitely to send the queued webhook deliveries, including any left over from before a restart.
+func (arena *Arena) runWebhookDeliveries() {
+	for {
+		arena.processWebhookDeliveries(time.Now())
+		select {
+		case <-arena.webhookWakeup:
+		case <-time.After(time.Second * webhookPeriodSec):
+		}
+	}
+}
+
+// Creates the deliveries for the queued events and resets the deliveries whose retry was requested, without waiting
+// for any more to be queued.
+func (arena *Arena) processWebhookQueue(currentTime time.Time) {
+	for {
+		select {
+		case event := <-arena.webhookEvents:
+			if err := arena.createWebhookDeliveries(event); err != nil {
+				log.Printf("Failed to queue webhook event %s: %s", event.event, err.Error())
+			}
+		case deliveryId := <-arena.webhookRetries:
+			if err := arena.resetWebhookDelivery(deliveryId, currentTime); err != nil {
+				log.Printf("Failed to retry webhook delivery %d: %s", deliveryId, err.Error())
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (arena *Arena) createWebhookDeliveries(event webhookEvent) error {
+	webhooks, err := arena.Database.GetAllWebhooks()
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		if !webhook.IsSubscribedTo(event.event) {
+			continue
+		}
+		delivery := model.WebhookDelivery{WebhookId: webhook.Id, Event: event.event, Payload: event.payload,
+			Status: model.WebhookDeliveryPending, CreatedAt: event.createdAt, NextAttemptAt: event.createdAt}
+		if err = arena.Database.CreateWebhookDelivery(&delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (arena *Arena) resetWebhookDelivery(deliveryId int, currentTime time.Time) error {
+	delivery, err := arena.Database.GetWebhookDeliveryById(deliveryId)
+	if err != nil || delivery == nil {
+		return err
+	}
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = currentTime
+	return arena.Database.SaveWebhookDelivery(delivery)
+}
+
+// Attempts to send each pending delivery that is due at the given time.
+func (arena *Arena) processWebhookDeliveries(currentTime time.Time) {
+	arena.processWebhookQueue(currentTime)
+	deliveries, err := arena.Database.GetPendingWebhookDeliveries()
+	if err != nil {
+		log.Printf("Failed to load webhook deliveries: %s", err.Error())
+		return
+	}
+	for _, delivery := range deliveries {
+		if delivery.NextAttemptAt.After(currentTime) {
+			continue
+		}
+		webhook, err := arena.Database.GetWebhookById(delivery.WebhookId)
+		if err != nil {
+			log.Printf("Failed to load webhook: %s", err.Error())
+			continue
+		}
+		var responseCode int
+		if webhook == nil {
+			err = fmt.Errorf("Webhook %d no longer exists.", delivery.WebhookId)
+		} else {
+			responseCode, err = sendWebhookDelivery(webhook, &delivery)
+		}
+		if err = arena.recordWebhookAttempt(&delivery, currentTime, responseCode, err); err != nil {
+			log.Printf("Failed to update webhook delivery: %s", err.Error())
+		}
+	}
+
+	if err = arena.Database.PruneWebhookDeliveries(webhookDeliveryRetainSize); err != nil {
+		log.Printf("Failed to prune webhook deliveries: %s", err.Error())
+	}
+}
+
+// Marks the given delivery as delivered if it succeeded, or schedules its retry if not.
+func (arena *Arena) recordWebhookAttempt(delivery *model.WebhookDelivery, currentTime time.Time, responseCode int,
+	sendErr error) error {
+	delivery.Attempts++
+	delivery.ResponseCode = responseCode
+	if sendErr == nil {
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		return arena.Database.SaveWebhookDelivery(delivery)
+	}
+
+	log.Printf("Failed to deliver webhook event %s: %s", delivery.Event, sendErr.Error())
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= WebhookMaxAttempts {
+		delivery.Status = model.WebhookDeliveryFailed
+	} else {
+		backoffSec := math.Min(webhookBaseBackoffSec*math.Pow(2, float64(delivery.Attempts-1)), webhookMaxBackoffSec)
+		delivery.NextAttemptAt = currentTime.Add(time.Duration(backoffSec) * time.Second)
+	}
+	return arena.Database.SaveWebhookDelivery(delivery)
+}
+
+// Posts the given delivery's payload to the webhook and returns the response's status code.
+func sendWebhookDelivery(webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	request, err := http.NewRequest("POST", webhook.Url, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Cheesy-Arena-Event", delivery.Event)
+	request.Header.Set("X-Cheesy-Arena-Delivery", strconv.Itoa(delivery.Id))
+	request.Header.Set("X-Cheesy-Arena-Signature", SignWebhookPayload(webhook.Secret, delivery.Payload))
+	response, err := webhookClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return response.StatusCode, fmt.Errorf("Got status code %d.", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Returns the signature of the given payload, which the receiver can recompute with the shared secret to verify that
+// the request came from this server.
+func SignWebhookPayload(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Returns the summary of the given match to include in a webhook payload.
+func NewWebhookMatch(match *model.Match) WebhookMatch {
+	return WebhookMatch{match.Id, match.Type, match.DisplayName, []int{match.Red1, match.Red2, match.Red3},
+		[]int{match.Blue1, match.Blue2, match.Blue3}}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package field
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	arena := setupTestArena(t)
+
+	// Mock a receiver that fails the first request.
+	var requests []*http.Request
+	var bodies []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		if len(requests) == 1 {
+			http.Error(w, "oops", 500)
+		}
+	}))
+	defer receiver.Close()
+	arena.Database.CreateWebhook(&model.Webhook{Name: "Hook", Url: receiver.URL, Secret: "shh",
+		Events: model.WebhookMatchLoaded, Enabled: true})
+	arena.Database.CreateWebhook(&model.Webhook{Name: "Other Hook", Url: receiver.URL,
+		Events: model.WebhookMatchCommitted, Enabled: true})
+
+	match := model.Match{Id: 254, Type: "qualification", DisplayName: "12", Red1: 1, Red2: 2, Red3: 3, Blue1: 4,
+		Blue2: 5, Blue3: 6}
+	arena.LoadMatch(&match)
+	ProcessTestWebhookQueue(arena)
+	deliveries, _ := arena.Database.GetPendingWebhookDeliveries()
+	if !assert.Equal(t, 1, len(deliveries)) {
+		return
+	}
+	assert.Equal(t, 1, deliveries[0].WebhookId)
+
+	startTime := time.Now()
+	arena.processWebhookDeliveries(startTime)
+	delivery, _ := arena.Database.GetWebhookDeliveryById(deliveries[0].Id)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, 500, delivery.ResponseCode)
+	assert.Contains(t, delivery.LastError, "Got status code 500")
+	assert.Equal(t, startTime.Add(webhookBaseBackoffSec*time.Second).Unix(), delivery.NextAttemptAt.Unix())
+
+	// Check that nothing is retried before the backoff time has elapsed.
+	arena.processWebhookDeliveries(startTime.Add(time.Second))
+	assert.Equal(t, 1, len(requests))
+
+	arena.processWebhookDeliveries(startTime.Add(webhookBaseBackoffSec * time.Second))
+	delivery, _ = arena.Database.GetWebhookDeliveryById(deliveries[0].Id)
+	assert.Equal(t, model.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 200, delivery.ResponseCode)
+	if assert.Equal(t, 2, len(requests)) {
+		assert.Equal(t, model.WebhookMatchLoaded, requests[1].Header.Get("X-Cheesy-Arena-Event"))
+		assert.Equal(t, SignWebhookPayload("shh", bodies[1]), requests[1].Header.Get("X-Cheesy-Arena-Signature"))
+		assert.NotEqual(t, SignWebhookPayload("", bodies[1]), requests[1].Header.Get("X-Cheesy-Arena-Signature"))
+		var payload struct {
+			Event string
+			Data  WebhookMatch
+		}
+		assert.Nil(t, json.Unmarshal([]byte(bodies[1]), &payload))
+		assert.Equal(t, model.WebhookMatchLoaded, payload.Event)
+		assert.Equal(t, "12", payload.Data.DisplayName)
+		assert.Equal(t, []int{4, 5, 6}, payload.Data.BlueTeams)
+	}
+}
+
+func TestWebhookDeliveryFailure(t *testing.T) {
+	arena := setupTestArena(t)
+
+	arena.Database.CreateWebhook(&model.Webhook{Name: "Hook", Url: "fakeurl", Events: model.WebhookTimeoutStarted,
+		Enabled: true})
+	arena.Database.CreateWebhook(&model.Webhook{Name: "Disabled Hook", Url: "fakeurl",
+		Events: model.WebhookTimeoutStarted})
+	arena.QueueWebhookEvent(model.WebhookTimeoutStarted, nil)
+	currentTime := time.Now()
+	for i := 0; i < WebhookMaxAttempts; i++ {
+		arena.processWebhookDeliveries(currentTime)
+		currentTime = currentTime.Add(webhookMaxBackoffSec * time.Second)
+	}
+	deliveries, _ := arena.Database.GetRecentWebhookDeliveries(10)
+	if assert.Equal(t, 1, len(deliveries)) {
+		assert.Equal(t, model.WebhookDeliveryFailed, deliveries[0].Status)
+		assert.Equal(t, WebhookMaxAttempts, deliveries[0].Attempts)
+	}
+
+	// Check that a failed delivery is no longer retried until it is explicitly retried.
+	arena.processWebhookDeliveries(currentTime)
+	delivery, _ := arena.Database.GetWebhookDeliveryById(deliveries[0].Id)
+	assert.Equal(t, WebhookMaxAttempts, delivery.Attempts)
+	assert.Nil(t, arena.RetryWebhookDelivery(delivery))
+	ProcessTestWebhookQueue(arena)
+	delivery, _ = arena.Database.GetWebhookDeliveryById(deliveries[0].Id)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+}
+
+func TestWebhookMatchEvents(t *testing.T) {
+	arena := setupTestArena(t)
+
+	arena.Database.CreateWebhook(&model.Webhook{Name: "Hook", Url: "http://localhost", Enabled: true,
+		Events: model.WebhookMatchStarted + "," + model.WebhookMatchEnded})
+	arena.LoadMatch(&model.Match{Type: "qualification"})
+	arena.AllianceStations["R1"].Bypass = true
+	arena.AllianceStations["R2"].Bypass = true
+	arena.AllianceStations["R3"].Bypass = true
+	arena.AllianceStations["B1"].Bypass = true
+	arena.AllianceStations["B2"].Bypass = true
+	arena.AllianceStations["B3"].Bypass = true
+	assert.Nil(t, arena.StartMatch())
+	arena.Update()
+	arena.MatchStartTime = time.Now().Add(-time.Duration(game.MatchTiming.WarmupDurationSec) * time.Second)
+	arena.Update()
+	arena.MatchStartTime = time.Now().Add(-time.Duration(game.MatchTiming.WarmupDurationSec+
+		game.MatchTiming.AutoDurationSec+game.MatchTiming.PauseDurationSec+game.MatchTiming.TeleopDurationSec) *
+		time.Second)
+	for i := 0; i < 4; i++ {
+		arena.Update()
+	}
+	assert.Equal(t, PostMatch, arena.MatchState)
+	ProcessTestWebhookQueue(arena)
+	deliveries, _ := arena.Database.GetPendingWebhookDeliveries()
+	if assert.Equal(t, 2, len(deliveries)) {
+		assert.Equal(t, model.WebhookMatchStarted, deliveries[0].Event)
+		assert.Equal(t, model.WebhookMatchEnded, deliveries[1].Event)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Game-specific criteria for breaking ties in elimination matches.
+
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ElimTiebreaker struct {
+	Key         string
+	Description string
+	value       func(summary *ScoreSummary) int // The alliance with the higher value wins the tiebreaker.
+}
+
+// All criteria from the 2018 game that may be used to break an elimination tie. Foul points are those awarded to an
+// alliance as a result of its opponent's fouls, so a higher value means that the alliance committed fewer fouls.
+var ElimTiebreakers = []ElimTiebreaker{
+	{"fouls", "Fewer Fouls", func(summary *ScoreSummary) int { return summary.FoulPoints }},
+	{"auto", "Auto Points", func(summary *ScoreSummary) int { return summary.AutoPoints }},
+	{"ownership", "Ownership Points", func(summary *ScoreSummary) int { return summary.OwnershipPoints }},
+	{"parkclimb", "Park/Climb Points", func(summary *ScoreSummary) int { return summary.ParkClimbPoints }},
+}
+
+// The ordered list of tiebreakers prescribed by the 2018 game manual.
+const DefaultElimTiebreakers = "fouls,auto,ownership,parkclimb"
+
+// Parses the given comma-separated list of tiebreaker keys into the corresponding ordered list of criteria.
+func ParseElimTiebreakers(keys string) ([]ElimTiebreaker, error) {
+	var tiebreakers []ElimTiebreaker
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		found := false
+		for _, tiebreaker := range ElimTiebreakers {
+			if tiebreaker.Key == key {
+				tiebreakers = append(tiebreakers, tiebreaker)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Invalid elimination tiebreaker '%s'.", key)
+		}
+	}
+	return tiebreakers, nil
+}
+
+// Evaluates the given tiebreakers in order for a tied elimination match. Returns the winning alliance and the criterion
+// that decided it, or NeitherAlliance and nil if none of the criteria break the tie.
+func BreakElimTie(redSummary, blueSummary *ScoreSummary,
+	tiebreakers []ElimTiebreaker) (Alliance, *ElimTiebreaker) {
+	for i, tiebreaker := range tiebreakers {
+		redValue := tiebreaker.value(redSummary)
+		blueValue := tiebreaker.value(blueSummary)
+		if redValue > blueValue {
+			return RedAlliance, &tiebreakers[i]
+		} else if blueValue > redValue {
+			return BlueAlliance, &tiebreakers[i]
+		}
+	}
+	return NeitherAlliance, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package game
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseElimTiebreakers(t *testing.T) {
+	tiebreakers, err := ParseElimTiebreakers(DefaultElimTiebreakers)
+	assert.Nil(t, err)
+	if assert.Equal(t, 4, len(tiebreakers)) {
+		assert.Equal(t, "fouls", tiebreakers[0].Key)
+		assert.Equal(t, "auto", tiebreakers[1].Key)
+		assert.Equal(t, "ownership", tiebreakers[2].Key)
+		assert.Equal(t, "parkclimb", tiebreakers[3].Key)
+	}
+
+	tiebreakers, err = ParseElimTiebreakers(" parkclimb, auto ")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(tiebreakers)) {
+		assert.Equal(t, "parkclimb", tiebreakers[0].Key)
+		assert.Equal(t, "auto", tiebreakers[1].Key)
+	}
+
+	tiebreakers, err = ParseElimTiebreakers("")
+	assert.Nil(t, err)
+	assert.Empty(t, tiebreakers)
+
+	_, err = ParseElimTiebreakers("auto,blorpy")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid elimination tiebreaker 'blorpy'.", err.Error())
+	}
+}
+
+func TestBreakElimTie(t *testing.T) {
+	tiebreakers, _ := ParseElimTiebreakers(DefaultElimTiebreakers)
+	redSummary := &ScoreSummary{FoulPoints: 5, AutoPoints: 10, OwnershipPoints: 20, ParkClimbPoints: 30, Score: 65}
+	blueSummary := &ScoreSummary{FoulPoints: 5, AutoPoints: 10, OwnershipPoints: 20, ParkClimbPoints: 30, Score: 65}
+
+	winner, tiebreaker := BreakElimTie(redSummary, blueSummary, tiebreakers)
+	assert.Equal(t, NeitherAlliance, winner)
+	assert.Nil(t, tiebreaker)
+
+	redSummary.ParkClimbPoints = 35
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, tiebreakers)
+	assert.Equal(t, RedAlliance, winner)
+	assert.Equal(t, "parkclimb", tiebreaker.Key)
+
+	blueSummary.OwnershipPoints = 21
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, tiebreakers)
+	assert.Equal(t, BlueAlliance, winner)
+	assert.Equal(t, "ownership", tiebreaker.Key)
+
+	redSummary.AutoPoints = 15
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, tiebreakers)
+	assert.Equal(t, RedAlliance, winner)
+	assert.Equal(t, "auto", tiebreaker.Key)
+
+	blueSummary.FoulPoints = 25
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, tiebreakers)
+	assert.Equal(t, BlueAlliance, winner)
+	assert.Equal(t, "fouls", tiebreaker.Key)
+	assert.Equal(t, "Fewer Fouls", tiebreaker.Description)
+
+	// Check that only the given criteria are considered.
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, tiebreakers[3:])
+	assert.Equal(t, RedAlliance, winner)
+	assert.Equal(t, "parkclimb", tiebreaker.Key)
+	winner, tiebreaker = BreakElimTie(redSummary, blueSummary, []ElimTiebreaker{})
+	assert.Equal(t, NeitherAlliance, winner)
+	assert.Nil(t, tiebreaker)
+}
@@ -1,30 +1,35 @@
 // Copyright 2018 Team 254. All Rights Reserved.
 // Author: pat@patfairbank.com (Patrick Fairbank)
 //
-// Represents an E1.31 sACN (DMX over Ethernet) LED controller with four outputs.
+// Represents an E1.31 sACN (DMX over Ethernet) LED controller driving two strips.
 
 package led
 
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 const (
-	port              = 5568
-	sourceName        = "Cheesy Arena"
-	packetTimeoutSec  = 1
-	numPixels         = 114
-	pixelDataOffset   = 126
-	nearStripUniverse = 1
-	farStripUniverse  = 2
+	port                     = 5568
+	sourceName               = "Cheesy Arena"
+	packetTimeoutSec         = 1
+	pixelDataOffset          = 126
+	DefaultNumPixels         = 114
+	DefaultNearStripUniverse = 1
+	DefaultFarStripUniverse  = 2
+	MaxNumPixels             = 170 // The most RGB pixels that fit in a single DMX universe.
 )
 
 type Controller struct {
-	nearStrip strip
-	farStrip  strip
-	conn      net.Conn
-	packet    []byte
+	nearStrip    strip
+	farStrip     strip
+	numPixels    int
+	nearUniverse int
+	farUniverse  int
+	conn         net.Conn
+	packet       []byte
 }
 
 func (controller *Controller) SetAddress(address string) error {
@@ -43,25 +48,34 @@ func (controller *Controller) SetAddress(address string) error {
 	return nil
 }
 
-// Sets the current LED sequence mode and resets the intra-sequence counter to the beginning.
-func (controller *Controller) SetMode(nearMode, farMode Mode) {
-	if nearMode != controller.nearStrip.currentMode {
-		controller.nearStrip.currentMode = nearMode
-		controller.nearStrip.counter = 0
+// Sets the number of pixels in each strip and the DMX universes that the strips are addressed by.
+func (controller *Controller) Configure(numPixels, nearUniverse, farUniverse int) error {
+	if numPixels <= 0 || numPixels > MaxNumPixels {
+		return fmt.Errorf("The number of pixels must be between 1 and %d.", MaxNumPixels)
 	}
-	if farMode != controller.farStrip.currentMode {
-		controller.farStrip.currentMode = farMode
-		controller.farStrip.counter = 0
+	if nearUniverse <= 0 || farUniverse <= 0 || nearUniverse == farUniverse {
+		return fmt.Errorf("The strips must have distinct, positive universe numbers.")
 	}
+	controller.numPixels = numPixels
+	controller.nearUniverse = nearUniverse
+	controller.farUniverse = farUniverse
+	controller.packet = nil
+	return nil
+}
+
+// Sets the current LED sequences, starting each from the beginning if it has changed. A nil sequence turns the strip
+// off.
+func (controller *Controller) SetSequence(nearSequence, farSequence *Sequence) {
+	controller.nearStrip.setSequence(nearSequence)
+	controller.farStrip.setSequence(farSequence)
 }
 
-// Returns the current mode if both sides are in the same mode, or off otherwise.
-func (controller *Controller) GetCurrentMode() Mode {
-	if controller.nearStrip.currentMode == controller.farStrip.currentMode {
-		return controller.nearStrip.currentMode
-	} else {
-		return OffMode
+// Returns the name of the current sequence if both sides are showing the same one, or off otherwise.
+func (controller *Controller) GetCurrentSequence() string {
+	if controller.nearStrip.sequence != nil && controller.nearStrip.sequence == controller.farStrip.sequence {
+		return controller.nearStrip.sequence.Name
 	}
+	return OffSequence
 }
 
 // Sets which side of the scale or switch belongs to which alliance. A value of true indicates that the side nearest the
@@ -71,30 +85,48 @@ func (controller *Controller) SetSidedness(nearIsRed bool) {
 	controller.farStrip.isRed = !nearIsRed
 }
 
+// Returns copies of the current pixel values of the near and far strips, so that they can be shown without the
+// physical strips.
+func (controller *Controller) GetPixels() ([][3]byte, [][3]byte) {
+	nearPixels := make([][3]byte, len(controller.nearStrip.pixels))
+	copy(nearPixels, controller.nearStrip.pixels)
+	farPixels := make([][3]byte, len(controller.farStrip.pixels))
+	copy(farPixels, controller.farStrip.pixels)
+	return nearPixels, farPixels
+}
+
 // Advances the pixel values through the current sequence and sends a packet if necessary. Should be called from a timed
 // loop.
 func (controller *Controller) Update() error {
+	if controller.numPixels == 0 {
+		// Fall back to the defaults if the controller hasn't been explicitly configured.
+		controller.Configure(DefaultNumPixels, DefaultNearStripUniverse, DefaultFarStripUniverse)
+	}
+	controller.nearStrip.setNumPixels(controller.numPixels)
+	controller.farStrip.setNumPixels(controller.numPixels)
+
+	now := time.Now()
+	controller.nearStrip.updatePixels(now)
+	controller.farStrip.updatePixels(now)
+
 	if controller.conn == nil {
-		// This controller is not configured; do nothing.
+		// This controller is not connected to any hardware; only keep the pixel values up to date.
 		return nil
 	}
 
-	controller.nearStrip.updatePixels()
-	controller.farStrip.updatePixels()
-
 	// Create the template packet if it doesn't already exist.
 	if len(controller.packet) == 0 {
-		controller.packet = createBlankPacket(numPixels)
+		controller.packet = createBlankPacket(controller.numPixels)
 	}
 
 	// Send packets if the pixel values have changed.
 	if controller.nearStrip.shouldSendPacket() {
 		controller.nearStrip.populatePacketPixels(controller.packet[pixelDataOffset:])
-		controller.sendPacket(nearStripUniverse)
+		controller.sendPacket(controller.nearUniverse)
 	}
 	if controller.farStrip.shouldSendPacket() {
 		controller.farStrip.populatePacketPixels(controller.packet[pixelDataOffset:])
-		controller.sendPacket(farStripUniverse)
+		controller.sendPacket(controller.farUniverse)
 	}
 
 	return nil
@@ -0,0 +1,28 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestControllerUpdateWithoutHardware(t *testing.T) {
+	var controller Controller
+	assert.Nil(t, controller.Configure(3, 1, 2))
+	red := &Sequence{Name: "Red", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"alliance"}}}}
+	controller.SetSequence(red, nil)
+	controller.SetSidedness(false)
+
+	// Check that the pixel values are still calculated when there is no hardware to send them to.
+	assert.Nil(t, controller.Update())
+	nearPixels, farPixels := controller.GetPixels()
+	assert.Equal(t, [][3]byte{Colors[Blue], Colors[Blue], Colors[Blue]}, nearPixels)
+	assert.Equal(t, [][3]byte{Colors[Black], Colors[Black], Colors[Black]}, farPixels)
+
+	// Check that the returned pixels are copies.
+	nearPixels[0] = Colors[White]
+	nearPixels, _ = controller.GetPixels()
+	assert.Equal(t, Colors[Blue], nearPixels[0])
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Contains the built-in set of LED sequences, which can be overridden by custom sequences of the same name.
+
+package led
+
+const OffSequence = "Off"
+
+var DefaultSequences = []Sequence{
+	{Name: OffSequence, Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"black"}}}},
+	{Name: "Red", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"red"}}}},
+	{Name: "Green", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"green"}}}},
+	{Name: "Blue", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"blue"}}}},
+	{Name: "White", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"white"}}}},
+	{Name: "Purple", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"purple"}}}},
+	{Name: "Chase", Loop: true, Keyframes: []Keyframe{
+		{Effect: WipeEffect, Colors: []string{"red", "white"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"orange", "red"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"yellow", "orange"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"green", "yellow"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"teal", "green"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"blue", "teal"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"purple", "blue"}, DurationMs: 1140},
+		{Effect: WipeEffect, Colors: []string{"white", "purple"}, DurationMs: 1140},
+	}},
+	{Name: "Warmup", Keyframes: []Keyframe{
+		{Effect: WipeEffect, Colors: []string{"alliance", "white"}, DurationMs: 2500, Symmetric: true},
+	}},
+	{Name: "Warmup Purple", Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"purple"}, DurationMs: 1000},
+		{Effect: FadeEffect, Colors: []string{"purple", "alliance"}, DurationMs: 1500},
+	}},
+	{Name: "Warmup Sneaky", Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"purple"}, DurationMs: 500},
+		{Effect: FadeEffect, Colors: []string{"purple", "midAlliance"}, DurationMs: 1750},
+		{Effect: FadeEffect, Colors: []string{"midAlliance", "alliance"}, DurationMs: 250},
+	}},
+	{Name: "Warmup Gradient", Keyframes: []Keyframe{
+		{Effect: GradientEffect, DurationMs: 1000, PeriodMs: 10, Width: 57, Reverse: true},
+		{Effect: WipeEffect, Colors: []string{"alliance", "purple"}, DurationMs: 1140},
+	}},
+	{Name: "Owned", Keyframes: []Keyframe{
+		{Effect: ChaseEffect, Colors: []string{"alliance", "alliance", "black", "black"}, PeriodMs: 300,
+			Reverse: true},
+	}},
+	{Name: "Not Owned", Keyframes: []Keyframe{{Effect: SolidEffect, Colors: []string{"dimAlliance"}}}},
+	{Name: "Force", Keyframes: []Keyframe{
+		{Effect: ChaseEffect, Colors: []string{"black", "black", "alliance", "dimOpposite", "alliance", "black",
+			"black"}, PeriodMs: 300},
+	}},
+	{Name: "Boost", Keyframes: []Keyframe{
+		{Effect: ChaseEffect, Colors: []string{"alliance", "black", "black", "black"}, PeriodMs: 40, Reverse: true},
+	}},
+	{Name: "Random", Keyframes: []Keyframe{
+		{Effect: RandomEffect, Colors: []string{"red", "orange", "yellow", "green", "teal", "blue", "purple", "white"},
+			PeriodMs: 100},
+	}},
+	{Name: "Fade Red/Blue", Loop: true, Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"black"}, DurationMs: 100},
+		{Effect: FadeEffect, Colors: []string{"black", "red"}, DurationMs: 400},
+		{Effect: SolidEffect, Colors: []string{"red"}, DurationMs: 100},
+		{Effect: FadeEffect, Colors: []string{"red", "black"}, DurationMs: 400},
+		{Effect: SolidEffect, Colors: []string{"black"}, DurationMs: 100},
+		{Effect: FadeEffect, Colors: []string{"black", "blue"}, DurationMs: 400},
+		{Effect: SolidEffect, Colors: []string{"blue"}, DurationMs: 100},
+		{Effect: FadeEffect, Colors: []string{"blue", "black"}, DurationMs: 400},
+	}},
+	{Name: "Fade Single", Loop: true, Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"black"}, DurationMs: 500},
+		{Effect: FadeEffect, Colors: []string{"black", "alliance"}, DurationMs: 1000},
+		{Effect: FadeEffect, Colors: []string{"alliance", "black"}, DurationMs: 1000},
+	}},
+	{Name: "Gradient", Keyframes: []Keyframe{
+		{Effect: GradientEffect, PeriodMs: 10, Width: 75, Reverse: true},
+	}},
+	{Name: "Blink", Keyframes: []Keyframe{
+		{Effect: BlinkEffect, Colors: []string{"white", "black"}, PeriodMs: 50},
+	}},
+}
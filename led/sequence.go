@@ -0,0 +1,339 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Data-driven definition of an LED sequence as a series of keyframes, and the logic for rendering it onto a strip.
+
+package led
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Effects that a keyframe can apply to the strip.
+const (
+	SolidEffect    = "solid"
+	FadeEffect     = "fade"
+	BlinkEffect    = "blink"
+	ChaseEffect    = "chase"
+	WipeEffect     = "wipe"
+	GradientEffect = "gradient"
+	RandomEffect   = "random"
+)
+
+var Effects = []string{SolidEffect, FadeEffect, BlinkEffect, ChaseEffect, WipeEffect, GradientEffect, RandomEffect}
+
+type Sequence struct {
+	Name      string
+	Keyframes []Keyframe
+	Loop      bool // Whether to start over after the last keyframe, rather than holding it.
+}
+
+// A single step of a sequence. Colors are given by name (e.g. "red"), as "#rrggbb", or relative to the alliance
+// that the strip belongs to ("alliance", "opposite", "dimAlliance", "dimOpposite" or "midAlliance").
+type Keyframe struct {
+	Effect     string
+	Colors     []string
+	DurationMs int  // How long the keyframe lasts; zero means that it lasts until the sequence is changed.
+	PeriodMs   int  // How long each step of a blink, chase, gradient or random effect lasts.
+	Width      int  // The number of pixels over which a gradient effect cycles through its colors.
+	Reverse    bool // Whether a chase, wipe or gradient effect runs towards the start of the strip.
+	Symmetric  bool // Whether a wipe effect fills in from both ends of the strip at once.
+}
+
+var colorNames = map[string]Color{
+	"red":        Red,
+	"orange":     Orange,
+	"yellow":     Yellow,
+	"green":      Green,
+	"teal":       Teal,
+	"blue":       Blue,
+	"purple":     Purple,
+	"white":      White,
+	"black":      Black,
+	"off":        Black,
+	"purpleRed":  PurpleRed,
+	"purpleBlue": PurpleBlue,
+	"dimRed":     DimRed,
+	"dimBlue":    DimBlue,
+}
+
+// Checks that the sequence is well-formed, returning an error describing the first problem found.
+func (sequence *Sequence) Validate() error {
+	if strings.TrimSpace(sequence.Name) == "" {
+		return fmt.Errorf("The sequence name can't be blank.")
+	}
+	if len(sequence.Keyframes) == 0 {
+		return fmt.Errorf("Sequence '%s' must have at least one keyframe.", sequence.Name)
+	}
+	for i, keyframe := range sequence.Keyframes {
+		if err := keyframe.validate(); err != nil {
+			return fmt.Errorf("Keyframe %d of sequence '%s': %s", i+1, sequence.Name, err.Error())
+		}
+		if keyframe.DurationMs == 0 && (sequence.Loop || i < len(sequence.Keyframes)-1) {
+			return fmt.Errorf("Keyframe %d of sequence '%s': only the last keyframe of a sequence that doesn't "+
+				"loop can have an unlimited duration.", i+1, sequence.Name)
+		}
+	}
+	return nil
+}
+
+func (keyframe *Keyframe) validate() error {
+	minColors, maxColors := 1, 1
+	needsPeriod, needsDuration := false, false
+	switch keyframe.Effect {
+	case SolidEffect:
+	case FadeEffect:
+		minColors, maxColors = 2, 2
+		needsDuration = true
+	case BlinkEffect:
+		maxColors = -1
+		needsPeriod = true
+	case ChaseEffect:
+		maxColors = -1
+		needsPeriod = true
+	case WipeEffect:
+		maxColors = 2
+		needsDuration = true
+	case GradientEffect:
+		minColors, maxColors = 0, -1
+		needsPeriod = true
+		if keyframe.Width <= 0 {
+			return fmt.Errorf("the gradient width must be positive.")
+		}
+	case RandomEffect:
+		maxColors = -1
+		needsPeriod = true
+	default:
+		return fmt.Errorf("unknown effect '%s'.", keyframe.Effect)
+	}
+
+	if len(keyframe.Colors) < minColors || maxColors >= 0 && len(keyframe.Colors) > maxColors {
+		return fmt.Errorf("the %s effect can't take %d colors.", keyframe.Effect, len(keyframe.Colors))
+	}
+	for _, color := range keyframe.Colors {
+		if _, err := parseColor(color, true); err != nil {
+			return err
+		}
+	}
+	if keyframe.DurationMs < 0 || keyframe.PeriodMs < 0 {
+		return fmt.Errorf("the duration and period can't be negative.")
+	}
+	if needsDuration && keyframe.DurationMs == 0 {
+		return fmt.Errorf("the %s effect must have a duration.", keyframe.Effect)
+	}
+	if needsPeriod && keyframe.PeriodMs == 0 {
+		return fmt.Errorf("the %s effect must have a period.", keyframe.Effect)
+	}
+	return nil
+}
+
+// Sets the given pixels to the values they should have after the sequence has been running for the given time.
+func (sequence *Sequence) Render(pixels [][3]byte, elapsed time.Duration, isRed bool) {
+	if len(sequence.Keyframes) == 0 {
+		fillPixels(pixels, Colors[Black])
+		return
+	}
+
+	elapsedMs := int(elapsed / time.Millisecond)
+	if totalMs := sequence.durationMs(); sequence.Loop && totalMs > 0 {
+		elapsedMs %= totalMs
+	}
+	for i, keyframe := range sequence.Keyframes {
+		if keyframe.DurationMs == 0 || elapsedMs < keyframe.DurationMs {
+			keyframe.render(pixels, elapsedMs, isRed)
+			return
+		}
+		if i == len(sequence.Keyframes)-1 {
+			// Hold the final state of the last keyframe.
+			keyframe.render(pixels, keyframe.DurationMs, isRed)
+			return
+		}
+		elapsedMs -= keyframe.DurationMs
+	}
+}
+
+// Returns the pixel values of the sequence at regular intervals for the given length of time, formatted as "#rrggbb"
+// strings for display in a browser.
+func (sequence *Sequence) Preview(numPixels int, isRed bool, frameInterval, length time.Duration) [][]string {
+	pixels := make([][3]byte, numPixels)
+	var frames [][]string
+	for elapsed := time.Duration(0); elapsed < length; elapsed += frameInterval {
+		sequence.Render(pixels, elapsed, isRed)
+		frame := make([]string, numPixels)
+		for i, pixel := range pixels {
+			frame[i] = "#" + hex.EncodeToString(pixel[:])
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// Returns the total length of the sequence in milliseconds, or zero if the last keyframe lasts indefinitely.
+func (sequence *Sequence) durationMs() int {
+	totalMs := 0
+	for _, keyframe := range sequence.Keyframes {
+		if keyframe.DurationMs == 0 {
+			return 0
+		}
+		totalMs += keyframe.DurationMs
+	}
+	return totalMs
+}
+
+// Returns how long the preview of the sequence should run to show it in its entirety.
+func (sequence *Sequence) PreviewLength() time.Duration {
+	const maxPreviewMs = 10000
+	totalMs := sequence.durationMs()
+	if totalMs == 0 {
+		// Show the effect of the last keyframe for a while after reaching it.
+		for _, keyframe := range sequence.Keyframes {
+			totalMs += keyframe.DurationMs
+		}
+		totalMs += 3000
+	} else if !sequence.Loop {
+		totalMs += 1000
+	}
+	if totalMs > maxPreviewMs {
+		totalMs = maxPreviewMs
+	}
+	return time.Duration(totalMs) * time.Millisecond
+}
+
+func (keyframe *Keyframe) render(pixels [][3]byte, elapsedMs int, isRed bool) {
+	colors := make([][3]byte, len(keyframe.Colors))
+	for i, name := range keyframe.Colors {
+		colors[i], _ = parseColor(name, isRed)
+	}
+	numPixels := len(pixels)
+	step := 0
+	if keyframe.PeriodMs > 0 {
+		step = elapsedMs / keyframe.PeriodMs
+	}
+
+	switch keyframe.Effect {
+	case SolidEffect:
+		fillPixels(pixels, colors[0])
+	case FadeEffect:
+		fillPixels(pixels, getFadeColor(colors[0], colors[1], elapsedMs, keyframe.DurationMs))
+	case BlinkEffect:
+		if len(colors) == 1 {
+			colors = append(colors, Colors[Black])
+		}
+		fillPixels(pixels, colors[step%len(colors)])
+	case ChaseEffect:
+		for i := 0; i < numPixels; i++ {
+			pixels[keyframe.pixelIndex(i, numPixels)] = colors[(i+step)%len(colors)]
+		}
+	case WipeEffect:
+		background := Colors[Black]
+		if len(colors) > 1 {
+			background = colors[1]
+		}
+		fillPixels(pixels, background)
+		length := numPixels
+		if keyframe.Symmetric {
+			length = (numPixels + 1) / 2
+		}
+		numLitPixels := length * elapsedMs / keyframe.DurationMs
+		if numLitPixels > length {
+			numLitPixels = length
+		}
+		for i := 0; i < numLitPixels; i++ {
+			pixels[keyframe.pixelIndex(i, numPixels)] = colors[0]
+			if keyframe.Symmetric {
+				pixels[keyframe.pixelIndex(numPixels-i-1, numPixels)] = colors[0]
+			}
+		}
+	case GradientEffect:
+		if len(colors) == 0 {
+			colors = [][3]byte{Colors[Red], Colors[Green], Colors[Blue]}
+		}
+		for i := 0; i < numPixels; i++ {
+			pixels[keyframe.pixelIndex(i, numPixels)] = getGradientColor(colors, i+step, keyframe.Width)
+		}
+	case RandomEffect:
+		for i := 0; i < numPixels; i++ {
+			pixels[i] = colors[pseudoRandom(step, i)%uint32(len(colors))]
+		}
+	}
+}
+
+// Maps the given position along the direction of the effect to the index of the pixel in the strip.
+func (keyframe *Keyframe) pixelIndex(position, numPixels int) int {
+	if keyframe.Reverse {
+		return numPixels - position - 1
+	}
+	return position
+}
+
+// Converts the given color name into its RGB value for a strip belonging to the given alliance.
+func parseColor(name string, isRed bool) ([3]byte, error) {
+	switch name {
+	case "alliance":
+		return Colors[allianceColor(isRed, Red, Blue)], nil
+	case "opposite":
+		return Colors[allianceColor(isRed, Blue, Red)], nil
+	case "dimAlliance":
+		return Colors[allianceColor(isRed, DimRed, DimBlue)], nil
+	case "dimOpposite":
+		return Colors[allianceColor(isRed, DimBlue, DimRed)], nil
+	case "midAlliance":
+		return Colors[allianceColor(isRed, PurpleBlue, PurpleRed)], nil
+	}
+	if color, ok := colorNames[name]; ok {
+		return Colors[color], nil
+	}
+	var rgb [3]byte
+	if len(name) == 7 && name[0] == '#' {
+		if bytes, err := hex.DecodeString(name[1:]); err == nil {
+			copy(rgb[:], bytes)
+			return rgb, nil
+		}
+	}
+	return rgb, fmt.Errorf("unknown color '%s'.", name)
+}
+
+func allianceColor(isRed bool, redColor, blueColor Color) Color {
+	if isRed {
+		return redColor
+	}
+	return blueColor
+}
+
+func fillPixels(pixels [][3]byte, color [3]byte) {
+	for i := range pixels {
+		pixels[i] = color
+	}
+}
+
+// Interpolates between the two colors based on the given fraction.
+func getFadeColor(from, to [3]byte, numerator, denominator int) [3]byte {
+	if numerator > denominator {
+		numerator = denominator
+	}
+	var fadeColor [3]byte
+	for i := 0; i < 3; i++ {
+		fadeColor[i] = byte(int(from[i]) + numerator*(int(to[i])-int(from[i]))/denominator)
+	}
+	return fadeColor
+}
+
+// Calculates the value of a single pixel in a gradient that cycles through the given colors over the given width.
+func getGradientColor(colors [][3]byte, offset, width int) [3]byte {
+	offset %= width
+	segment := offset * len(colors) / width
+	segmentOffset := offset*len(colors) - segment*width
+	return getFadeColor(colors[segment], colors[(segment+1)%len(colors)], segmentOffset, width)
+}
+
+// Returns a repeatable pseudo-random number for the given step and pixel, so that rendering doesn't depend on state.
+func pseudoRandom(step, pixel int) uint32 {
+	value := uint32(step)*2654435761 ^ uint32(pixel)*40503
+	value ^= value >> 13
+	value *= 1274126177
+	value ^= value >> 16
+	return value
+}
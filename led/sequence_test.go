@@ -0,0 +1,128 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDefaultSequencesAreValid(t *testing.T) {
+	for _, sequence := range DefaultSequences {
+		assert.Nil(t, sequence.Validate(), sequence.Name)
+	}
+}
+
+func TestSequenceValidation(t *testing.T) {
+	sequence := Sequence{Name: "Test"}
+	assert.Contains(t, sequence.Validate().Error(), "at least one keyframe")
+	sequence.Keyframes = []Keyframe{{Effect: "sparkle", Colors: []string{"red"}}}
+	assert.Contains(t, sequence.Validate().Error(), "unknown effect 'sparkle'")
+	sequence.Keyframes = []Keyframe{{Effect: SolidEffect, Colors: []string{"chartreuse"}}}
+	assert.Contains(t, sequence.Validate().Error(), "unknown color 'chartreuse'")
+	sequence.Keyframes = []Keyframe{{Effect: FadeEffect, Colors: []string{"red"}, DurationMs: 100}}
+	assert.Contains(t, sequence.Validate().Error(), "can't take 1 colors")
+	sequence.Keyframes = []Keyframe{{Effect: BlinkEffect, Colors: []string{"red"}}}
+	assert.Contains(t, sequence.Validate().Error(), "must have a period")
+	sequence.Keyframes = []Keyframe{{Effect: SolidEffect, Colors: []string{"red"}},
+		{Effect: SolidEffect, Colors: []string{"#00ff00"}}}
+	assert.Contains(t, sequence.Validate().Error(), "only the last keyframe")
+	sequence.Keyframes[0].DurationMs = 100
+	assert.Nil(t, sequence.Validate())
+	sequence.Loop = true
+	assert.Contains(t, sequence.Validate().Error(), "only the last keyframe")
+}
+
+func TestSequenceRenderKeyframes(t *testing.T) {
+	sequence := Sequence{Name: "Test", Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"alliance"}, DurationMs: 100},
+		{Effect: FadeEffect, Colors: []string{"black", "#c8c8c8"}, DurationMs: 200},
+	}}
+	pixels := make([][3]byte, 4)
+	sequence.Render(pixels, 50*time.Millisecond, true)
+	assert.Equal(t, Colors[Red], pixels[0])
+	sequence.Render(pixels, 50*time.Millisecond, false)
+	assert.Equal(t, Colors[Blue], pixels[3])
+	sequence.Render(pixels, 150*time.Millisecond, true)
+	assert.Equal(t, [3]byte{50, 50, 50}, pixels[0])
+
+	// Check that the last keyframe is held at its end state unless the sequence loops.
+	sequence.Render(pixels, time.Second, true)
+	assert.Equal(t, [3]byte{200, 200, 200}, pixels[0])
+	sequence.Loop = true
+	sequence.Render(pixels, 350*time.Millisecond, true)
+	assert.Equal(t, Colors[Red], pixels[0])
+}
+
+func TestSequenceRenderEffects(t *testing.T) {
+	pixels := make([][3]byte, 4)
+	red, blue, black := Colors[Red], Colors[Blue], Colors[Black]
+
+	blink := Sequence{Keyframes: []Keyframe{{Effect: BlinkEffect, Colors: []string{"red"}, PeriodMs: 50}}}
+	blink.Render(pixels, 40*time.Millisecond, true)
+	assert.Equal(t, red, pixels[0])
+	blink.Render(pixels, 60*time.Millisecond, true)
+	assert.Equal(t, black, pixels[0])
+
+	chase := Sequence{Keyframes: []Keyframe{{Effect: ChaseEffect, Colors: []string{"red", "blue"}, PeriodMs: 100}}}
+	chase.Render(pixels, 0, true)
+	assert.Equal(t, [][3]byte{red, blue, red, blue}, pixels)
+	chase.Render(pixels, 100*time.Millisecond, true)
+	assert.Equal(t, [][3]byte{blue, red, blue, red}, pixels)
+
+	wipe := Sequence{Keyframes: []Keyframe{{Effect: WipeEffect, Colors: []string{"red", "blue"}, DurationMs: 400}}}
+	wipe.Render(pixels, 200*time.Millisecond, true)
+	assert.Equal(t, [][3]byte{red, red, blue, blue}, pixels)
+	wipe.Keyframes[0].Reverse = true
+	wipe.Render(pixels, 100*time.Millisecond, true)
+	assert.Equal(t, [][3]byte{blue, blue, blue, red}, pixels)
+	wipe.Keyframes[0].Reverse = false
+	wipe.Keyframes[0].Symmetric = true
+	wipe.Render(pixels, 200*time.Millisecond, true)
+	assert.Equal(t, [][3]byte{red, blue, blue, red}, pixels)
+
+	gradient := Sequence{Keyframes: []Keyframe{{Effect: GradientEffect, Colors: []string{"red", "blue"}, PeriodMs: 10,
+		Width: 4}}}
+	gradient.Render(pixels, 0, true)
+	assert.Equal(t, [][3]byte{red, {128, 0, 127}, blue, {127, 0, 128}}, pixels)
+
+	// Check that the random effect is repeatable for the same step.
+	random := Sequence{Keyframes: []Keyframe{{Effect: RandomEffect, Colors: []string{"red", "blue"}, PeriodMs: 100}}}
+	random.Render(pixels, 10*time.Millisecond, true)
+	firstPixels := append([][3]byte{}, pixels...)
+	random.Render(pixels, 90*time.Millisecond, true)
+	assert.Equal(t, firstPixels, pixels)
+}
+
+func TestSequencePreview(t *testing.T) {
+	sequence := Sequence{Name: "Test", Loop: true, Keyframes: []Keyframe{
+		{Effect: SolidEffect, Colors: []string{"red"}, DurationMs: 100},
+		{Effect: SolidEffect, Colors: []string{"teal"}, DurationMs: 100},
+	}}
+	assert.Equal(t, 200*time.Millisecond, sequence.PreviewLength())
+	frames := sequence.Preview(2, true, 50*time.Millisecond, sequence.PreviewLength())
+	assert.Equal(t, [][]string{{"#ff0000", "#ff0000"}, {"#ff0000", "#ff0000"}, {"#006464", "#006464"},
+		{"#006464", "#006464"}}, frames)
+
+	sequence.Loop = false
+	assert.Equal(t, 1200*time.Millisecond, sequence.PreviewLength())
+	sequence.Keyframes[1].DurationMs = 0
+	assert.Equal(t, 3100*time.Millisecond, sequence.PreviewLength())
+}
+
+func TestControllerSequences(t *testing.T) {
+	var controller Controller
+	assert.NotNil(t, controller.Configure(0, 1, 2))
+	assert.NotNil(t, controller.Configure(MaxNumPixels+1, 1, 2))
+	assert.NotNil(t, controller.Configure(100, 1, 1))
+	assert.Nil(t, controller.Configure(100, 3, 4))
+
+	assert.Equal(t, OffSequence, controller.GetCurrentSequence())
+	owned := &Sequence{Name: "Owned"}
+	controller.SetSequence(owned, owned)
+	assert.Equal(t, "Owned", controller.GetCurrentSequence())
+	controller.SetSequence(owned, &Sequence{Name: "Not Owned"})
+	assert.Equal(t, OffSequence, controller.GetCurrentSequence())
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Packet construction for the Art-Net protocol.
+
+package lighting
+
+const (
+	artNetPort            = 6454
+	artNetDataOffset      = 18
+	artNetSyncPacketSize  = 14
+	artNetProtocolVersion = 14
+	artNetMaxUniverse     = 32767
+	artNetOpDmx           = 0x5000
+	artNetOpSync          = 0x5200
+)
+
+// Constructs an ArtDmx packet carrying a full universe of DMX data. The universe is used as the 15-bit Art-Net port
+// address.
+func createArtNetDmxPacket(universe int, sequence byte, data *[UniverseSize]byte) []byte {
+	packet := make([]byte, artNetDataOffset+UniverseSize)
+	writeArtNetHeader(packet, artNetOpDmx)
+	packet[12] = sequence
+
+	// Physical input port (informational only)
+	packet[13] = 0
+
+	// Sub-net and universe in the low byte, followed by the net in the high byte
+	packet[14] = byte(universe & 0xff)
+	packet[15] = byte(universe >> 8 & 0x7f)
+
+	// Data length
+	packet[16] = byte(UniverseSize >> 8)
+	packet[17] = byte(UniverseSize & 0xff)
+
+	copy(packet[artNetDataOffset:], data[:])
+	return packet
+}
+
+// Constructs an ArtSync packet, which tells receivers to output the data they have buffered.
+func createArtNetSyncPacket() []byte {
+	packet := make([]byte, artNetSyncPacketSize)
+	writeArtNetHeader(packet, artNetOpSync)
+	return packet
+}
+
+// Populates the ID, opcode and protocol version common to all Art-Net packets.
+func writeArtNetHeader(packet []byte, opCode int) {
+	copy(packet[0:8], "Art-Net\x00")
+
+	// The opcode is little-endian, unlike the rest of the packet.
+	packet[8] = byte(opCode & 0xff)
+	packet[9] = byte(opCode >> 8)
+
+	packet[10] = 0
+	packet[11] = artNetProtocolVersion
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Drives a set of DMX fixtures through lighting cues, fading between them and sending the result to the output.
+
+package lighting
+
+import (
+	"sync"
+	"time"
+)
+
+// The exported methods are safe to call from different goroutines, since the fixtures are reloaded from the web
+// handlers while the arena loop is sending them to the output.
+type Controller struct {
+	mutex      sync.Mutex
+	currentCue string
+	output     Output
+	fixtures   []Fixture
+	fromStates []FixtureState
+	toStates   []FixtureState
+	fadeStart  time.Time
+	fadeMs     int
+}
+
+// Sets where and how the DMX data is sent; see Output.Configure.
+func (controller *Controller) Configure(protocol, address, componentId string, priority, syncUniverse int) error {
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	return controller.output.Configure(protocol, address, componentId, priority, syncUniverse)
+}
+
+// Sets the fixtures being controlled. Fixtures that were already being controlled, matched by name, keep their state
+// and any fade in progress, while new ones start off.
+func (controller *Controller) SetFixtures(fixtures []Fixture) {
+	// Replace the fixtures and their states together so that they always correspond.
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	fromStates := make([]FixtureState, len(fixtures))
+	toStates := make([]FixtureState, len(fixtures))
+	for i, fixture := range fixtures {
+		for j, oldFixture := range controller.fixtures {
+			if oldFixture.Name == fixture.Name {
+				fromStates[i] = controller.fromStates[j]
+				toStates[i] = controller.toStates[j]
+				break
+			}
+		}
+	}
+	controller.fixtures = fixtures
+	controller.fromStates = fromStates
+	controller.toStates = toStates
+}
+
+// Starts fading the fixtures to the given cue, given the alliance that won the most recent match ("R", "B" or "T").
+func (controller *Controller) FireCue(cue *Cue, winner string) {
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	controller.fireCue(cue, winner, time.Now())
+}
+
+// Returns the name of the most recently fired cue, or a blank string if there hasn't been one.
+func (controller *Controller) GetCurrentCue() string {
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	return controller.currentCue
+}
+
+// Returns the state of each fixture at the current point in any fade.
+func (controller *Controller) GetFixtureStates() []FixtureState {
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	return controller.getFixtureStates(time.Now())
+}
+
+// Sends the current state of the fixtures to the output if necessary. Should be called from a timed loop.
+func (controller *Controller) Update() error {
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	return controller.update(time.Now())
+}
+
+func (controller *Controller) fireCue(cue *Cue, winner string, now time.Time) {
+	controller.fromStates = controller.getFixtureStates(now)
+	for i, fixture := range controller.fixtures {
+		for _, look := range cue.Looks {
+			if fixture.matchesTarget(look.Target) {
+				controller.toStates[i] = look.apply(controller.toStates[i], winner)
+			}
+		}
+	}
+	controller.fadeStart = now
+	controller.fadeMs = cue.FadeMs
+	controller.currentCue = cue.Name
+}
+
+func (controller *Controller) getFixtureStates(now time.Time) []FixtureState {
+	states := make([]FixtureState, len(controller.fixtures))
+	progress := 1.0
+	if elapsedMs := now.Sub(controller.fadeStart).Seconds() * 1000; elapsedMs < float64(controller.fadeMs) {
+		progress = elapsedMs / float64(controller.fadeMs)
+	}
+	for i := range controller.fixtures {
+		states[i] = interpolateState(controller.fromStates[i], controller.toStates[i], progress)
+	}
+	return states
+}
+
+func (controller *Controller) update(now time.Time) error {
+	frames := make(map[int]*[UniverseSize]byte)
+	for i, state := range controller.getFixtureStates(now) {
+		fixture := &controller.fixtures[i]
+		data, ok := frames[fixture.Universe]
+		if !ok {
+			data = new([UniverseSize]byte)
+			frames[fixture.Universe] = data
+		}
+		fixture.render(state, data)
+	}
+	return controller.output.send(frames, now)
+}
+
+// Returns the state the given fraction of the way between the two states. The strobe changes immediately since
+// intermediate values don't represent intermediate rates on most fixtures.
+func interpolateState(from, to FixtureState, progress float64) FixtureState {
+	state := FixtureState{Strobe: to.Strobe}
+	for i := range state.Color {
+		state.Color[i] = byte(float64(from.Color[i]) + progress*(float64(to.Color[i])-float64(from.Color[i])))
+	}
+	state.Dimmer = from.Dimmer + progress*(to.Dimmer-from.Dimmer)
+	state.Pan = from.Pan + progress*(to.Pan-from.Pan)
+	state.Tilt = from.Tilt + progress*(to.Tilt-from.Tilt)
+	return state
+}
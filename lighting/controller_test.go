@@ -0,0 +1,141 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package lighting
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFixtureValidate(t *testing.T) {
+	fixture := Fixture{Name: "Stage Wash", Profile: "RGBW Par", Universe: 1, Address: 509}
+	assert.Nil(t, fixture.Validate())
+
+	fixture.Address = 510
+	assert.EqualError(t, fixture.Validate(), "Fixture 'Stage Wash' must have its channels between addresses 1 and 512.")
+	fixture.Address = 1
+	fixture.Universe = 0
+	assert.EqualError(t, fixture.Validate(), "Fixture 'Stage Wash' must have a universe between 1 and 63999.")
+	fixture.Universe = 1
+	fixture.Profile = "Fog Machine"
+	assert.EqualError(t, fixture.Validate(), "Fixture 'Stage Wash' has unknown profile 'Fog Machine'.")
+	fixture.Profile = CustomProfile
+	assert.EqualError(t, fixture.Validate(), "Fixture 'Stage Wash' must have at least one channel.")
+	fixture.Channels = []string{DimmerChannel, "fog"}
+	assert.EqualError(t, fixture.Validate(), "Fixture 'Stage Wash' has unknown channel type 'fog'.")
+	fixture.Channels = []string{DimmerChannel, RedChannel}
+	assert.Nil(t, fixture.Validate())
+	fixture.Name = " "
+	assert.EqualError(t, fixture.Validate(), "The fixture name can't be blank.")
+}
+
+func TestFixtureRender(t *testing.T) {
+	var data [UniverseSize]byte
+	state := FixtureState{Color: [3]byte{255, 100, 50}, Dimmer: 50, Strobe: 20, Pan: 50, Tilt: 100}
+
+	// Fixtures without a dimmer channel scale their colors instead.
+	fixture := Fixture{Profile: "RGB Par", Address: 1}
+	fixture.render(state, &data)
+	assert.Equal(t, []byte{127, 50, 25}, data[0:3])
+
+	fixture = Fixture{Profile: "RGBW Par", Address: 10}
+	fixture.render(state, &data)
+	assert.Equal(t, []byte{102, 25, 0, 25}, data[9:13])
+
+	fixture = Fixture{Profile: "Dimmer RGB Par", Address: 20}
+	fixture.render(state, &data)
+	assert.Equal(t, []byte{127, 255, 100, 50, 20}, data[19:24])
+
+	fixture = Fixture{Profile: "Moving Head", Address: 30}
+	fixture.render(state, &data)
+	assert.Equal(t, []byte{127, 255, 255, 255, 0, 127, 20, 205, 50, 0, 50}, data[29:40])
+
+	fixture = Fixture{Profile: CustomProfile, Channels: []string{UnusedChannel, BlueChannel}, Address: 50}
+	fixture.render(state, &data)
+	assert.Equal(t, []byte{0, 25}, data[49:51])
+}
+
+func TestCueValidate(t *testing.T) {
+	var cue Cue
+	assert.Nil(t, json.Unmarshal([]byte(`{"Name": "Final Score", "FadeMs": 500, "Looks": [
+		{"Target": "all", "Color": "winner", "Dimmer": 100},
+		{"Target": "Movers", "Pan": 25, "Tilt": 75, "Strobe": 0}]}`), &cue))
+	assert.Nil(t, cue.Validate())
+	assert.Nil(t, cue.Looks[0].Pan)
+	assert.Equal(t, 75.0, *cue.Looks[1].Tilt)
+
+	cue.Looks[1].Color = "mauve"
+	assert.EqualError(t, cue.Validate(), "Look 2 of cue 'Final Score': unknown color 'mauve'.")
+	cue.Looks[1].Color = "#00ff00"
+	strobe := 256
+	cue.Looks[1].Strobe = &strobe
+	assert.EqualError(t, cue.Validate(),
+		"Look 2 of cue 'Final Score': the strobe must be a DMX value between 0 and 255.")
+	cue.Looks[1].Strobe = nil
+	cue.Looks[0].Target = ""
+	assert.EqualError(t, cue.Validate(), "Look 1 of cue 'Final Score': the target can't be blank.")
+	cue.Looks[0].Target = "all"
+	dimmer := 101.0
+	cue.Looks[0].Dimmer = &dimmer
+	assert.NotNil(t, cue.Validate())
+	cue.Looks[0].Dimmer = nil
+	cue.FadeMs = -1
+	assert.NotNil(t, cue.Validate())
+	cue.FadeMs = 0
+	cue.Name = ""
+	assert.EqualError(t, cue.Validate(), "The cue name can't be blank.")
+}
+
+func TestControllerCues(t *testing.T) {
+	var controller Controller
+	controller.SetFixtures([]Fixture{
+		{Name: "Wash 1", Group: "Wash", Profile: "Dimmer RGB Par", Universe: 1, Address: 1},
+		{Name: "Wash 2", Group: "Wash", Profile: "Dimmer RGB Par", Universe: 2, Address: 1},
+		{Name: "Mover", Profile: "Moving Head", Universe: 1, Address: 10},
+	})
+	full, half, quarter := 100.0, 50.0, 25.0
+
+	now := time.Now()
+	controller.fireCue(&Cue{Name: "Walk-in", Looks: []Look{{Target: AllFixtures, Color: "white", Dimmer: &full},
+		{Target: "Mover", Pan: &half, Tilt: &quarter}}}, "", now)
+	assert.Equal(t, "Walk-in", controller.currentCue)
+	states := controller.getFixtureStates(now)
+	assert.Equal(t, FixtureState{Color: [3]byte{255, 255, 255}, Dimmer: 100}, states[0])
+	assert.Equal(t, FixtureState{Color: [3]byte{255, 255, 255}, Dimmer: 100, Pan: 50, Tilt: 25}, states[2])
+
+	// Check that the cue fades and that attributes not set by the cue track their previous values.
+	controller.fireCue(&Cue{Name: "Final Score", FadeMs: 1000, Looks: []Look{{Target: "Wash", Color: WinnerColor,
+		Dimmer: &half}, {Target: "Mover", Color: WinnerColor}}}, "B", now)
+	states = controller.getFixtureStates(now.Add(500 * time.Millisecond))
+	assert.Equal(t, FixtureState{Color: [3]byte{127, 127, 255}, Dimmer: 75}, states[0])
+	assert.Equal(t, FixtureState{Color: [3]byte{127, 127, 255}, Dimmer: 100, Pan: 50, Tilt: 25}, states[2])
+	states = controller.getFixtureStates(now.Add(1500 * time.Millisecond))
+	assert.Equal(t, FixtureState{Color: [3]byte{0, 0, 255}, Dimmer: 50}, states[1])
+	assert.Equal(t, FixtureState{Color: [3]byte{0, 0, 255}, Dimmer: 100, Pan: 50, Tilt: 25}, states[2])
+
+	// Check that each fixture is rendered into its own universe.
+	receiver, address := setupTestReceiver(t)
+	defer receiver.Close()
+	assert.Nil(t, controller.Configure(E131Protocol, address, testComponentId, DefaultPriority, 0))
+	assert.Nil(t, controller.update(now.Add(1500*time.Millisecond)))
+	packet := receivePacket(t, receiver)
+	assert.Equal(t, []byte{0, 1}, packet[113:115])
+	assert.Equal(t, []byte{127, 0, 0, 255, 0}, packet[126:131])
+	assert.Equal(t, []byte{127, 255, 63, 255, 0, 255, 0, 0, 0, 255, 0}, packet[135:146])
+	packet = receivePacket(t, receiver)
+	assert.Equal(t, []byte{0, 2}, packet[113:115])
+	assert.Equal(t, []byte{127, 0, 0, 255, 0}, packet[126:131])
+
+	// Check that reloading the fixtures keeps the state of the existing ones and starts the new ones off.
+	controller.SetFixtures([]Fixture{
+		{Name: "Mover", Profile: "Moving Head", Universe: 1, Address: 20},
+		{Name: "Wash 3", Group: "Wash", Profile: "Dimmer RGB Par", Universe: 3, Address: 1},
+	})
+	assert.Equal(t, "Final Score", controller.currentCue)
+	states = controller.getFixtureStates(now.Add(1500 * time.Millisecond))
+	assert.Equal(t, FixtureState{Color: [3]byte{0, 0, 255}, Dimmer: 100, Pan: 50, Tilt: 25}, states[0])
+	assert.Equal(t, FixtureState{}, states[1])
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Data-driven definition of a lighting cue as a set of looks applied to fixtures.
+
+package lighting
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	AllFixtures = "all"    // A look target that matches every fixture.
+	WinnerColor = "winner" // The color of the alliance that won the match, or white if it was a tie.
+)
+
+type Cue struct {
+	Name   string
+	FadeMs int // How long the fixtures take to reach the new look from wherever they were.
+	Looks  []Look
+}
+
+// The state to apply to the fixtures matching the target, which is the name of a fixture, the name of a group of
+// fixtures, or "all". Attributes that are left unset keep the value they had before the cue, so that for example a
+// color change doesn't also move the moving heads. Colors are given by name (e.g. "red"), as "#rrggbb", or as
+// "winner".
+type Look struct {
+	Target string
+	Color  string   `json:",omitempty"`
+	Dimmer *float64 `json:",omitempty"`
+	Strobe *int     `json:",omitempty"`
+	Pan    *float64 `json:",omitempty"`
+	Tilt   *float64 `json:",omitempty"`
+}
+
+var colorNames = map[string][3]byte{
+	"red":    {255, 0, 0},
+	"orange": {255, 50, 0},
+	"yellow": {255, 255, 0},
+	"green":  {0, 255, 0},
+	"teal":   {0, 100, 100},
+	"blue":   {0, 0, 255},
+	"purple": {100, 0, 100},
+	"white":  {255, 255, 255},
+	"black":  {0, 0, 0},
+	"off":    {0, 0, 0},
+}
+
+// Checks that the cue is well-formed, returning an error describing the first problem found.
+func (cue *Cue) Validate() error {
+	if strings.TrimSpace(cue.Name) == "" {
+		return fmt.Errorf("The cue name can't be blank.")
+	}
+	if cue.FadeMs < 0 {
+		return fmt.Errorf("The fade time of cue '%s' can't be negative.", cue.Name)
+	}
+	for i, look := range cue.Looks {
+		if err := look.validate(); err != nil {
+			return fmt.Errorf("Look %d of cue '%s': %s", i+1, cue.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (look *Look) validate() error {
+	if strings.TrimSpace(look.Target) == "" {
+		return fmt.Errorf("the target can't be blank.")
+	}
+	if look.Color != "" {
+		if _, err := parseColor(look.Color, ""); err != nil {
+			return err
+		}
+	}
+	for _, percent := range []*float64{look.Dimmer, look.Pan, look.Tilt} {
+		if percent != nil && (*percent < 0 || *percent > 100) {
+			return fmt.Errorf("the dimmer, pan and tilt must be percentages between 0 and 100.")
+		}
+	}
+	if look.Strobe != nil && (*look.Strobe < 0 || *look.Strobe > 255) {
+		return fmt.Errorf("the strobe must be a DMX value between 0 and 255.")
+	}
+	return nil
+}
+
+// Returns the given fixture state modified by the attributes that the look sets.
+func (look *Look) apply(state FixtureState, winner string) FixtureState {
+	if look.Color != "" {
+		state.Color, _ = parseColor(look.Color, winner)
+	}
+	if look.Dimmer != nil {
+		state.Dimmer = *look.Dimmer
+	}
+	if look.Strobe != nil {
+		state.Strobe = *look.Strobe
+	}
+	if look.Pan != nil {
+		state.Pan = *look.Pan
+	}
+	if look.Tilt != nil {
+		state.Tilt = *look.Tilt
+	}
+	return state
+}
+
+// Converts the given color name into its RGB value, given the alliance that won the match ("R", "B" or anything else
+// for a tie).
+func parseColor(name, winner string) ([3]byte, error) {
+	if name == WinnerColor {
+		switch winner {
+		case "R":
+			return colorNames["red"], nil
+		case "B":
+			return colorNames["blue"], nil
+		default:
+			return colorNames["white"], nil
+		}
+	}
+	if color, ok := colorNames[name]; ok {
+		return color, nil
+	}
+	var rgb [3]byte
+	if len(name) == 7 && name[0] == '#' {
+		if bytes, err := hex.DecodeString(name[1:]); err == nil {
+			copy(rgb[:], bytes)
+			return rgb, nil
+		}
+	}
+	return rgb, fmt.Errorf("unknown color '%s'.", name)
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Packet construction for the E1.31 (Streaming ACN) protocol.
+
+package lighting
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	e131Port              = 5568
+	e131DataOffset        = 126
+	e131SyncPacketSize    = 49
+	e131SourceName        = "Cheesy Arena"
+	e131MulticastPrefixA  = 239
+	e131MulticastPrefixB  = 255
+	e131MaxUniverse       = 63999
+	e131ComponentIdLength = 16
+)
+
+// Returns a new random component ID encoded as hex, for identifying an installation to the E1.31 receivers. It should
+// be stored so that the receivers see the same source across restarts.
+func NewComponentId() string {
+	var componentId [e131ComponentIdLength]byte
+	rand.Read(componentId[:])
+	return hex.EncodeToString(componentId[:])
+}
+
+func parseComponentId(componentIdHex string) ([e131ComponentIdLength]byte, error) {
+	var componentId [e131ComponentIdLength]byte
+	data, err := hex.DecodeString(componentIdHex)
+	if err != nil || len(data) != e131ComponentIdLength {
+		return componentId, fmt.Errorf("Invalid lighting component ID '%s'.", componentIdHex)
+	}
+	copy(componentId[:], data)
+	return componentId, nil
+}
+
+// Constructs an E1.31 data packet carrying a full universe of DMX data.
+func createE131DataPacket(componentId *[e131ComponentIdLength]byte, universe, priority, syncUniverse int,
+	sequence byte, data *[UniverseSize]byte) []byte {
+	size := e131DataOffset + UniverseSize
+	packet := make([]byte, size)
+	writeE131RootLayer(packet, 0x00000004, componentId)
+
+	// Framing PDU length and flags
+	framingPduLength := size - 38
+	packet[38] = 0x70 | byte(framingPduLength>>8)
+	packet[39] = byte(framingPduLength & 0xff)
+
+	// E1.31 vector indicating that this is a data packet
+	packet[43] = 0x02
+
+	// Source name
+	copy(packet[44:108], e131SourceName)
+
+	packet[108] = byte(priority)
+
+	// Universe that the receiver should wait on for a synchronization packet before acting on the data, if any
+	packet[109] = byte(syncUniverse >> 8)
+	packet[110] = byte(syncUniverse & 0xff)
+
+	packet[111] = sequence
+
+	// Options flags
+	packet[112] = 0x00
+
+	packet[113] = byte(universe >> 8)
+	packet[114] = byte(universe & 0xff)
+
+	// DMP layer PDU length
+	dmpPduLength := size - 115
+	packet[115] = 0x70 | byte(dmpPduLength>>8)
+	packet[116] = byte(dmpPduLength & 0xff)
+
+	// E1.31 vector indicating set property
+	packet[117] = 0x02
+
+	// Address and data type
+	packet[118] = 0xa1
+
+	// First property address
+	packet[119] = 0x00
+	packet[120] = 0x00
+
+	// Address increment
+	packet[121] = 0x00
+	packet[122] = 0x01
+
+	// Property value count
+	count := 1 + UniverseSize
+	packet[123] = byte(count >> 8)
+	packet[124] = byte(count & 0xff)
+
+	// DMX start code
+	packet[125] = 0
+
+	copy(packet[e131DataOffset:], data[:])
+	return packet
+}
+
+// Constructs an E1.31 synchronization packet, which tells receivers to act on the data they have buffered for the
+// universes that reference the given synchronization universe.
+func createE131SyncPacket(componentId *[e131ComponentIdLength]byte, syncUniverse int, sequence byte) []byte {
+	packet := make([]byte, e131SyncPacketSize)
+	writeE131RootLayer(packet, 0x00000008, componentId)
+
+	// Framing PDU length and flags
+	framingPduLength := e131SyncPacketSize - 38
+	packet[38] = 0x70 | byte(framingPduLength>>8)
+	packet[39] = byte(framingPduLength & 0xff)
+
+	// E1.31 vector indicating that this is a synchronization packet
+	packet[43] = 0x01
+
+	packet[44] = sequence
+	packet[45] = byte(syncUniverse >> 8)
+	packet[46] = byte(syncUniverse & 0xff)
+
+	// The remaining two bytes are reserved.
+	return packet
+}
+
+// Populates the preamble and root layer common to all E1.31 packets.
+func writeE131RootLayer(packet []byte, vector byte, componentId *[e131ComponentIdLength]byte) {
+	// Preamble size
+	packet[0] = 0x00
+	packet[1] = 0x10
+
+	// ACN packet identifier
+	copy(packet[4:16], []byte{0x41, 0x53, 0x43, 0x2d, 0x45, 0x31, 0x2e, 0x31, 0x37, 0x00, 0x00, 0x00})
+
+	// Root PDU length and flags
+	rootPduLength := len(packet) - 16
+	packet[16] = 0x70 | byte(rootPduLength>>8)
+	packet[17] = byte(rootPduLength & 0xff)
+
+	packet[21] = vector
+
+	copy(packet[22:22+e131ComponentIdLength], componentId[:])
+}
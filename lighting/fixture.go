@@ -0,0 +1,204 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// DMX fixture profiles and the logic for rendering the state of a fixture into its channels.
+
+package lighting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Functions that a DMX channel of a fixture can have.
+const (
+	DimmerChannel   = "dimmer"
+	RedChannel      = "red"
+	GreenChannel    = "green"
+	BlueChannel     = "blue"
+	WhiteChannel    = "white"
+	AmberChannel    = "amber"
+	StrobeChannel   = "strobe"
+	PanChannel      = "pan"
+	PanFineChannel  = "panFine"
+	TiltChannel     = "tilt"
+	TiltFineChannel = "tiltFine"
+	UnusedChannel   = "unused"
+)
+
+var Channels = []string{DimmerChannel, RedChannel, GreenChannel, BlueChannel, WhiteChannel, AmberChannel,
+	StrobeChannel, PanChannel, PanFineChannel, TiltChannel, TiltFineChannel, UnusedChannel}
+
+// The profile name for a fixture whose channels are given individually.
+const CustomProfile = "Custom"
+
+type FixtureProfile struct {
+	Name     string
+	Channels []string
+}
+
+// Channel layouts of common fixtures. Others can be set up using the custom profile.
+var FixtureProfiles = []FixtureProfile{
+	{"RGB Par", []string{RedChannel, GreenChannel, BlueChannel}},
+	{"RGBW Par", []string{RedChannel, GreenChannel, BlueChannel, WhiteChannel}},
+	{"Dimmer RGB Par", []string{DimmerChannel, RedChannel, GreenChannel, BlueChannel, StrobeChannel}},
+	{"Dimmer RGBAW Par", []string{DimmerChannel, RedChannel, GreenChannel, BlueChannel, AmberChannel, WhiteChannel,
+		StrobeChannel}},
+	{"Moving Head", []string{PanChannel, PanFineChannel, TiltChannel, TiltFineChannel, UnusedChannel, DimmerChannel,
+		StrobeChannel, RedChannel, GreenChannel, BlueChannel, WhiteChannel}},
+}
+
+type Fixture struct {
+	Name     string
+	Group    string   // Optional name by which cues can address several fixtures at once.
+	Profile  string   // The name of one of the FixtureProfiles, or CustomProfile.
+	Channels []string // The channel layout if using the custom profile.
+	Universe int
+	Address  int // The DMX address of the first channel, starting at 1.
+}
+
+// The output of a fixture at a moment in time.
+type FixtureState struct {
+	Color  [3]byte
+	Dimmer float64 // Percentage of full intensity.
+	Strobe int     // Raw DMX value, since the meaning varies by fixture; zero is normally off.
+	Pan    float64 // Percentage of the full pan range.
+	Tilt   float64 // Percentage of the full tilt range.
+}
+
+// Returns the built-in profile having the given name, or nil if there is none.
+func GetFixtureProfile(name string) *FixtureProfile {
+	for i, profile := range FixtureProfiles {
+		if profile.Name == name {
+			return &FixtureProfiles[i]
+		}
+	}
+	return nil
+}
+
+// Checks that the fixture is well-formed, returning an error describing the first problem found.
+func (fixture *Fixture) Validate() error {
+	if strings.TrimSpace(fixture.Name) == "" {
+		return fmt.Errorf("The fixture name can't be blank.")
+	}
+	if fixture.Profile == CustomProfile {
+		if len(fixture.Channels) == 0 {
+			return fmt.Errorf("Fixture '%s' must have at least one channel.", fixture.Name)
+		}
+		for _, channel := range fixture.Channels {
+			if !isValidChannel(channel) {
+				return fmt.Errorf("Fixture '%s' has unknown channel type '%s'.", fixture.Name, channel)
+			}
+		}
+	} else if GetFixtureProfile(fixture.Profile) == nil {
+		return fmt.Errorf("Fixture '%s' has unknown profile '%s'.", fixture.Name, fixture.Profile)
+	}
+	if fixture.Universe < 1 || fixture.Universe > e131MaxUniverse {
+		return fmt.Errorf("Fixture '%s' must have a universe between 1 and %d.", fixture.Name, e131MaxUniverse)
+	}
+	if fixture.Address < 1 || fixture.Address+len(fixture.GetChannels())-1 > UniverseSize {
+		return fmt.Errorf("Fixture '%s' must have its channels between addresses 1 and %d.", fixture.Name,
+			UniverseSize)
+	}
+	return nil
+}
+
+// Returns the layout of the fixture's channels.
+func (fixture *Fixture) GetChannels() []string {
+	if fixture.Profile == CustomProfile {
+		return fixture.Channels
+	}
+	if profile := GetFixtureProfile(fixture.Profile); profile != nil {
+		return profile.Channels
+	}
+	return nil
+}
+
+// Returns true if the fixture should respond to a look having the given target.
+func (fixture *Fixture) matchesTarget(target string) bool {
+	return target == AllFixtures || target == fixture.Name || fixture.Group != "" && target == fixture.Group
+}
+
+// Sets the fixture's channels in the given universe data to represent the given state.
+func (fixture *Fixture) render(state FixtureState, data *[UniverseSize]byte) {
+	channels := fixture.GetChannels()
+	hasDimmer, hasWhite := false, false
+	for _, channel := range channels {
+		hasDimmer = hasDimmer || channel == DimmerChannel
+		hasWhite = hasWhite || channel == WhiteChannel
+	}
+
+	// Fixtures without a dimmer channel are dimmed by scaling their colors.
+	color := state.Color
+	if !hasDimmer {
+		for i := range color {
+			color[i] = byte(float64(color[i]) * clampPercent(state.Dimmer) / 100)
+		}
+	}
+
+	// Move the component of the color that all three share onto the white channel, if there is one.
+	var white byte
+	if hasWhite {
+		white = color[0]
+		for _, value := range color[1:] {
+			if value < white {
+				white = value
+			}
+		}
+		for i := range color {
+			color[i] -= white
+		}
+	}
+
+	pan := percentToUint16(state.Pan)
+	tilt := percentToUint16(state.Tilt)
+	for i, channel := range channels {
+		var value byte
+		switch channel {
+		case DimmerChannel:
+			value = byte(clampPercent(state.Dimmer) * 255 / 100)
+		case RedChannel:
+			value = color[0]
+		case GreenChannel:
+			value = color[1]
+		case BlueChannel:
+			value = color[2]
+		case WhiteChannel:
+			value = white
+		case StrobeChannel:
+			value = byte(state.Strobe)
+		case PanChannel:
+			value = byte(pan >> 8)
+		case PanFineChannel:
+			value = byte(pan & 0xff)
+		case TiltChannel:
+			value = byte(tilt >> 8)
+		case TiltFineChannel:
+			value = byte(tilt & 0xff)
+		}
+		data[fixture.Address-1+i] = value
+	}
+}
+
+func isValidChannel(channel string) bool {
+	for _, validChannel := range Channels {
+		if channel == validChannel {
+			return true
+		}
+	}
+	return false
+}
+
+func clampPercent(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 100 {
+		return 100
+	}
+	return value
+}
+
+func percentToUint16(value float64) int {
+	return int(clampPercent(value) * 65535 / 100)
+}
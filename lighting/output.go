@@ -0,0 +1,202 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Represents a network connection over which DMX universes are sent to lighting fixtures using either E1.31 or
+// Art-Net.
+
+package lighting
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Protocols that the output can speak.
+const (
+	E131Protocol   = "e131"
+	ArtNetProtocol = "artnet"
+)
+
+const (
+	UniverseSize     = 512
+	DefaultPriority  = 100
+	MaxPriority      = 200
+	MulticastAddress = "multicast" // Sends each E1.31 universe to its standard multicast group.
+	keepaliveMs      = 1000
+)
+
+var Protocols = []string{E131Protocol, ArtNetProtocol}
+
+type Output struct {
+	protocol     string
+	componentId  [e131ComponentIdLength]byte
+	priority     int
+	syncUniverse int
+	conn         *net.UDPConn
+	destination  *net.UDPAddr // Nil if sending to the E1.31 multicast groups.
+	universes    map[int]*universeState
+	syncSequence byte
+}
+
+// The most recently sent contents of a universe.
+type universeState struct {
+	data         [UniverseSize]byte
+	sequence     byte
+	lastSentTime time.Time
+}
+
+// Sets the protocol and destination of the output and the E1.31 component ID (see NewComponentId), priority and
+// synchronization universe (zero to disable synchronization). The address may include a port, and is either a unicast
+// address, a broadcast address for Art-Net, or "multicast" for E1.31. A blank address disables the output.
+func (output *Output) Configure(protocol, address, componentId string, priority, syncUniverse int) error {
+	if output.conn != nil {
+		output.conn.Close()
+		output.conn = nil
+	}
+	output.destination = nil
+	output.universes = make(map[int]*universeState)
+
+	if address == "" {
+		return nil
+	}
+	if err := ValidateOutputSettings(protocol, address, priority, syncUniverse); err != nil {
+		return err
+	}
+	var err error
+	if output.componentId, err = parseComponentId(componentId); err != nil {
+		return err
+	}
+	output.protocol = protocol
+	output.priority = priority
+	output.syncUniverse = syncUniverse
+
+	if address != MulticastAddress {
+		port := e131Port
+		if protocol == ArtNetProtocol {
+			port = artNetPort
+		}
+		if host, portString, err := net.SplitHostPort(address); err == nil {
+			address = host
+			port, _ = strconv.Atoi(portString)
+		}
+		output.destination, err = net.ResolveUDPAddr("udp4", net.JoinHostPort(address, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+	}
+	output.conn, err = net.ListenUDP("udp4", nil)
+	return err
+}
+
+// Checks the given output settings, returning an error describing the first problem found.
+func ValidateOutputSettings(protocol, address string, priority, syncUniverse int) error {
+	maxUniverse := e131MaxUniverse
+	switch protocol {
+	case E131Protocol:
+	case ArtNetProtocol:
+		maxUniverse = artNetMaxUniverse
+		if address == MulticastAddress {
+			return fmt.Errorf("Art-Net doesn't support multicast; use a unicast or broadcast address instead.")
+		}
+	default:
+		return fmt.Errorf("Unknown lighting protocol '%s'.", protocol)
+	}
+	if priority < 0 || priority > MaxPriority {
+		return fmt.Errorf("The lighting priority must be between 0 and %d.", MaxPriority)
+	}
+	if syncUniverse < 0 || syncUniverse > maxUniverse {
+		return fmt.Errorf("The synchronization universe must be between 0 (disabled) and %d.", maxUniverse)
+	}
+	if address != "" && address != MulticastAddress {
+		host := address
+		if splitHost, port, err := net.SplitHostPort(address); err == nil {
+			if portNum, err := strconv.Atoi(port); err != nil || portNum <= 0 || portNum > 65535 {
+				return fmt.Errorf("Invalid port in lighting address '%s'.", address)
+			}
+			host = splitHost
+		}
+		if host == "" {
+			return fmt.Errorf("Invalid lighting address '%s'.", address)
+		}
+	}
+	return nil
+}
+
+// Returns the largest universe number that the given protocol can address.
+func MaxUniverse(protocol string) int {
+	if protocol == ArtNetProtocol {
+		return artNetMaxUniverse
+	}
+	return e131MaxUniverse
+}
+
+// Sends each of the given universes whose data has changed or hasn't been sent recently, followed by a
+// synchronization packet if enabled.
+func (output *Output) send(frames map[int]*[UniverseSize]byte, now time.Time) error {
+	if output.conn == nil {
+		// This output is not configured; do nothing.
+		return nil
+	}
+
+	// Send the universes in a consistent order.
+	universes := make([]int, 0, len(frames))
+	for universe := range frames {
+		universes = append(universes, universe)
+	}
+	sort.Ints(universes)
+
+	sentData := false
+	for _, universe := range universes {
+		data := frames[universe]
+		state, ok := output.universes[universe]
+		if !ok {
+			state = new(universeState)
+			output.universes[universe] = state
+		} else if state.data == *data && now.Sub(state.lastSentTime).Seconds()*1000 < keepaliveMs {
+			continue
+		}
+
+		var packet []byte
+		if output.protocol == ArtNetProtocol {
+			packet = createArtNetDmxPacket(universe, state.sequence, data)
+		} else {
+			packet = createE131DataPacket(&output.componentId, universe, output.priority, output.syncUniverse,
+				state.sequence, data)
+		}
+		if err := output.write(packet, universe); err != nil {
+			return err
+		}
+		state.data = *data
+		state.sequence++
+		state.lastSentTime = now
+		sentData = true
+	}
+
+	if sentData && output.syncUniverse > 0 {
+		var packet []byte
+		if output.protocol == ArtNetProtocol {
+			packet = createArtNetSyncPacket()
+		} else {
+			packet = createE131SyncPacket(&output.componentId, output.syncUniverse, output.syncSequence)
+			output.syncSequence++
+		}
+		if err := output.write(packet, output.syncUniverse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sends the given packet to the configured destination, or to the multicast group for the given universe.
+func (output *Output) write(packet []byte, universe int) error {
+	destination := output.destination
+	if destination == nil {
+		destination = &net.UDPAddr{IP: net.IPv4(e131MulticastPrefixA, e131MulticastPrefixB, byte(universe>>8),
+			byte(universe&0xff)), Port: e131Port}
+	}
+	_, err := output.conn.WriteToUDP(packet, destination)
+	return err
+}
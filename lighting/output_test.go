@@ -0,0 +1,156 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package lighting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+const testComponentId = "00112233445566778899aabbccddeeff"
+
+func TestOutputE131(t *testing.T) {
+	receiver, address := setupTestReceiver(t)
+	defer receiver.Close()
+	var output Output
+	assert.Nil(t, output.Configure(E131Protocol, address, testComponentId, 150, 0))
+
+	now := time.Now()
+	frames := map[int]*[UniverseSize]byte{2: {1, 2, 3}, 1: {4, 5, 6}}
+	assert.Nil(t, output.send(frames, now))
+	packet := receivePacket(t, receiver)
+	if assert.Equal(t, 638, len(packet)) {
+		assert.Equal(t, "ASC-E1.17", string(packet[4:13]))
+		assert.Equal(t, byte(0x04), packet[21])
+		assert.Equal(t, []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd,
+			0xee, 0xff}, packet[22:38])
+		assert.Equal(t, "Cheesy Arena", string(packet[44:56]))
+		assert.Equal(t, byte(150), packet[108])
+		assert.Equal(t, []byte{0, 0}, packet[109:111])
+		assert.Equal(t, byte(0), packet[111])
+		assert.Equal(t, []byte{0, 1}, packet[113:115])
+		assert.Equal(t, []byte{0x72, 0x0b}, packet[115:117])
+		assert.Equal(t, []byte{4, 5, 6, 0}, packet[126:130])
+	}
+	packet = receivePacket(t, receiver)
+	assert.Equal(t, []byte{0, 2}, packet[113:115])
+	assert.Equal(t, []byte{1, 2, 3, 0}, packet[126:130])
+
+	// Check that only changed universes are sent, until it's time for a keepalive.
+	frames[2][0] = 255
+	assert.Nil(t, output.send(frames, now.Add(100*time.Millisecond)))
+	packet = receivePacket(t, receiver)
+	assert.Equal(t, []byte{0, 2}, packet[113:115])
+	assert.Equal(t, byte(1), packet[111])
+	assert.Equal(t, byte(255), packet[126])
+	assertNoPacket(t, receiver)
+	assert.Nil(t, output.send(frames, now.Add(1050*time.Millisecond)))
+	packet = receivePacket(t, receiver)
+	assert.Equal(t, []byte{0, 1}, packet[113:115])
+	assert.Equal(t, byte(1), packet[111])
+	assertNoPacket(t, receiver)
+}
+
+func TestOutputE131Sync(t *testing.T) {
+	receiver, address := setupTestReceiver(t)
+	defer receiver.Close()
+	var output Output
+	assert.Nil(t, output.Configure(E131Protocol, address, testComponentId, DefaultPriority, 7))
+
+	frames := map[int]*[UniverseSize]byte{1: {4, 5, 6}, 2: {1, 2, 3}}
+	assert.Nil(t, output.send(frames, time.Now()))
+	for i := 0; i < 2; i++ {
+		packet := receivePacket(t, receiver)
+		assert.Equal(t, []byte{0, 7}, packet[109:111])
+		assert.Equal(t, byte(DefaultPriority), packet[108])
+	}
+	packet := receivePacket(t, receiver)
+	if assert.Equal(t, 49, len(packet)) {
+		assert.Equal(t, "ASC-E1.17", string(packet[4:13]))
+		assert.Equal(t, []byte{0x70, 0x21}, packet[16:18])
+		assert.Equal(t, byte(0x08), packet[21])
+		assert.Equal(t, []byte{0x70, 0x0b}, packet[38:40])
+		assert.Equal(t, byte(0x01), packet[43])
+		assert.Equal(t, byte(0), packet[44])
+		assert.Equal(t, []byte{0, 7}, packet[45:47])
+	}
+
+	// Check that nothing is sent if nothing has changed.
+	assert.Nil(t, output.send(frames, time.Now()))
+	assertNoPacket(t, receiver)
+}
+
+func TestOutputArtNet(t *testing.T) {
+	receiver, address := setupTestReceiver(t)
+	defer receiver.Close()
+	var output Output
+	assert.Nil(t, output.Configure(ArtNetProtocol, address, testComponentId, DefaultPriority, 1))
+
+	frames := map[int]*[UniverseSize]byte{258: {9, 8, 7}}
+	assert.Nil(t, output.send(frames, time.Now()))
+	packet := receivePacket(t, receiver)
+	if assert.Equal(t, 530, len(packet)) {
+		assert.Equal(t, "Art-Net\x00", string(packet[0:8]))
+		assert.Equal(t, []byte{0x00, 0x50, 0, 14}, packet[8:12])
+		assert.Equal(t, []byte{2, 1}, packet[14:16])
+		assert.Equal(t, []byte{2, 0}, packet[16:18])
+		assert.Equal(t, []byte{9, 8, 7, 0}, packet[18:22])
+	}
+	packet = receivePacket(t, receiver)
+	assert.Equal(t, []byte{'A', 'r', 't', '-', 'N', 'e', 't', 0, 0x00, 0x52, 0, 14, 0, 0}, packet)
+}
+
+func TestOutputNotConfigured(t *testing.T) {
+	var output Output
+	assert.Nil(t, output.send(map[int]*[UniverseSize]byte{1: {}}, time.Now()))
+	assert.Nil(t, output.Configure(E131Protocol, "", testComponentId, DefaultPriority, 0))
+	assert.Nil(t, output.send(map[int]*[UniverseSize]byte{1: {}}, time.Now()))
+}
+
+func TestOutputComponentId(t *testing.T) {
+	var output Output
+	componentId := NewComponentId()
+	assert.Equal(t, 32, len(componentId))
+	assert.NotEqual(t, componentId, NewComponentId())
+	assert.Nil(t, output.Configure(E131Protocol, "127.0.0.1", componentId, DefaultPriority, 0))
+	err := output.Configure(E131Protocol, "127.0.0.1", "blorpy", DefaultPriority, 0)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid lighting component ID 'blorpy'.", err.Error())
+	}
+}
+
+func TestValidateOutputSettings(t *testing.T) {
+	assert.Nil(t, ValidateOutputSettings(E131Protocol, "", DefaultPriority, 0))
+	assert.Nil(t, ValidateOutputSettings(E131Protocol, MulticastAddress, 0, 63999))
+	assert.Nil(t, ValidateOutputSettings(ArtNetProtocol, "10.0.100.255:6454", MaxPriority, 32767))
+	assert.NotNil(t, ValidateOutputSettings("dmx", "10.0.100.5", DefaultPriority, 0))
+	assert.NotNil(t, ValidateOutputSettings(ArtNetProtocol, MulticastAddress, DefaultPriority, 0))
+	assert.NotNil(t, ValidateOutputSettings(E131Protocol, "10.0.100.5", 201, 0))
+	assert.NotNil(t, ValidateOutputSettings(ArtNetProtocol, "10.0.100.5", DefaultPriority, 32768))
+	assert.NotNil(t, ValidateOutputSettings(E131Protocol, "10.0.100.5:blorpy", DefaultPriority, 0))
+	assert.NotNil(t, ValidateOutputSettings(E131Protocol, ":5568", DefaultPriority, 0))
+}
+
+func setupTestReceiver(t *testing.T) (*net.UDPConn, string) {
+	receiver, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.Nil(t, err)
+	return receiver, receiver.LocalAddr().String()
+}
+
+func receivePacket(t *testing.T, receiver *net.UDPConn) []byte {
+	buffer := make([]byte, 1024)
+	receiver.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := receiver.Read(buffer)
+	assert.Nil(t, err)
+	return buffer[:n]
+}
+
+func assertNoPacket(t *testing.T, receiver *net.UDPConn) {
+	buffer := make([]byte, 1024)
+	receiver.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_, err := receiver.Read(buffer)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for the state of an in-progress or completed alliance selection.
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	allianceSelectionId          = 0
+	AllianceSelectionPickTimeSec = 120
+	NumBackupTeams               = 8
+)
+
+type AllianceSelection struct {
+	Id                 int
+	Alliances          [][]AllianceTeam
+	RankedTeams        []AllianceSelectionTeam
+	InvitedTeamId      int       // Zero if no invitation is outstanding.
+	PickTimerStartedAt time.Time // Zero if the pick timer is not running.
+}
+
+type AllianceSelectionTeam struct {
+	Rank     int
+	TeamId   int
+	Picked   bool
+	Declined bool
+}
+
+type AllianceSelectionDb struct {
+	Id                 int
+	AlliancesJson      string
+	RankedTeamsJson    string
+	InvitedTeamId      int
+	PickTimerStartedAt time.Time
+}
+
+// Returns the alliance selection state, or nil if alliance selection has not been started.
+func (database *Database) GetAllianceSelection() (*AllianceSelection, error) {
+	allianceSelectionDb := new(AllianceSelectionDb)
+	err := database.allianceSelectionMap.Get(allianceSelectionDb, allianceSelectionId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return allianceSelectionDb.Deserialize()
+}
+
+// Saves the alliance selection state, creating the record if it doesn't exist yet.
+func (database *Database) SaveAllianceSelection(allianceSelection *AllianceSelection) error {
+	allianceSelection.Id = allianceSelectionId
+	allianceSelectionDb, err := allianceSelection.Serialize()
+	if err != nil {
+		return err
+	}
+	count, err := database.allianceSelectionMap.Update(allianceSelectionDb)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return database.allianceSelectionMap.Insert(allianceSelectionDb)
+	}
+	return nil
+}
+
+func (database *Database) TruncateAllianceSelection() error {
+	return database.allianceSelectionMap.TruncateTables()
+}
+
+// Returns the ranked team entry for the given team, or nil if the team is not in the list.
+func (allianceSelection *AllianceSelection) GetRankedTeam(teamId int) *AllianceSelectionTeam {
+	for i, team := range allianceSelection.RankedTeams {
+		if team.TeamId == teamId {
+			return &allianceSelection.RankedTeams[i]
+		}
+	}
+	return nil
+}
+
+// Returns the highest-ranked teams that are not part of an alliance and didn't decline an invitation, which are the
+// candidates to be called upon as backups during the elimination rounds.
+func (allianceSelection *AllianceSelection) BackupTeams() []AllianceSelectionTeam {
+	backupTeams := make([]AllianceSelectionTeam, 0)
+	for _, team := range allianceSelection.RankedTeams {
+		if len(backupTeams) == NumBackupTeams {
+			break
+		}
+		if !team.Picked && !team.Declined {
+			backupTeams = append(backupTeams, team)
+		}
+	}
+	return backupTeams
+}
+
+// Returns the number of seconds remaining in the current pick, or zero if the timer isn't running or has expired.
+func (allianceSelection *AllianceSelection) PickTimeRemainingSec(currentTime time.Time) int {
+	if allianceSelection.PickTimerStartedAt.IsZero() {
+		return 0
+	}
+	remainingSec := AllianceSelectionPickTimeSec - int(currentTime.Sub(allianceSelection.PickTimerStartedAt).Seconds())
+	if remainingSec < 0 {
+		return 0
+	}
+	return remainingSec
+}
+
+// Converts the nested struct AllianceSelection to the DB version that has JSON fields.
+func (allianceSelection *AllianceSelection) Serialize() (*AllianceSelectionDb, error) {
+	allianceSelectionDb := AllianceSelectionDb{Id: allianceSelection.Id,
+		InvitedTeamId: allianceSelection.InvitedTeamId, PickTimerStartedAt: allianceSelection.PickTimerStartedAt}
+	if err := serializeHelper(&allianceSelectionDb.AlliancesJson, allianceSelection.Alliances); err != nil {
+		return nil, err
+	}
+	if err := serializeHelper(&allianceSelectionDb.RankedTeamsJson, allianceSelection.RankedTeams); err != nil {
+		return nil, err
+	}
+	return &allianceSelectionDb, nil
+}
+
+// Converts the DB AllianceSelection with JSON fields to the nested struct version.
+func (allianceSelectionDb *AllianceSelectionDb) Deserialize() (*AllianceSelection, error) {
+	allianceSelection := AllianceSelection{Id: allianceSelectionDb.Id,
+		InvitedTeamId: allianceSelectionDb.InvitedTeamId, PickTimerStartedAt: allianceSelectionDb.PickTimerStartedAt}
+	if err := json.Unmarshal([]byte(allianceSelectionDb.AlliancesJson), &allianceSelection.Alliances); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(allianceSelectionDb.RankedTeamsJson), &allianceSelection.RankedTeams); err != nil {
+		return nil, err
+	}
+	return &allianceSelection, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetNonexistentAllianceSelection(t *testing.T) {
+	db := setupTestDb(t)
+
+	allianceSelection, err := db.GetAllianceSelection()
+	assert.Nil(t, err)
+	assert.Nil(t, allianceSelection)
+}
+
+func TestAllianceSelectionReadWrite(t *testing.T) {
+	db := setupTestDb(t)
+
+	allianceSelection := AllianceSelection{
+		Alliances: [][]AllianceTeam{{{0, 1, 0, 254, 0, 0}, {0, 1, 1, 0, 0, 0}}, {{0, 2, 0, 0, 0, 0}, {0, 2, 1, 0, 0, 0}}},
+		RankedTeams: []AllianceSelectionTeam{{1, 254, true, false}, {2, 1114, false, true},
+			{3, 2056, false, false}},
+		InvitedTeamId:      2056,
+		PickTimerStartedAt: time.Unix(1000, 0).UTC(),
+	}
+	assert.Nil(t, db.SaveAllianceSelection(&allianceSelection))
+	allianceSelection2, err := db.GetAllianceSelection()
+	assert.Nil(t, err)
+	assert.Equal(t, allianceSelection, *allianceSelection2)
+
+	allianceSelection.InvitedTeamId = 0
+	allianceSelection.RankedTeams[2].Picked = true
+	assert.Nil(t, db.SaveAllianceSelection(&allianceSelection))
+	allianceSelection2, err = db.GetAllianceSelection()
+	assert.Nil(t, err)
+	assert.Equal(t, allianceSelection, *allianceSelection2)
+
+	db.TruncateAllianceSelection()
+	allianceSelection2, err = db.GetAllianceSelection()
+	assert.Nil(t, err)
+	assert.Nil(t, allianceSelection2)
+}
+
+func TestAllianceSelectionBackupTeams(t *testing.T) {
+	allianceSelection := AllianceSelection{}
+	for i := 1; i <= 15; i++ {
+		allianceSelection.RankedTeams = append(allianceSelection.RankedTeams,
+			AllianceSelectionTeam{Rank: i, TeamId: 100 + i, Picked: i%3 == 1})
+	}
+	allianceSelection.GetRankedTeam(105).Declined = true
+	assert.Nil(t, allianceSelection.GetRankedTeam(254))
+
+	backupTeams := allianceSelection.BackupTeams()
+	if assert.Equal(t, NumBackupTeams, len(backupTeams)) {
+		assert.Equal(t, 102, backupTeams[0].TeamId)
+		assert.Equal(t, 103, backupTeams[1].TeamId)
+		assert.Equal(t, 106, backupTeams[2].TeamId)
+		assert.Equal(t, 114, backupTeams[7].TeamId)
+	}
+	for _, team := range backupTeams {
+		assert.NotEqual(t, 105, team.TeamId)
+	}
+}
+
+func TestAllianceSelectionPickTimeRemaining(t *testing.T) {
+	allianceSelection := AllianceSelection{}
+	assert.Equal(t, 0, allianceSelection.PickTimeRemainingSec(time.Unix(1000, 0)))
+
+	allianceSelection.PickTimerStartedAt = time.Unix(1000, 0)
+	assert.Equal(t, AllianceSelectionPickTimeSec, allianceSelection.PickTimeRemainingSec(time.Unix(1000, 0)))
+	assert.Equal(t, AllianceSelectionPickTimeSec-45, allianceSelection.PickTimeRemainingSec(time.Unix(1045, 0)))
+	assert.Equal(t, 0, allianceSelection.PickTimeRemainingSec(time.Unix(5000, 0)))
+}
@@ -6,10 +6,12 @@
 package model
 
 type AllianceTeam struct {
-	Id           int
-	AllianceId   int
-	PickPosition int
-	TeamId       int
+	Id                int
+	AllianceId        int
+	PickPosition      int
+	TeamId            int
+	ReplacedTeamId    int // Non-zero if this team was called in as a backup.
+	BackupFromMatchId int // The first match that the backup team played in place of the replaced team.
 }
 
 func (database *Database) CreateAllianceTeam(allianceTeam *AllianceTeam) error {
@@ -33,6 +35,11 @@ func (database *Database) DeleteAllianceTeam(allianceTeam *AllianceTeam) error {
 	return err
 }
 
+// Returns true if the alliance team was called in as a backup to replace another team during the elimination rounds.
+func (allianceTeam *AllianceTeam) IsBackup() bool {
+	return allianceTeam.ReplacedTeamId > 0
+}
+
 func (database *Database) TruncateAllianceTeams() error {
 	return database.allianceTeamMap.TruncateTables()
 }
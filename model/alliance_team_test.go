@@ -19,7 +19,7 @@ func TestGetNonexistentAlliance(t *testing.T) {
 func TestAllianceTeamCrud(t *testing.T) {
 	db := setupTestDb(t)
 
-	allianceTeam := AllianceTeam{0, 1, 0, 254}
+	allianceTeam := AllianceTeam{0, 1, 0, 254, 0, 0}
 	db.CreateAllianceTeam(&allianceTeam)
 	allianceTeams, err := db.GetTeamsByAlliance(1)
 	assert.Nil(t, err)
@@ -32,6 +32,15 @@ func TestAllianceTeamCrud(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(allianceTeams))
 	assert.Equal(t, allianceTeam.TeamId, allianceTeams[0].TeamId)
+	assert.False(t, allianceTeams[0].IsBackup())
+
+	allianceTeam.ReplacedTeamId = 254
+	allianceTeam.BackupFromMatchId = 12
+	db.SaveAllianceTeam(&allianceTeam)
+	allianceTeams, err = db.GetTeamsByAlliance(1)
+	assert.Nil(t, err)
+	assert.Equal(t, allianceTeam, allianceTeams[0])
+	assert.True(t, allianceTeams[0].IsBackup())
 
 	db.DeleteAllianceTeam(&allianceTeam)
 	allianceTeams, err = db.GetTeamsByAlliance(1)
@@ -62,7 +71,7 @@ func TestGetTeamsByAlliance(t *testing.T) {
 func TestTruncateAllianceTeams(t *testing.T) {
 	db := setupTestDb(t)
 
-	allianceTeam := AllianceTeam{0, 1, 0, 254}
+	allianceTeam := AllianceTeam{0, 1, 0, 254, 0, 0}
 	db.CreateAllianceTeam(&allianceTeam)
 	db.TruncateAllianceTeams()
 	allianceTeams, err := db.GetTeamsByAlliance(1)
@@ -0,0 +1,76 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a key granting a third-party application access to the public API.
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const apiKeyLengthBytes = 16
+
+type ApiKey struct {
+	Id                int
+	Name              string
+	Key               string
+	RequestsPerMinute int // Zero if the key isn't rate limited.
+}
+
+// Returns a new random key string.
+func GenerateApiKey() (string, error) {
+	bytes := make([]byte, apiKeyLengthBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func (database *Database) CreateApiKey(apiKey *ApiKey) error {
+	return database.apiKeyMap.Insert(apiKey)
+}
+
+func (database *Database) GetApiKeyById(id int) (*ApiKey, error) {
+	apiKey := new(ApiKey)
+	err := database.apiKeyMap.Get(apiKey, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		apiKey = nil
+		err = nil
+	}
+	return apiKey, err
+}
+
+// Returns the API key having the given key string, or nil if there isn't one.
+func (database *Database) GetApiKeyByKey(key string) (*ApiKey, error) {
+	var apiKeys []ApiKey
+	err := database.apiKeyMap.Select(&apiKeys, "SELECT * FROM api_keys WHERE key = ?", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiKeys) == 0 {
+		return nil, nil
+	}
+	return &apiKeys[0], err
+}
+
+func (database *Database) SaveApiKey(apiKey *ApiKey) error {
+	_, err := database.apiKeyMap.Update(apiKey)
+	return err
+}
+
+func (database *Database) DeleteApiKey(apiKey *ApiKey) error {
+	_, err := database.apiKeyMap.Delete(apiKey)
+	return err
+}
+
+func (database *Database) TruncateApiKeys() error {
+	return database.apiKeyMap.TruncateTables()
+}
+
+func (database *Database) GetAllApiKeys() ([]ApiKey, error) {
+	var apiKeys []ApiKey
+	err := database.apiKeyMap.Select(&apiKeys, "SELECT * FROM api_keys ORDER BY id")
+	return apiKeys, err
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentApiKey(t *testing.T) {
+	db := setupTestDb(t)
+
+	apiKey, err := db.GetApiKeyById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, apiKey)
+	apiKey, err = db.GetApiKeyByKey("blorpy")
+	assert.Nil(t, err)
+	assert.Nil(t, apiKey)
+}
+
+func TestApiKeyCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	key, err := GenerateApiKey()
+	assert.Nil(t, err)
+	assert.Equal(t, 2*apiKeyLengthBytes, len(key))
+	apiKey := ApiKey{Name: "Scouting App", Key: key, RequestsPerMinute: 60}
+	assert.Nil(t, db.CreateApiKey(&apiKey))
+	apiKey2, err := db.GetApiKeyById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, apiKey, *apiKey2)
+	apiKey2, err = db.GetApiKeyByKey(key)
+	assert.Nil(t, err)
+	assert.Equal(t, apiKey, *apiKey2)
+
+	// Check that keys are unique.
+	assert.NotNil(t, db.CreateApiKey(&ApiKey{Name: "Stream Overlay", Key: key}))
+
+	apiKey.RequestsPerMinute = 0
+	db.SaveApiKey(&apiKey)
+	apiKey2, err = db.GetApiKeyById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, apiKey2.RequestsPerMinute)
+
+	db.DeleteApiKey(&apiKey)
+	apiKey2, err = db.GetApiKeyById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, apiKey2)
+}
+
+func TestTruncateApiKeys(t *testing.T) {
+	db := setupTestDb(t)
+
+	apiKey := ApiKey{Name: "Scouting App", Key: "abcd"}
+	db.CreateApiKey(&apiKey)
+	db.TruncateApiKeys()
+	apiKey2, err := db.GetApiKeyById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, apiKey2)
+	apiKeys, err := db.GetAllApiKeys()
+	assert.Nil(t, err)
+	assert.Empty(t, apiKeys)
+}
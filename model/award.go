@@ -0,0 +1,54 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for an award given out at an event.
+
+package model
+
+type Award struct {
+	Id              int
+	Name            string
+	PresenterScript string
+	DisplayOrder    int
+}
+
+// Names of the awards commonly given out at an event, used to populate the catalog.
+var StandardAwardNames = []string{"Winner", "Finalist", "Chairman's Award", "Engineering Inspiration Award",
+	"Rookie All Star Award", "Gracious Professionalism Award", "Industrial Design Award", "Innovation in Control Award",
+	"Quality Award", "Excellence in Engineering Award", "Creativity Award", "Entrepreneurship Award",
+	"Team Spirit Award", "Imagery Award", "Safety Award", "Judges' Award", "Highest Rookie Seed",
+	"Rookie Inspiration Award", "Woodie Flowers Finalist Award", "Dean's List Finalist Award", "Volunteer of the Year"}
+
+func (database *Database) CreateAward(award *Award) error {
+	return database.awardMap.Insert(award)
+}
+
+func (database *Database) GetAwardById(id int) (*Award, error) {
+	award := new(Award)
+	err := database.awardMap.Get(award, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		award = nil
+		err = nil
+	}
+	return award, err
+}
+
+func (database *Database) SaveAward(award *Award) error {
+	_, err := database.awardMap.Update(award)
+	return err
+}
+
+func (database *Database) DeleteAward(award *Award) error {
+	_, err := database.awardMap.Delete(award)
+	return err
+}
+
+func (database *Database) TruncateAwards() error {
+	return database.awardMap.TruncateTables()
+}
+
+func (database *Database) GetAllAwards() ([]Award, error) {
+	var awards []Award
+	err := database.awardMap.Select(&awards, "SELECT * FROM awards ORDER BY displayorder, id")
+	return awards, err
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentAward(t *testing.T) {
+	db := setupTestDb(t)
+
+	award, err := db.GetAwardById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, award)
+}
+
+func TestAwardCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	award := Award{0, "Chairman's Award", "This team inspires us all.", 1}
+	db.CreateAward(&award)
+	award2, err := db.GetAwardById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, award, *award2)
+
+	award.PresenterScript = "Blorpy"
+	db.SaveAward(&award)
+	award2, err = db.GetAwardById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, award.PresenterScript, award2.PresenterScript)
+
+	db.DeleteAward(&award)
+	award2, err = db.GetAwardById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, award2)
+}
+
+func TestGetAllAwards(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateAward(&Award{Name: "Winner", DisplayOrder: 2})
+	db.CreateAward(&Award{Name: "Safety Award", DisplayOrder: 0})
+	db.CreateAward(&Award{Name: "Finalist", DisplayOrder: 2})
+	awards, err := db.GetAllAwards()
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(awards)) {
+		assert.Equal(t, "Safety Award", awards[0].Name)
+		assert.Equal(t, "Winner", awards[1].Name)
+		assert.Equal(t, "Finalist", awards[2].Name)
+	}
+
+	db.TruncateAwards()
+	awards, err = db.GetAllAwards()
+	assert.Nil(t, err)
+	assert.Empty(t, awards)
+}
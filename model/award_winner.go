@@ -0,0 +1,54 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for the recipient of an award, which may be a team or a person.
+
+package model
+
+type AwardWinner struct {
+	Id         int
+	AwardId    int
+	TeamId     int    // Zero if the award isn't associated with a team.
+	PersonName string // Blank if the award was given to the team as a whole.
+}
+
+func (database *Database) CreateAwardWinner(awardWinner *AwardWinner) error {
+	return database.awardWinnerMap.Insert(awardWinner)
+}
+
+func (database *Database) GetAwardWinnerById(id int) (*AwardWinner, error) {
+	awardWinner := new(AwardWinner)
+	err := database.awardWinnerMap.Get(awardWinner, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		awardWinner = nil
+		err = nil
+	}
+	return awardWinner, err
+}
+
+func (database *Database) SaveAwardWinner(awardWinner *AwardWinner) error {
+	_, err := database.awardWinnerMap.Update(awardWinner)
+	return err
+}
+
+func (database *Database) DeleteAwardWinner(awardWinner *AwardWinner) error {
+	_, err := database.awardWinnerMap.Delete(awardWinner)
+	return err
+}
+
+func (database *Database) TruncateAwardWinners() error {
+	return database.awardWinnerMap.TruncateTables()
+}
+
+func (database *Database) GetAwardWinnersByAward(awardId int) ([]AwardWinner, error) {
+	var awardWinners []AwardWinner
+	err := database.awardWinnerMap.Select(&awardWinners, "SELECT * FROM award_winners WHERE awardid = ? ORDER BY id",
+		awardId)
+	return awardWinners, err
+}
+
+func (database *Database) GetAllAwardWinners() ([]AwardWinner, error) {
+	var awardWinners []AwardWinner
+	err := database.awardWinnerMap.Select(&awardWinners, "SELECT * FROM award_winners ORDER BY awardid, id")
+	return awardWinners, err
+}
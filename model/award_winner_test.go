@@ -0,0 +1,60 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentAwardWinner(t *testing.T) {
+	db := setupTestDb(t)
+
+	awardWinner, err := db.GetAwardWinnerById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, awardWinner)
+}
+
+func TestAwardWinnerCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	awardWinner := AwardWinner{0, 3, 254, ""}
+	db.CreateAwardWinner(&awardWinner)
+	awardWinner2, err := db.GetAwardWinnerById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, awardWinner, *awardWinner2)
+
+	awardWinner.PersonName = "Patrick Fairbank"
+	db.SaveAwardWinner(&awardWinner)
+	awardWinner2, err = db.GetAwardWinnerById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, awardWinner.PersonName, awardWinner2.PersonName)
+
+	db.DeleteAwardWinner(&awardWinner)
+	awardWinner2, err = db.GetAwardWinnerById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, awardWinner2)
+}
+
+func TestGetAwardWinnersByAward(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateAwardWinner(&AwardWinner{AwardId: 1, TeamId: 254})
+	db.CreateAwardWinner(&AwardWinner{AwardId: 2, PersonName: "Jane Doe"})
+	db.CreateAwardWinner(&AwardWinner{AwardId: 1, TeamId: 1114})
+	awardWinners, err := db.GetAwardWinnersByAward(1)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(awardWinners)) {
+		assert.Equal(t, 254, awardWinners[0].TeamId)
+		assert.Equal(t, 1114, awardWinners[1].TeamId)
+	}
+	awardWinners, err = db.GetAllAwardWinners()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(awardWinners))
+
+	db.TruncateAwardWinners()
+	awardWinners, err = db.GetAllAwardWinners()
+	assert.Nil(t, err)
+	assert.Empty(t, awardWinners)
+}
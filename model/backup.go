@@ -0,0 +1,264 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Methods for listing, pruning, verifying and comparing the database backups in the backups directory.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const backupTimeFormat = "20060102150405"
+
+var backupFilenamePattern = regexp.MustCompile(`^(.*)_(\d{14})_(.+)\.db$`)
+var backupMatchReasonPattern = regexp.MustCompile(`^post_([a-z]+)_match_(.+)$`)
+
+type BackupFile struct {
+	Filename  string
+	EventName string
+	Time      time.Time
+	Reason    string
+	MatchType string // Blank if the backup wasn't taken after a match.
+	MatchName string
+	SizeBytes int64
+}
+
+// Describes how a match would be affected by restoring a backup.
+type BackupMatchDiff struct {
+	Match          Match  // The version of the match from the backup, or the current one if it would be removed.
+	ScheduleChange string // One of "added", "removed" or "changed", or blank if the match itself is unchanged.
+	ResultChanged  bool
+}
+
+// Returns the backups in the backups directory, newest first. Files that don't follow the backup naming convention are
+// ignored.
+func ListBackups() ([]BackupFile, error) {
+	return listBackups(filepath.Join(BaseDir, backupsDir))
+}
+
+// Returns the full path of the given backup, or an error if the filename doesn't refer to an existing backup.
+func GetBackupPath(filename string) (string, error) {
+	if filename != filepath.Base(filename) || !backupFilenamePattern.MatchString(filename) {
+		return "", fmt.Errorf("Invalid backup filename '%s'.", filename)
+	}
+	path := filepath.Join(BaseDir, backupsDir, filename)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("Backup '%s' doesn't exist.", filename)
+	}
+	return path, nil
+}
+
+// Deletes old backups, keeping the given number of the most recent ones plus the latest backup within each hour and
+// any backups taken as a safety measure before a destructive operation. Returns the backups that were deleted. Does
+// nothing if keepLast is zero.
+func PruneBackups(keepLast int) ([]BackupFile, error) {
+	return pruneBackups(filepath.Join(BaseDir, backupsDir), keepLast)
+}
+
+// Checks that the given backup is intact and can be loaded by this version of the software. The backup is checked
+// using a copy so that the migrations run on opening it don't modify the original.
+func VerifyBackup(filename string) error {
+	backupDb, err := openBackupCopy(filename)
+	if err != nil {
+		return err
+	}
+	defer backupDb.closeAndRemove()
+
+	var result string
+	if err = backupDb.db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("Integrity check failed: %s", result)
+	}
+	_, err = backupDb.GetEventSettings()
+	return err
+}
+
+// Returns the matches that would be added, removed or changed, or whose results would change, if the given backup
+// were restored in place of this database.
+func (database *Database) DiffBackup(filename string) ([]BackupMatchDiff, error) {
+	backupDb, err := openBackupCopy(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer backupDb.closeAndRemove()
+
+	currentMatches, err := database.getAllMatches()
+	if err != nil {
+		return nil, err
+	}
+	backupMatches, err := backupDb.getAllMatches()
+	if err != nil {
+		return nil, err
+	}
+	currentMatchesById := make(map[int]Match)
+	for _, match := range currentMatches {
+		currentMatchesById[match.Id] = match
+	}
+
+	var diffs []BackupMatchDiff
+	for _, backupMatch := range backupMatches {
+		diff := BackupMatchDiff{Match: backupMatch}
+		currentMatch, ok := currentMatchesById[backupMatch.Id]
+		if !ok {
+			diff.ScheduleChange = "added"
+		} else if !reflect.DeepEqual(currentMatch, backupMatch) {
+			diff.ScheduleChange = "changed"
+		}
+		delete(currentMatchesById, backupMatch.Id)
+		if diff.ResultChanged, err = resultsDiffer(database, backupDb, backupMatch.Id); err != nil {
+			return nil, err
+		}
+		if diff.ScheduleChange != "" || diff.ResultChanged {
+			diffs = append(diffs, diff)
+		}
+	}
+	for _, currentMatch := range currentMatches {
+		if _, ok := currentMatchesById[currentMatch.Id]; ok {
+			diff := BackupMatchDiff{Match: currentMatch, ScheduleChange: "removed"}
+			if diff.ResultChanged, err = resultsDiffer(database, backupDb, currentMatch.Id); err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+func listBackups(backupsPath string) ([]BackupFile, error) {
+	fileInfos, err := ioutil.ReadDir(backupsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BackupFile{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []BackupFile{}
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+		backup, ok := parseBackupFilename(fileInfo.Name())
+		if !ok {
+			continue
+		}
+		backup.SizeBytes = fileInfo.Size()
+		backups = append(backups, *backup)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Filename > backups[j].Filename
+		}
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+func pruneBackups(backupsPath string, keepLast int) ([]BackupFile, error) {
+	if keepLast <= 0 {
+		return nil, nil
+	}
+	backups, err := listBackups(backupsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var prunedBackups []BackupFile
+	keptHours := make(map[string]bool)
+	for i, backup := range backups {
+		hour := backup.Time.Format("2006010215")
+		keep := i < keepLast || !keptHours[hour] || strings.HasPrefix(backup.Reason, "pre_")
+		keptHours[hour] = true
+		if keep {
+			continue
+		}
+		if err = os.Remove(filepath.Join(backupsPath, backup.Filename)); err != nil {
+			return prunedBackups, err
+		}
+		prunedBackups = append(prunedBackups, backup)
+	}
+	return prunedBackups, nil
+}
+
+func parseBackupFilename(filename string) (*BackupFile, bool) {
+	matches := backupFilenamePattern.FindStringSubmatch(filename)
+	if matches == nil {
+		return nil, false
+	}
+	backupTime, err := time.ParseInLocation(backupTimeFormat, matches[2], time.Local)
+	if err != nil {
+		return nil, false
+	}
+	backup := BackupFile{Filename: filename, EventName: strings.Replace(matches[1], "_", " ", -1),
+		Time: backupTime, Reason: matches[3]}
+	if reasonMatches := backupMatchReasonPattern.FindStringSubmatch(backup.Reason); reasonMatches != nil {
+		backup.MatchType = reasonMatches[1]
+		backup.MatchName = reasonMatches[2]
+	}
+	return &backup, true
+}
+
+// Opens a temporary copy of the given backup.
+func openBackupCopy(filename string) (*Database, error) {
+	path, err := GetBackupPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	source, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	tempFile, err := ioutil.TempFile("", "backup-copy-")
+	if err != nil {
+		return nil, err
+	}
+	defer tempFile.Close()
+	if _, err = io.Copy(tempFile, source); err != nil {
+		os.Remove(tempFile.Name())
+		return nil, err
+	}
+	tempFile.Close()
+
+	backupDb, err := OpenDatabase(tempFile.Name())
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return nil, fmt.Errorf("Could not open backup '%s': %v", filename, err)
+	}
+	return backupDb, nil
+}
+
+func (database *Database) closeAndRemove() {
+	database.Close()
+	os.Remove(database.Path)
+}
+
+func (database *Database) getAllMatches() ([]Match, error) {
+	var matches []Match
+	err := database.matchMap.Select(&matches, "SELECT * FROM matches ORDER BY id")
+	return matches, err
+}
+
+func resultsDiffer(database1, database2 *Database, matchId int) (bool, error) {
+	matchResult1, err := database1.GetMatchResultForMatch(matchId)
+	if err != nil {
+		return false, err
+	}
+	matchResult2, err := database2.GetMatchResultForMatch(matchId)
+	if err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(matchResult1, matchResult2), nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseBackupFilename(t *testing.T) {
+	backup, ok := parseBackupFilename("Chezy_Champs_20181103142500_post_qualification_match_12.db")
+	if assert.True(t, ok) {
+		assert.Equal(t, "Chezy Champs", backup.EventName)
+		assert.Equal(t, time.Date(2018, 11, 3, 14, 25, 0, 0, time.Local), backup.Time)
+		assert.Equal(t, "post_qualification_match_12", backup.Reason)
+		assert.Equal(t, "qualification", backup.MatchType)
+		assert.Equal(t, "12", backup.MatchName)
+	}
+
+	backup, ok = parseBackupFilename("Chezy_Champs_20181103142500_pre_clear.db")
+	if assert.True(t, ok) {
+		assert.Equal(t, "pre_clear", backup.Reason)
+		assert.Equal(t, "", backup.MatchType)
+	}
+
+	_, ok = parseBackupFilename("notes.txt")
+	assert.False(t, ok)
+}
+
+func TestListAndPruneBackups(t *testing.T) {
+	backupsPath, _ := ioutil.TempDir("", "backups")
+	defer os.RemoveAll(backupsPath)
+	filenames := []string{
+		"Event_20181103090000_post_scheduling.db",
+		"Event_20181103092000_pre_clear.db",
+		"Event_20181103093000_post_qualification_match_1.db",
+		"Event_20181103100000_post_qualification_match_2.db",
+		"Event_20181103101000_post_qualification_match_3.db",
+		"Event_20181103102000_post_qualification_match_4.db",
+		"Event_20181103103000_post_qualification_match_5.db",
+		"unrelated.db",
+	}
+	for _, filename := range filenames {
+		ioutil.WriteFile(filepath.Join(backupsPath, filename), []byte("data"), 0644)
+	}
+
+	backups, err := listBackups(backupsPath)
+	assert.Nil(t, err)
+	if assert.Equal(t, 7, len(backups)) {
+		assert.Equal(t, filenames[6], backups[0].Filename)
+		assert.Equal(t, filenames[0], backups[6].Filename)
+		assert.Equal(t, int64(4), backups[0].SizeBytes)
+	}
+
+	prunedBackups, err := pruneBackups(backupsPath, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, prunedBackups)
+
+	// Keep the two newest, the newest in each hour, the safety backup and any files that aren't backups.
+	prunedBackups, err = pruneBackups(backupsPath, 2)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(prunedBackups)) {
+		assert.Equal(t, filenames[4], prunedBackups[0].Filename)
+		assert.Equal(t, filenames[3], prunedBackups[1].Filename)
+		assert.Equal(t, filenames[0], prunedBackups[2].Filename)
+	}
+	backups, _ = listBackups(backupsPath)
+	assert.Equal(t, 4, len(backups))
+	_, err = os.Stat(filepath.Join(backupsPath, "unrelated.db"))
+	assert.Nil(t, err)
+
+	backups, err = listBackups(filepath.Join(backupsPath, "nonexistent"))
+	assert.Nil(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestVerifyAndDiffBackup(t *testing.T) {
+	db := setupTestDb(t)
+	match1 := Match{Type: "qualification", DisplayName: "1"}
+	match2 := Match{Type: "qualification", DisplayName: "2"}
+	db.CreateMatch(&match1)
+	db.CreateMatch(&match2)
+	assert.Nil(t, db.Backup("Test Event", "verify_diff_test"))
+	backups, _ := ListBackups()
+	var filename string
+	for _, backup := range backups {
+		if backup.Reason == "verify_diff_test" {
+			filename = backup.Filename
+		}
+	}
+	defer os.Remove(filepath.Join(BaseDir, backupsDir, filename))
+
+	assert.Nil(t, VerifyBackup(filename))
+	err := VerifyBackup("../event.db")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Invalid backup filename")
+	}
+
+	diffs, err := db.DiffBackup(filename)
+	assert.Nil(t, err)
+	assert.Empty(t, diffs)
+
+	// Change the current database and check that restoring would undo each change.
+	match1.Status = "complete"
+	db.SaveMatch(&match1)
+	db.CreateMatchResult(BuildTestMatchResult(match1.Id, 1))
+	match3 := Match{Type: "qualification", DisplayName: "3"}
+	db.CreateMatch(&match3)
+	db.DeleteMatch(&match2)
+	diffs, err = db.DiffBackup(filename)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(diffs)) {
+		assert.Equal(t, "1", diffs[0].Match.DisplayName)
+		assert.Equal(t, "", diffs[0].Match.Status)
+		assert.Equal(t, "changed", diffs[0].ScheduleChange)
+		assert.True(t, diffs[0].ResultChanged)
+		assert.Equal(t, "2", diffs[1].Match.DisplayName)
+		assert.Equal(t, "added", diffs[1].ScheduleChange)
+		assert.Equal(t, "3", diffs[2].Match.DisplayName)
+		assert.Equal(t, "removed", diffs[2].ScheduleChange)
+	}
+}
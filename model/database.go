@@ -13,6 +13,7 @@ import (
 	"github.com/jmoiron/modl"
 	_ "github.com/mattn/go-sqlite3"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,17 +26,35 @@ const migrationsDir = "db/migrations"
 var BaseDir = "." // Mutable for testing
 
 type Database struct {
-	Path             string
-	db               *sql.DB
-	eventSettingsMap *modl.DbMap
-	matchMap         *modl.DbMap
-	matchResultMap   *modl.DbMap
-	rankingMap       *modl.DbMap
-	teamMap          *modl.DbMap
-	allianceTeamMap  *modl.DbMap
-	lowerThirdMap    *modl.DbMap
-	sponsorSlideMap  *modl.DbMap
-	scheduleBlockMap *modl.DbMap
+	Path                 string
+	db                   *sql.DB
+	openedAt             time.Time
+	eventSettingsMap     *modl.DbMap
+	matchMap             *modl.DbMap
+	matchResultMap       *modl.DbMap
+	rankingMap           *modl.DbMap
+	teamMap              *modl.DbMap
+	allianceTeamMap      *modl.DbMap
+	allianceSelectionMap *modl.DbMap
+	lowerThirdMap        *modl.DbMap
+	sponsorSlideMap      *modl.DbMap
+	scheduleBlockMap     *modl.DbMap
+	tbaOutboxItemMap     *modl.DbMap
+	tbaPublishedMatchMap *modl.DbMap
+	awardMap             *modl.DbMap
+	awardWinnerMap       *modl.DbMap
+	displayMap           *modl.DbMap
+	displayGroupMap      *modl.DbMap
+	displayPlaylistMap   *modl.DbMap
+	apiKeyMap            *modl.DbMap
+	webhookMap           *modl.DbMap
+	webhookDeliveryMap   *modl.DbMap
+	ledSequenceMap       *modl.DbMap
+	ledSettingsMap       *modl.DbMap
+	lightingFixtureMap   *modl.DbMap
+	lightingCueMap       *modl.DbMap
+	soundPackMap         *modl.DbMap
+	soundPackFileMap     *modl.DbMap
 }
 
 // Opens the SQLite database at the given path, creating it if it doesn't exist, and runs any pending
@@ -43,14 +62,11 @@ type Database struct {
 func OpenDatabase(filename string) (*Database, error) {
 	// Find and run the migrations using goose. This also auto-creates the DB.
 	database := Database{Path: filename}
-	migrationsPath := filepath.Join(BaseDir, migrationsDir)
-	dbDriver := goose.DBDriver{"sqlite3", database.Path, "github.com/mattn/go-sqlite3", &goose.Sqlite3Dialect{}}
-	dbConf := goose.DBConf{MigrationsDir: migrationsPath, Env: "prod", Driver: dbDriver}
-	target, err := goose.GetMostRecentDBVersion(migrationsPath)
+	target, err := GetLatestSchemaVersion()
 	if err != nil {
 		return nil, err
 	}
-	err = goose.RunMigrations(&dbConf, migrationsPath, target)
+	err = goose.RunMigrations(newGooseConf(database.Path), filepath.Join(BaseDir, migrationsDir), target)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +76,7 @@ func OpenDatabase(filename string) (*Database, error) {
 		return nil, err
 	}
 	database.db = db
+	database.openedAt = time.Now()
 	database.mapTables()
 
 	return &database, nil
@@ -91,6 +108,16 @@ func (database *Database) Backup(eventName, reason string) error {
 	if _, err := io.Copy(dest, src); err != nil {
 		return err
 	}
+
+	// Keep the backups from filling up the disk, without failing the backup that was just taken if pruning fails.
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		log.Printf("Failed to prune backups: %s", err.Error())
+		return nil
+	}
+	if _, err = PruneBackups(eventSettings.BackupRetentionCount); err != nil {
+		log.Printf("Failed to prune backups: %s", err.Error())
+	}
 	return nil
 }
 
@@ -116,6 +143,9 @@ func (database *Database) mapTables() {
 	database.allianceTeamMap = modl.NewDbMap(database.db, dialect)
 	database.allianceTeamMap.AddTableWithName(AllianceTeam{}, "alliance_teams").SetKeys(true, "Id")
 
+	database.allianceSelectionMap = modl.NewDbMap(database.db, dialect)
+	database.allianceSelectionMap.AddTableWithName(AllianceSelectionDb{}, "alliance_selection").SetKeys(false, "Id")
+
 	database.lowerThirdMap = modl.NewDbMap(database.db, dialect)
 	database.lowerThirdMap.AddTableWithName(LowerThird{}, "lower_thirds").SetKeys(true, "Id")
 
@@ -124,6 +154,54 @@ func (database *Database) mapTables() {
 
 	database.scheduleBlockMap = modl.NewDbMap(database.db, dialect)
 	database.scheduleBlockMap.AddTableWithName(ScheduleBlock{}, "schedule_blocks").SetKeys(true, "Id")
+
+	database.tbaOutboxItemMap = modl.NewDbMap(database.db, dialect)
+	database.tbaOutboxItemMap.AddTableWithName(TbaOutboxItem{}, "tba_outbox_items").SetKeys(true, "Id")
+
+	database.tbaPublishedMatchMap = modl.NewDbMap(database.db, dialect)
+	database.tbaPublishedMatchMap.AddTableWithName(TbaPublishedMatch{}, "tba_published_matches").SetKeys(true, "Id")
+
+	database.awardMap = modl.NewDbMap(database.db, dialect)
+	database.awardMap.AddTableWithName(Award{}, "awards").SetKeys(true, "Id")
+
+	database.awardWinnerMap = modl.NewDbMap(database.db, dialect)
+	database.awardWinnerMap.AddTableWithName(AwardWinner{}, "award_winners").SetKeys(true, "Id")
+
+	database.displayMap = modl.NewDbMap(database.db, dialect)
+	database.displayMap.AddTableWithName(DisplayDb{}, "displays").SetKeys(false, "Id")
+
+	database.displayGroupMap = modl.NewDbMap(database.db, dialect)
+	database.displayGroupMap.AddTableWithName(DisplayGroupDb{}, "display_groups").SetKeys(true, "Id")
+
+	database.displayPlaylistMap = modl.NewDbMap(database.db, dialect)
+	database.displayPlaylistMap.AddTableWithName(DisplayPlaylistDb{}, "display_playlists").SetKeys(true, "Id")
+
+	database.apiKeyMap = modl.NewDbMap(database.db, dialect)
+	database.apiKeyMap.AddTableWithName(ApiKey{}, "api_keys").SetKeys(true, "Id")
+
+	database.webhookMap = modl.NewDbMap(database.db, dialect)
+	database.webhookMap.AddTableWithName(Webhook{}, "webhooks").SetKeys(true, "Id")
+
+	database.webhookDeliveryMap = modl.NewDbMap(database.db, dialect)
+	database.webhookDeliveryMap.AddTableWithName(WebhookDelivery{}, "webhook_deliveries").SetKeys(true, "Id")
+
+	database.ledSequenceMap = modl.NewDbMap(database.db, dialect)
+	database.ledSequenceMap.AddTableWithName(LedSequenceDb{}, "led_sequences").SetKeys(true, "Id")
+
+	database.ledSettingsMap = modl.NewDbMap(database.db, dialect)
+	database.ledSettingsMap.AddTableWithName(LedSettingsDb{}, "led_settings").SetKeys(false, "Id")
+
+	database.lightingFixtureMap = modl.NewDbMap(database.db, dialect)
+	database.lightingFixtureMap.AddTableWithName(LightingFixtureDb{}, "lighting_fixtures").SetKeys(true, "Id")
+
+	database.lightingCueMap = modl.NewDbMap(database.db, dialect)
+	database.lightingCueMap.AddTableWithName(LightingCueDb{}, "lighting_cues").SetKeys(true, "Id")
+
+	database.soundPackMap = modl.NewDbMap(database.db, dialect)
+	database.soundPackMap.AddTableWithName(SoundPack{}, "sound_packs").SetKeys(true, "Id")
+
+	database.soundPackFileMap = modl.NewDbMap(database.db, dialect)
+	database.soundPackFileMap.AddTableWithName(SoundPackFile{}, "sound_pack_files").SetKeys(true, "Id")
 }
 
 func serializeHelper(target *string, source interface{}) error {
@@ -0,0 +1,115 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for the persisted configuration of a remote web display.
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Display struct {
+	Id            string
+	Nickname      string
+	Type          int
+	Configuration map[string]string
+	IpAddress     string
+	LastSeenTime  time.Time
+	GroupId       int // Zero if the display isn't part of a group.
+}
+
+type DisplayDb struct {
+	Id                string
+	Nickname          string
+	Type              int
+	ConfigurationJson string
+	IpAddress         string
+	LastSeenTime      time.Time
+	GroupId           int
+}
+
+func (database *Database) CreateDisplay(display *Display) error {
+	displayDb, err := display.Serialize()
+	if err != nil {
+		return err
+	}
+	return database.displayMap.Insert(displayDb)
+}
+
+func (database *Database) GetDisplayById(id string) (*Display, error) {
+	displayDb := new(DisplayDb)
+	err := database.displayMap.Get(displayDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return displayDb.Deserialize()
+}
+
+func (database *Database) SaveDisplay(display *Display) error {
+	displayDb, err := display.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayMap.Update(displayDb)
+	return err
+}
+
+func (database *Database) DeleteDisplay(display *Display) error {
+	displayDb, err := display.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayMap.Delete(displayDb)
+	return err
+}
+
+func (database *Database) TruncateDisplays() error {
+	return database.displayMap.TruncateTables()
+}
+
+func (database *Database) GetAllDisplays() ([]Display, error) {
+	var displayDbs []DisplayDb
+	err := database.displayMap.Select(&displayDbs, "SELECT * FROM displays ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	displays := make([]Display, len(displayDbs))
+	for i, displayDb := range displayDbs {
+		display, err := displayDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		displays[i] = *display
+	}
+	return displays, nil
+}
+
+// Converts the nested struct Display to the flattened DisplayDb for saving to the database.
+func (display *Display) Serialize() (*DisplayDb, error) {
+	displayDb := DisplayDb{Id: display.Id, Nickname: display.Nickname, Type: display.Type,
+		IpAddress: display.IpAddress, LastSeenTime: display.LastSeenTime, GroupId: display.GroupId}
+	configurationJson, err := json.Marshal(display.Configuration)
+	if err != nil {
+		return nil, err
+	}
+	displayDb.ConfigurationJson = string(configurationJson)
+	return &displayDb, nil
+}
+
+// Converts the flattened DisplayDb to the nested struct Display.
+func (displayDb *DisplayDb) Deserialize() (*Display, error) {
+	display := Display{Id: displayDb.Id, Nickname: displayDb.Nickname, Type: displayDb.Type,
+		IpAddress: displayDb.IpAddress, LastSeenTime: displayDb.LastSeenTime, GroupId: displayDb.GroupId}
+	if err := json.Unmarshal([]byte(displayDb.ConfigurationJson), &display.Configuration); err != nil {
+		return nil, err
+	}
+	if display.Configuration == nil {
+		display.Configuration = make(map[string]string)
+	}
+	return &display, nil
+}
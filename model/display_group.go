@@ -0,0 +1,110 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a named group of displays that are configured together.
+
+package model
+
+import "encoding/json"
+
+type DisplayGroup struct {
+	Id            int
+	Name          string
+	Type          int
+	Configuration map[string]string
+	PlaylistId    int // Zero if the group isn't rotating through a playlist.
+}
+
+type DisplayGroupDb struct {
+	Id                int
+	Name              string
+	Type              int
+	ConfigurationJson string
+	PlaylistId        int
+}
+
+func (database *Database) CreateDisplayGroup(displayGroup *DisplayGroup) error {
+	displayGroupDb, err := displayGroup.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = database.displayGroupMap.Insert(displayGroupDb); err != nil {
+		return err
+	}
+	displayGroup.Id = displayGroupDb.Id
+	return nil
+}
+
+func (database *Database) GetDisplayGroupById(id int) (*DisplayGroup, error) {
+	displayGroupDb := new(DisplayGroupDb)
+	err := database.displayGroupMap.Get(displayGroupDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return displayGroupDb.Deserialize()
+}
+
+func (database *Database) SaveDisplayGroup(displayGroup *DisplayGroup) error {
+	displayGroupDb, err := displayGroup.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayGroupMap.Update(displayGroupDb)
+	return err
+}
+
+func (database *Database) DeleteDisplayGroup(displayGroup *DisplayGroup) error {
+	displayGroupDb, err := displayGroup.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayGroupMap.Delete(displayGroupDb)
+	return err
+}
+
+func (database *Database) TruncateDisplayGroups() error {
+	return database.displayGroupMap.TruncateTables()
+}
+
+func (database *Database) GetAllDisplayGroups() ([]DisplayGroup, error) {
+	var displayGroupDbs []DisplayGroupDb
+	err := database.displayGroupMap.Select(&displayGroupDbs, "SELECT * FROM display_groups ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	displayGroups := make([]DisplayGroup, len(displayGroupDbs))
+	for i, displayGroupDb := range displayGroupDbs {
+		displayGroup, err := displayGroupDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		displayGroups[i] = *displayGroup
+	}
+	return displayGroups, nil
+}
+
+// Converts the nested struct DisplayGroup to the flattened DisplayGroupDb for saving to the database.
+func (displayGroup *DisplayGroup) Serialize() (*DisplayGroupDb, error) {
+	displayGroupDb := DisplayGroupDb{Id: displayGroup.Id, Name: displayGroup.Name, Type: displayGroup.Type,
+		PlaylistId: displayGroup.PlaylistId}
+	if err := serializeHelper(&displayGroupDb.ConfigurationJson, displayGroup.Configuration); err != nil {
+		return nil, err
+	}
+	return &displayGroupDb, nil
+}
+
+// Converts the flattened DisplayGroupDb to the nested struct DisplayGroup.
+func (displayGroupDb *DisplayGroupDb) Deserialize() (*DisplayGroup, error) {
+	displayGroup := DisplayGroup{Id: displayGroupDb.Id, Name: displayGroupDb.Name, Type: displayGroupDb.Type,
+		PlaylistId: displayGroupDb.PlaylistId}
+	if err := json.Unmarshal([]byte(displayGroupDb.ConfigurationJson), &displayGroup.Configuration); err != nil {
+		return nil, err
+	}
+	if displayGroup.Configuration == nil {
+		displayGroup.Configuration = make(map[string]string)
+	}
+	return &displayGroup, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentDisplayGroup(t *testing.T) {
+	db := setupTestDb(t)
+
+	displayGroup, err := db.GetDisplayGroupById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, displayGroup)
+}
+
+func TestDisplayGroupCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	displayGroup := DisplayGroup{Name: "Pit TVs", Type: 6, Configuration: map[string]string{"scrollMsPerRow": "1000"}}
+	assert.Nil(t, db.CreateDisplayGroup(&displayGroup))
+	displayGroup2, err := db.GetDisplayGroupById(displayGroup.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, displayGroup, *displayGroup2)
+
+	displayGroup.Name = "Stands"
+	displayGroup.PlaylistId = 2
+	assert.Nil(t, db.SaveDisplayGroup(&displayGroup))
+	displayGroups, err := db.GetAllDisplayGroups()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(displayGroups)) {
+		assert.Equal(t, displayGroup, displayGroups[0])
+	}
+
+	assert.Nil(t, db.DeleteDisplayGroup(&displayGroup))
+	displayGroup2, err = db.GetDisplayGroupById(displayGroup.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, displayGroup2)
+
+	db.CreateDisplayGroup(&DisplayGroup{Name: "Queue"})
+	db.TruncateDisplayGroups()
+	displayGroups, _ = db.GetAllDisplayGroups()
+	assert.Empty(t, displayGroups)
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a sequence of display configurations that a display group rotates through.
+
+package model
+
+import "encoding/json"
+
+type DisplayPlaylist struct {
+	Id    int
+	Name  string
+	Steps []DisplayPlaylistStep
+}
+
+type DisplayPlaylistStep struct {
+	Type          int
+	Configuration map[string]string
+	DwellTimeSec  int
+}
+
+type DisplayPlaylistDb struct {
+	Id        int
+	Name      string
+	StepsJson string
+}
+
+func (database *Database) CreateDisplayPlaylist(displayPlaylist *DisplayPlaylist) error {
+	displayPlaylistDb, err := displayPlaylist.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = database.displayPlaylistMap.Insert(displayPlaylistDb); err != nil {
+		return err
+	}
+	displayPlaylist.Id = displayPlaylistDb.Id
+	return nil
+}
+
+func (database *Database) GetDisplayPlaylistById(id int) (*DisplayPlaylist, error) {
+	displayPlaylistDb := new(DisplayPlaylistDb)
+	err := database.displayPlaylistMap.Get(displayPlaylistDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return displayPlaylistDb.Deserialize()
+}
+
+func (database *Database) SaveDisplayPlaylist(displayPlaylist *DisplayPlaylist) error {
+	displayPlaylistDb, err := displayPlaylist.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayPlaylistMap.Update(displayPlaylistDb)
+	return err
+}
+
+func (database *Database) DeleteDisplayPlaylist(displayPlaylist *DisplayPlaylist) error {
+	displayPlaylistDb, err := displayPlaylist.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.displayPlaylistMap.Delete(displayPlaylistDb)
+	return err
+}
+
+func (database *Database) TruncateDisplayPlaylists() error {
+	return database.displayPlaylistMap.TruncateTables()
+}
+
+func (database *Database) GetAllDisplayPlaylists() ([]DisplayPlaylist, error) {
+	var displayPlaylistDbs []DisplayPlaylistDb
+	err := database.displayPlaylistMap.Select(&displayPlaylistDbs, "SELECT * FROM display_playlists ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	displayPlaylists := make([]DisplayPlaylist, len(displayPlaylistDbs))
+	for i, displayPlaylistDb := range displayPlaylistDbs {
+		displayPlaylist, err := displayPlaylistDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		displayPlaylists[i] = *displayPlaylist
+	}
+	return displayPlaylists, nil
+}
+
+// Converts the nested struct DisplayPlaylist to the flattened DisplayPlaylistDb for saving to the database.
+func (displayPlaylist *DisplayPlaylist) Serialize() (*DisplayPlaylistDb, error) {
+	displayPlaylistDb := DisplayPlaylistDb{Id: displayPlaylist.Id, Name: displayPlaylist.Name}
+	if err := serializeHelper(&displayPlaylistDb.StepsJson, displayPlaylist.Steps); err != nil {
+		return nil, err
+	}
+	return &displayPlaylistDb, nil
+}
+
+// Converts the flattened DisplayPlaylistDb to the nested struct DisplayPlaylist.
+func (displayPlaylistDb *DisplayPlaylistDb) Deserialize() (*DisplayPlaylist, error) {
+	displayPlaylist := DisplayPlaylist{Id: displayPlaylistDb.Id, Name: displayPlaylistDb.Name}
+	if err := json.Unmarshal([]byte(displayPlaylistDb.StepsJson), &displayPlaylist.Steps); err != nil {
+		return nil, err
+	}
+	return &displayPlaylist, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentDisplayPlaylist(t *testing.T) {
+	db := setupTestDb(t)
+
+	displayPlaylist, err := db.GetDisplayPlaylistById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, displayPlaylist)
+}
+
+func TestDisplayPlaylistCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	displayPlaylist := DisplayPlaylist{Name: "Pit Rotation", Steps: []DisplayPlaylistStep{
+		{Type: 6, Configuration: map[string]string{"scrollMsPerRow": "1000"}, DwellTimeSec: 60},
+		{Type: 9, Configuration: map[string]string{}, DwellTimeSec: 30},
+	}}
+	assert.Nil(t, db.CreateDisplayPlaylist(&displayPlaylist))
+	displayPlaylist2, err := db.GetDisplayPlaylistById(displayPlaylist.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, displayPlaylist, *displayPlaylist2)
+
+	displayPlaylist.Steps = displayPlaylist.Steps[1:]
+	assert.Nil(t, db.SaveDisplayPlaylist(&displayPlaylist))
+	displayPlaylists, err := db.GetAllDisplayPlaylists()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(displayPlaylists)) {
+		assert.Equal(t, displayPlaylist, displayPlaylists[0])
+	}
+
+	assert.Nil(t, db.DeleteDisplayPlaylist(&displayPlaylist))
+	displayPlaylist2, err = db.GetDisplayPlaylistById(displayPlaylist.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, displayPlaylist2)
+
+	db.CreateDisplayPlaylist(&DisplayPlaylist{Name: "Stands"})
+	db.TruncateDisplayPlaylists()
+	displayPlaylists, _ = db.GetAllDisplayPlaylists()
+	assert.Empty(t, displayPlaylists)
+}
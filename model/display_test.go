@@ -0,0 +1,66 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetNonexistentDisplay(t *testing.T) {
+	db := setupTestDb(t)
+
+	display, err := db.GetDisplayById("254")
+	assert.Nil(t, err)
+	assert.Nil(t, display)
+}
+
+func TestDisplayCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	display := Display{Id: "254", Nickname: "Red Alliance Station", Type: 2,
+		Configuration: map[string]string{"station": "R1"}, IpAddress: "10.0.100.20",
+		LastSeenTime: time.Unix(1000, 0).UTC()}
+	assert.Nil(t, db.CreateDisplay(&display))
+	display2, err := db.GetDisplayById("254")
+	assert.Nil(t, err)
+	assert.Equal(t, display, *display2)
+
+	display.Nickname = "Blue Alliance Station"
+	display.Configuration["station"] = "B1"
+	assert.Nil(t, db.SaveDisplay(&display))
+	display2, err = db.GetDisplayById("254")
+	assert.Nil(t, err)
+	assert.Equal(t, display, *display2)
+
+	assert.Nil(t, db.DeleteDisplay(&display))
+	display2, err = db.GetDisplayById("254")
+	assert.Nil(t, err)
+	assert.Nil(t, display2)
+}
+
+func TestGetAllDisplays(t *testing.T) {
+	db := setupTestDb(t)
+
+	displays, err := db.GetAllDisplays()
+	assert.Nil(t, err)
+	assert.Empty(t, displays)
+
+	db.CreateDisplay(&Display{Id: "254", Type: 1})
+	db.CreateDisplay(&Display{Id: "148", Type: 6, Configuration: map[string]string{"scrollMsPerRow": "1000"}})
+	displays, err = db.GetAllDisplays()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(displays)) {
+		assert.Equal(t, "148", displays[0].Id)
+		assert.Equal(t, "1000", displays[0].Configuration["scrollMsPerRow"])
+		assert.Equal(t, "254", displays[1].Id)
+		assert.Equal(t, map[string]string{}, displays[1].Configuration)
+	}
+
+	db.TruncateDisplays()
+	displays, err = db.GetAllDisplays()
+	assert.Nil(t, err)
+	assert.Empty(t, displays)
+}
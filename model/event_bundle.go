@@ -0,0 +1,481 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Export and import of a portable, versioned bundle containing all of an event's data, for archiving an event or
+// moving it to another installation independently of the database migration history.
+
+package model
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/modl"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const (
+	eventBundleDataFile = "event.json"
+	eventBundleLogsDir  = "logs"
+)
+
+// Functions for upgrading the decoded JSON of a bundle written by an older version of the software, where the entry
+// at index i converts a version i+1 bundle into a version i+2 bundle. Append to this list whenever a change is made
+// that would cause older bundles to be imported incorrectly (e.g. a renamed field or a new field with a non-zero
+// default); the current bundle version is derived from its length.
+var eventBundleUpgrades []func(bundle map[string]interface{}) error
+
+type EventBundle struct {
+	Version             int
+	ExportedAt          time.Time
+	SchemaVersion       int64 // Database schema version of the exporting instance; informational only.
+	EventSettings       *EventSettings
+	Teams               []Team
+	Matches             []Match
+	MatchResults        []MatchResultDb
+	Rankings            []RankingDb
+	AllianceTeams       []AllianceTeam
+	AllianceSelection   *AllianceSelectionDb
+	LowerThirds         []LowerThird
+	SponsorSlides       []SponsorSlide
+	ScheduleBlocks      []ScheduleBlock
+	Awards              []Award
+	AwardWinners        []AwardWinner
+	Displays            []DisplayDb
+	DisplayGroups       []DisplayGroupDb
+	DisplayPlaylists    []DisplayPlaylistDb
+	ApiKeys             []ApiKey
+	Webhooks            []Webhook
+	LedSequences        []LedSequenceDb
+	LedSettings         *LedSettingsDb
+	LightingFixtures    []LightingFixtureDb
+	LightingCues        []LightingCueDb
+	SoundPacks          []SoundPack
+	SoundPackFiles      []SoundPackFile
+	TbaOutboxItems      []TbaOutboxItem
+	TbaPublishedMatches []TbaPublishedMatch
+}
+
+// Names of the tables carried by an event bundle, in the order in which they are restored.
+var EventBundleTables = []string{"event_settings", "teams", "matches", "match_results", "rankings", "alliance_teams",
+	"alliance_selection", "lower_thirds", "sponsor_slides", "schedule_blocks", "awards", "award_winners", "displays",
+	"display_groups", "display_playlists", "api_keys", "webhooks", "led_sequences", "led_settings", "lighting_fixtures",
+	"lighting_cues", "sound_packs", "sound_pack_files", "tba_outbox_items", "tba_published_matches"}
+
+// Returns the version number that newly exported bundles are stamped with.
+func EventBundleVersion() int {
+	return len(eventBundleUpgrades) + 1
+}
+
+// Writes a zip archive containing all of the event's data along with the team match logs in the given directory.
+func (database *Database) ExportEventBundle(writer io.Writer, logsPath string) error {
+	bundle, err := database.BuildEventBundle()
+	if err != nil {
+		return err
+	}
+
+	zipWriter := zip.NewWriter(writer)
+	dataWriter, err := zipWriter.Create(eventBundleDataFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(dataWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	logFiles, err := filepath.Glob(filepath.Join(logsPath, "*.csv"))
+	if err != nil {
+		return err
+	}
+	for _, logFile := range logFiles {
+		if err = addFileToZip(zipWriter, logFile, path.Join(eventBundleLogsDir, filepath.Base(logFile))); err != nil {
+			return err
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+// Replaces all of the event's data with the contents of the given bundle, upgrading it first if it was exported by an
+// older version, and extracts its team match logs into the given directory.
+func (database *Database) ImportEventBundle(reader io.ReaderAt, size int64, logsPath string) error {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return fmt.Errorf("The file is not a valid event bundle: %v", err)
+	}
+
+	var bundle *EventBundle
+	for _, file := range zipReader.File {
+		if file.Name == eventBundleDataFile {
+			if bundle, err = readEventBundleData(file); err != nil {
+				return err
+			}
+		}
+	}
+	if bundle == nil {
+		return fmt.Errorf("The event bundle is missing its %s file.", eventBundleDataFile)
+	}
+	if bundle.EventSettings == nil {
+		return fmt.Errorf("The event bundle doesn't contain any event settings.")
+	}
+
+	if err = database.RestoreEventBundle(bundle); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(logsPath, 0755); err != nil {
+		return err
+	}
+	for _, file := range zipReader.File {
+		if path.Dir(file.Name) != eventBundleLogsDir || file.FileInfo().IsDir() {
+			continue
+		}
+		if err = extractFileFromZip(file, filepath.Join(logsPath, path.Base(file.Name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes the bundle's data file, applying any upgrades needed to bring it up to the current version.
+func parseEventBundle(data []byte) (*EventBundle, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("Could not parse the event bundle data: %v", err)
+	}
+	version, ok := fields["Version"].(float64)
+	if !ok || version < 1 {
+		return nil, fmt.Errorf("The event bundle is missing its version number.")
+	}
+	if int(version) > EventBundleVersion() {
+		return nil, fmt.Errorf("The event bundle has version %d but this installation only supports up to version "+
+			"%d; upgrade the software before importing it.", int(version), EventBundleVersion())
+	}
+	for i := int(version) - 1; i < len(eventBundleUpgrades); i++ {
+		if err := eventBundleUpgrades[i](fields); err != nil {
+			return nil, fmt.Errorf("Could not upgrade the event bundle from version %d: %v", i+1, err)
+		}
+	}
+	fields["Version"] = EventBundleVersion()
+
+	upgradedData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	bundle := new(EventBundle)
+	if err = json.Unmarshal(upgradedData, bundle); err != nil {
+		return nil, fmt.Errorf("Could not parse the event bundle data: %v", err)
+	}
+	return bundle, nil
+}
+
+// Reads all of the event's data out of the database in its stored form.
+func (database *Database) BuildEventBundle() (*EventBundle, error) {
+	return database.BuildEventBundleTables(EventBundleTables)
+}
+
+// Reads the given tables out of the database in their stored form, leaving the bundle's other fields empty.
+func (database *Database) BuildEventBundleTables(tables []string) (*EventBundle, error) {
+	bundle := EventBundle{Version: EventBundleVersion(), ExportedAt: time.Now()}
+	var err error
+	if bundle.SchemaVersion, err = database.GetSchemaVersion(); err != nil {
+		return nil, err
+	}
+
+	var allianceSelections []AllianceSelectionDb
+	var ledSettings []LedSettingsDb
+	selects := map[string]func() error{
+		"event_settings": func() (err error) {
+			bundle.EventSettings, err = database.GetEventSettings()
+			return
+		},
+		"teams": func() error {
+			return database.teamMap.Select(&bundle.Teams, "SELECT * FROM teams ORDER BY id")
+		},
+		"matches": func() error {
+			return database.matchMap.Select(&bundle.Matches, "SELECT * FROM matches ORDER BY id")
+		},
+		"match_results": func() error {
+			return database.matchResultMap.Select(&bundle.MatchResults, "SELECT * FROM match_results ORDER BY id")
+		},
+		"rankings": func() error {
+			return database.rankingMap.Select(&bundle.Rankings, "SELECT * FROM rankings ORDER BY teamid")
+		},
+		"alliance_teams": func() error {
+			return database.allianceTeamMap.Select(&bundle.AllianceTeams, "SELECT * FROM alliance_teams ORDER BY id")
+		},
+		"alliance_selection": func() error {
+			return database.allianceSelectionMap.Select(&allianceSelections, "SELECT * FROM alliance_selection")
+		},
+		"lower_thirds": func() error {
+			return database.lowerThirdMap.Select(&bundle.LowerThirds, "SELECT * FROM lower_thirds ORDER BY id")
+		},
+		"sponsor_slides": func() error {
+			return database.sponsorSlideMap.Select(&bundle.SponsorSlides, "SELECT * FROM sponsor_slides ORDER BY id")
+		},
+		"schedule_blocks": func() error {
+			return database.scheduleBlockMap.Select(&bundle.ScheduleBlocks,
+				"SELECT * FROM schedule_blocks ORDER BY id")
+		},
+		"awards": func() error {
+			return database.awardMap.Select(&bundle.Awards, "SELECT * FROM awards ORDER BY id")
+		},
+		"award_winners": func() error {
+			return database.awardWinnerMap.Select(&bundle.AwardWinners, "SELECT * FROM award_winners ORDER BY id")
+		},
+		"displays": func() error {
+			return database.displayMap.Select(&bundle.Displays, "SELECT * FROM displays ORDER BY id")
+		},
+		"display_groups": func() error {
+			return database.displayGroupMap.Select(&bundle.DisplayGroups, "SELECT * FROM display_groups ORDER BY id")
+		},
+		"display_playlists": func() error {
+			return database.displayPlaylistMap.Select(&bundle.DisplayPlaylists,
+				"SELECT * FROM display_playlists ORDER BY id")
+		},
+		"api_keys": func() error {
+			return database.apiKeyMap.Select(&bundle.ApiKeys, "SELECT * FROM api_keys ORDER BY id")
+		},
+		"webhooks": func() error {
+			return database.webhookMap.Select(&bundle.Webhooks, "SELECT * FROM webhooks ORDER BY id")
+		},
+		"led_sequences": func() error {
+			return database.ledSequenceMap.Select(&bundle.LedSequences, "SELECT * FROM led_sequences ORDER BY id")
+		},
+		"led_settings": func() error {
+			return database.ledSettingsMap.Select(&ledSettings, "SELECT * FROM led_settings")
+		},
+		"lighting_fixtures": func() error {
+			return database.lightingFixtureMap.Select(&bundle.LightingFixtures,
+				"SELECT * FROM lighting_fixtures ORDER BY id")
+		},
+		"lighting_cues": func() error {
+			return database.lightingCueMap.Select(&bundle.LightingCues, "SELECT * FROM lighting_cues ORDER BY id")
+		},
+		"sound_packs": func() error {
+			return database.soundPackMap.Select(&bundle.SoundPacks, "SELECT * FROM sound_packs ORDER BY id")
+		},
+		"sound_pack_files": func() error {
+			return database.soundPackFileMap.Select(&bundle.SoundPackFiles,
+				"SELECT * FROM sound_pack_files ORDER BY id")
+		},
+		"tba_outbox_items": func() error {
+			return database.tbaOutboxItemMap.Select(&bundle.TbaOutboxItems,
+				"SELECT * FROM tba_outbox_items ORDER BY id")
+		},
+		"tba_published_matches": func() error {
+			return database.tbaPublishedMatchMap.Select(&bundle.TbaPublishedMatches,
+				"SELECT * FROM tba_published_matches ORDER BY id")
+		},
+	}
+	for _, table := range tables {
+		selectTable, ok := selects[table]
+		if !ok {
+			return nil, fmt.Errorf("Table %s is not part of an event bundle.", table)
+		}
+		if err = selectTable(); err != nil {
+			return nil, err
+		}
+	}
+	if len(allianceSelections) > 0 {
+		bundle.AllianceSelection = &allianceSelections[0]
+	}
+	if len(ledSettings) > 0 {
+		bundle.LedSettings = &ledSettings[0]
+	}
+	return &bundle, nil
+}
+
+// Replaces the contents of every event table with the bundle's records in a single transaction, keeping the original
+// IDs so that references between records remain intact.
+func (database *Database) RestoreEventBundle(bundle *EventBundle) error {
+	return database.RestoreEventBundleTables(bundle, EventBundleTables)
+}
+
+// Replaces the contents of the given tables with the bundle's records as above, leaving the other tables untouched.
+func (database *Database) RestoreEventBundleTables(bundle *EventBundle, tables []string) error {
+	importMap := modl.NewDbMap(database.db, new(modl.SqliteDialect))
+	importMap.AddTableWithName(EventSettings{}, "event_settings").SetKeys(false, "Id")
+	importMap.AddTableWithName(Team{}, "teams").SetKeys(false, "Id")
+	importMap.AddTableWithName(Match{}, "matches").SetKeys(false, "Id")
+	importMap.AddTableWithName(MatchResultDb{}, "match_results").SetKeys(false, "Id")
+	importMap.AddTableWithName(RankingDb{}, "rankings").SetKeys(false, "TeamId")
+	importMap.AddTableWithName(AllianceTeam{}, "alliance_teams").SetKeys(false, "Id")
+	importMap.AddTableWithName(AllianceSelectionDb{}, "alliance_selection").SetKeys(false, "Id")
+	importMap.AddTableWithName(LowerThird{}, "lower_thirds").SetKeys(false, "Id")
+	importMap.AddTableWithName(SponsorSlide{}, "sponsor_slides").SetKeys(false, "Id")
+	importMap.AddTableWithName(ScheduleBlock{}, "schedule_blocks").SetKeys(false, "Id")
+	importMap.AddTableWithName(Award{}, "awards").SetKeys(false, "Id")
+	importMap.AddTableWithName(AwardWinner{}, "award_winners").SetKeys(false, "Id")
+	importMap.AddTableWithName(DisplayDb{}, "displays").SetKeys(false, "Id")
+	importMap.AddTableWithName(DisplayGroupDb{}, "display_groups").SetKeys(false, "Id")
+	importMap.AddTableWithName(DisplayPlaylistDb{}, "display_playlists").SetKeys(false, "Id")
+	importMap.AddTableWithName(ApiKey{}, "api_keys").SetKeys(false, "Id")
+	importMap.AddTableWithName(Webhook{}, "webhooks").SetKeys(false, "Id")
+	importMap.AddTableWithName(LedSequenceDb{}, "led_sequences").SetKeys(false, "Id")
+	importMap.AddTableWithName(LedSettingsDb{}, "led_settings").SetKeys(false, "Id")
+	importMap.AddTableWithName(LightingFixtureDb{}, "lighting_fixtures").SetKeys(false, "Id")
+	importMap.AddTableWithName(LightingCueDb{}, "lighting_cues").SetKeys(false, "Id")
+	importMap.AddTableWithName(SoundPack{}, "sound_packs").SetKeys(false, "Id")
+	importMap.AddTableWithName(SoundPackFile{}, "sound_pack_files").SetKeys(false, "Id")
+	importMap.AddTableWithName(TbaOutboxItem{}, "tba_outbox_items").SetKeys(false, "Id")
+	importMap.AddTableWithName(TbaPublishedMatch{}, "tba_published_matches").SetKeys(false, "Id")
+
+	var records []interface{}
+	if bundle.EventSettings != nil {
+		// Keep this installation's own lighting component ID rather than taking the one where the bundle came from.
+		localEventSettings, err := database.GetEventSettings()
+		if err != nil {
+			return err
+		}
+		eventSettings := *bundle.EventSettings
+		eventSettings.LightingComponentId = localEventSettings.LightingComponentId
+		records = append(records, &eventSettings)
+	}
+	for i := range bundle.Teams {
+		records = append(records, &bundle.Teams[i])
+	}
+	for i := range bundle.Matches {
+		records = append(records, &bundle.Matches[i])
+	}
+	for i := range bundle.MatchResults {
+		records = append(records, &bundle.MatchResults[i])
+	}
+	for i := range bundle.Rankings {
+		records = append(records, &bundle.Rankings[i])
+	}
+	for i := range bundle.AllianceTeams {
+		records = append(records, &bundle.AllianceTeams[i])
+	}
+	if bundle.AllianceSelection != nil {
+		records = append(records, bundle.AllianceSelection)
+	}
+	for i := range bundle.LowerThirds {
+		records = append(records, &bundle.LowerThirds[i])
+	}
+	for i := range bundle.SponsorSlides {
+		records = append(records, &bundle.SponsorSlides[i])
+	}
+	for i := range bundle.ScheduleBlocks {
+		records = append(records, &bundle.ScheduleBlocks[i])
+	}
+	for i := range bundle.Awards {
+		records = append(records, &bundle.Awards[i])
+	}
+	for i := range bundle.AwardWinners {
+		records = append(records, &bundle.AwardWinners[i])
+	}
+	for i := range bundle.Displays {
+		records = append(records, &bundle.Displays[i])
+	}
+	for i := range bundle.DisplayGroups {
+		records = append(records, &bundle.DisplayGroups[i])
+	}
+	for i := range bundle.DisplayPlaylists {
+		records = append(records, &bundle.DisplayPlaylists[i])
+	}
+	for i := range bundle.ApiKeys {
+		records = append(records, &bundle.ApiKeys[i])
+	}
+	for i := range bundle.Webhooks {
+		records = append(records, &bundle.Webhooks[i])
+	}
+	for i := range bundle.LedSequences {
+		records = append(records, &bundle.LedSequences[i])
+	}
+	if bundle.LedSettings != nil {
+		records = append(records, bundle.LedSettings)
+	}
+	for i := range bundle.LightingFixtures {
+		records = append(records, &bundle.LightingFixtures[i])
+	}
+	for i := range bundle.LightingCues {
+		records = append(records, &bundle.LightingCues[i])
+	}
+	for i := range bundle.SoundPacks {
+		records = append(records, &bundle.SoundPacks[i])
+	}
+	for i := range bundle.SoundPackFiles {
+		records = append(records, &bundle.SoundPackFiles[i])
+	}
+	for i := range bundle.TbaOutboxItems {
+		records = append(records, &bundle.TbaOutboxItems[i])
+	}
+	for i := range bundle.TbaPublishedMatches {
+		records = append(records, &bundle.TbaPublishedMatches[i])
+	}
+
+	clearedTables := append([]string{}, tables...)
+	for _, table := range tables {
+		if table == "webhooks" {
+			// The delivery log refers to the webhooks being replaced, so it is cleared rather than carried over.
+			clearedTables = append(clearedTables, "webhook_deliveries")
+		}
+	}
+	transaction, err := importMap.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range clearedTables {
+		if _, err = transaction.Exec("DELETE FROM " + table); err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+	for _, record := range records {
+		if err = transaction.Insert(record); err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+	return transaction.Commit()
+}
+
+func readEventBundleData(file *zip.File) (*EventBundle, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseEventBundle(data)
+}
+
+func addFileToZip(zipWriter *zip.Writer, sourcePath, name string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	dest, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, source)
+	return err
+}
+
+func extractFileFromZip(file *zip.File, destPath string) error {
+	source, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	_, err = io.Copy(dest, source)
+	return err
+}
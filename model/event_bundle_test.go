@@ -0,0 +1,213 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportImportEventBundle(t *testing.T) {
+	sourceDb := SetupTestDb(t, "event_bundle_source")
+	defer sourceDb.Close()
+	sourceLogsPath, _ := ioutil.TempDir("", "source-logs")
+	defer os.RemoveAll(sourceLogsPath)
+	ioutil.WriteFile(filepath.Join(sourceLogsPath, "254_Match_Q1.csv"), []byte("matchTimeSec,packetType\n"), 0644)
+
+	eventSettings, _ := sourceDb.GetEventSettings()
+	eventSettings.Name = "Chezy Champs"
+	sourceDb.SaveEventSettings(eventSettings)
+	sourceDb.CreateTeam(&Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	match1 := Match{Type: "qualification", DisplayName: "1", Time: time.Unix(1543078800, 0).UTC(), Red1: 254}
+	match2 := Match{Type: "qualification", DisplayName: "2", Time: time.Unix(1543079220, 0).UTC(), Red1: 254}
+	sourceDb.CreateMatch(&match1)
+	sourceDb.CreateMatch(&match2)
+	sourceDb.DeleteMatch(&match1)
+	sourceDb.CreateMatchResult(BuildTestMatchResult(match2.Id, 1))
+	sourceDb.CreateRanking(game.TestRanking1())
+	sourceDb.SaveAllianceSelection(&AllianceSelection{InvitedTeamId: 254})
+	sourceDb.CreateAllianceTeam(&AllianceTeam{AllianceId: 1, PickPosition: 0, TeamId: 254})
+	sourceDb.CreateSponsorSlide(&SponsorSlide{Subtitle: "Sponsor", DisplayTimeSec: 10})
+	sourceDb.CreateAward(&Award{Name: "Winner"})
+	sourceDb.CreateAwardWinner(&AwardWinner{AwardId: 1, TeamId: 254})
+	sourceDb.CreateLowerThird(&LowerThird{TopText: "Winner", AwardId: 1})
+	sourceDb.CreateScheduleBlock(&ScheduleBlock{MatchType: "qualification", NumMatches: 10, MatchSpacingSec: 360})
+	sourceDb.CreateDisplay(&Display{Id: "100", Nickname: "Stage", Configuration: map[string]string{"a": "b"}})
+	sourceDb.CreateDisplayGroup(&DisplayGroup{Name: "Pits"})
+	sourceDb.CreateDisplayPlaylist(&DisplayPlaylist{Name: "Breaks"})
+	sourceDb.CreateApiKey(&ApiKey{Name: "Stream", Key: "abc123"})
+	sourceDb.CreateWebhook(&Webhook{Name: "Scores", Url: "http://example.com", Enabled: true})
+	sourceDb.CreateLedSequence(&LedSequence{Name: "sparkle"})
+	sourceDb.SaveLedSettings(&LedSettings{EventSequences: map[string]string{"match-start": "sparkle"}})
+	sourceDb.CreateLightingFixture(&LightingFixture{Name: "Wash 1", Universe: 1, Address: 1})
+	sourceDb.CreateLightingCue(&LightingCue{Name: "Pre-match", Trigger: "pre-match"})
+	sourceDb.CreateSoundPack(&SoundPack{Name: "Classic", Season: 2018})
+	sourceDb.CreateSoundPackFile(&SoundPackFile{SoundPackId: 1, Cue: "match-start", Data: []byte{1, 2, 3}})
+	sourceDb.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishRankings})
+
+	var buffer bytes.Buffer
+	assert.Nil(t, sourceDb.ExportEventBundle(&buffer, sourceLogsPath))
+
+	db := setupTestDb(t)
+	defer db.Close()
+	db.CreateTeam(&Team{Id: 1114})
+	db.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishTeams})
+	localEventSettings, _ := db.GetEventSettings()
+	logsPath, _ := ioutil.TempDir("", "logs")
+	defer os.RemoveAll(logsPath)
+	assert.Nil(t, db.ImportEventBundle(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), logsPath))
+
+	eventSettings, _ = db.GetEventSettings()
+	assert.Equal(t, "Chezy Champs", eventSettings.Name)
+	assert.Equal(t, localEventSettings.LightingComponentId, eventSettings.LightingComponentId)
+	teams, _ := db.GetAllTeams()
+	if assert.Equal(t, 1, len(teams)) {
+		assert.Equal(t, "The Cheesy Poofs", teams[0].Nickname)
+	}
+	matches, _ := db.GetMatchesByType("qualification")
+	if assert.Equal(t, 1, len(matches)) {
+		assert.Equal(t, match2.Id, matches[0].Id)
+		assert.Equal(t, match2.Time.Unix(), matches[0].Time.Unix())
+	}
+	matchResult, _ := db.GetMatchResultForMatch(match2.Id)
+	if assert.NotNil(t, matchResult) {
+		assert.Equal(t, game.TestScore1(), matchResult.RedScore)
+	}
+	rankings, _ := db.GetAllRankings()
+	assert.Equal(t, 1, len(rankings))
+	allianceSelection, _ := db.GetAllianceSelection()
+	if assert.NotNil(t, allianceSelection) {
+		assert.Equal(t, 254, allianceSelection.InvitedTeamId)
+	}
+	alliances, _ := db.GetAllAlliances()
+	assert.Equal(t, 1, len(alliances))
+	sponsorSlides, _ := db.GetAllSponsorSlides()
+	assert.Equal(t, 1, len(sponsorSlides))
+	lowerThirds, _ := db.GetLowerThirdsByAwardId(1)
+	assert.Equal(t, 1, len(lowerThirds))
+	awardWinners, _ := db.GetAwardWinnersByAward(1)
+	assert.Equal(t, 1, len(awardWinners))
+	scheduleBlocks, _ := db.GetScheduleBlocksByMatchType("qualification")
+	assert.Equal(t, 1, len(scheduleBlocks))
+	tbaOutboxItems, _ := db.GetAllTbaOutboxItems()
+	if assert.Equal(t, 1, len(tbaOutboxItems)) {
+		assert.Equal(t, TbaPublishRankings, tbaOutboxItems[0].Action)
+	}
+	display, _ := db.GetDisplayById("100")
+	if assert.NotNil(t, display) {
+		assert.Equal(t, "b", display.Configuration["a"])
+	}
+	displayGroups, _ := db.GetAllDisplayGroups()
+	assert.Equal(t, 1, len(displayGroups))
+	displayPlaylists, _ := db.GetAllDisplayPlaylists()
+	assert.Equal(t, 1, len(displayPlaylists))
+	apiKeys, _ := db.GetAllApiKeys()
+	assert.Equal(t, 1, len(apiKeys))
+	webhooks, _ := db.GetAllWebhooks()
+	assert.Equal(t, 1, len(webhooks))
+	ledSequences, _ := db.GetAllLedSequences()
+	assert.Equal(t, 1, len(ledSequences))
+	ledSettings, _ := db.GetLedSettings()
+	assert.Equal(t, "sparkle", ledSettings.EventSequences["match-start"])
+	lightingFixtures, _ := db.GetAllLightingFixtures()
+	assert.Equal(t, 1, len(lightingFixtures))
+	lightingCues, _ := db.GetAllLightingCues()
+	assert.Equal(t, 1, len(lightingCues))
+	soundPacks, _ := db.GetAllSoundPacks()
+	assert.Equal(t, 1, len(soundPacks))
+	soundPackFile, _ := db.GetSoundPackFile(1, "match-start")
+	if assert.NotNil(t, soundPackFile) {
+		assert.Equal(t, []byte{1, 2, 3}, soundPackFile.Data)
+	}
+	logData, err := ioutil.ReadFile(filepath.Join(logsPath, "254_Match_Q1.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "matchTimeSec,packetType\n", string(logData))
+
+	// Check that new records don't collide with the imported IDs.
+	match3 := Match{Type: "qualification", DisplayName: "3"}
+	assert.Nil(t, db.CreateMatch(&match3))
+	assert.True(t, match3.Id > match2.Id)
+}
+
+func TestRestoreEventBundleTables(t *testing.T) {
+	sourceDb := SetupTestDb(t, "event_bundle_source")
+	defer sourceDb.Close()
+	sourceDb.CreateTeam(&Team{Id: 254})
+	sourceDb.CreateMatch(&Match{Type: "qualification", DisplayName: "1"})
+	bundle, err := sourceDb.BuildEventBundleTables([]string{"teams"})
+	assert.Nil(t, err)
+	assert.Nil(t, bundle.EventSettings)
+	assert.Empty(t, bundle.Matches)
+	_, err = sourceDb.BuildEventBundleTables([]string{"blorpy"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Table blorpy is not part of an event bundle.", err.Error())
+	}
+
+	db := setupTestDb(t)
+	defer db.Close()
+	db.CreateTeam(&Team{Id: 1114})
+	db.CreateMatch(&Match{Type: "practice", DisplayName: "P1"})
+	assert.Nil(t, db.RestoreEventBundleTables(bundle, []string{"teams"}))
+	teams, _ := db.GetAllTeams()
+	if assert.Equal(t, 1, len(teams)) {
+		assert.Equal(t, 254, teams[0].Id)
+	}
+	matches, _ := db.GetMatchesByType("practice")
+	assert.Equal(t, 1, len(matches))
+}
+
+func TestImportEventBundleErrors(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	err := db.ImportEventBundle(bytes.NewReader([]byte("invalid")), 7, ".")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "not a valid event bundle")
+	}
+
+	_, err = parseEventBundle([]byte("{\"Teams\": []}"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "missing its version number")
+	}
+
+	_, err = parseEventBundle([]byte(fmt.Sprintf("{\"Version\": %d}", EventBundleVersion()+1)))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "upgrade the software")
+	}
+}
+
+func TestParseEventBundleUpgrades(t *testing.T) {
+	defer func(upgrades []func(map[string]interface{}) error) {
+		eventBundleUpgrades = upgrades
+	}(eventBundleUpgrades)
+	eventBundleUpgrades = append(eventBundleUpgrades, func(bundle map[string]interface{}) error {
+		bundle["Teams"] = bundle["TeamList"]
+		delete(bundle, "TeamList")
+		return nil
+	})
+
+	oldVersion := EventBundleVersion() - 1
+	bundle, err := parseEventBundle([]byte(fmt.Sprintf("{\"Version\": %d, \"TeamList\": [{\"Id\": 254}]}",
+		oldVersion)))
+	assert.Nil(t, err)
+	if assert.NotNil(t, bundle) {
+		assert.Equal(t, EventBundleVersion(), bundle.Version)
+		assert.Equal(t, []Team{{Id: 254}}, bundle.Teams)
+	}
+
+	// Check that a bundle that is already at the current version is left alone.
+	bundle, err = parseEventBundle([]byte(fmt.Sprintf("{\"Version\": %d, \"Teams\": [{\"Id\": 1114}]}",
+		EventBundleVersion())))
+	assert.Nil(t, err)
+	if assert.NotNil(t, bundle) {
+		assert.Equal(t, []Team{{Id: 1114}}, bundle.Teams)
+	}
+}
@@ -5,6 +5,11 @@
 
 package model
 
+import (
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/lighting"
+)
+
 type EventSettings struct {
 	Id                     int
 	Name                   string
@@ -13,9 +18,13 @@ type EventSettings struct {
 	SelectionRound3Order   string
 	TBADownloadEnabled     bool
 	TbaPublishingEnabled   bool
+	TbaMirrorEnabled       bool
 	TbaEventCode           string
 	TbaSecretId            string
 	TbaSecret              string
+	FrcEventsUsername      string
+	FrcEventsAuthToken     string
+	FrcEventsEventCode     string
 	NetworkSecurityEnabled bool
 	ApAddress              string
 	ApUsername             string
@@ -33,6 +42,18 @@ type EventSettings struct {
 	BlueSwitchLedAddress   string
 	RedVaultLedAddress     string
 	BlueVaultLedAddress    string
+	ElimTiebreakers        string
+	StandbyEnabled         bool
+	StandbyPrimaryAddress  string
+	StandbyPrimaryPassword string
+	BackupRetentionCount   int
+	LightingProtocol       string
+	LightingAddress        string
+	LightingPriority       int
+	LightingSyncUniverse   int
+	LightingComponentId    string // Identifies this installation to the E1.31 receivers; not part of the event.
+	SoundPackId            int    // Zero if the built-in sounds are used.
+	SoundPlayerCommand     string
 }
 
 const eventSettingsId = 0
@@ -50,6 +71,11 @@ func (database *Database) GetEventSettings() (*EventSettings, error) {
 		eventSettings.ApTeamChannel = 157
 		eventSettings.ApAdminChannel = 0
 		eventSettings.ApAdminWpaKey = "1234Five"
+		eventSettings.ElimTiebreakers = game.DefaultElimTiebreakers
+		eventSettings.BackupRetentionCount = 20
+		eventSettings.LightingProtocol = lighting.E131Protocol
+		eventSettings.LightingPriority = lighting.DefaultPriority
+		eventSettings.LightingComponentId = lighting.NewComponentId()
 
 		err = database.eventSettingsMap.Insert(eventSettings)
 		if err != nil {
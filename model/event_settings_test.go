@@ -15,7 +15,10 @@ func TestEventSettingsReadWrite(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, EventSettings{Id: 0, Name: "Untitled Event", NumElimAlliances: 8, SelectionRound2Order: "L",
 		SelectionRound3Order: "", TBADownloadEnabled: true, ApTeamChannel: 157, ApAdminChannel: 0,
-		ApAdminWpaKey: "1234Five"}, *eventSettings)
+		ApAdminWpaKey: "1234Five", ElimTiebreakers: "fouls,auto,ownership,parkclimb", BackupRetentionCount: 20,
+		LightingProtocol: "e131", LightingPriority: 100, LightingComponentId: eventSettings.LightingComponentId},
+		*eventSettings)
+	assert.Equal(t, 32, len(eventSettings.LightingComponentId))
 
 	eventSettings.Name = "Chezy Champs"
 	eventSettings.NumElimAlliances = 6
@@ -0,0 +1,111 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a custom LED sequence defined by the event staff.
+
+package model
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/led"
+)
+
+type LedSequence struct {
+	Id        int
+	Name      string
+	Loop      bool
+	Keyframes []led.Keyframe
+}
+
+type LedSequenceDb struct {
+	Id            int
+	Name          string
+	Loop          bool
+	KeyframesJson string
+}
+
+func (database *Database) CreateLedSequence(ledSequence *LedSequence) error {
+	ledSequenceDb, err := ledSequence.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = database.ledSequenceMap.Insert(ledSequenceDb); err != nil {
+		return err
+	}
+	ledSequence.Id = ledSequenceDb.Id
+	return nil
+}
+
+func (database *Database) GetLedSequenceById(id int) (*LedSequence, error) {
+	ledSequenceDb := new(LedSequenceDb)
+	err := database.ledSequenceMap.Get(ledSequenceDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ledSequenceDb.Deserialize()
+}
+
+func (database *Database) SaveLedSequence(ledSequence *LedSequence) error {
+	ledSequenceDb, err := ledSequence.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.ledSequenceMap.Update(ledSequenceDb)
+	return err
+}
+
+func (database *Database) DeleteLedSequence(ledSequence *LedSequence) error {
+	ledSequenceDb, err := ledSequence.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.ledSequenceMap.Delete(ledSequenceDb)
+	return err
+}
+
+func (database *Database) TruncateLedSequences() error {
+	return database.ledSequenceMap.TruncateTables()
+}
+
+func (database *Database) GetAllLedSequences() ([]LedSequence, error) {
+	var ledSequenceDbs []LedSequenceDb
+	err := database.ledSequenceMap.Select(&ledSequenceDbs, "SELECT * FROM led_sequences ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	ledSequences := make([]LedSequence, len(ledSequenceDbs))
+	for i, ledSequenceDb := range ledSequenceDbs {
+		ledSequence, err := ledSequenceDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		ledSequences[i] = *ledSequence
+	}
+	return ledSequences, nil
+}
+
+// Returns the renderable sequence that this record defines.
+func (ledSequence *LedSequence) Sequence() *led.Sequence {
+	return &led.Sequence{Name: ledSequence.Name, Keyframes: ledSequence.Keyframes, Loop: ledSequence.Loop}
+}
+
+// Converts the nested struct LedSequence to the flattened LedSequenceDb for saving to the database.
+func (ledSequence *LedSequence) Serialize() (*LedSequenceDb, error) {
+	ledSequenceDb := LedSequenceDb{Id: ledSequence.Id, Name: ledSequence.Name, Loop: ledSequence.Loop}
+	if err := serializeHelper(&ledSequenceDb.KeyframesJson, ledSequence.Keyframes); err != nil {
+		return nil, err
+	}
+	return &ledSequenceDb, nil
+}
+
+// Converts the flattened LedSequenceDb to the nested struct LedSequence.
+func (ledSequenceDb *LedSequenceDb) Deserialize() (*LedSequence, error) {
+	ledSequence := LedSequence{Id: ledSequenceDb.Id, Name: ledSequenceDb.Name, Loop: ledSequenceDb.Loop}
+	if err := json.Unmarshal([]byte(ledSequenceDb.KeyframesJson), &ledSequence.Keyframes); err != nil {
+		return nil, err
+	}
+	return &ledSequence, nil
+}
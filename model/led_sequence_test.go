@@ -0,0 +1,59 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentLedSequence(t *testing.T) {
+	db := setupTestDb(t)
+
+	ledSequence, err := db.GetLedSequenceById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, ledSequence)
+}
+
+func TestLedSequenceCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	ledSequence := LedSequence{Name: "Sparkle", Loop: true, Keyframes: []led.Keyframe{
+		{Effect: led.BlinkEffect, Colors: []string{"white", "#102030"}, DurationMs: 500, PeriodMs: 50},
+		{Effect: led.SolidEffect, Colors: []string{"alliance"}, DurationMs: 500},
+	}}
+	assert.Nil(t, db.CreateLedSequence(&ledSequence))
+	ledSequence2, err := db.GetLedSequenceById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, ledSequence, *ledSequence2)
+	assert.Equal(t, &led.Sequence{Name: "Sparkle", Loop: true, Keyframes: ledSequence.Keyframes},
+		ledSequence2.Sequence())
+
+	// Check that names are unique.
+	assert.NotNil(t, db.CreateLedSequence(&LedSequence{Name: "Sparkle"}))
+
+	ledSequence.Loop = false
+	db.SaveLedSequence(&ledSequence)
+	ledSequences, err := db.GetAllLedSequences()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(ledSequences)) {
+		assert.Equal(t, ledSequence, ledSequences[0])
+	}
+
+	db.DeleteLedSequence(&ledSequence)
+	ledSequence2, err = db.GetLedSequenceById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, ledSequence2)
+}
+
+func TestTruncateLedSequences(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateLedSequence(&LedSequence{Name: "Sparkle"})
+	db.TruncateLedSequences()
+	ledSequences, err := db.GetAllLedSequences()
+	assert.Nil(t, err)
+	assert.Empty(t, ledSequences)
+}
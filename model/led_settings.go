@@ -0,0 +1,90 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore read/write methods for the configuration of the field LED controllers and the sequences that
+// they show in response to arena and game events.
+
+package model
+
+import "encoding/json"
+
+const ledSettingsId = 0
+
+type LedSettings struct {
+	Id             int
+	Controllers    map[string]LedControllerSettings // Keyed by the name of the field element.
+	EventSequences map[string]string                // Comma-separated sequence names, keyed by event.
+}
+
+type LedControllerSettings struct {
+	NumPixels    int
+	NearUniverse int
+	FarUniverse  int
+}
+
+type LedSettingsDb struct {
+	Id                 int
+	ControllersJson    string
+	EventSequencesJson string
+}
+
+// Returns the LED settings, which are empty (meaning that the defaults apply) until they are first saved.
+func (database *Database) GetLedSettings() (*LedSettings, error) {
+	ledSettingsDb := new(LedSettingsDb)
+	err := database.ledSettingsMap.Get(ledSettingsDb, ledSettingsId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return &LedSettings{Id: ledSettingsId, Controllers: map[string]LedControllerSettings{},
+				EventSequences: map[string]string{}}, nil
+		}
+		return nil, err
+	}
+	return ledSettingsDb.Deserialize()
+}
+
+func (database *Database) SaveLedSettings(ledSettings *LedSettings) error {
+	ledSettings.Id = ledSettingsId
+	ledSettingsDb, err := ledSettings.Serialize()
+	if err != nil {
+		return err
+	}
+	count, err := database.ledSettingsMap.Update(ledSettingsDb)
+	if err == nil && count == 0 {
+		err = database.ledSettingsMap.Insert(ledSettingsDb)
+	}
+	return err
+}
+
+func (database *Database) TruncateLedSettings() error {
+	return database.ledSettingsMap.TruncateTables()
+}
+
+// Converts the nested struct LedSettings to the flattened LedSettingsDb for saving to the database.
+func (ledSettings *LedSettings) Serialize() (*LedSettingsDb, error) {
+	ledSettingsDb := LedSettingsDb{Id: ledSettings.Id}
+	if err := serializeHelper(&ledSettingsDb.ControllersJson, ledSettings.Controllers); err != nil {
+		return nil, err
+	}
+	if err := serializeHelper(&ledSettingsDb.EventSequencesJson, ledSettings.EventSequences); err != nil {
+		return nil, err
+	}
+	return &ledSettingsDb, nil
+}
+
+// Converts the flattened LedSettingsDb to the nested struct LedSettings.
+func (ledSettingsDb *LedSettingsDb) Deserialize() (*LedSettings, error) {
+	ledSettings := LedSettings{Id: ledSettingsDb.Id}
+	if err := json.Unmarshal([]byte(ledSettingsDb.ControllersJson), &ledSettings.Controllers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(ledSettingsDb.EventSequencesJson), &ledSettings.EventSequences); err != nil {
+		return nil, err
+	}
+	if ledSettings.Controllers == nil {
+		ledSettings.Controllers = map[string]LedControllerSettings{}
+	}
+	if ledSettings.EventSequences == nil {
+		ledSettings.EventSequences = map[string]string{}
+	}
+	return &ledSettings, nil
+}
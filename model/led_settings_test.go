@@ -0,0 +1,36 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLedSettingsReadWrite(t *testing.T) {
+	db := setupTestDb(t)
+
+	ledSettings, err := db.GetLedSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, LedSettings{Id: 0, Controllers: map[string]LedControllerSettings{},
+		EventSequences: map[string]string{}}, *ledSettings)
+
+	ledSettings.Controllers["scale"] = LedControllerSettings{NumPixels: 150, NearUniverse: 5, FarUniverse: 6}
+	ledSettings.EventSequences["warmup"] = "Warmup,Sparkle"
+	assert.Nil(t, db.SaveLedSettings(ledSettings))
+	ledSettings2, err := db.GetLedSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, ledSettings, ledSettings2)
+
+	ledSettings.EventSequences["warmup"] = ""
+	assert.Nil(t, db.SaveLedSettings(ledSettings))
+	ledSettings2, err = db.GetLedSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, "", ledSettings2.EventSequences["warmup"])
+
+	db.TruncateLedSettings()
+	ledSettings2, err = db.GetLedSettings()
+	assert.Nil(t, err)
+	assert.Empty(t, ledSettings2.Controllers)
+}
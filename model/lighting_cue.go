@@ -0,0 +1,115 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a venue lighting cue, optionally fired automatically by a match event.
+
+package model
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/lighting"
+)
+
+type LightingCue struct {
+	Id      int
+	Name    string
+	Trigger string // The match event that fires the cue automatically, or blank if it is only fired by hand.
+	FadeMs  int
+	Looks   []lighting.Look
+}
+
+type LightingCueDb struct {
+	Id        int
+	Name      string
+	Trigger   string
+	FadeMs    int
+	LooksJson string
+}
+
+func (database *Database) CreateLightingCue(lightingCue *LightingCue) error {
+	lightingCueDb, err := lightingCue.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = database.lightingCueMap.Insert(lightingCueDb); err != nil {
+		return err
+	}
+	lightingCue.Id = lightingCueDb.Id
+	return nil
+}
+
+func (database *Database) GetLightingCueById(id int) (*LightingCue, error) {
+	lightingCueDb := new(LightingCueDb)
+	err := database.lightingCueMap.Get(lightingCueDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lightingCueDb.Deserialize()
+}
+
+func (database *Database) SaveLightingCue(lightingCue *LightingCue) error {
+	lightingCueDb, err := lightingCue.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.lightingCueMap.Update(lightingCueDb)
+	return err
+}
+
+func (database *Database) DeleteLightingCue(lightingCue *LightingCue) error {
+	lightingCueDb, err := lightingCue.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.lightingCueMap.Delete(lightingCueDb)
+	return err
+}
+
+func (database *Database) TruncateLightingCues() error {
+	return database.lightingCueMap.TruncateTables()
+}
+
+func (database *Database) GetAllLightingCues() ([]LightingCue, error) {
+	var lightingCueDbs []LightingCueDb
+	err := database.lightingCueMap.Select(&lightingCueDbs, "SELECT * FROM lighting_cues ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	lightingCues := make([]LightingCue, len(lightingCueDbs))
+	for i, lightingCueDb := range lightingCueDbs {
+		lightingCue, err := lightingCueDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		lightingCues[i] = *lightingCue
+	}
+	return lightingCues, nil
+}
+
+// Returns the fireable cue that this record defines.
+func (lightingCue *LightingCue) Cue() *lighting.Cue {
+	return &lighting.Cue{Name: lightingCue.Name, FadeMs: lightingCue.FadeMs, Looks: lightingCue.Looks}
+}
+
+// Converts the nested struct LightingCue to the flattened LightingCueDb for saving to the database.
+func (lightingCue *LightingCue) Serialize() (*LightingCueDb, error) {
+	lightingCueDb := LightingCueDb{Id: lightingCue.Id, Name: lightingCue.Name, Trigger: lightingCue.Trigger,
+		FadeMs: lightingCue.FadeMs}
+	if err := serializeHelper(&lightingCueDb.LooksJson, lightingCue.Looks); err != nil {
+		return nil, err
+	}
+	return &lightingCueDb, nil
+}
+
+// Converts the flattened LightingCueDb to the nested struct LightingCue.
+func (lightingCueDb *LightingCueDb) Deserialize() (*LightingCue, error) {
+	lightingCue := LightingCue{Id: lightingCueDb.Id, Name: lightingCueDb.Name, Trigger: lightingCueDb.Trigger,
+		FadeMs: lightingCueDb.FadeMs}
+	if err := json.Unmarshal([]byte(lightingCueDb.LooksJson), &lightingCue.Looks); err != nil {
+		return nil, err
+	}
+	return &lightingCue, nil
+}
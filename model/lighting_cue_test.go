@@ -0,0 +1,58 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentLightingCue(t *testing.T) {
+	db := setupTestDb(t)
+
+	lightingCue, err := db.GetLightingCueById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, lightingCue)
+}
+
+func TestLightingCueCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	dimmer, pan := 80.0, 50.0
+	lightingCue := LightingCue{Name: "Final Score", Trigger: "finalScore", FadeMs: 500, Looks: []lighting.Look{
+		{Target: "Wash", Color: lighting.WinnerColor, Dimmer: &dimmer}, {Target: "Mover", Pan: &pan}}}
+	assert.Nil(t, db.CreateLightingCue(&lightingCue))
+	lightingCue2, err := db.GetLightingCueById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, lightingCue, *lightingCue2)
+	assert.Nil(t, lightingCue2.Looks[1].Dimmer)
+	assert.Equal(t, &lighting.Cue{Name: "Final Score", FadeMs: 500, Looks: lightingCue.Looks}, lightingCue2.Cue())
+
+	// Check that names are unique.
+	assert.NotNil(t, db.CreateLightingCue(&LightingCue{Name: "Final Score"}))
+
+	lightingCue.Trigger = ""
+	db.SaveLightingCue(&lightingCue)
+	lightingCues, err := db.GetAllLightingCues()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(lightingCues)) {
+		assert.Equal(t, lightingCue, lightingCues[0])
+	}
+
+	db.DeleteLightingCue(&lightingCue)
+	lightingCue2, err = db.GetLightingCueById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, lightingCue2)
+}
+
+func TestTruncateLightingCues(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateLightingCue(&LightingCue{Name: "Walk-in"})
+	db.TruncateLightingCues()
+	lightingCues, err := db.GetAllLightingCues()
+	assert.Nil(t, err)
+	assert.Empty(t, lightingCues)
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a venue lighting fixture controlled over DMX.
+
+package model
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/lighting"
+)
+
+type LightingFixture struct {
+	Id       int
+	Name     string
+	Group    string
+	Profile  string
+	Channels []string
+	Universe int
+	Address  int
+}
+
+type LightingFixtureDb struct {
+	Id           int
+	Name         string
+	GroupName    string
+	Profile      string
+	ChannelsJson string
+	Universe     int
+	Address      int
+}
+
+func (database *Database) CreateLightingFixture(lightingFixture *LightingFixture) error {
+	lightingFixtureDb, err := lightingFixture.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = database.lightingFixtureMap.Insert(lightingFixtureDb); err != nil {
+		return err
+	}
+	lightingFixture.Id = lightingFixtureDb.Id
+	return nil
+}
+
+func (database *Database) GetLightingFixtureById(id int) (*LightingFixture, error) {
+	lightingFixtureDb := new(LightingFixtureDb)
+	err := database.lightingFixtureMap.Get(lightingFixtureDb, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lightingFixtureDb.Deserialize()
+}
+
+func (database *Database) SaveLightingFixture(lightingFixture *LightingFixture) error {
+	lightingFixtureDb, err := lightingFixture.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.lightingFixtureMap.Update(lightingFixtureDb)
+	return err
+}
+
+func (database *Database) DeleteLightingFixture(lightingFixture *LightingFixture) error {
+	lightingFixtureDb, err := lightingFixture.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = database.lightingFixtureMap.Delete(lightingFixtureDb)
+	return err
+}
+
+func (database *Database) TruncateLightingFixtures() error {
+	return database.lightingFixtureMap.TruncateTables()
+}
+
+func (database *Database) GetAllLightingFixtures() ([]LightingFixture, error) {
+	var lightingFixtureDbs []LightingFixtureDb
+	err := database.lightingFixtureMap.Select(&lightingFixtureDbs,
+		"SELECT * FROM lighting_fixtures ORDER BY universe, address")
+	if err != nil {
+		return nil, err
+	}
+	lightingFixtures := make([]LightingFixture, len(lightingFixtureDbs))
+	for i, lightingFixtureDb := range lightingFixtureDbs {
+		lightingFixture, err := lightingFixtureDb.Deserialize()
+		if err != nil {
+			return nil, err
+		}
+		lightingFixtures[i] = *lightingFixture
+	}
+	return lightingFixtures, nil
+}
+
+// Returns the controllable fixture that this record defines.
+func (lightingFixture *LightingFixture) Fixture() lighting.Fixture {
+	return lighting.Fixture{Name: lightingFixture.Name, Group: lightingFixture.Group, Profile: lightingFixture.Profile,
+		Channels: lightingFixture.Channels, Universe: lightingFixture.Universe, Address: lightingFixture.Address}
+}
+
+// Converts the nested struct LightingFixture to the flattened LightingFixtureDb for saving to the database.
+func (lightingFixture *LightingFixture) Serialize() (*LightingFixtureDb, error) {
+	lightingFixtureDb := LightingFixtureDb{Id: lightingFixture.Id, Name: lightingFixture.Name,
+		GroupName: lightingFixture.Group, Profile: lightingFixture.Profile, Universe: lightingFixture.Universe,
+		Address: lightingFixture.Address}
+	if err := serializeHelper(&lightingFixtureDb.ChannelsJson, lightingFixture.Channels); err != nil {
+		return nil, err
+	}
+	return &lightingFixtureDb, nil
+}
+
+// Converts the flattened LightingFixtureDb to the nested struct LightingFixture.
+func (lightingFixtureDb *LightingFixtureDb) Deserialize() (*LightingFixture, error) {
+	lightingFixture := LightingFixture{Id: lightingFixtureDb.Id, Name: lightingFixtureDb.Name,
+		Group: lightingFixtureDb.GroupName, Profile: lightingFixtureDb.Profile, Universe: lightingFixtureDb.Universe,
+		Address: lightingFixtureDb.Address}
+	if err := json.Unmarshal([]byte(lightingFixtureDb.ChannelsJson), &lightingFixture.Channels); err != nil {
+		return nil, err
+	}
+	return &lightingFixture, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentLightingFixture(t *testing.T) {
+	db := setupTestDb(t)
+
+	lightingFixture, err := db.GetLightingFixtureById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, lightingFixture)
+}
+
+func TestLightingFixtureCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	lightingFixture := LightingFixture{Name: "Stage Left", Group: "Wash", Profile: "RGBW Par", Universe: 2,
+		Address: 17}
+	assert.Nil(t, db.CreateLightingFixture(&lightingFixture))
+	lightingFixture2, err := db.GetLightingFixtureById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, lightingFixture, *lightingFixture2)
+	assert.Equal(t, lighting.Fixture{Name: "Stage Left", Group: "Wash", Profile: "RGBW Par", Universe: 2, Address: 17},
+		lightingFixture2.Fixture())
+
+	// Check that names are unique.
+	assert.NotNil(t, db.CreateLightingFixture(&LightingFixture{Name: "Stage Left"}))
+
+	lightingFixture.Profile = lighting.CustomProfile
+	lightingFixture.Channels = []string{lighting.DimmerChannel, lighting.AmberChannel}
+	db.SaveLightingFixture(&lightingFixture)
+	db.CreateLightingFixture(&LightingFixture{Name: "Mover", Profile: "Moving Head", Universe: 1, Address: 100})
+	lightingFixtures, err := db.GetAllLightingFixtures()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(lightingFixtures)) {
+		assert.Equal(t, "Mover", lightingFixtures[0].Name)
+		assert.Equal(t, lightingFixture, lightingFixtures[1])
+	}
+
+	db.DeleteLightingFixture(&lightingFixture)
+	lightingFixture2, err = db.GetLightingFixtureById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, lightingFixture2)
+}
+
+func TestTruncateLightingFixtures(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateLightingFixture(&LightingFixture{Name: "Stage Left"})
+	db.TruncateLightingFixtures()
+	lightingFixtures, err := db.GetAllLightingFixtures()
+	assert.Nil(t, err)
+	assert.Empty(t, lightingFixtures)
+}
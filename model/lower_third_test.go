@@ -19,7 +19,7 @@ func TestGetNonexistentLowerThird(t *testing.T) {
 func TestLowerThirdCrud(t *testing.T) {
 	db := setupTestDb(t)
 
-	lowerThird := LowerThird{0, "Top Text", "Bottom Text", 0}
+	lowerThird := LowerThird{0, "Top Text", "Bottom Text", 0, 0}
 	db.CreateLowerThird(&lowerThird)
 	lowerThird2, err := db.GetLowerThirdById(1)
 	assert.Nil(t, err)
@@ -40,7 +40,7 @@ func TestLowerThirdCrud(t *testing.T) {
 func TestTruncateLowerThirds(t *testing.T) {
 	db := setupTestDb(t)
 
-	lowerThird := LowerThird{0, "Top Text", "Bottom Text", 0}
+	lowerThird := LowerThird{0, "Top Text", "Bottom Text", 0, 0}
 	db.CreateLowerThird(&lowerThird)
 	db.TruncateLowerThirds()
 	lowerThird2, err := db.GetLowerThirdById(1)
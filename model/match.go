@@ -12,30 +12,31 @@ import (
 )
 
 type Match struct {
-	Id               int
-	Type             string
-	DisplayName      string
-	Time             time.Time
-	ElimRound        int
-	ElimGroup        int
-	ElimInstance     int
-	Red1             int
-	Red1IsSurrogate  bool
-	Red2             int
-	Red2IsSurrogate  bool
-	Red3             int
-	Red3IsSurrogate  bool
-	Blue1            int
-	Blue1IsSurrogate bool
-	Blue2            int
-	Blue2IsSurrogate bool
-	Blue3            int
-	Blue3IsSurrogate bool
-	Status           string
-	StartedAt        time.Time
-	ScoreCommittedAt time.Time
-	Winner           string
-	GameSpecificData string
+	Id                int
+	Type              string
+	DisplayName       string
+	Time              time.Time
+	ElimRound         int
+	ElimGroup         int
+	ElimInstance      int
+	Red1              int
+	Red1IsSurrogate   bool
+	Red2              int
+	Red2IsSurrogate   bool
+	Red3              int
+	Red3IsSurrogate   bool
+	Blue1             int
+	Blue1IsSurrogate  bool
+	Blue2             int
+	Blue2IsSurrogate  bool
+	Blue3             int
+	Blue3IsSurrogate  bool
+	Status            string
+	StartedAt         time.Time
+	ScoreCommittedAt  time.Time
+	Winner            string
+	GameSpecificData  string
+	TiebreakCriterion string
 }
 
 var ElimRoundNames = map[int]string{1: "F", 2: "SF", 4: "QF", 8: "EF"}
@@ -90,6 +90,11 @@ func (database *Database) DeleteMatchResult(matchResult *MatchResult) error {
 	return err
 }
 
+func (database *Database) DeleteMatchResultsForMatch(matchId int) error {
+	_, err := database.matchResultMap.Exec("DELETE FROM match_results WHERE matchid = ?", matchId)
+	return err
+}
+
 func (database *Database) TruncateMatchResults() error {
 	return database.matchResultMap.TruncateTables()
 }
@@ -104,7 +109,8 @@ func (matchResult *MatchResult) BlueScoreSummary() *game.ScoreSummary {
 	return matchResult.BlueScore.Summarize(matchResult.RedScore.Fouls)
 }
 
-// Checks the score for disqualifications or a tie and adjusts it appropriately.
+// Checks the score for disqualifications and adjusts it appropriately. Ties are resolved separately when the match is
+// committed, using the elimination tiebreakers configured for the event.
 func (matchResult *MatchResult) CorrectEliminationScore() {
 	matchResult.RedScore.ElimDq = false
 	for _, card := range matchResult.RedCards {
@@ -117,8 +123,6 @@ func (matchResult *MatchResult) CorrectEliminationScore() {
 			matchResult.BlueScore.ElimDq = true
 		}
 	}
-
-	// No elimination tiebreakers.
 }
 
 // Converts the nested struct MatchResult to the DB version that has JSON fields.
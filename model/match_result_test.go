@@ -48,6 +48,21 @@ func TestTruncateMatchResults(t *testing.T) {
 	assert.Nil(t, matchResult2)
 }
 
+func TestDeleteMatchResultsForMatch(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateMatchResult(BuildTestMatchResult(254, 1))
+	db.CreateMatchResult(BuildTestMatchResult(254, 2))
+	db.CreateMatchResult(BuildTestMatchResult(1114, 1))
+	assert.Nil(t, db.DeleteMatchResultsForMatch(254))
+	matchResult, err := db.GetMatchResultForMatch(254)
+	assert.Nil(t, err)
+	assert.Nil(t, matchResult)
+	matchResult, err = db.GetMatchResultForMatch(1114)
+	assert.Nil(t, err)
+	assert.NotNil(t, matchResult)
+}
+
 func TestGetMatchResultForMatch(t *testing.T) {
 	db := setupTestDb(t)
 
@@ -0,0 +1,61 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Datastore methods for tracking changes to the event data, used to keep a standby instance in sync.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Returns an opaque identifier for the current state of the event data. It changes whenever a row in any of the tables
+// carried by an event bundle is written, and also whenever the database is reopened (e.g. after being restored from a
+// backup), so that a standby instance can cheaply tell whether it needs to resync.
+func (database *Database) GetRevision() (string, error) {
+	var revision int64
+	err := database.db.QueryRow("SELECT revision FROM replication_revision WHERE id = 0").Scan(&revision)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", database.openedAt.UnixNano(), revision), nil
+}
+
+// Returns the event bundle tables that have been written since the given revision, in the order in which they are
+// restored. All of them are returned if the revision is blank or wasn't issued since the database was last opened.
+func (database *Database) GetChangedTables(sinceRevision string) ([]string, error) {
+	openedAt := fmt.Sprintf("%d-", database.openedAt.UnixNano())
+	if !strings.HasPrefix(sinceRevision, openedAt) {
+		return EventBundleTables, nil
+	}
+	revision, err := strconv.ParseInt(strings.TrimPrefix(sinceRevision, openedAt), 10, 64)
+	if err != nil {
+		return EventBundleTables, nil
+	}
+
+	rows, err := database.db.Query("SELECT tablename FROM replication_changes WHERE revision > ?", revision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	changedTables := make(map[string]bool)
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		changedTables[table] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, table := range EventBundleTables {
+		if changedTables[table] {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetRevision(t *testing.T) {
+	db := setupTestDb(t)
+
+	revision1, err := db.GetRevision()
+	assert.Nil(t, err)
+	revision2, _ := db.GetRevision()
+	assert.Equal(t, revision1, revision2)
+
+	db.CreateTeam(&Team{Id: 254})
+	revision3, _ := db.GetRevision()
+	assert.NotEqual(t, revision2, revision3)
+
+	// Check that writes to tables that aren't replicated don't change the revision.
+	db.CreateWebhookDelivery(&WebhookDelivery{WebhookId: 1})
+	revision4, _ := db.GetRevision()
+	assert.Equal(t, revision3, revision4)
+
+	db.Close()
+	db, _ = OpenDatabase(db.Path)
+	revision5, _ := db.GetRevision()
+	assert.NotEqual(t, revision4, revision5)
+}
+
+func TestGetChangedTables(t *testing.T) {
+	db := setupTestDb(t)
+
+	tables, err := db.GetChangedTables("")
+	assert.Nil(t, err)
+	assert.Equal(t, EventBundleTables, tables)
+	revision, _ := db.GetRevision()
+	tables, err = db.GetChangedTables(revision)
+	assert.Nil(t, err)
+	assert.Empty(t, tables)
+
+	db.CreateMatch(&Match{Type: "qualification"})
+	db.CreateTeam(&Team{Id: 254})
+	db.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishTeams})
+	tables, _ = db.GetChangedTables(revision)
+	assert.Equal(t, []string{"teams", "matches", "tba_outbox_items"}, tables)
+	revision, _ = db.GetRevision()
+	db.CreateTeam(&Team{Id: 1114})
+	tables, _ = db.GetChangedTables(revision)
+	assert.Equal(t, []string{"teams"}, tables)
+
+	// Check that a revision from before the database was reopened requires everything to be resynced.
+	db.Close()
+	db, _ = OpenDatabase(db.Path)
+	tables, _ = db.GetChangedTables(revision)
+	assert.Equal(t, EventBundleTables, tables)
+}
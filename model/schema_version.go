@@ -0,0 +1,83 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for inspecting and changing the schema version of a database file via its goose migrations.
+
+package model
+
+import (
+	"bitbucket.org/liamstask/goose/lib/goose"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// Returns the schema version of the most recent migration known to this version of the software.
+func GetLatestSchemaVersion() (int64, error) {
+	return goose.GetMostRecentDBVersion(filepath.Join(BaseDir, migrationsDir))
+}
+
+// Returns the schema versions of all the migrations known to this version of the software, in ascending order.
+func GetSchemaVersions() ([]int64, error) {
+	migrations, err := goose.CollectMigrations(filepath.Join(BaseDir, migrationsDir), 0, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, len(migrations))
+	for i, migration := range migrations {
+		versions[i] = migration.Version
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// Returns the schema version of the database file at the given path without running any migrations on it.
+func GetDatabaseSchemaVersion(filename string) (int64, error) {
+	return goose.GetDBVersion(newGooseConf(filename))
+}
+
+// Runs the migrations needed to move the database file at the given path up or down to the given schema version,
+// which must either be zero or the version of one of the known migrations. Migrating down discards the data in any
+// tables and columns that were added after the target version.
+func MigrateDatabase(filename string, version int64) error {
+	if version != 0 {
+		versions, err := GetSchemaVersions()
+		if err != nil {
+			return err
+		}
+		known := false
+		for _, knownVersion := range versions {
+			if knownVersion == version {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("Unknown schema version %d.", version)
+		}
+	}
+	currentVersion, err := GetDatabaseSchemaVersion(filename)
+	if err != nil {
+		return err
+	}
+	latestVersion, err := GetLatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if currentVersion > latestVersion {
+		return fmt.Errorf("Database schema version %d is newer than the latest version %d supported by this version "+
+			"of the software.", currentVersion, latestVersion)
+	}
+	return goose.RunMigrations(newGooseConf(filename), filepath.Join(BaseDir, migrationsDir), version)
+}
+
+// Returns the schema version of this database.
+func (database *Database) GetSchemaVersion() (int64, error) {
+	return GetDatabaseSchemaVersion(database.Path)
+}
+
+func newGooseConf(filename string) *goose.DBConf {
+	dbDriver := goose.DBDriver{"sqlite3", filename, "github.com/mattn/go-sqlite3", &goose.Sqlite3Dialect{}}
+	return &goose.DBConf{MigrationsDir: filepath.Join(BaseDir, migrationsDir), Env: "prod", Driver: dbDriver}
+}
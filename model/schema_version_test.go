@@ -0,0 +1,88 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetSchemaVersions(t *testing.T) {
+	setupTestDb(t)
+	versions, err := GetSchemaVersions()
+	assert.Nil(t, err)
+	latestVersion, err := GetLatestSchemaVersion()
+	assert.Nil(t, err)
+	if assert.NotEmpty(t, versions) {
+		assert.Equal(t, latestVersion, versions[len(versions)-1])
+		for i := 1; i < len(versions); i++ {
+			assert.True(t, versions[i] > versions[i-1])
+		}
+	}
+
+	// Every migration needs to be reversible so that a database can be moved back to an older build.
+	filenames, _ := filepath.Glob(filepath.Join(BaseDir, migrationsDir, "*.sql"))
+	assert.Equal(t, len(versions), len(filenames))
+	for _, filename := range filenames {
+		contents, _ := ioutil.ReadFile(filename)
+		assert.Contains(t, string(contents), "-- +goose Down", filename)
+		downSection := strings.SplitN(string(contents), "-- +goose Down", 2)
+		if len(downSection) == 2 {
+			assert.NotEqual(t, "", strings.TrimSpace(downSection[1]), filename)
+		}
+	}
+}
+
+func TestMigrateDatabase(t *testing.T) {
+	db := setupTestDb(t)
+	db.CreateTeam(&Team{Id: 254})
+	db.CreateMatch(&Match{Type: "qualification", DisplayName: "1"})
+	db.Close()
+	latestVersion, _ := GetLatestSchemaVersion()
+	versions, _ := GetSchemaVersions()
+	version, err := GetDatabaseSchemaVersion(db.Path)
+	assert.Nil(t, err)
+	assert.Equal(t, latestVersion, version)
+
+	err = MigrateDatabase(db.Path, 12345)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Unknown schema version 12345")
+	}
+
+	// Step down through every migration and back up again.
+	for i := len(versions) - 2; i >= 0; i-- {
+		if !assert.Nil(t, MigrateDatabase(db.Path, versions[i]), "%d", versions[i]) {
+			return
+		}
+		version, _ = GetDatabaseSchemaVersion(db.Path)
+		assert.Equal(t, versions[i], version)
+	}
+	assert.Nil(t, MigrateDatabase(db.Path, 0))
+	version, _ = GetDatabaseSchemaVersion(db.Path)
+	assert.Equal(t, int64(0), version)
+	assert.Nil(t, MigrateDatabase(db.Path, latestVersion))
+
+	db, err = OpenDatabase(db.Path)
+	assert.Nil(t, err)
+	defer db.Close()
+	version, _ = db.GetSchemaVersion()
+	assert.Equal(t, latestVersion, version)
+	eventSettings, err := db.GetEventSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, 20, eventSettings.BackupRetentionCount)
+	teams, _ := db.GetAllTeams()
+	assert.Empty(t, teams)
+
+	// Check that a database from a newer version of the software is left alone.
+	db.db.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, 1)", latestVersion+1)
+	err = MigrateDatabase(db.Path, versions[0])
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "newer than the latest version")
+	}
+	version, _ = db.GetSchemaVersion()
+	assert.Equal(t, latestVersion+1, version)
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for an uploaded set of sound files that replace the built-in match sounds.
+
+package model
+
+type SoundPack struct {
+	Id     int
+	Name   string
+	Season int
+}
+
+func (database *Database) CreateSoundPack(soundPack *SoundPack) error {
+	return database.soundPackMap.Insert(soundPack)
+}
+
+func (database *Database) GetSoundPackById(id int) (*SoundPack, error) {
+	soundPack := new(SoundPack)
+	err := database.soundPackMap.Get(soundPack, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		soundPack = nil
+		err = nil
+	}
+	return soundPack, err
+}
+
+func (database *Database) SaveSoundPack(soundPack *SoundPack) error {
+	_, err := database.soundPackMap.Update(soundPack)
+	return err
+}
+
+// Deletes the given sound pack along with all of its files.
+func (database *Database) DeleteSoundPack(soundPack *SoundPack) error {
+	if _, err := database.soundPackFileMap.Exec("DELETE FROM sound_pack_files WHERE soundpackid = ?",
+		soundPack.Id); err != nil {
+		return err
+	}
+	_, err := database.soundPackMap.Delete(soundPack)
+	return err
+}
+
+func (database *Database) TruncateSoundPacks() error {
+	return database.soundPackMap.TruncateTables()
+}
+
+// Returns all sound packs with the most recent seasons first.
+func (database *Database) GetAllSoundPacks() ([]SoundPack, error) {
+	var soundPacks []SoundPack
+	err := database.soundPackMap.Select(&soundPacks, "SELECT * FROM sound_packs ORDER BY season DESC, name")
+	return soundPacks, err
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for the audio file that a sound pack plays for a given sound cue.
+
+package model
+
+type SoundPackFile struct {
+	Id          int
+	SoundPackId int
+	Cue         string
+	Filename    string
+	Data        []byte
+}
+
+func (database *Database) CreateSoundPackFile(soundPackFile *SoundPackFile) error {
+	return database.soundPackFileMap.Insert(soundPackFile)
+}
+
+// Returns the file that the given sound pack plays for the given cue, or nil if the pack doesn't include one.
+func (database *Database) GetSoundPackFile(soundPackId int, cue string) (*SoundPackFile, error) {
+	var soundPackFiles []SoundPackFile
+	err := database.soundPackFileMap.Select(&soundPackFiles,
+		"SELECT * FROM sound_pack_files WHERE soundpackid = ? AND cue = ?", soundPackId, cue)
+	if err != nil || len(soundPackFiles) == 0 {
+		return nil, err
+	}
+	return &soundPackFiles[0], nil
+}
+
+// Returns all the files belonging to the given sound pack, ordered by cue.
+func (database *Database) GetSoundPackFiles(soundPackId int) ([]SoundPackFile, error) {
+	var soundPackFiles []SoundPackFile
+	err := database.soundPackFileMap.Select(&soundPackFiles,
+		"SELECT * FROM sound_pack_files WHERE soundpackid = ? ORDER BY cue", soundPackId)
+	return soundPackFiles, err
+}
+
+func (database *Database) TruncateSoundPackFiles() error {
+	return database.soundPackFileMap.TruncateTables()
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentSoundPackFile(t *testing.T) {
+	db := setupTestDb(t)
+
+	soundPackFile, err := db.GetSoundPackFile(1, "match-start")
+	assert.Nil(t, err)
+	assert.Nil(t, soundPackFile)
+}
+
+func TestSoundPackFileCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	soundPackFile := SoundPackFile{SoundPackId: 3, Cue: "match-start", Filename: "charge.wav", Data: []byte("RIFF")}
+	assert.Nil(t, db.CreateSoundPackFile(&soundPackFile))
+	soundPackFile2, err := db.GetSoundPackFile(3, "match-start")
+	assert.Nil(t, err)
+	assert.Equal(t, soundPackFile, *soundPackFile2)
+	soundPackFile2, err = db.GetSoundPackFile(4, "match-start")
+	assert.Nil(t, err)
+	assert.Nil(t, soundPackFile2)
+
+	// Check that each pack can only have one file per cue.
+	assert.NotNil(t, db.CreateSoundPackFile(&SoundPackFile{SoundPackId: 3, Cue: "match-start"}))
+
+	db.CreateSoundPackFile(&SoundPackFile{SoundPackId: 3, Cue: "match-end", Filename: "buzzer.mp3"})
+	soundPackFiles, err := db.GetSoundPackFiles(3)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(soundPackFiles)) {
+		assert.Equal(t, "match-end", soundPackFiles[0].Cue)
+		assert.Equal(t, soundPackFile, soundPackFiles[1])
+	}
+}
+
+func TestTruncateSoundPackFiles(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateSoundPackFile(&SoundPackFile{SoundPackId: 3, Cue: "match-start"})
+	db.TruncateSoundPackFiles()
+	soundPackFiles, err := db.GetSoundPackFiles(3)
+	assert.Nil(t, err)
+	assert.Empty(t, soundPackFiles)
+}
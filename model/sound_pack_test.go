@@ -0,0 +1,63 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentSoundPack(t *testing.T) {
+	db := setupTestDb(t)
+
+	soundPack, err := db.GetSoundPackById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, soundPack)
+}
+
+func TestSoundPackCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	soundPack := SoundPack{Name: "Power Up", Season: 2018}
+	assert.Nil(t, db.CreateSoundPack(&soundPack))
+	soundPack2, err := db.GetSoundPackById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, soundPack, *soundPack2)
+
+	soundPack.Name = "Power Up Remix"
+	assert.Nil(t, db.SaveSoundPack(&soundPack))
+	db.CreateSoundPack(&SoundPack{Name: "Steamworks", Season: 2017})
+	db.CreateSoundPack(&SoundPack{Name: "Deep Space", Season: 2019})
+	soundPacks, err := db.GetAllSoundPacks()
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(soundPacks)) {
+		assert.Equal(t, "Deep Space", soundPacks[0].Name)
+		assert.Equal(t, soundPack, soundPacks[1])
+		assert.Equal(t, "Steamworks", soundPacks[2].Name)
+	}
+
+	// Check that deleting a pack also deletes its files.
+	db.CreateSoundPackFile(&SoundPackFile{SoundPackId: soundPack.Id, Cue: "match-start", Filename: "start.wav"})
+	db.CreateSoundPackFile(&SoundPackFile{SoundPackId: 2, Cue: "match-start", Filename: "start.wav"})
+	assert.Nil(t, db.DeleteSoundPack(&soundPack))
+	soundPack2, err = db.GetSoundPackById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, soundPack2)
+	soundPackFiles, err := db.GetSoundPackFiles(soundPack.Id)
+	assert.Nil(t, err)
+	assert.Empty(t, soundPackFiles)
+	soundPackFiles, err = db.GetSoundPackFiles(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(soundPackFiles))
+}
+
+func TestTruncateSoundPacks(t *testing.T) {
+	db := setupTestDb(t)
+
+	db.CreateSoundPack(&SoundPack{Name: "Power Up", Season: 2018})
+	db.TruncateSoundPacks()
+	soundPacks, err := db.GetAllSoundPacks()
+	assert.Nil(t, err)
+	assert.Empty(t, soundPacks)
+}
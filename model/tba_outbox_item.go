@@ -0,0 +1,78 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a pending publish of event data to The Blue Alliance.
+
+package model
+
+import "time"
+
+const (
+	TbaPublishAlliances  = "alliances"
+	TbaPublishAwards     = "awards"
+	TbaPublishMatches    = "matches"
+	TbaPublishRankings   = "rankings"
+	TbaPublishTeams      = "teams"
+	TbaRepublishSchedule = "republish_schedule" // Deletes all published matches before publishing them again.
+)
+
+const (
+	TbaOutboxPending = "pending"
+	TbaOutboxFailed  = "failed"
+)
+
+type TbaOutboxItem struct {
+	Id            int
+	Action        string
+	Status        string
+	Attempts      int
+	LastError     string
+	QueuedAt      time.Time // The time of the most recent request for this publish.
+	NextAttemptAt time.Time
+}
+
+func (database *Database) CreateTbaOutboxItem(item *TbaOutboxItem) error {
+	return database.tbaOutboxItemMap.Insert(item)
+}
+
+func (database *Database) GetTbaOutboxItemById(id int) (*TbaOutboxItem, error) {
+	item := new(TbaOutboxItem)
+	err := database.tbaOutboxItemMap.Get(item, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		item = nil
+		err = nil
+	}
+	return item, err
+}
+
+func (database *Database) GetTbaOutboxItemByAction(action string) (*TbaOutboxItem, error) {
+	var items []TbaOutboxItem
+	err := database.tbaOutboxItemMap.Select(&items, "SELECT * FROM tba_outbox_items WHERE action = ?", action)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return &items[0], nil
+}
+
+func (database *Database) SaveTbaOutboxItem(item *TbaOutboxItem) error {
+	_, err := database.tbaOutboxItemMap.Update(item)
+	return err
+}
+
+func (database *Database) DeleteTbaOutboxItem(item *TbaOutboxItem) error {
+	_, err := database.tbaOutboxItemMap.Delete(item)
+	return err
+}
+
+func (database *Database) TruncateTbaOutboxItems() error {
+	return database.tbaOutboxItemMap.TruncateTables()
+}
+
+func (database *Database) GetAllTbaOutboxItems() ([]TbaOutboxItem, error) {
+	var items []TbaOutboxItem
+	err := database.tbaOutboxItemMap.Select(&items, "SELECT * FROM tba_outbox_items ORDER BY id")
+	return items, err
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetNonexistentTbaOutboxItem(t *testing.T) {
+	db := setupTestDb(t)
+
+	item, err := db.GetTbaOutboxItemById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, item)
+	item, err = db.GetTbaOutboxItemByAction(TbaPublishMatches)
+	assert.Nil(t, err)
+	assert.Nil(t, item)
+}
+
+func TestTbaOutboxItemCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	item := TbaOutboxItem{Action: TbaPublishMatches, Status: TbaOutboxPending, QueuedAt: time.Unix(1000, 0).UTC(),
+		NextAttemptAt: time.Unix(1000, 0).UTC()}
+	assert.Nil(t, db.CreateTbaOutboxItem(&item))
+	item2, err := db.GetTbaOutboxItemById(item.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, item, *item2)
+	item2, err = db.GetTbaOutboxItemByAction(TbaPublishMatches)
+	assert.Nil(t, err)
+	assert.Equal(t, item, *item2)
+
+	// Check that only one item can exist for a given action.
+	assert.NotNil(t, db.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishMatches}))
+
+	item.Status = TbaOutboxFailed
+	item.Attempts = 3
+	item.LastError = "Got status code 500 from TBA"
+	db.SaveTbaOutboxItem(&item)
+	item2, err = db.GetTbaOutboxItemById(item.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, item, *item2)
+
+	db.DeleteTbaOutboxItem(&item)
+	item2, err = db.GetTbaOutboxItemById(item.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, item2)
+}
+
+func TestGetAllTbaOutboxItems(t *testing.T) {
+	db := setupTestDb(t)
+
+	items, err := db.GetAllTbaOutboxItems()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+
+	db.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishRankings})
+	db.CreateTbaOutboxItem(&TbaOutboxItem{Action: TbaPublishMatches})
+	items, err = db.GetAllTbaOutboxItems()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(items)) {
+		assert.Equal(t, TbaPublishRankings, items[0].Action)
+		assert.Equal(t, TbaPublishMatches, items[1].Action)
+	}
+
+	db.TruncateTbaOutboxItems()
+	items, err = db.GetAllTbaOutboxItems()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,36 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for a record of the last version of a match published to The Blue Alliance.
+
+package model
+
+type TbaPublishedMatch struct {
+	Id       int
+	MatchKey string // The TBA key of the match without the event prefix, e.g. "qm12" or "sf1m2".
+	Hash     string // A hash of the match payload last sent to TBA, used to detect changes.
+}
+
+func (database *Database) CreateTbaPublishedMatch(publishedMatch *TbaPublishedMatch) error {
+	return database.tbaPublishedMatchMap.Insert(publishedMatch)
+}
+
+func (database *Database) SaveTbaPublishedMatch(publishedMatch *TbaPublishedMatch) error {
+	_, err := database.tbaPublishedMatchMap.Update(publishedMatch)
+	return err
+}
+
+func (database *Database) DeleteTbaPublishedMatch(publishedMatch *TbaPublishedMatch) error {
+	_, err := database.tbaPublishedMatchMap.Delete(publishedMatch)
+	return err
+}
+
+func (database *Database) TruncateTbaPublishedMatches() error {
+	return database.tbaPublishedMatchMap.TruncateTables()
+}
+
+func (database *Database) GetAllTbaPublishedMatches() ([]TbaPublishedMatch, error) {
+	var publishedMatches []TbaPublishedMatch
+	err := database.tbaPublishedMatchMap.Select(&publishedMatches, "SELECT * FROM tba_published_matches ORDER BY id")
+	return publishedMatches, err
+}
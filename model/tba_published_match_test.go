@@ -0,0 +1,45 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTbaPublishedMatchCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	publishedMatches, err := db.GetAllTbaPublishedMatches()
+	assert.Nil(t, err)
+	assert.Empty(t, publishedMatches)
+
+	publishedMatch := TbaPublishedMatch{MatchKey: "qm12", Hash: "abc"}
+	assert.Nil(t, db.CreateTbaPublishedMatch(&publishedMatch))
+	db.CreateTbaPublishedMatch(&TbaPublishedMatch{MatchKey: "sf1m2", Hash: "def"})
+	publishedMatches, err = db.GetAllTbaPublishedMatches()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(publishedMatches)) {
+		assert.Equal(t, publishedMatch, publishedMatches[0])
+		assert.Equal(t, "sf1m2", publishedMatches[1].MatchKey)
+	}
+
+	// Check that only one record can exist for a given match.
+	assert.NotNil(t, db.CreateTbaPublishedMatch(&TbaPublishedMatch{MatchKey: "qm12"}))
+
+	publishedMatch.Hash = "ghi"
+	assert.Nil(t, db.SaveTbaPublishedMatch(&publishedMatch))
+	publishedMatches, _ = db.GetAllTbaPublishedMatches()
+	assert.Equal(t, "ghi", publishedMatches[0].Hash)
+
+	assert.Nil(t, db.DeleteTbaPublishedMatch(&publishedMatch))
+	publishedMatches, _ = db.GetAllTbaPublishedMatches()
+	if assert.Equal(t, 1, len(publishedMatches)) {
+		assert.Equal(t, "sf1m2", publishedMatches[0].MatchKey)
+	}
+
+	db.TruncateTbaPublishedMatches()
+	publishedMatches, _ = db.GetAllTbaPublishedMatches()
+	assert.Empty(t, publishedMatches)
+}
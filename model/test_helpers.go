@@ -33,10 +33,10 @@ func BuildTestMatchResult(matchId int, playNumber int) *MatchResult {
 }
 
 func BuildTestAlliances(database *Database) {
-	database.CreateAllianceTeam(&AllianceTeam{0, 2, 0, 1718})
-	database.CreateAllianceTeam(&AllianceTeam{0, 1, 3, 74})
-	database.CreateAllianceTeam(&AllianceTeam{0, 1, 1, 469})
-	database.CreateAllianceTeam(&AllianceTeam{0, 1, 0, 254})
-	database.CreateAllianceTeam(&AllianceTeam{0, 1, 2, 2848})
-	database.CreateAllianceTeam(&AllianceTeam{0, 2, 1, 2451})
+	database.CreateAllianceTeam(&AllianceTeam{0, 2, 0, 1718, 0, 0})
+	database.CreateAllianceTeam(&AllianceTeam{0, 1, 3, 74, 0, 0})
+	database.CreateAllianceTeam(&AllianceTeam{0, 1, 1, 469, 0, 0})
+	database.CreateAllianceTeam(&AllianceTeam{0, 1, 0, 254, 0, 0})
+	database.CreateAllianceTeam(&AllianceTeam{0, 1, 2, 2848, 0, 0})
+	database.CreateAllianceTeam(&AllianceTeam{0, 2, 1, 2451, 0, 0})
 }
@@ -0,0 +1,79 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for an external URL that is notified of event lifecycle changes.
+
+package model
+
+import "strings"
+
+const (
+	WebhookMatchLoaded                = "match_loaded"
+	WebhookMatchStarted               = "match_started"
+	WebhookMatchEnded                 = "match_ended"
+	WebhookMatchCommitted             = "match_committed"
+	WebhookTimeoutStarted             = "timeout_started"
+	WebhookRankingsUpdated            = "rankings_updated"
+	WebhookAllianceSelectionFinalized = "alliance_selection_finalized"
+	WebhookElimSeriesWon              = "elim_series_won"
+)
+
+// All the events that a webhook can subscribe to, in the order that they are listed on the configuration page.
+var WebhookEvents = []string{WebhookMatchLoaded, WebhookMatchStarted, WebhookMatchEnded, WebhookMatchCommitted,
+	WebhookTimeoutStarted, WebhookRankingsUpdated, WebhookAllianceSelectionFinalized, WebhookElimSeriesWon}
+
+type Webhook struct {
+	Id      int
+	Name    string
+	Url     string
+	Secret  string // Used to sign the payloads so that the receiver can verify that they came from this server.
+	Events  string // Comma-separated list of the events that the webhook is subscribed to.
+	Enabled bool
+}
+
+func (database *Database) CreateWebhook(webhook *Webhook) error {
+	return database.webhookMap.Insert(webhook)
+}
+
+func (database *Database) GetWebhookById(id int) (*Webhook, error) {
+	webhook := new(Webhook)
+	err := database.webhookMap.Get(webhook, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		webhook = nil
+		err = nil
+	}
+	return webhook, err
+}
+
+func (database *Database) SaveWebhook(webhook *Webhook) error {
+	_, err := database.webhookMap.Update(webhook)
+	return err
+}
+
+func (database *Database) DeleteWebhook(webhook *Webhook) error {
+	_, err := database.webhookMap.Delete(webhook)
+	return err
+}
+
+func (database *Database) TruncateWebhooks() error {
+	return database.webhookMap.TruncateTables()
+}
+
+func (database *Database) GetAllWebhooks() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := database.webhookMap.Select(&webhooks, "SELECT * FROM webhooks ORDER BY id")
+	return webhooks, err
+}
+
+// Returns true if the webhook is enabled and subscribed to the given event.
+func (webhook *Webhook) IsSubscribedTo(event string) bool {
+	if !webhook.Enabled {
+		return false
+	}
+	for _, subscribedEvent := range strings.Split(webhook.Events, ",") {
+		if strings.TrimSpace(subscribedEvent) == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Model and datastore CRUD methods for an attempted delivery of an event to a webhook, which doubles as the log of past
+// deliveries.
+
+package model
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type WebhookDelivery struct {
+	Id            int
+	WebhookId     int
+	Event         string
+	Payload       string
+	Status        string
+	Attempts      int
+	ResponseCode  int // The HTTP status code of the most recent attempt, or zero if no response was received.
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
+
+func (database *Database) CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	return database.webhookDeliveryMap.Insert(delivery)
+}
+
+func (database *Database) GetWebhookDeliveryById(id int) (*WebhookDelivery, error) {
+	delivery := new(WebhookDelivery)
+	err := database.webhookDeliveryMap.Get(delivery, id)
+	if err != nil && err.Error() == "sql: no rows in result set" {
+		delivery = nil
+		err = nil
+	}
+	return delivery, err
+}
+
+func (database *Database) SaveWebhookDelivery(delivery *WebhookDelivery) error {
+	_, err := database.webhookDeliveryMap.Update(delivery)
+	return err
+}
+
+func (database *Database) TruncateWebhookDeliveries() error {
+	return database.webhookDeliveryMap.TruncateTables()
+}
+
+// Returns the deliveries that are still waiting to be sent, oldest first.
+func (database *Database) GetPendingWebhookDeliveries() ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := database.webhookDeliveryMap.Select(&deliveries,
+		"SELECT * FROM webhook_deliveries WHERE status = ? ORDER BY id", WebhookDeliveryPending)
+	return deliveries, err
+}
+
+// Returns the given number of most recent deliveries, newest first.
+func (database *Database) GetRecentWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := database.webhookDeliveryMap.Select(&deliveries, "SELECT * FROM webhook_deliveries ORDER BY id DESC LIMIT ?",
+		limit)
+	return deliveries, err
+}
+
+// Deletes all but the given number of most recent finished deliveries, to keep the log from growing without bound.
+func (database *Database) PruneWebhookDeliveries(keepCount int) error {
+	_, err := database.webhookDeliveryMap.Exec("DELETE FROM webhook_deliveries WHERE status != ? AND id NOT IN "+
+		"(SELECT id FROM webhook_deliveries ORDER BY id DESC LIMIT ?)", WebhookDeliveryPending, keepCount)
+	return err
+}
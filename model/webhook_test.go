@@ -0,0 +1,90 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetNonexistentWebhook(t *testing.T) {
+	db := setupTestDb(t)
+
+	webhook, err := db.GetWebhookById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, webhook)
+	delivery, err := db.GetWebhookDeliveryById(1114)
+	assert.Nil(t, err)
+	assert.Nil(t, delivery)
+}
+
+func TestWebhookCrud(t *testing.T) {
+	db := setupTestDb(t)
+
+	webhook := Webhook{Name: "Stream Overlay", Url: "http://localhost/hook", Secret: "shh",
+		Events: WebhookMatchLoaded + "," + WebhookMatchCommitted, Enabled: true}
+	assert.Nil(t, db.CreateWebhook(&webhook))
+	webhook2, err := db.GetWebhookById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, webhook, *webhook2)
+
+	webhook.Url = "https://example.com/hook"
+	db.SaveWebhook(&webhook)
+	webhook2, err = db.GetWebhookById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, webhook.Url, webhook2.Url)
+
+	db.DeleteWebhook(&webhook)
+	webhook2, err = db.GetWebhookById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, webhook2)
+}
+
+func TestWebhookIsSubscribedTo(t *testing.T) {
+	webhook := Webhook{Events: WebhookMatchLoaded + "," + WebhookMatchCommitted, Enabled: true}
+	assert.True(t, webhook.IsSubscribedTo(WebhookMatchLoaded))
+	assert.True(t, webhook.IsSubscribedTo(WebhookMatchCommitted))
+	assert.False(t, webhook.IsSubscribedTo(WebhookMatchStarted))
+
+	webhook.Enabled = false
+	assert.False(t, webhook.IsSubscribedTo(WebhookMatchLoaded))
+}
+
+func TestWebhookDeliveryQueries(t *testing.T) {
+	db := setupTestDb(t)
+
+	now := time.Unix(1546700000, 0)
+	for i, status := range []string{WebhookDeliveryDelivered, WebhookDeliveryPending, WebhookDeliveryFailed,
+		WebhookDeliveryDelivered, WebhookDeliveryPending} {
+		delivery := WebhookDelivery{WebhookId: 1, Event: WebhookMatchLoaded, Status: status,
+			CreatedAt: now.Add(time.Duration(i) * time.Second), NextAttemptAt: now}
+		assert.Nil(t, db.CreateWebhookDelivery(&delivery))
+	}
+
+	deliveries, err := db.GetPendingWebhookDeliveries()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(deliveries)) {
+		assert.Equal(t, 2, deliveries[0].Id)
+		assert.Equal(t, 5, deliveries[1].Id)
+	}
+	deliveries, err = db.GetRecentWebhookDeliveries(2)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(deliveries)) {
+		assert.Equal(t, 5, deliveries[0].Id)
+		assert.Equal(t, 4, deliveries[1].Id)
+	}
+
+	// Check that pruning keeps the pending deliveries no matter how old they are.
+	assert.Nil(t, db.PruneWebhookDeliveries(1))
+	deliveries, _ = db.GetRecentWebhookDeliveries(10)
+	if assert.Equal(t, 2, len(deliveries)) {
+		assert.Equal(t, 5, deliveries[0].Id)
+		assert.Equal(t, 2, deliveries[1].Id)
+	}
+
+	db.TruncateWebhookDeliveries()
+	deliveries, _ = db.GetRecentWebhookDeliveries(10)
+	assert.Empty(t, deliveries)
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Methods for retrieving official team info, awards and schedules from the FRC Events API.
+
+package partner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	frcEventsBaseUrl           = "https://frc-api.firstinspires.org"
+	frcEventsRequestTimeoutSec = 30
+	frcEventsAwardYears        = 3
+)
+
+type FrcEventsClient struct {
+	BaseUrl   string
+	username  string
+	authToken string
+	eventCode string
+	season    int
+}
+
+type frcEventsTeams struct {
+	Teams []frcEventsTeam `json:"teams"`
+}
+
+type frcEventsTeam struct {
+	TeamNumber int    `json:"teamNumber"`
+	NameFull   string `json:"nameFull"`
+	NameShort  string `json:"nameShort"`
+	City       string `json:"city"`
+	StateProv  string `json:"stateProv"`
+	Country    string `json:"country"`
+	RookieYear int    `json:"rookieYear"`
+	RobotName  string `json:"robotName"`
+}
+
+type frcEventsAwards struct {
+	Awards []frcEventsAward `json:"Awards"`
+}
+
+type frcEventsAward struct {
+	Name      string `json:"name"`
+	EventCode string `json:"eventCode"`
+}
+
+type frcEventsAvatars struct {
+	Teams []frcEventsAvatar `json:"teams"`
+}
+
+type frcEventsAvatar struct {
+	TeamNumber    int    `json:"teamNumber"`
+	EncodedAvatar string `json:"encodedAvatar"`
+}
+
+type frcEventsSchedule struct {
+	Schedule []frcEventsScheduledMatch `json:"Schedule"`
+}
+
+type frcEventsScheduledMatch struct {
+	MatchNumber int                     `json:"matchNumber"`
+	StartTime   string                  `json:"startTime"`
+	Teams       []frcEventsScheduleTeam `json:"teams"`
+}
+
+type frcEventsScheduleTeam struct {
+	TeamNumber int    `json:"teamNumber"`
+	Station    string `json:"station"`
+	Surrogate  bool   `json:"surrogate"`
+}
+
+func NewFrcEventsClient(username, authToken, eventCode string, season int) *FrcEventsClient {
+	return &FrcEventsClient{BaseUrl: frcEventsBaseUrl, username: username, authToken: authToken,
+		eventCode: eventCode, season: season}
+}
+
+func (client *FrcEventsClient) SourceName() string {
+	return "FRC Events"
+}
+
+// Returns the team's info as of the given season, or nil if the FRC Events API doesn't know the team.
+func (client *FrcEventsClient) GetTeamInfo(teamNumber, year int) (*TeamInfo, error) {
+	var teams frcEventsTeams
+	found, err := client.getJson(fmt.Sprintf("/v2.0/%d/teams?teamNumber=%d", year, teamNumber), &teams)
+	if err != nil || !found || len(teams.Teams) == 0 {
+		return nil, err
+	}
+	team := teams.Teams[0]
+	return &TeamInfo{team.TeamNumber, team.NameFull, team.NameShort, team.City, team.StateProv, team.Country,
+		team.RookieYear, team.RobotName}, nil
+}
+
+// Returns the team's awards from the last few seasons in chronological order. The FRC Events API doesn't give the
+// full event names, so the event codes are used in their place.
+func (client *FrcEventsClient) GetRecentAwards(teamNumber int) ([]Award, error) {
+	awards := []Award{}
+	for year := client.season - frcEventsAwardYears + 1; year <= client.season; year++ {
+		var frcAwards frcEventsAwards
+		found, err := client.getJson(fmt.Sprintf("/v2.0/%d/awards/%d", year, teamNumber), &frcAwards)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, frcAward := range frcAwards.Awards {
+			awards = append(awards, Award{frcAward.Name, frcAward.EventCode, year})
+		}
+	}
+	return awards, nil
+}
+
+// Downloads the team's avatar for the given season and stores it to disk as a PNG file.
+func (client *FrcEventsClient) DownloadTeamAvatar(teamNumber, year int) error {
+	var avatars frcEventsAvatars
+	found, err := client.getJson(fmt.Sprintf("/v2.0/%d/avatars?teamNumber=%d", year, teamNumber), &avatars)
+	if err != nil {
+		return err
+	}
+	if !found || len(avatars.Teams) == 0 || avatars.Teams[0].EncodedAvatar == "" {
+		return fmt.Errorf("No avatar found for team %d in year %d.", teamNumber, year)
+	}
+	avatarBytes, err := base64.StdEncoding.DecodeString(avatars.Teams[0].EncodedAvatar)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fmt.Sprintf("%s/%d.png", avatarsDir, teamNumber), avatarBytes, 0644)
+}
+
+// Returns the official schedule for the event. Only qualification matches are supported, since the playoff schedule
+// is generated locally from the alliance selection results.
+func (client *FrcEventsClient) GetEventSchedule(matchType string) ([]model.Match, error) {
+	if matchType != "qualification" {
+		return nil, fmt.Errorf("Importing the %s schedule is not supported.", matchType)
+	}
+	if client.eventCode == "" {
+		return nil, fmt.Errorf("No FRC Events event code is configured.")
+	}
+	var schedule frcEventsSchedule
+	found, err := client.getJson(fmt.Sprintf("/v2.0/%d/schedule/%s?tournamentLevel=qual", client.season,
+		client.eventCode), &schedule)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("Event %s was not found in the FRC Events API.", client.eventCode)
+	}
+	return schedule.toMatches(matchType), nil
+}
+
+// Parses a schedule file in the format returned by the FRC Events API into matches of the given type. Matches whose
+// start time can't be parsed are returned with a zero time.
+func ParseFrcEventsSchedule(data []byte, matchType string) ([]model.Match, error) {
+	var schedule frcEventsSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, err
+	}
+	return schedule.toMatches(matchType), nil
+}
+
+func (schedule *frcEventsSchedule) toMatches(matchType string) []model.Match {
+	matches := make([]model.Match, len(schedule.Schedule))
+	for i, scheduledMatch := range schedule.Schedule {
+		match := &matches[i]
+		match.Type = matchType
+		match.DisplayName = fmt.Sprintf("%d", scheduledMatch.MatchNumber)
+		// The FRC Events API gives times in the event's local time zone, which is assumed to be this one.
+		match.Time, _ = time.ParseInLocation("2006-01-02T15:04:05", scheduledMatch.StartTime, time.Local)
+		for _, team := range scheduledMatch.Teams {
+			switch team.Station {
+			case "Red1":
+				match.Red1, match.Red1IsSurrogate = team.TeamNumber, team.Surrogate
+			case "Red2":
+				match.Red2, match.Red2IsSurrogate = team.TeamNumber, team.Surrogate
+			case "Red3":
+				match.Red3, match.Red3IsSurrogate = team.TeamNumber, team.Surrogate
+			case "Blue1":
+				match.Blue1, match.Blue1IsSurrogate = team.TeamNumber, team.Surrogate
+			case "Blue2":
+				match.Blue2, match.Blue2IsSurrogate = team.TeamNumber, team.Surrogate
+			case "Blue3":
+				match.Blue3, match.Blue3IsSurrogate = team.TeamNumber, team.Surrogate
+			}
+		}
+	}
+	return matches
+}
+
+// Makes a GET request to the given FRC Events API path and unmarshals the JSON response into the given target.
+// Returns false if the requested data doesn't exist.
+func (client *FrcEventsClient) getJson(path string, target interface{}) (bool, error) {
+	httpClient := &http.Client{Timeout: frcEventsRequestTimeoutSec * time.Second}
+	request, err := http.NewRequest("GET", client.BaseUrl+path, nil)
+	if err != nil {
+		return false, err
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(client.username + ":" + client.authToken))
+	request.Header.Set("Authorization", "Basic "+credentials)
+	request.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == 404 {
+		return false, nil
+	}
+	if resp.StatusCode != 200 {
+		return false, fmt.Errorf("Got status code %d from FRC Events: %s", resp.StatusCode,
+			strings.TrimSpace(string(body)))
+	}
+	return true, json.Unmarshal(body, target)
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package partner
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFrcEventsGetTeamInfo(t *testing.T) {
+	frcEventsServer := setupMockFrcEventsServer(t)
+	defer frcEventsServer.Close()
+	client := NewFrcEventsClient("user", "token", "CASJ", 2018)
+	client.BaseUrl = frcEventsServer.URL
+
+	teamInfo, err := client.GetTeamInfo(7777, 2018)
+	assert.Nil(t, err)
+	if assert.NotNil(t, teamInfo) {
+		assert.Equal(t, TeamInfo{7777, "Rookie Sponsors & Rookie High School", "Rookie Robotics", "San Jose", "CA",
+			"USA", 2018, "Fresh"}, *teamInfo)
+	}
+
+	// Check that an unknown team returns nothing.
+	teamInfo, err = client.GetTeamInfo(9999, 2018)
+	assert.Nil(t, err)
+	assert.Nil(t, teamInfo)
+}
+
+func TestFrcEventsGetRecentAwards(t *testing.T) {
+	frcEventsServer := setupMockFrcEventsServer(t)
+	defer frcEventsServer.Close()
+	client := NewFrcEventsClient("user", "token", "CASJ", 2018)
+	client.BaseUrl = frcEventsServer.URL
+
+	awards, err := client.GetRecentAwards(7777)
+	assert.Nil(t, err)
+	assert.Equal(t, []Award{{"Rookie All Star Award", "CASJ", 2018}}, awards)
+}
+
+func TestFrcEventsDownloadTeamAvatar(t *testing.T) {
+	frcEventsServer := setupMockFrcEventsServer(t)
+	defer frcEventsServer.Close()
+	client := NewFrcEventsClient("user", "token", "CASJ", 2018)
+	client.BaseUrl = frcEventsServer.URL
+
+	err := client.DownloadTeamAvatar(9999, 2018)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "No avatar found for team 9999 in year 2018.", err.Error())
+	}
+}
+
+func TestFrcEventsGetEventSchedule(t *testing.T) {
+	frcEventsServer := setupMockFrcEventsServer(t)
+	defer frcEventsServer.Close()
+	client := NewFrcEventsClient("user", "token", "CASJ", 2018)
+	client.BaseUrl = frcEventsServer.URL
+
+	matches, err := client.GetEventSchedule("qualification")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, "qualification", matches[0].Type)
+		assert.Equal(t, "1", matches[0].DisplayName)
+		assert.Equal(t, time.Date(2018, 3, 2, 9, 0, 0, 0, time.Local), matches[0].Time)
+		assert.Equal(t, []int{254, 1114, 7777, 1, 2, 3}, []int{matches[0].Red1, matches[0].Red2, matches[0].Red3,
+			matches[0].Blue1, matches[0].Blue2, matches[0].Blue3})
+		assert.True(t, matches[1].Blue3IsSurrogate)
+		assert.False(t, matches[1].Blue2IsSurrogate)
+	}
+
+	_, err = client.GetEventSchedule("practice")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Importing the practice schedule is not supported.", err.Error())
+	}
+	client.eventCode = "NOPE"
+	_, err = client.GetEventSchedule("qualification")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Event NOPE was not found in the FRC Events API.", err.Error())
+	}
+}
+
+func TestFrcEventsErrors(t *testing.T) {
+	frcEventsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", 401)
+	}))
+	defer frcEventsServer.Close()
+	client := NewFrcEventsClient("user", "badtoken", "CASJ", 2018)
+	client.BaseUrl = frcEventsServer.URL
+
+	_, err := client.GetTeamInfo(254, 2018)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Got status code 401 from FRC Events: Unauthorized", err.Error())
+	}
+	_, err = client.GetRecentAwards(254)
+	assert.NotNil(t, err)
+	_, err = client.GetEventSchedule("qualification")
+	assert.NotNil(t, err)
+}
+
+func TestParseFrcEventsSchedule(t *testing.T) {
+	matches, err := ParseFrcEventsSchedule([]byte(`{"Schedule": [
+		{"matchNumber": 3, "startTime": "2018-03-01T15:30:00", "teams": [
+			{"teamNumber": 254, "station": "Blue2", "surrogate": true}]},
+		{"matchNumber": 4, "startTime": "bogus", "teams": []}]}`), "practice")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, "practice", matches[0].Type)
+		assert.Equal(t, "3", matches[0].DisplayName)
+		assert.Equal(t, 15, matches[0].Time.Hour())
+		assert.Equal(t, 254, matches[0].Blue2)
+		assert.True(t, matches[0].Blue2IsSurrogate)
+		assert.True(t, matches[1].Time.IsZero())
+	}
+
+	_, err = ParseFrcEventsSchedule([]byte("Match,Time"), "practice")
+	assert.NotNil(t, err)
+}
+
+// Sets up a mock FRC Events API server that serves data for a rookie team and a small event.
+func setupMockFrcEventsServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "token", password)
+
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/v2.0/2018/teams?teamNumber=7777":
+			w.Write([]byte(`{"teams": [{"teamNumber": 7777, "nameFull": "Rookie Sponsors & Rookie High School",
+				"nameShort": "Rookie Robotics", "city": "San Jose", "stateProv": "CA", "country": "USA",
+				"rookieYear": 2018, "robotName": "Fresh"}]}`))
+		case "/v2.0/2018/teams?teamNumber=9999":
+			w.Write([]byte(`{"teams": []}`))
+		case "/v2.0/2018/awards/7777?":
+			w.Write([]byte(`{"Awards": [{"name": "Rookie All Star Award", "eventCode": "CASJ"}]}`))
+		case "/v2.0/2018/avatars?teamNumber=9999":
+			w.Write([]byte(`{"teams": []}`))
+		case "/v2.0/2018/schedule/CASJ?tournamentLevel=qual":
+			w.Write([]byte(`{"Schedule": [
+				{"matchNumber": 1, "startTime": "2018-03-02T09:00:00", "teams": [
+					{"teamNumber": 254, "station": "Red1"}, {"teamNumber": 1114, "station": "Red2"},
+					{"teamNumber": 7777, "station": "Red3"}, {"teamNumber": 1, "station": "Blue1"},
+					{"teamNumber": 2, "station": "Blue2"}, {"teamNumber": 3, "station": "Blue3"}]},
+				{"matchNumber": 2, "startTime": "2018-03-02T09:07:00", "teams": [
+					{"teamNumber": 4, "station": "Red1"}, {"teamNumber": 5, "station": "Red2"},
+					{"teamNumber": 6, "station": "Red3"}, {"teamNumber": 7, "station": "Blue1"},
+					{"teamNumber": 8, "station": "Blue2"}, {"teamNumber": 254, "station": "Blue3", "surrogate": true}]}
+			]}`))
+		default:
+			http.Error(w, "Not found", 404)
+		}
+	}))
+}
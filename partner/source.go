@@ -0,0 +1,158 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Common interface for the partner services that provide official team info, awards and schedules, along with a
+// source that falls back between them.
+
+package partner
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"strings"
+)
+
+// Official data about an event or its teams, as provided by a partner service.
+type Source interface {
+	// Returns a human-readable name for the source for use in error messages.
+	SourceName() string
+
+	// Returns the info for the given team as of the given season, or nil if the team isn't known to the source.
+	GetTeamInfo(teamNumber, year int) (*TeamInfo, error)
+
+	// Returns the awards won by the given team, in chronological order.
+	GetRecentAwards(teamNumber int) ([]Award, error)
+
+	// Downloads the given team's avatar for the given season and stores it to disk.
+	DownloadTeamAvatar(teamNumber, year int) error
+
+	// Returns the official schedule for the event of the given match type.
+	GetEventSchedule(matchType string) ([]model.Match, error)
+}
+
+type TeamInfo struct {
+	TeamNumber int
+	Name       string
+	Nickname   string
+	City       string
+	StateProv  string
+	Country    string
+	RookieYear int
+	RobotName  string
+}
+
+type Award struct {
+	Name      string
+	EventName string
+	Year      int
+}
+
+// Source that tries each of the given sources in order, so that one can be used to fill in gaps in another.
+type FallbackSource struct {
+	sources []Source
+}
+
+func NewFallbackSource(sources ...Source) *FallbackSource {
+	return &FallbackSource{sources: sources}
+}
+
+func (source *FallbackSource) SourceName() string {
+	var names []string
+	for _, childSource := range source.sources {
+		names = append(names, childSource.SourceName())
+	}
+	return strings.Join(names, "/")
+}
+
+// Returns the team info from the first source that knows about the team, with any fields it leaves blank filled in
+// from the subsequent sources.
+func (source *FallbackSource) GetTeamInfo(teamNumber, year int) (*TeamInfo, error) {
+	var teamInfo *TeamInfo
+	var errs []string
+	for _, childSource := range source.sources {
+		childTeamInfo, err := childSource.GetTeamInfo(teamNumber, year)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", childSource.SourceName(), err.Error()))
+			continue
+		}
+		if childTeamInfo == nil {
+			continue
+		}
+		if teamInfo == nil {
+			teamInfo = childTeamInfo
+			continue
+		}
+		fillString(&teamInfo.Name, childTeamInfo.Name)
+		fillString(&teamInfo.Nickname, childTeamInfo.Nickname)
+		fillString(&teamInfo.City, childTeamInfo.City)
+		fillString(&teamInfo.StateProv, childTeamInfo.StateProv)
+		fillString(&teamInfo.Country, childTeamInfo.Country)
+		fillString(&teamInfo.RobotName, childTeamInfo.RobotName)
+		if teamInfo.RookieYear == 0 {
+			teamInfo.RookieYear = childTeamInfo.RookieYear
+		}
+	}
+	if teamInfo == nil && len(errs) == len(source.sources) {
+		return nil, source.combineErrors(errs)
+	}
+	return teamInfo, nil
+}
+
+// Returns the awards from the first source that can provide them.
+func (source *FallbackSource) GetRecentAwards(teamNumber int) ([]Award, error) {
+	var errs []string
+	for _, childSource := range source.sources {
+		awards, err := childSource.GetRecentAwards(teamNumber)
+		if err == nil {
+			return awards, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", childSource.SourceName(), err.Error()))
+	}
+	return nil, source.combineErrors(errs)
+}
+
+// Downloads the avatar from the first source that has one.
+func (source *FallbackSource) DownloadTeamAvatar(teamNumber, year int) error {
+	var errs []string
+	for _, childSource := range source.sources {
+		err := childSource.DownloadTeamAvatar(teamNumber, year)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", childSource.SourceName(), err.Error()))
+	}
+	return source.combineErrors(errs)
+}
+
+// Returns the schedule from the first source that has a non-empty one.
+func (source *FallbackSource) GetEventSchedule(matchType string) ([]model.Match, error) {
+	var errs []string
+	for _, childSource := range source.sources {
+		matches, err := childSource.GetEventSchedule(matchType)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", childSource.SourceName(), err.Error()))
+			continue
+		}
+		if len(matches) > 0 {
+			return matches, nil
+		}
+	}
+	if len(errs) > 0 || len(source.sources) == 0 {
+		return nil, source.combineErrors(errs)
+	}
+	return []model.Match{}, nil
+}
+
+func (source *FallbackSource) combineErrors(errs []string) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("No data sources are configured.")
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// Sets the given target to the given value if it is blank.
+func fillString(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package partner
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type fakeSource struct {
+	name     string
+	teamInfo *TeamInfo
+	awards   []Award
+	matches  []model.Match
+	err      error
+}
+
+func (source *fakeSource) SourceName() string {
+	return source.name
+}
+
+func (source *fakeSource) GetTeamInfo(teamNumber, year int) (*TeamInfo, error) {
+	return source.teamInfo, source.err
+}
+
+func (source *fakeSource) GetRecentAwards(teamNumber int) ([]Award, error) {
+	return source.awards, source.err
+}
+
+func (source *fakeSource) DownloadTeamAvatar(teamNumber, year int) error {
+	return source.err
+}
+
+func (source *fakeSource) GetEventSchedule(matchType string) ([]model.Match, error) {
+	return source.matches, source.err
+}
+
+func TestFallbackSourceGetTeamInfo(t *testing.T) {
+	incompleteSource := &fakeSource{name: "A", teamInfo: &TeamInfo{TeamNumber: 7777, Name: "Rookie HS"}}
+	completeSource := &fakeSource{name: "B", teamInfo: &TeamInfo{7777, "Other", "Rookie Robotics", "San Jose", "CA",
+		"USA", 2018, "Fresh"}}
+	failingSource := &fakeSource{name: "C", err: fmt.Errorf("oh noes")}
+	emptySource := &fakeSource{name: "D"}
+
+	// Check that blank fields are filled in from the later sources without overwriting the earlier ones.
+	teamInfo, err := NewFallbackSource(failingSource, incompleteSource, emptySource, completeSource).GetTeamInfo(7777,
+		2018)
+	assert.Nil(t, err)
+	assert.Equal(t, TeamInfo{7777, "Rookie HS", "Rookie Robotics", "San Jose", "CA", "USA", 2018, "Fresh"},
+		*teamInfo)
+
+	teamInfo, err = NewFallbackSource(emptySource, failingSource).GetTeamInfo(7777, 2018)
+	assert.Nil(t, err)
+	assert.Nil(t, teamInfo)
+
+	_, err = NewFallbackSource(failingSource, failingSource).GetTeamInfo(7777, 2018)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "C: oh noes; C: oh noes", err.Error())
+	}
+	_, err = NewFallbackSource().GetTeamInfo(7777, 2018)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "No data sources are configured.", err.Error())
+	}
+}
+
+func TestFallbackSourceOtherData(t *testing.T) {
+	failingSource := &fakeSource{name: "C", err: fmt.Errorf("oh noes")}
+	emptySource := &fakeSource{name: "D"}
+	source := &fakeSource{name: "E", awards: []Award{{"Winner", "Chezy Champs", 2018}},
+		matches: []model.Match{{DisplayName: "1"}}}
+	fallbackSource := NewFallbackSource(failingSource, emptySource, source)
+	assert.Equal(t, "C/D/E", fallbackSource.SourceName())
+
+	awards, err := fallbackSource.GetRecentAwards(254)
+	assert.Nil(t, err)
+	assert.Nil(t, awards)
+	assert.Nil(t, fallbackSource.DownloadTeamAvatar(254, 2018))
+	matches, err := fallbackSource.GetEventSchedule("qualification")
+	assert.Nil(t, err)
+	assert.Equal(t, source.matches, matches)
+
+	_, err = NewFallbackSource(failingSource).GetRecentAwards(254)
+	assert.NotNil(t, err)
+	assert.NotNil(t, NewFallbackSource(failingSource).DownloadTeamAvatar(254, 2018))
+	_, err = NewFallbackSource(failingSource, emptySource).GetEventSchedule("qualification")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "C: oh noes", err.Error())
+	}
+}
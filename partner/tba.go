@@ -15,13 +15,17 @@ import (
 	"github.com/Team254/cheesy-arena/model"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 )
 
 const (
-	tbaBaseUrl = "https://www.thebluealliance.com"
-	tbaAuthKey = "MAApv9MCuKY9MSFkXLuzTSYBCdosboxDq8Q3ujUE2Mn8PD3Nmv64uczu5Lvy0NQ3"
-	avatarsDir = "static/img/avatars"
+	tbaBaseUrl           = "https://www.thebluealliance.com"
+	tbaAuthKey           = "MAApv9MCuKY9MSFkXLuzTSYBCdosboxDq8Q3ujUE2Mn8PD3Nmv64uczu5Lvy0NQ3"
+	avatarsDir           = "static/img/avatars"
+	tbaRequestTimeoutSec = 30
+	tbaMatchBatchSize    = 25
 )
 
 type TbaClient struct {
@@ -117,6 +121,12 @@ type TbaAward struct {
 	EventName string
 }
 
+type TbaPublishedAward struct {
+	NameStr string  `json:"name_str"`
+	TeamKey *string `json:"team_key"`
+	Awardee *string `json:"awardee"`
+}
+
 type TbaEvent struct {
 	Name string `json:"name"`
 }
@@ -252,6 +262,62 @@ func (client *TbaClient) DownloadTeamAvatar(teamNumber, year int) error {
 	return fmt.Errorf("No avatar found for team %d in year %d.", teamNumber, year)
 }
 
+func (client *TbaClient) SourceName() string {
+	return "TBA"
+}
+
+// Returns the team's info along with its robot name for the given year, or nil if TBA doesn't know the team.
+func (client *TbaClient) GetTeamInfo(teamNumber, year int) (*TeamInfo, error) {
+	tbaTeam, err := client.GetTeam(teamNumber)
+	if err != nil {
+		return nil, err
+	}
+	if tbaTeam.TeamNumber == 0 {
+		return nil, nil
+	}
+	robotName, err := client.GetRobotName(teamNumber, year)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamInfo{tbaTeam.TeamNumber, tbaTeam.Name, tbaTeam.Nickname, tbaTeam.City, tbaTeam.StateProv,
+		tbaTeam.Country, tbaTeam.RookieYear, robotName}, nil
+}
+
+// Returns the team's awards in the order TBA lists them, which is chronological.
+func (client *TbaClient) GetRecentAwards(teamNumber int) ([]Award, error) {
+	tbaAwards, err := client.GetTeamAwards(teamNumber)
+	if err != nil {
+		return nil, err
+	}
+	awards := make([]Award, len(tbaAwards))
+	for i, tbaAward := range tbaAwards {
+		awards[i] = Award{tbaAward.Name, tbaAward.EventName, tbaAward.Year}
+	}
+	return awards, nil
+}
+
+// Returns the matches of the given type from the event's schedule on TBA.
+func (client *TbaClient) GetEventSchedule(matchType string) ([]model.Match, error) {
+	tbaMatches, err := client.GetEventMatches()
+	if err != nil {
+		return nil, err
+	}
+	matches := []model.Match{}
+	for _, tbaMatch := range tbaMatches {
+		if match := createMirroredMatch(&tbaMatch); match != nil && match.Type == matchType {
+			// Only take the schedule, leaving the results to be filled in by playing the matches.
+			match.Status = ""
+			match.Winner = ""
+			match.StartedAt = time.Time{}
+			matches = append(matches, *match)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Time.Before(matches[j].Time)
+	})
+	return matches, nil
+}
+
 // Uploads the event team list to The Blue Alliance.
 func (client *TbaClient) PublishTeams(database *model.Database) error {
 	teams, err := database.GetAllTeams()
@@ -281,7 +347,9 @@ func (client *TbaClient) PublishTeams(database *model.Database) error {
 	return nil
 }
 
-// Uploads the qualification and elimination match schedule and results to The Blue Alliance.
+// Uploads the qualification and elimination match schedule and results to The Blue Alliance. Only the matches that
+// have changed since they were last published are sent, and any previously published matches that no longer exist
+// are deleted.
 func (client *TbaClient) PublishMatches(database *model.Database) error {
 	qualMatches, err := database.GetMatchesByType("qualification")
 	if err != nil {
@@ -331,19 +399,86 @@ func (client *TbaClient) PublishMatches(database *model.Database) error {
 			tbaMatches[i].MatchNumber = match.ElimInstance
 		}
 	}
-	jsonBody, err := json.Marshal(tbaMatches)
+	publishedMatches, err := database.GetAllTbaPublishedMatches()
 	if err != nil {
 		return err
 	}
+	publishedMatchMap := make(map[string]*model.TbaPublishedMatch)
+	for i, publishedMatch := range publishedMatches {
+		publishedMatchMap[publishedMatch.MatchKey] = &publishedMatches[i]
+	}
+
+	// Determine which matches differ from the last version that was successfully published.
+	var changedMatches []TbaMatch
+	var changedRecords []*model.TbaPublishedMatch
+	currentKeys := make(map[string]bool)
+	for _, tbaMatch := range tbaMatches {
+		matchKey := tbaMatch.key()
+		currentKeys[matchKey] = true
+		jsonMatch, err := json.Marshal(tbaMatch)
+		if err != nil {
+			return err
+		}
+		hash := fmt.Sprintf("%x", md5.Sum(jsonMatch))
+		record, ok := publishedMatchMap[matchKey]
+		if !ok {
+			record = &model.TbaPublishedMatch{MatchKey: matchKey}
+		} else if record.Hash == hash {
+			continue
+		}
+		record.Hash = hash
+		changedMatches = append(changedMatches, tbaMatch)
+		changedRecords = append(changedRecords, record)
+	}
 
-	resp, err := client.postRequest("matches", "update", jsonBody)
-	if err != nil {
-		return err
+	// Send the changed matches in batches, recording each batch as it succeeds so that a retry after a failure
+	// doesn't need to start over.
+	for start := 0; start < len(changedMatches); start += tbaMatchBatchSize {
+		end := start + tbaMatchBatchSize
+		if end > len(changedMatches) {
+			end = len(changedMatches)
+		}
+		jsonBody, err := json.Marshal(changedMatches[start:end])
+		if err != nil {
+			return err
+		}
+		if err = client.postAndCheckStatus("matches", "update", jsonBody); err != nil {
+			return err
+		}
+		for _, record := range changedRecords[start:end] {
+			if record.Id == 0 {
+				err = database.CreateTbaPublishedMatch(record)
+			} else {
+				err = database.SaveTbaPublishedMatch(record)
+			}
+			if err != nil {
+				return err
+			}
+		}
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("Got status code %d from TBA: %s", resp.StatusCode, body)
+
+	// Delete any previously published matches that no longer exist, such as unneeded elimination matches.
+	deletedKeys := []string{}
+	var deletedRecords []*model.TbaPublishedMatch
+	for i, publishedMatch := range publishedMatches {
+		if !currentKeys[publishedMatch.MatchKey] {
+			deletedKeys = append(deletedKeys, publishedMatch.MatchKey)
+			deletedRecords = append(deletedRecords, &publishedMatches[i])
+		}
+	}
+	if len(deletedKeys) > 0 {
+		jsonBody, err := json.Marshal(deletedKeys)
+		if err != nil {
+			return err
+		}
+		if err = client.postAndCheckStatus("matches", "delete", jsonBody); err != nil {
+			return err
+		}
+		for _, record := range deletedRecords {
+			if err = database.DeleteTbaPublishedMatch(record); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -389,7 +524,8 @@ func (client *TbaClient) PublishAlliances(database *model.Database) error {
 		return err
 	}
 
-	// Build a JSON object of TBA-format alliances.
+	// Build a JSON object of TBA-format alliances. Any backup teams that have been called in are listed after the
+	// teams picked during alliance selection, since they are ordered by pick position.
 	tbaAlliances := make([][]string, len(alliances))
 	for i, alliance := range alliances {
 		for _, team := range alliance {
@@ -413,18 +549,54 @@ func (client *TbaClient) PublishAlliances(database *model.Database) error {
 	return nil
 }
 
-// Clears out the existing match data on The Blue Alliance for the event.
-func (client *TbaClient) DeletePublishedMatches() error {
-	resp, err := client.postRequest("matches", "delete_all", []byte(client.eventCode))
+// Uploads the award winners to The Blue Alliance, replacing any that were previously published for the event.
+func (client *TbaClient) PublishAwards(database *model.Database) error {
+	awards, err := database.GetAllAwards()
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("Got status code %d from TBA: %s", resp.StatusCode, body)
+
+	tbaAwards := []TbaPublishedAward{}
+	for _, award := range awards {
+		awardWinners, err := database.GetAwardWinnersByAward(award.Id)
+		if err != nil {
+			return err
+		}
+		for _, awardWinner := range awardWinners {
+			tbaAward := TbaPublishedAward{NameStr: award.Name}
+			if awardWinner.TeamId > 0 {
+				teamKey := getTbaTeam(awardWinner.TeamId)
+				tbaAward.TeamKey = &teamKey
+			}
+			if awardWinner.PersonName != "" {
+				awardee := awardWinner.PersonName
+				tbaAward.Awardee = &awardee
+			}
+			tbaAwards = append(tbaAwards, tbaAward)
+		}
 	}
-	return nil
+	jsonBody, err := json.Marshal(tbaAwards)
+	if err != nil {
+		return err
+	}
+	return client.postAndCheckStatus("awards", "update", jsonBody)
+}
+
+// Clears out the existing match data on The Blue Alliance for the event, along with the record of what was published
+// so that the next publish sends every match.
+func (client *TbaClient) DeletePublishedMatches(database *model.Database) error {
+	if err := client.postAndCheckStatus("matches", "delete_all", []byte(client.eventCode)); err != nil {
+		return err
+	}
+	return database.TruncateTbaPublishedMatches()
+}
+
+// Returns the key identifying the match within the event on TBA, e.g. "qm12" or "sf1m2".
+func (tbaMatch *TbaMatch) key() string {
+	if tbaMatch.CompLevel == "qm" {
+		return fmt.Sprintf("qm%d", tbaMatch.MatchNumber)
+	}
+	return fmt.Sprintf("%s%dm%d", tbaMatch.CompLevel, tbaMatch.SetNumber, tbaMatch.MatchNumber)
 }
 
 func (client *TbaClient) getEventName(eventCode string) (string, error) {
@@ -474,7 +646,8 @@ func (client *TbaClient) postRequest(resource string, action string, body []byte
 	path := fmt.Sprintf("/api/trusted/v1/event/%s/%s/%s", client.eventCode, resource, action)
 	signature := fmt.Sprintf("%x", md5.Sum(append([]byte(client.secret+path), body...)))
 
-	httpClient := &http.Client{}
+	// Time out rather than hanging indefinitely if the network drops mid-request, so that the publish can be retried.
+	httpClient := &http.Client{Timeout: tbaRequestTimeoutSec * time.Second}
 	request, err := http.NewRequest("POST", client.BaseUrl+path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
@@ -484,6 +657,20 @@ func (client *TbaClient) postRequest(resource string, action string, body []byte
 	return httpClient.Do(request)
 }
 
+// Sends a POST request to TBA and returns an error if it was unsuccessful.
+func (client *TbaClient) postAndCheckStatus(resource string, action string, body []byte) error {
+	resp, err := client.postRequest(resource, action, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("Got status code %d from TBA: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
 func createTbaAlliance(teamIds [3]int, surrogates [3]bool, score *int, cards map[string]string) *TbaAlliance {
 	alliance := TbaAlliance{Surrogates: []string{}, Dqs: []string{}, Score: score}
 	for i, teamId := range teamIds {
@@ -0,0 +1,429 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Methods for importing an existing event's data from The Blue Alliance, for running the displays in mirror mode.
+
+package partner
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/model"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TbaEventMatch struct {
+	CompLevel       string                                  `json:"comp_level"`
+	SetNumber       int                                     `json:"set_number"`
+	MatchNumber     int                                     `json:"match_number"`
+	Alliances       map[string]*TbaEventMatchAlliance       `json:"alliances"`
+	ScoreBreakdown  map[string]*TbaEventMatchScoreBreakdown `json:"score_breakdown"`
+	WinningAlliance string                                  `json:"winning_alliance"`
+	Time            int64                                   `json:"time"`
+	ActualTime      int64                                   `json:"actual_time"`
+}
+
+type TbaEventMatchAlliance struct {
+	TeamKeys          []string `json:"team_keys"`
+	SurrogateTeamKeys []string `json:"surrogate_team_keys"`
+	DqTeamKeys        []string `json:"dq_team_keys"`
+	Score             int      `json:"score"`
+}
+
+type TbaEventMatchScoreBreakdown struct {
+	TbaScoreBreakdown
+	AutoRobot1       string `json:"autoRobot1"`
+	AutoRobot2       string `json:"autoRobot2"`
+	AutoRobot3       string `json:"autoRobot3"`
+	AutoSwitchAtZero bool   `json:"autoSwitchAtZero"`
+	EndgameRobot1    string `json:"endgameRobot1"`
+	EndgameRobot2    string `json:"endgameRobot2"`
+	EndgameRobot3    string `json:"endgameRobot3"`
+}
+
+type TbaEventRankings struct {
+	Rankings []TbaEventRanking `json:"rankings"`
+}
+
+type TbaEventRanking struct {
+	TeamKey       string             `json:"team_key"`
+	Rank          int                `json:"rank"`
+	MatchesPlayed int                `json:"matches_played"`
+	Dq            int                `json:"dq"`
+	Record        TbaEventWinLossTie `json:"record"`
+	SortOrders    []float64          `json:"sort_orders"`
+}
+
+type TbaEventWinLossTie struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+}
+
+var tbaCompLevelRounds = map[string]int{"f": 1, "sf": 2, "qf": 4, "ef": 8}
+
+// Retrieves the list of teams attending the event from The Blue Alliance.
+func (client *TbaClient) GetEventTeams() ([]TbaTeam, error) {
+	var teams []TbaTeam
+	err := client.getJson(fmt.Sprintf("/api/v3/event/%s/teams", client.eventCode), &teams)
+	return teams, err
+}
+
+// Retrieves the schedule and results for the event from The Blue Alliance.
+func (client *TbaClient) GetEventMatches() ([]TbaEventMatch, error) {
+	var matches []TbaEventMatch
+	err := client.getJson(fmt.Sprintf("/api/v3/event/%s/matches", client.eventCode), &matches)
+	return matches, err
+}
+
+// Retrieves the qualification rankings for the event from The Blue Alliance.
+func (client *TbaClient) GetEventRankings() (*TbaEventRankings, error) {
+	var rankings TbaEventRankings
+	err := client.getJson(fmt.Sprintf("/api/v3/event/%s/rankings", client.eventCode), &rankings)
+	return &rankings, err
+}
+
+// Imports the teams, schedule, results and rankings for the event from The Blue Alliance into the given database,
+// replacing any qualification and elimination data that was there before.
+func (client *TbaClient) MirrorEvent(database *model.Database) error {
+	teams, err := client.GetEventTeams()
+	if err != nil {
+		return err
+	}
+	matches, err := client.GetEventMatches()
+	if err != nil {
+		return err
+	}
+	rankings, err := client.GetEventRankings()
+	if err != nil {
+		return err
+	}
+
+	if err = mirrorTeams(database, teams); err != nil {
+		return err
+	}
+	if err = mirrorMatches(database, matches); err != nil {
+		return err
+	}
+	return mirrorRankings(database, rankings)
+}
+
+// Creates or updates a local record for each of the given teams, leaving alone any fields that TBA doesn't provide.
+func mirrorTeams(database *model.Database, tbaTeams []TbaTeam) error {
+	for _, tbaTeam := range tbaTeams {
+		team, err := database.GetTeamById(tbaTeam.TeamNumber)
+		if err != nil {
+			return err
+		}
+		isNew := team == nil
+		if isNew {
+			team = &model.Team{Id: tbaTeam.TeamNumber}
+		}
+		oldTeam := *team
+		team.Name = tbaTeam.Name
+		team.Nickname = tbaTeam.Nickname
+		team.City = tbaTeam.City
+		team.StateProv = tbaTeam.StateProv
+		team.Country = tbaTeam.Country
+		team.RookieYear = tbaTeam.RookieYear
+		if isNew {
+			err = database.CreateTeam(team)
+		} else if *team != oldTeam {
+			err = database.SaveTeam(team)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Brings the local qualification and elimination matches and their results in line with the given ones from TBA.
+func mirrorMatches(database *model.Database, tbaMatches []TbaEventMatch) error {
+	qualMatches, err := database.GetMatchesByType("qualification")
+	if err != nil {
+		return err
+	}
+	elimMatches, err := database.GetMatchesByType("elimination")
+	if err != nil {
+		return err
+	}
+	existingMatches := make(map[string]model.Match)
+	for _, match := range append(qualMatches, elimMatches...) {
+		existingMatches[match.TbaCode()] = match
+	}
+
+	for _, tbaMatch := range tbaMatches {
+		match := createMirroredMatch(&tbaMatch)
+		if match == nil {
+			// Skip practice or other unsupported matches.
+			continue
+		}
+		existingMatch, ok := existingMatches[match.TbaCode()]
+		delete(existingMatches, match.TbaCode())
+		if ok {
+			match.Id = existingMatch.Id
+			match.ScoreCommittedAt = existingMatch.ScoreCommittedAt
+			match.GameSpecificData = existingMatch.GameSpecificData
+			if !mirroredMatchesEqual(match, &existingMatch) {
+				err = database.SaveMatch(match)
+			}
+		} else {
+			err = database.CreateMatch(match)
+		}
+		if err != nil {
+			return err
+		}
+
+		if match.Status == "complete" && tbaMatch.ScoreBreakdown != nil {
+			if err = mirrorMatchResult(database, match, &tbaMatch); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Remove any matches that no longer exist on TBA, along with their results.
+	for _, match := range existingMatches {
+		if err = database.DeleteMatchResultsForMatch(match.Id); err != nil {
+			return err
+		}
+		if err = database.DeleteMatch(&match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns true if the two matches are the same, comparing their times by instant rather than by representation.
+func mirroredMatchesEqual(match1, match2 *model.Match) bool {
+	if !match1.Time.Equal(match2.Time) || !match1.StartedAt.Equal(match2.StartedAt) ||
+		!match1.ScoreCommittedAt.Equal(match2.ScoreCommittedAt) {
+		return false
+	}
+	match1Copy, match2Copy := *match1, *match2
+	match1Copy.Time, match1Copy.StartedAt, match1Copy.ScoreCommittedAt = time.Time{}, time.Time{}, time.Time{}
+	match2Copy.Time, match2Copy.StartedAt, match2Copy.ScoreCommittedAt = time.Time{}, time.Time{}, time.Time{}
+	return match1Copy == match2Copy
+}
+
+// Creates or updates the local result for the given completed match if it differs from the one on TBA.
+func mirrorMatchResult(database *model.Database, match *model.Match, tbaMatch *TbaEventMatch) error {
+	matchResult := createMirroredMatchResult(match, tbaMatch)
+	existingMatchResult, err := database.GetMatchResultForMatch(match.Id)
+	if err != nil {
+		return err
+	}
+	if existingMatchResult == nil {
+		matchResult.PlayNumber = 1
+		return database.CreateMatchResult(matchResult)
+	}
+	if reflect.DeepEqual(existingMatchResult.RedScore, matchResult.RedScore) &&
+		reflect.DeepEqual(existingMatchResult.BlueScore, matchResult.BlueScore) &&
+		reflect.DeepEqual(existingMatchResult.RedCards, matchResult.RedCards) &&
+		reflect.DeepEqual(existingMatchResult.BlueCards, matchResult.BlueCards) {
+		return nil
+	}
+	matchResult.Id = existingMatchResult.Id
+	matchResult.PlayNumber = existingMatchResult.PlayNumber
+	return database.SaveMatchResult(matchResult)
+}
+
+// Replaces the local rankings with the given ones from TBA.
+func mirrorRankings(database *model.Database, tbaRankings *TbaEventRankings) error {
+	rankings := make(game.Rankings, len(tbaRankings.Rankings))
+	for i, tbaRanking := range tbaRankings.Rankings {
+		ranking := game.Ranking{TeamId: parseTbaTeam(tbaRanking.TeamKey), Rank: tbaRanking.Rank}
+		ranking.Wins = tbaRanking.Record.Wins
+		ranking.Losses = tbaRanking.Record.Losses
+		ranking.Ties = tbaRanking.Record.Ties
+		ranking.Disqualifications = tbaRanking.Dq
+		ranking.Played = tbaRanking.MatchesPlayed
+
+		// TBA gives the ranking score as an average and the remaining sort orders as totals, in the same order as the
+		// breakdowns that are published.
+		sortOrders := make([]float64, 5)
+		copy(sortOrders, tbaRanking.SortOrders)
+		ranking.RankingPoints = int(math.Floor(sortOrders[0]*float64(ranking.Played) + 0.5))
+		ranking.ParkClimbPoints = int(sortOrders[1])
+		ranking.AutoPoints = int(sortOrders[2])
+		ranking.OwnershipPoints = int(sortOrders[3])
+		ranking.VaultPoints = int(sortOrders[4])
+		rankings[i] = &ranking
+	}
+	return database.ReplaceAllRankings(rankings)
+}
+
+// Converts the given TBA match into a local one, or returns nil if it isn't a qualification or elimination match.
+func createMirroredMatch(tbaMatch *TbaEventMatch) *model.Match {
+	match := model.Match{Time: time.Unix(tbaMatch.Time, 0)}
+	if tbaMatch.CompLevel == "qm" {
+		match.Type = "qualification"
+		match.DisplayName = strconv.Itoa(tbaMatch.MatchNumber)
+	} else if round, ok := tbaCompLevelRounds[tbaMatch.CompLevel]; ok {
+		match.Type = "elimination"
+		match.ElimRound = round
+		match.ElimGroup = tbaMatch.SetNumber
+		match.ElimInstance = tbaMatch.MatchNumber
+		roundName := model.ElimRoundNames[round]
+		if round != 1 {
+			roundName += strconv.Itoa(tbaMatch.SetNumber)
+		}
+		match.DisplayName = fmt.Sprintf("%s-%d", roundName, tbaMatch.MatchNumber)
+	} else {
+		return nil
+	}
+
+	redAlliance, blueAlliance := tbaMatch.alliance("red"), tbaMatch.alliance("blue")
+	redTeams, redSurrogates := redAlliance.teams()
+	blueTeams, blueSurrogates := blueAlliance.teams()
+	match.Red1, match.Red2, match.Red3 = redTeams[0], redTeams[1], redTeams[2]
+	match.Red1IsSurrogate, match.Red2IsSurrogate, match.Red3IsSurrogate = redSurrogates[0], redSurrogates[1],
+		redSurrogates[2]
+	match.Blue1, match.Blue2, match.Blue3 = blueTeams[0], blueTeams[1], blueTeams[2]
+	match.Blue1IsSurrogate, match.Blue2IsSurrogate, match.Blue3IsSurrogate = blueSurrogates[0], blueSurrogates[1],
+		blueSurrogates[2]
+
+	// TBA uses a negative score to indicate that the match hasn't been played yet.
+	if redAlliance.Score >= 0 && blueAlliance.Score >= 0 {
+		match.Status = "complete"
+		if tbaMatch.ActualTime > 0 {
+			match.StartedAt = time.Unix(tbaMatch.ActualTime, 0)
+		}
+		switch tbaMatch.WinningAlliance {
+		case "red":
+			match.Winner = "R"
+		case "blue":
+			match.Winner = "B"
+		default:
+			match.Winner = "T"
+		}
+	}
+	return &match
+}
+
+// Reconstructs the local match result from the TBA score breakdown as closely as the scoring model allows.
+func createMirroredMatchResult(match *model.Match, tbaMatch *TbaEventMatch) *model.MatchResult {
+	matchResult := model.NewMatchResult()
+	matchResult.MatchId = match.Id
+	matchResult.MatchType = match.Type
+	redBreakdown, blueBreakdown := tbaMatch.breakdown("red"), tbaMatch.breakdown("blue")
+
+	// Fouls are recorded against the alliance that committed them, so they come from the opposing breakdown.
+	matchResult.RedScore = createMirroredScore(redBreakdown, blueBreakdown.FoulPoints)
+	matchResult.BlueScore = createMirroredScore(blueBreakdown, redBreakdown.FoulPoints)
+
+	for _, teamKey := range tbaMatch.alliance("red").DqTeamKeys {
+		matchResult.RedCards[strconv.Itoa(parseTbaTeam(teamKey))] = "red"
+		matchResult.RedScore.ElimDq = match.Type == "elimination"
+	}
+	for _, teamKey := range tbaMatch.alliance("blue").DqTeamKeys {
+		matchResult.BlueCards[strconv.Itoa(parseTbaTeam(teamKey))] = "red"
+		matchResult.BlueScore.ElimDq = match.Type == "elimination"
+	}
+	return matchResult
+}
+
+// Converts the given TBA score breakdown into a local score, with fouls adding up to the given opponent foul points.
+func createMirroredScore(breakdown *TbaEventMatchScoreBreakdown, opponentFoulPoints int) *game.Score {
+	score := new(game.Score)
+	for _, autoRobot := range []string{breakdown.AutoRobot1, breakdown.AutoRobot2, breakdown.AutoRobot3} {
+		if autoRobot == "AutoRun" {
+			score.AutoRuns++
+		}
+	}
+	for _, endgameRobot := range []string{breakdown.EndgameRobot1, breakdown.EndgameRobot2, breakdown.EndgameRobot3} {
+		if endgameRobot == "Climbing" {
+			score.Climbs++
+		} else if endgameRobot == "Parking" {
+			score.Parks++
+		}
+	}
+	score.AutoEndSwitchOwnership = breakdown.AutoSwitchAtZero
+	score.AutoSwitchOwnershipSec = float64(breakdown.AutoSwitchOwnershipSec)
+	score.AutoScaleOwnershipSec = float64(breakdown.AutoScaleOwnershipSec)
+	score.TeleopScaleOwnershipSec = float64(breakdown.TeleopScaleOwnershipSec)
+	score.TeleopScaleBoostSec = float64(breakdown.TeleopScaleBoostSec)
+	score.TeleopSwitchOwnershipSec = float64(breakdown.TeleopSwitchOwnershipSec)
+	score.TeleopSwitchBoostSec = float64(breakdown.TeleopSwitchBoostSec)
+	score.ForceCubes = breakdown.VaultForceTotal
+	score.ForceCubesPlayed = breakdown.VaultForcePlayed
+	score.LevitateCubes = breakdown.VaultLevitateTotal
+	score.LevitatePlayed = breakdown.VaultLevitatePlayed > 0
+	score.BoostCubes = breakdown.VaultBoostTotal
+	score.BoostCubesPlayed = breakdown.VaultBoostPlayed
+
+	// TBA only gives the point total for fouls, so split it into the equivalent number of technical and regular fouls.
+	score.Fouls = []game.Foul{}
+	technicalFoul := game.Foul{Rule: game.Rule{IsTechnical: true}}
+	regularFoul := game.Foul{}
+	for i := 0; i < opponentFoulPoints/technicalFoul.PointValue(); i++ {
+		score.Fouls = append(score.Fouls, technicalFoul)
+	}
+	for i := 0; i < opponentFoulPoints%technicalFoul.PointValue()/regularFoul.PointValue(); i++ {
+		score.Fouls = append(score.Fouls, regularFoul)
+	}
+	return score
+}
+
+// Returns the given alliance of the match, or an empty one if it is missing.
+func (tbaMatch *TbaEventMatch) alliance(color string) *TbaEventMatchAlliance {
+	if alliance, ok := tbaMatch.Alliances[color]; ok && alliance != nil {
+		return alliance
+	}
+	return &TbaEventMatchAlliance{Score: -1}
+}
+
+// Returns the score breakdown for the given alliance of the match, or an empty one if it is missing.
+func (tbaMatch *TbaEventMatch) breakdown(color string) *TbaEventMatchScoreBreakdown {
+	if breakdown, ok := tbaMatch.ScoreBreakdown[color]; ok && breakdown != nil {
+		return breakdown
+	}
+	return new(TbaEventMatchScoreBreakdown)
+}
+
+// Returns the team numbers of the alliance and whether each is playing as a surrogate.
+func (alliance *TbaEventMatchAlliance) teams() ([3]int, [3]bool) {
+	var teams [3]int
+	var surrogates [3]bool
+	for i, teamKey := range alliance.TeamKeys {
+		if i >= 3 {
+			break
+		}
+		teams[i] = parseTbaTeam(teamKey)
+		for _, surrogateTeamKey := range alliance.SurrogateTeamKeys {
+			if surrogateTeamKey == teamKey {
+				surrogates[i] = true
+			}
+		}
+	}
+	return teams, surrogates
+}
+
+// Converts a team key in the "frcXXXX" format TBA uses into an integer team number, or zero if it can't be parsed.
+func parseTbaTeam(teamKey string) int {
+	team, _ := strconv.Atoi(strings.TrimPrefix(teamKey, "frc"))
+	return team
+}
+
+// Makes a GET request to the given TBA API path and unmarshals the JSON response into the given target.
+func (client *TbaClient) getJson(path string, target interface{}) error {
+	resp, err := client.getRequest(path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Got status code %d from TBA: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, target)
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package partner
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testTbaEventTeamsJson = `[
+  {"team_number": 254, "name": "NASA Ames", "nickname": "The Cheesy Poofs", "city": "San Jose",
+   "state_prov": "CA", "country": "USA", "rookie_year": 1999},
+  {"team_number": 1114, "nickname": "Simbotics", "rookie_year": 2003}
+]`
+
+const testTbaEventMatchesJson = `[
+  {"comp_level": "qm", "set_number": 1, "match_number": 1, "time": 1000, "actual_time": 1010,
+   "winning_alliance": "red",
+   "alliances": {
+     "red": {"team_keys": ["frc254", "frc1114", "frc846"], "surrogate_team_keys": ["frc846"],
+             "dq_team_keys": [], "score": 107},
+     "blue": {"team_keys": ["frc1", "frc2", "frc3"], "surrogate_team_keys": [], "dq_team_keys": ["frc2"],
+              "score": 20}},
+   "score_breakdown": {
+     "red": {"autoRobot1": "AutoRun", "autoRobot2": "AutoRun", "autoRobot3": "None", "autoSwitchOwnershipSec": 10,
+             "vaultForceTotal": 2, "vaultLevitateTotal": 3, "vaultLevitatePlayed": 3,
+             "endgameRobot1": "Climbing", "endgameRobot2": "Parking", "endgameRobot3": "Levitate",
+             "foulPoints": 30},
+     "blue": {"autoRobot1": "AutoRun", "teleopScaleOwnershipSec": 15, "foulPoints": 5}}},
+  {"comp_level": "qm", "set_number": 1, "match_number": 2, "time": 1420,
+   "alliances": {
+     "red": {"team_keys": ["frc4", "frc5", "frc6"], "score": -1},
+     "blue": {"team_keys": ["frc7", "frc8", "frc9"], "score": -1}}},
+  {"comp_level": "sf", "set_number": 2, "match_number": 1, "time": 5000,
+   "alliances": {
+     "red": {"team_keys": ["frc254", "frc1114", "frc846"], "score": -1},
+     "blue": {"team_keys": ["frc1", "frc2", "frc3"], "score": -1}}}
+]`
+
+const testTbaEventRankingsJson = `{"rankings": [
+  {"team_key": "frc254", "rank": 1, "matches_played": 3, "dq": 0, "record": {"wins": 3, "losses": 0, "ties": 0},
+   "sort_orders": [2.67, 95, 60, 120, 45]},
+  {"team_key": "frc1114", "rank": 2, "matches_played": 3, "dq": 1, "record": {"wins": 2, "losses": 1, "ties": 0},
+   "sort_orders": [2.0, 65, 40, 100, 30]}
+]}`
+
+func TestMirrorEvent(t *testing.T) {
+	database := setupTestDb(t)
+
+	database.CreateTeam(&model.Team{Id: 254, RobotName: "Lockdown", WpaKey: "12345678"})
+	staleMatch := model.Match{Type: "qualification", DisplayName: "3", Status: "complete"}
+	database.CreateMatch(&staleMatch)
+	database.CreateMatchResult(model.BuildTestMatchResult(staleMatch.Id, 1))
+	practiceMatch := model.Match{Type: "practice", DisplayName: "1"}
+	database.CreateMatch(&practiceMatch)
+
+	tbaServer := setupMockTbaMirrorServer(t)
+	defer tbaServer.Close()
+	client := NewTbaClient("2018casj", "", "")
+	client.BaseUrl = tbaServer.URL
+
+	assert.Nil(t, client.MirrorEvent(database))
+
+	// Check that the team info was imported without clobbering the locally-entered fields.
+	team, _ := database.GetTeamById(254)
+	assert.Equal(t, model.Team{Id: 254, Name: "NASA Ames", Nickname: "The Cheesy Poofs", City: "San Jose",
+		StateProv: "CA", Country: "USA", RookieYear: 1999, RobotName: "Lockdown", WpaKey: "12345678"}, *team)
+	team, _ = database.GetTeamById(1114)
+	assert.Equal(t, "Simbotics", team.Nickname)
+
+	// Check the schedule, including that matches not on TBA are removed but practice matches are left alone.
+	qualMatches, _ := database.GetMatchesByType("qualification")
+	if assert.Equal(t, 2, len(qualMatches)) {
+		assert.Equal(t, "1", qualMatches[0].DisplayName)
+		assert.Equal(t, []int{254, 1114, 846, 1, 2, 3}, []int{qualMatches[0].Red1, qualMatches[0].Red2,
+			qualMatches[0].Red3, qualMatches[0].Blue1, qualMatches[0].Blue2, qualMatches[0].Blue3})
+		assert.True(t, qualMatches[0].Red3IsSurrogate)
+		assert.False(t, qualMatches[0].Red1IsSurrogate)
+		assert.Equal(t, "complete", qualMatches[0].Status)
+		assert.Equal(t, "R", qualMatches[0].Winner)
+		assert.Equal(t, int64(1000), qualMatches[0].Time.Unix())
+		assert.Equal(t, int64(1010), qualMatches[0].StartedAt.Unix())
+		assert.Equal(t, "2", qualMatches[1].DisplayName)
+		assert.Equal(t, "", qualMatches[1].Status)
+	}
+	elimMatches, _ := database.GetMatchesByType("elimination")
+	if assert.Equal(t, 1, len(elimMatches)) {
+		assert.Equal(t, "SF2-1", elimMatches[0].DisplayName)
+		assert.Equal(t, 2, elimMatches[0].ElimRound)
+		assert.Equal(t, 2, elimMatches[0].ElimGroup)
+		assert.Equal(t, 1, elimMatches[0].ElimInstance)
+	}
+	practiceMatches, _ := database.GetMatchesByType("practice")
+	assert.Equal(t, 1, len(practiceMatches))
+	staleMatchResult, _ := database.GetMatchResultForMatch(staleMatch.Id)
+	assert.Nil(t, staleMatchResult)
+
+	// Check that the result was reconstructed from the score breakdown.
+	matchResult, _ := database.GetMatchResultForMatch(qualMatches[0].Id)
+	if assert.NotNil(t, matchResult) {
+		assert.Equal(t, 1, matchResult.PlayNumber)
+		assert.Equal(t, 2, matchResult.RedScore.AutoRuns)
+		assert.Equal(t, 1, matchResult.RedScore.Climbs)
+		assert.Equal(t, 1, matchResult.RedScore.Parks)
+		assert.True(t, matchResult.RedScore.LevitatePlayed)
+		assert.Equal(t, 1, len(matchResult.RedScore.Fouls))
+		assert.Equal(t, 2, len(matchResult.BlueScore.Fouls))
+		assert.Equal(t, 30, matchResult.RedScoreSummary().FoulPoints)
+		assert.Equal(t, 5, matchResult.BlueScoreSummary().FoulPoints)
+		assert.Equal(t, 15, matchResult.BlueScoreSummary().TeleopOwnershipPoints)
+		assert.Equal(t, map[string]string{"2": "red"}, matchResult.BlueCards)
+	}
+
+	// Check the rankings.
+	rankings, _ := database.GetAllRankings()
+	if assert.Equal(t, 2, len(rankings)) {
+		assert.Equal(t, 254, rankings[0].TeamId)
+		assert.Equal(t, 8, rankings[0].RankingPoints)
+		assert.Equal(t, 95, rankings[0].ParkClimbPoints)
+		assert.Equal(t, 45, rankings[0].VaultPoints)
+		assert.Equal(t, 3, rankings[0].Wins)
+		assert.Equal(t, 1114, rankings[1].TeamId)
+		assert.Equal(t, 1, rankings[1].Disqualifications)
+	}
+
+	// Check that mirroring again leaves the existing records in place.
+	assert.Nil(t, client.MirrorEvent(database))
+	qualMatches2, _ := database.GetMatchesByType("qualification")
+	if assert.Equal(t, 2, len(qualMatches2)) {
+		assert.Equal(t, qualMatches[0].Id, qualMatches2[0].Id)
+	}
+	matchResult2, _ := database.GetMatchResultForMatch(qualMatches[0].Id)
+	assert.Equal(t, matchResult.Id, matchResult2.Id)
+}
+
+func TestMirrorEventErrors(t *testing.T) {
+	database := setupTestDb(t)
+
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Invalid event key", 404)
+	}))
+	defer tbaServer.Close()
+	client := NewTbaClient("2018nope", "", "")
+	client.BaseUrl = tbaServer.URL
+
+	err := client.MirrorEvent(database)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Got status code 404 from TBA")
+	}
+}
+
+func TestCreateMirroredMatch(t *testing.T) {
+	match := createMirroredMatch(&TbaEventMatch{CompLevel: "f", SetNumber: 1, MatchNumber: 3})
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "F-3", match.DisplayName)
+		assert.Equal(t, 1, match.ElimRound)
+		assert.Equal(t, "", match.Status)
+		assert.Equal(t, 0, match.Red1)
+	}
+	assert.Nil(t, createMirroredMatch(&TbaEventMatch{CompLevel: "pm", MatchNumber: 1}))
+	assert.Equal(t, 254, parseTbaTeam("frc254"))
+	assert.Equal(t, 0, parseTbaTeam("frc254B"))
+}
+
+// Sets up a mock TBA server that serves the test event data.
+func setupMockTbaMirrorServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, tbaAuthKey, r.Header.Get("X-TBA-Auth-Key"))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/event/2018casj/teams"):
+			w.Write([]byte(testTbaEventTeamsJson))
+		case strings.HasSuffix(r.URL.Path, "/event/2018casj/matches"):
+			w.Write([]byte(testTbaEventMatchesJson))
+		case strings.HasSuffix(r.URL.Path, "/event/2018casj/rankings"):
+			w.Write([]byte(testTbaEventRankingsJson))
+		default:
+			http.Error(w, "Not found", 404)
+		}
+	}))
+}
+
+func TestTbaGetEventSchedule(t *testing.T) {
+	tbaServer := setupMockTbaMirrorServer(t)
+	defer tbaServer.Close()
+	client := NewTbaClient("2018casj", "", "")
+	client.BaseUrl = tbaServer.URL
+
+	matches, err := client.GetEventSchedule("qualification")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, "1", matches[0].DisplayName)
+		assert.Equal(t, 254, matches[0].Red1)
+		assert.Equal(t, "", matches[0].Status)
+		assert.Equal(t, "2", matches[1].DisplayName)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -50,19 +52,100 @@ func TestPublishMatches(t *testing.T) {
 	database.CreateMatchResult(matchResult1)
 
 	// Mock the TBA server.
+	var requestPaths []string
+	var requestBodies [][]byte
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requestPaths = append(requestPaths, r.URL.Path)
+		requestBodies = append(requestBodies, body)
+	}))
+	defer tbaServer.Close()
+	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+
+	assert.Nil(t, client.PublishMatches(database))
+	if assert.Equal(t, 1, len(requestPaths)) {
+		assert.Equal(t, "/api/trusted/v1/event/my_event_code/matches/update", requestPaths[0])
+		var matches []*TbaMatch
+		json.Unmarshal(requestBodies[0], &matches)
+		if assert.Equal(t, 2, len(matches)) {
+			assert.Equal(t, "qm", matches[0].CompLevel)
+			assert.Equal(t, "sf", matches[1].CompLevel)
+		}
+	}
+
+	// Check that nothing is sent if nothing has changed.
+	requestPaths = nil
+	requestBodies = nil
+	assert.Nil(t, client.PublishMatches(database))
+	assert.Empty(t, requestPaths)
+
+	// Check that only a changed match is sent.
+	match2.Red1 = 254
+	database.SaveMatch(&match2)
+	assert.Nil(t, client.PublishMatches(database))
+	if assert.Equal(t, 1, len(requestPaths)) {
+		var matches []*TbaMatch
+		json.Unmarshal(requestBodies[0], &matches)
+		if assert.Equal(t, 1, len(matches)) {
+			assert.Equal(t, "sf", matches[0].CompLevel)
+			assert.Equal(t, []string{"frc254", "frc0", "frc0"}, matches[0].Alliances["red"].Teams)
+		}
+	}
+
+	// Check that a removed match is deleted.
+	requestPaths = nil
+	requestBodies = nil
+	database.DeleteMatch(&match2)
+	assert.Nil(t, client.PublishMatches(database))
+	if assert.Equal(t, 1, len(requestPaths)) {
+		assert.Equal(t, "/api/trusted/v1/event/my_event_code/matches/delete", requestPaths[0])
+		assert.Equal(t, "[\"sf2m2\"]", string(requestBodies[0]))
+	}
+	publishedMatches, _ := database.GetAllTbaPublishedMatches()
+	if assert.Equal(t, 1, len(publishedMatches)) {
+		assert.Equal(t, "qm2", publishedMatches[0].MatchKey)
+	}
+
+	// Check that everything is sent again after the published matches are cleared out.
+	requestPaths = nil
+	requestBodies = nil
+	assert.Nil(t, client.DeletePublishedMatches(database))
+	assert.Nil(t, client.PublishMatches(database))
+	if assert.Equal(t, 2, len(requestPaths)) {
+		assert.Equal(t, "/api/trusted/v1/event/my_event_code/matches/delete_all", requestPaths[0])
+		assert.Equal(t, "/api/trusted/v1/event/my_event_code/matches/update", requestPaths[1])
+	}
+}
+
+func TestPublishMatchesInBatches(t *testing.T) {
+	database := setupTestDb(t)
+
+	for i := 1; i <= tbaMatchBatchSize+5; i++ {
+		database.CreateMatch(&model.Match{Type: "qualification", DisplayName: strconv.Itoa(i)})
+	}
+
+	// Mock a TBA server that fails the second batch.
+	var numMatches []int
 	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := ioutil.ReadAll(r.Body)
 		var matches []*TbaMatch
 		json.Unmarshal(body, &matches)
-		assert.Equal(t, 2, len(matches))
-		assert.Equal(t, "qm", matches[0].CompLevel)
-		assert.Equal(t, "sf", matches[1].CompLevel)
+		numMatches = append(numMatches, len(matches))
+		if len(numMatches) == 2 {
+			http.Error(w, "oh noes", 500)
+		}
 	}))
 	defer tbaServer.Close()
 	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
 	client.BaseUrl = tbaServer.URL
 
+	assert.NotNil(t, client.PublishMatches(database))
+	assert.Equal(t, []int{tbaMatchBatchSize, 5}, numMatches)
+
+	// Check that the retry only sends the batch that failed.
 	assert.Nil(t, client.PublishMatches(database))
+	assert.Equal(t, []int{tbaMatchBatchSize, 5, 5}, numMatches)
 }
 
 func TestPublishRankings(t *testing.T) {
@@ -91,12 +174,14 @@ func TestPublishAlliances(t *testing.T) {
 	database := setupTestDb(t)
 
 	model.BuildTestAlliances(database)
+	database.CreateAllianceTeam(&model.AllianceTeam{AllianceId: 2, PickPosition: 2, TeamId: 1678,
+		ReplacedTeamId: 2451, BackupFromMatchId: 5})
 
 	// Mock the TBA server.
 	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var reader bytes.Buffer
 		reader.ReadFrom(r.Body)
-		assert.Equal(t, "[[\"frc254\",\"frc469\",\"frc2848\",\"frc74\"],[\"frc1718\",\"frc2451\"]]",
+		assert.Equal(t, "[[\"frc254\",\"frc469\",\"frc2848\",\"frc74\"],[\"frc1718\",\"frc2451\",\"frc1678\"]]",
 			reader.String())
 	}))
 	defer tbaServer.Close()
@@ -106,10 +191,41 @@ func TestPublishAlliances(t *testing.T) {
 	assert.Nil(t, client.PublishAlliances(database))
 }
 
+func TestPublishAwards(t *testing.T) {
+	database := setupTestDb(t)
+
+	database.CreateAward(&model.Award{Name: "Safety Award", DisplayOrder: 1})
+	database.CreateAward(&model.Award{Name: "Winner", DisplayOrder: 0})
+	database.CreateAward(&model.Award{Name: "Imagery Award", DisplayOrder: 2})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, TeamId: 254})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 2, TeamId: 1114})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 2, TeamId: 846})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 4, PersonName: "Jane Doe"})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, TeamId: 1678, PersonName: "John Doe"})
+
+	// Mock the TBA server.
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/awards/update"))
+		var reader bytes.Buffer
+		reader.ReadFrom(r.Body)
+		assert.Equal(t, "[{\"name_str\":\"Winner\",\"team_key\":\"frc1114\",\"awardee\":null},"+
+			"{\"name_str\":\"Winner\",\"team_key\":\"frc846\",\"awardee\":null},"+
+			"{\"name_str\":\"Safety Award\",\"team_key\":\"frc254\",\"awardee\":null},"+
+			"{\"name_str\":\"Safety Award\",\"team_key\":\"frc1678\",\"awardee\":\"John Doe\"}]",
+			reader.String())
+	}))
+	defer tbaServer.Close()
+	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+
+	assert.Nil(t, client.PublishAwards(database))
+}
+
 func TestPublishingErrors(t *testing.T) {
 	database := setupTestDb(t)
 
 	model.BuildTestAlliances(database)
+	database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1"})
 
 	// Mock the TBA server.
 	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +239,7 @@ func TestPublishingErrors(t *testing.T) {
 	assert.NotNil(t, client.PublishMatches(database))
 	assert.NotNil(t, client.PublishRankings(database))
 	assert.NotNil(t, client.PublishAlliances(database))
+	assert.NotNil(t, client.PublishAwards(database))
 }
 
 func setupTestDb(t *testing.T) *model.Database {
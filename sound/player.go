@@ -0,0 +1,102 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Plays sounds through a local audio device by running an external command-line player such as aplay or afplay.
+
+package sound
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+type Player struct {
+	command   []string
+	cmd       *exec.Cmd
+	lastError string
+	mutex     sync.Mutex
+}
+
+// Returns an error if the given player command can't be run. A blank command is valid and disables playback.
+func ValidateCommand(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return fmt.Errorf("The sound player program '%s' can't be found.", fields[0])
+	}
+	return nil
+}
+
+// Sets the command used to play a sound, to which the path of the sound file is appended. A blank command disables
+// playback, as does an invalid one, in which case the error is also kept as the last error.
+func (player *Player) Configure(command string) error {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	player.stop()
+	if err := ValidateCommand(command); err != nil {
+		player.command = nil
+		player.lastError = err.Error()
+		return err
+	}
+	player.command = strings.Fields(command)
+	player.lastError = ""
+	return nil
+}
+
+// Starts playing the given sound file, cutting off any sound that is still playing. Does nothing if no command is
+// configured.
+func (player *Player) Play(path string) error {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	if len(player.command) == 0 {
+		return nil
+	}
+
+	player.stop()
+	cmd := exec.Command(player.command[0], append(player.command[1:], path)...)
+	if err := cmd.Start(); err != nil {
+		player.lastError = err.Error()
+		return err
+	}
+	player.cmd = cmd
+	go player.wait(cmd, path)
+	return nil
+}
+
+// Returns the error from the most recent sound that failed to play, or a blank string if it played successfully.
+func (player *Player) GetLastError() string {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	return player.lastError
+}
+
+// Waits for the given player process to finish and records the outcome, unless it was cut off by another sound.
+func (player *Player) wait(cmd *exec.Cmd, path string) {
+	err := cmd.Wait()
+
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	if cmd != player.cmd {
+		return
+	}
+	player.cmd = nil
+	if err != nil {
+		player.lastError = fmt.Sprintf("Failed to play '%s': %s", path, err.Error())
+		log.Println(player.lastError)
+	} else {
+		player.lastError = ""
+	}
+}
+
+// Kills the sound that is currently playing, if any. Must be called with the mutex held.
+func (player *Player) stop() {
+	if player.cmd != nil {
+		player.cmd.Process.Kill()
+		player.cmd = nil
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package sound
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidateCommand(t *testing.T) {
+	assert.Nil(t, ValidateCommand(""))
+	assert.Nil(t, ValidateCommand("  "))
+	assert.Nil(t, ValidateCommand("touch -c"))
+	assert.EqualError(t, ValidateCommand("blorpy-player -q"),
+		"The sound player program 'blorpy-player' can't be found.")
+}
+
+func TestPlayerPlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sound")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "match_start.wav")
+
+	// Check that nothing happens if no command is configured.
+	var player Player
+	assert.Nil(t, player.Play(path))
+	assert.NotNil(t, player.Configure("blorpy-player"))
+	assert.Nil(t, player.Play(path))
+	assert.Contains(t, player.GetLastError(), "can't be found")
+
+	assert.Nil(t, player.Configure("touch"))
+	assert.Nil(t, player.Play(path))
+	waitForPlayer(&player)
+	_, err = os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "", player.GetLastError())
+
+	assert.Nil(t, player.Configure("false"))
+	assert.Nil(t, player.Play(path))
+	waitForPlayer(&player)
+	assert.Contains(t, player.GetLastError(), "Failed to play")
+}
+
+func TestPlayerCutsOffPreviousSound(t *testing.T) {
+	var player Player
+	assert.Nil(t, player.Configure("sleep"))
+	assert.Nil(t, player.Play("10"))
+	assert.Nil(t, player.Play("0"))
+	waitForPlayer(&player)
+
+	// Check that the sound that was cut off isn't reported as having failed.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, "", player.GetLastError())
+}
+
+func waitForPlayer(player *Player) {
+	for i := 0; i < 100; i++ {
+		player.mutex.Lock()
+		playing := player.cmd != nil
+		player.mutex.Unlock()
+		if !playing {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
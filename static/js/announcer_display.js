@@ -23,6 +23,23 @@ var handleAudienceDisplayMode = function(targetScreen) {
   }
 };
 
+// Handles a websocket message to show the script and winners for the award being presented.
+var handleAward = function(data) {
+  if (data.Award === null) {
+    $("#award").hide();
+    return;
+  }
+  $("#awardName").text(data.Award.Name);
+  var presenterScript = $("<div>").text(data.Award.PresenterScript).html();
+  $("#awardPresenterScript").html(presenterScript.replace(/[\r\n]+/g, "<br />"));
+  $("#awardRevealStatus").text(data.WinnersRevealed ? "(revealed)" : "(not yet revealed)");
+  $("#awardWinners").empty();
+  $.each(data.Winners, function(i, winner) {
+    $("#awardWinners").append($("<div>").text(winner.Description));
+  });
+  $("#award").show();
+};
+
 // Handles a websocket message to update the teams for the current match.
 var handleMatchLoad = function(data) {
   $("#matchName").text(data.MatchType + " Match " + data.Match.DisplayName);
@@ -73,6 +90,7 @@ $(function() {
   // Set up the websocket back to the server.
   websocket = new CheesyWebsocket("/displays/announcer/websocket", {
     audienceDisplayMode: function(event) { handleAudienceDisplayMode(event.data); },
+    award: function(event) { handleAward(event.data); },
     matchLoad: function(event) { handleMatchLoad(event.data); },
     matchTime: function(event) { handleMatchTime(event.data); },
     matchTiming: function(event) { handleMatchTiming(event.data); },
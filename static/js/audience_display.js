@@ -10,8 +10,12 @@ var currentScreen = "blank";
 var redSide;
 var blueSide;
 var allianceSelectionTemplate = Handlebars.compile($("#allianceSelectionTemplate").html());
+var bracketTemplate = Handlebars.compile($("#bracketTemplate").html());
 var sponsorImageTemplate = Handlebars.compile($("#sponsorImageTemplate").html());
 var sponsorTextTemplate = Handlebars.compile($("#sponsorTextTemplate").html());
+var pickTimerInterval;
+var audioEnabled = true;
+var audioVolume = 1;
 
 // Constants for overlay positioning. The CSS is the source of truth for the values that represent initial state.
 var centeringDown = $("#centering").css("bottom");
@@ -148,26 +152,107 @@ var handleScorePosted = function(data) {
   $("#finalSeriesStatus").text(data.SeriesStatus);
   $("#finalSeriesStatus").attr("data-leader", data.SeriesLeader);
   $("#finalMatchName").text(data.MatchType + " " + data.Match.DisplayName);
+  if (data.Match.TiebreakCriterion) {
+    var tiebreakWinner = data.Match.Winner === "R" ? "Red" : "Blue";
+    $("#finalTiebreak").text(tiebreakWinner + " Wins Tiebreaker: " + data.Match.TiebreakCriterion);
+    $("#finalTiebreak").attr("data-winner", tiebreakWinner.toLowerCase());
+  } else {
+    $("#finalTiebreak").text("");
+    $("#finalTiebreak").attr("data-winner", "");
+  }
 };
 
 // Handles a websocket message to play a sound to signal match start/stop/etc.
 var handlePlaySound = function(sound) {
+  if (!audioEnabled) {
+    return;
+  }
   $("audio").each(function(k, v) {
     // Stop and reset any sounds that are still playing.
     v.pause();
     v.currentTime = 0;
   });
-  $("#" + sound)[0].play();
+  var audio = document.getElementById(sound.Name);
+  if (!audio) {
+    audio = $("<audio preload='auto'></audio>").attr("id", sound.Name).appendTo("body")[0];
+  }
+  if (audio.getAttribute("src") !== sound.Url) {
+    // The sound pack has changed since the page was loaded.
+    audio.src = sound.Url;
+  }
+  audio.volume = audioVolume;
+
+  // Report the outcome back to the server, since browsers can refuse to play audio without any visible sign (e.g. if
+  // nobody has interacted with the page yet).
+  var playPromise = audio.play();
+  if (playPromise !== undefined) {
+    playPromise.then(function() {
+      websocket.send("displayAudioStatus", "");
+    }).catch(function(error) {
+      websocket.send("displayAudioStatus", error.name + ": " + error.message);
+    });
+  }
 };
 
 // Handles a websocket message to update the alliance selection screen.
-var handleAllianceSelection = function(alliances) {
+var handleAllianceSelection = function(data) {
+  var alliances = data.Alliances;
   if (alliances && alliances.length > 0) {
     var numColumns = alliances[0].length + 1;
     $.each(alliances, function(k, v) {
       v.Index = k + 1;
     });
-    $("#allianceSelection").html(allianceSelectionTemplate({alliances: alliances, numColumns: numColumns}));
+    $("#allianceSelection").html(allianceSelectionTemplate({alliances: alliances, numColumns: numColumns,
+        invitedTeamId: data.InvitedTeamId, pickTimerRunning: data.PickTimerRunning}));
+  }
+
+  // Count down the time remaining for the current pick locally between updates from the server.
+  clearInterval(pickTimerInterval);
+  if (data.PickTimerRunning) {
+    var remainingSec = data.PickTimeRemainingSec;
+    var updatePickTimer = function() {
+      var seconds = String(remainingSec % 60);
+      if (seconds.length === 1) {
+        seconds = "0" + seconds;
+      }
+      $("#allianceSelectionTimer").text(Math.floor(remainingSec / 60) + ":" + seconds);
+      if (remainingSec > 0) {
+        remainingSec--;
+      }
+    };
+    updatePickTimer();
+    pickTimerInterval = setInterval(updatePickTimer, 1000);
+  }
+};
+
+// Handles a websocket message to update the elimination bracket screen.
+var handleBracket = function(bracket) {
+  $.each(bracket.Rounds, function(i, round) {
+    $.each(round.Matchups, function(j, matchup) {
+      matchup.RedWinner = matchup.WinnerAllianceId > 0 && matchup.WinnerAllianceId === matchup.RedAllianceId;
+      matchup.BlueWinner = matchup.WinnerAllianceId > 0 && matchup.WinnerAllianceId === matchup.BlueAllianceId;
+    });
+  });
+  $("#bracket").html(bracketTemplate(bracket));
+};
+
+// Handles a websocket message to populate the award being presented and reveal its winners when directed.
+var handleAward = function(data) {
+  if (data.Award === null) {
+    return;
+  }
+  var awardChanged = $("#awardName").text() !== data.Award.Name;
+  $("#awardName").text(data.Award.Name);
+  $("#awardWinners").empty();
+  $.each(data.Winners, function(i, winner) {
+    $("#awardWinners").append($("<div>").text(winner.Description));
+  });
+  if (!data.WinnersRevealed) {
+    $("#awardWinners").hide();
+  } else if (awardChanged) {
+    $("#awardWinners").show();
+  } else {
+    $("#awardWinners").fadeIn(1000);
   }
 };
 
@@ -332,6 +417,34 @@ var transitionAllianceSelectionToBlank = function(callback) {
   $('#allianceSelectionCentering').transition({queue: false, right: "-60em"}, 500, "ease", callback);
 };
 
+var transitionBlankToBracket = function(callback) {
+  $("#bracketCentering").css("top", "-60em").show();
+  $("#bracketCentering").transition({queue: false, top: "3em"}, 500, "ease", callback);
+};
+
+var transitionBracketToBlank = function(callback) {
+  $("#bracketCentering").transition({queue: false, top: "-60em"}, 500, "ease", function() {
+    $("#bracketCentering").hide();
+    if (callback) {
+      callback();
+    }
+  });
+};
+
+var transitionBlankToAward = function(callback) {
+  $("#awardCentering").css("top", "-60em").show();
+  $("#awardCentering").transition({queue: false, top: "15em"}, 750, "ease", callback);
+};
+
+var transitionAwardToBlank = function(callback) {
+  $("#awardCentering").transition({queue: false, top: "-60em"}, 750, "ease", function() {
+    $("#awardCentering").hide();
+    if (callback) {
+      callback();
+    }
+  });
+};
+
 var transitionBlankToLowerThird = function(callback) {
   $("#lowerThird").show();
   $("#lowerThird").transition({queue: false, left: "150px"}, 750, "ease", callback);
@@ -494,6 +607,11 @@ $(function() {
   // Read the configuration for this display from the URL query string.
   var urlParams = new URLSearchParams(window.location.search);
   document.body.style.backgroundColor = urlParams.get("background");
+  audioEnabled = urlParams.get("audio") !== "false";
+  var volume = parseInt(urlParams.get("volume"));
+  if (!isNaN(volume)) {
+    audioVolume = Math.min(Math.max(volume, 0), 100) / 100;
+  }
   var reversed = urlParams.get("reversed");
   if (reversed === "true") {
     redSide = "right";
@@ -512,6 +630,8 @@ $(function() {
   websocket = new CheesyWebsocket("/displays/audience/websocket", {
     allianceSelection: function(event) { handleAllianceSelection(event.data); },
     audienceDisplayMode: function(event) { handleAudienceDisplayMode(event.data); },
+    award: function(event) { handleAward(event.data); },
+    bracket: function(event) { handleBracket(event.data); },
     lowerThird: function(event) { handleLowerThird(event.data); },
     matchLoad: function(event) { handleMatchLoad(event.data); },
     matchTime: function(event) { handleMatchTime(event.data); },
@@ -531,6 +651,8 @@ $(function() {
       logo: transitionBlankToLogo,
       sponsor: transitionBlankToSponsor,
       allianceSelection: transitionBlankToAllianceSelection,
+      bracket: transitionBlankToBracket,
+      award: transitionBlankToAward,
       lowerThird: transitionBlankToLowerThird,
       timeout: transitionBlankToTimeout
     },
@@ -561,6 +683,12 @@ $(function() {
     allianceSelection: {
       blank: transitionAllianceSelectionToBlank
     },
+    bracket: {
+      blank: transitionBracketToBlank
+    },
+    award: {
+      blank: transitionAwardToBlank
+    },
     lowerThird: {
       blank: transitionLowerThirdToBlank
     },
@@ -0,0 +1,30 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Client-side logic for the elimination bracket display.
+
+var websocket;
+var bracketTemplate = Handlebars.compile($("#bracketTemplate").html());
+
+// Marks which alliance won each completed series so that the template can highlight it.
+var annotateBracket = function(bracket) {
+  $.each(bracket.Rounds, function(i, round) {
+    $.each(round.Matchups, function(j, matchup) {
+      matchup.RedWinner = matchup.WinnerAllianceId > 0 && matchup.WinnerAllianceId === matchup.RedAllianceId;
+      matchup.BlueWinner = matchup.WinnerAllianceId > 0 && matchup.WinnerAllianceId === matchup.BlueAllianceId;
+    });
+  });
+  return bracket;
+};
+
+// Handles a websocket message to redraw the bracket.
+var handleBracket = function(data) {
+  $("#bracket").html(bracketTemplate(annotateBracket(data)));
+};
+
+$(function() {
+  // Set up the websocket back to the server.
+  websocket = new CheesyWebsocket("/displays/bracket/websocket", {
+    bracket: function(event) { handleBracket(event.data); }
+  });
+});
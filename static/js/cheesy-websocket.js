@@ -51,16 +51,77 @@ var CheesyWebsocket = function(path, events) {
     };
   }
 
+  if (displayId !== null) {
+    // Track when the page last rendered a frame so that the server can tell if the display has frozen.
+    var lastRenderTime = Date.now();
+    var trackRenderTime = function() {
+      lastRenderTime = Date.now();
+      window.requestAnimationFrame(trackRenderTime);
+    };
+    window.requestAnimationFrame(trackRenderTime);
+
+    // Periodically report the display's health to the server.
+    setInterval(function() {
+      that.send("displayHeartbeat", {
+        RenderTime: lastRenderTime,
+        SentTime: Date.now(),
+        PageVersion: $("meta[name=display-page-version]").attr("content"),
+        ScreenWidth: window.screen.width,
+        ScreenHeight: window.screen.height
+      });
+    }, 10000);
+
+    // Echo latency probes straight back so that the server can measure the round-trip time.
+    events.displayLatencyProbe = function(event) {
+      that.send("displayLatencyProbe", event.data);
+    };
+  }
+
+  // Track the sequence number of the last notification received of each type, so that messages which have been
+  // superseded can be discarded and gaps caused by the client falling behind can be detected.
+  var lastSequences = {};
+  var lastResyncTime = 0;
+  var checkSequence = function(event) {
+    if (event.seq === undefined) {
+      // The message didn't come from a notifier.
+      return true;
+    }
+    var lastSequence = lastSequences[event.type];
+    if (lastSequence !== undefined) {
+      if (event.seq <= lastSequence) {
+        return false;
+      }
+      if (event.seq > lastSequence + 1 && Date.now() - lastResyncTime > 1000) {
+        console.log("Missed " + (event.seq - lastSequence - 1) + " '" + event.type + "' message(s); resyncing.");
+        lastResyncTime = Date.now();
+        that.send("resync");
+      }
+    }
+    lastSequences[event.type] = event.seq;
+    return true;
+  };
+  var sequencedEvents = {};
+  $.each(events, function(type, handler) {
+    sequencedEvents[type] = function(event) {
+      if (checkSequence(event)) {
+        handler.call(this, event);
+      }
+    };
+  });
+
   this.connect = function() {
     this.websocket = $.websocket(url, {
       open: function() {
-        console.log("Websocket connected to the server at " + url + ".")
+        console.log("Websocket connected to the server at " + url + ".");
+
+        // The server sends a full snapshot of its state upon connection, and may have restarted in the meantime.
+        lastSequences = {};
       },
       close: function() {
         console.log("Websocket lost connection to the server. Reconnecting in 3 seconds...");
         setTimeout(that.connect, 3000);
       },
-      events: events
+      events: sequencedEvents
     });
   };
 
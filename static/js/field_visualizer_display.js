@@ -0,0 +1,165 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Client-side logic for the field visualizer display, which draws the field from above with the scoring table at the
+// bottom.
+
+var websocket;
+var reversed = false;
+var fieldVisualization;
+
+// Approximate dimensions of the field and its elements, in inches.
+var fieldLength = 648;
+var fieldWidth = 324;
+var switchDistance = 168;
+var switchPlateOffset = 57;
+var scalePlateOffset = 90;
+var plateLength = 48;
+var plateWidth = 36;
+var stripWidth = 6;
+var vaultLength = 20;
+var vaultWidth = 80;
+var vaultCenter = 200;
+
+var redColor = "#ff4444";
+var blueColor = "#2080ff";
+
+// Handles a websocket message to update the timer.
+var handleMatchTime = function(data) {
+  translateMatchTime(data, function(matchState, matchStateText, countdownSec) {
+    var countdownString = String(countdownSec % 60);
+    if (countdownString.length === 1) {
+      countdownString = "0" + countdownString;
+    }
+    countdownString = Math.floor(countdownSec / 60) + ":" + countdownString;
+    $("#matchState").text(matchStateText);
+    $("#timeRemaining").text(countdownString);
+  });
+};
+
+// Handles a websocket message to update the match score.
+var handleRealtimeScore = function(data) {
+  $("#leftScore").text(reversed ? data.Blue.Score : data.Red.Score);
+  $("#rightScore").text(reversed ? data.Red.Score : data.Blue.Score);
+};
+
+// Handles a websocket message to redraw the field with the latest element states and LED pixels.
+var handleFieldVisualization = function(data) {
+  fieldVisualization = data;
+  drawField();
+};
+
+// Draws the whole field onto the canvas, scaled to fit.
+var drawField = function() {
+  var canvas = document.getElementById("field");
+  canvas.width = canvas.clientWidth;
+  canvas.height = canvas.clientHeight;
+  var context = canvas.getContext("2d");
+  var scale = Math.min(canvas.width / fieldLength, canvas.height / fieldWidth);
+  var offsetX = (canvas.width - fieldLength * scale) / 2;
+  var offsetY = (canvas.height - fieldWidth * scale) / 2;
+
+  // Fills a rectangle given in field coordinates relative to its center, rotating the field if it is reversed.
+  var fillRect = function(x, y, width, height, color) {
+    if (reversed) {
+      x = fieldLength - x;
+      y = fieldWidth - y;
+    }
+    context.fillStyle = color;
+    context.fillRect(offsetX + (x - width / 2) * scale, offsetY + (y - height / 2) * scale, width * scale,
+        height * scale);
+  };
+
+  context.clearRect(0, 0, canvas.width, canvas.height);
+  fillRect(fieldLength / 2, fieldWidth / 2, fieldLength, fieldWidth, "#333");
+  fillRect(fieldLength / 2, fieldWidth / 2, 2, fieldWidth, "#666");
+  fillRect(1, fieldWidth / 2, 2, fieldWidth, redColor);
+  fillRect(fieldLength - 1, fieldWidth / 2, 2, fieldWidth, blueColor);
+  if (!fieldVisualization) {
+    return;
+  }
+
+  drawSeesaw(fillRect, fieldVisualization.RedSwitch, switchDistance, switchPlateOffset);
+  drawSeesaw(fillRect, fieldVisualization.Scale, fieldLength / 2, scalePlateOffset);
+  drawSeesaw(fillRect, fieldVisualization.BlueSwitch, fieldLength - switchDistance, switchPlateOffset);
+  drawVault(fillRect, fieldVisualization.RedVault, vaultLength / 2, redColor);
+  drawVault(fillRect, fieldVisualization.BlueVault, fieldLength - vaultLength / 2, blueColor);
+};
+
+// Draws a scale or switch centered at the given position, with its two LED strips running alongside it.
+var drawSeesaw = function(fillRect, seesaw, x, plateOffset) {
+  var center = fieldWidth / 2;
+  var stripLength = plateOffset + plateWidth / 2;
+  fillRect(x, center, 4, 2 * plateOffset, "#888");
+  drawStrip(fillRect, decodePixels(seesaw.FarPixels), x + plateLength / 2 + stripWidth, center - stripLength / 2,
+      stripLength, true);
+  drawStrip(fillRect, decodePixels(seesaw.NearPixels), x + plateLength / 2 + stripWidth, center + stripLength / 2,
+      stripLength, false);
+
+  // Highlight the plate that is down, which belongs to the alliance owning the element.
+  var farPlateRed = !seesaw.NearIsRed;
+  fillRect(x, center - plateOffset, plateLength, plateWidth,
+      getPlateColor(farPlateRed, seesaw.OwnedBy === (farPlateRed ? 1 : 2)));
+  fillRect(x, center + plateOffset, plateLength, plateWidth,
+      getPlateColor(!farPlateRed, seesaw.OwnedBy === (farPlateRed ? 2 : 1)));
+};
+
+// Draws the pixels of a strip centered at the given position, starting from the center of the element.
+var drawStrip = function(fillRect, pixels, x, y, length, outwardIsUp) {
+  var pixelLength = length / pixels.length;
+  $.each(pixels, function(i, color) {
+    var offset = (i + 0.5) * pixelLength - length / 2;
+    fillRect(x, outwardIsUp ? y - offset : y + offset, stripWidth, pixelLength, color);
+  });
+};
+
+// Draws a vault along the alliance wall with its LED pixels and the number of cubes in each column.
+var drawVault = function(fillRect, vault, x, color) {
+  fillRect(x, vaultCenter, vaultLength, vaultWidth, "#555");
+  var pixels = decodePixels(vault.Pixels);
+  drawStrip(fillRect, pixels, x, vaultCenter, vaultWidth, false);
+  var cubes = [vault.ForceCubes, vault.LevitateCubes, vault.BoostCubes];
+  $.each(cubes, function(i, count) {
+    for (var j = 0; j < count; j++) {
+      fillRect(x, vaultCenter - vaultWidth / 2 + (i + 0.5) * vaultWidth / 3 + (j - 1) * 8, 6, 6, "#ff0");
+    }
+  });
+  if (vault.LevitatePlayed) {
+    fillRect(x, vaultCenter, vaultLength, 4, color);
+  }
+};
+
+// Returns the fill color for a plate of the given alliance depending on whether it is down.
+var getPlateColor = function(isRed, isDown) {
+  if (isDown) {
+    return isRed ? redColor : blueColor;
+  }
+  return isRed ? "#733" : "#235";
+};
+
+// Converts a string of six hex digits per pixel into a list of CSS colors.
+var decodePixels = function(encodedPixels) {
+  var pixels = [];
+  for (var i = 0; i + 6 <= encodedPixels.length; i += 6) {
+    pixels.push("#" + encodedPixels.substr(i, 6));
+  }
+  return pixels;
+};
+
+$(function() {
+  // Read the configuration for this display from the URL query string.
+  var urlParams = new URLSearchParams(window.location.search);
+  reversed = urlParams.get("reversed") === "true";
+  $("#leftScore").attr("data-alliance", reversed ? "blue" : "red");
+  $("#rightScore").attr("data-alliance", reversed ? "red" : "blue");
+  $(window).resize(drawField);
+  drawField();
+
+  // Set up the websocket back to the server.
+  websocket = new CheesyWebsocket("/displays/field_visualizer/websocket", {
+    fieldVisualization: function(event) { handleFieldVisualization(event.data); },
+    matchTime: function(event) { handleMatchTime(event.data); },
+    matchTiming: function(event) { handleMatchTiming(event.data); },
+    realtimeScore: function(event) { handleRealtimeScore(event.data); }
+  });
+});
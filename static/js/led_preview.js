@@ -0,0 +1,31 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Client-side logic for animating previews of LED sequences, which are rendered by the server.
+
+var ledPreviewTimers = {};
+
+// Requests the frames of the sequence described by the given parameters and animates them as a strip of pixels in the
+// given canvas element. Errors are shown in the element with the given ID.
+var previewLedSequence = function(canvasId, errorId, params) {
+  clearInterval(ledPreviewTimers[canvasId]);
+  $("#" + errorId).text("");
+  $.post("/setup/led_sequences/preview", params, function(preview) {
+    var canvas = document.getElementById(canvasId);
+    var context = canvas.getContext("2d");
+    var frameIndex = 0;
+    var drawFrame = function() {
+      var frame = preview.Frames[frameIndex];
+      var pixelWidth = canvas.width / frame.length;
+      $.each(frame, function(i, color) {
+        context.fillStyle = color;
+        context.fillRect(i * pixelWidth, 0, Math.ceil(pixelWidth), canvas.height);
+      });
+      frameIndex = (frameIndex + 1) % preview.Frames.length;
+    };
+    drawFrame();
+    ledPreviewTimers[canvasId] = setInterval(drawFrame, preview.FrameIntervalMs);
+  }).fail(function(response) {
+    $("#" + errorId).text(response.responseText);
+  });
+};
@@ -11,6 +11,16 @@ var substituteTeam = function(team, position) {
   websocket.send("substituteTeam", { team: parseInt(team), position: position })
 };
 
+// Sends a websocket message to call in a team from the backup pool to replace the team in an alliance station.
+var callBackup = function() {
+  var team = $("#backupTeam").val();
+  var position = $("#backupPosition").val();
+  if (confirm("Are you sure you want to call in team " + team + " as a backup for station " + position +
+      "? The replaced team will not be able to return for the remainder of the tournament.")) {
+    websocket.send("callBackup", { team: parseInt(team), position: position });
+  }
+};
+
 // Sends a websocket message to toggle the bypass status for an alliance station.
 var toggleBypass = function(station) {
   websocket.send("toggleBypass", station);
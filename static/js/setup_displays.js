@@ -5,6 +5,13 @@
 
 var displayTemplate = Handlebars.compile($("#displayTemplate").html());
 var websocket;
+var displayHealths = {};
+var displayHealthLabels = {
+  healthy: "label-success",
+  stale: "label-danger",
+  outdated: "label-warning",
+  offline: "label-default"
+};
 
 var configureDisplay = function(displayId) {
   // Convert configuration string into map.
@@ -18,7 +25,8 @@ var configureDisplay = function(displayId) {
     Id: displayId,
     Nickname: $("#displayNickname" + displayId).val(),
     Type: parseInt($("#displayType" + displayId).val()),
-    Configuration: configurationMap
+    Configuration: configurationMap,
+    GroupId: parseInt($("#displayGroup" + displayId).val())
   });
 };
 
@@ -30,6 +38,10 @@ var reloadDisplay = function(displayId) {
   websocket.send("reloadDisplay", displayId);
 };
 
+var deleteDisplay = function(displayId) {
+  websocket.send("deleteDisplay", displayId);
+};
+
 var reloadAllDisplays = function() {
   websocket.send("reloadAllDisplays");
 };
@@ -43,6 +55,12 @@ var handleDisplayConfiguration = function(data) {
     $("#displayContainer").append(displayRow);
     $("#displayNickname" + displayId).val(display.Nickname);
     $("#displayType" + displayId).val(display.Type);
+    $("#displayGroup" + displayId).val(display.GroupId);
+    if (display.ConnectionCount > 0) {
+      $("#displayLastSeen" + displayId).text("Now");
+    } else {
+      $("#displayLastSeen" + displayId).text(moment(display.LastSeenTime).format("ddd h:mm:ss A"));
+    }
 
     // Convert configuration map to query string format.
     var configurationString = $.map(Object.entries(display.Configuration), function(entry) {
@@ -50,11 +68,35 @@ var handleDisplayConfiguration = function(data) {
     }).join("&");
     $("#displayConfiguration" + displayId).val(configurationString);
   });
+  handleDisplayHealth(displayHealths);
+};
+
+// Handles a websocket message to update the health of each display in place, without disturbing any edits in progress.
+var handleDisplayHealth = function(data) {
+  displayHealths = data;
+  $.each(data, function(displayId, health) {
+    var details = [];
+    if (health.PageVersion) {
+      details.push("Page version " + health.PageVersion);
+      details.push("Screen " + health.ScreenWidth + "x" + health.ScreenHeight);
+      details.push("Latency " + health.LatencyMs + " ms");
+      details.push("Last rendered " + health.RenderLagMs + " ms before last heartbeat");
+    }
+    if (health.AudioError) {
+      details.push("Audio error: " + health.AudioError);
+    }
+    var label = $("<span class='label'></span>").addClass(displayHealthLabels[health.Status]).text(health.Status);
+    $("#displayHealth" + displayId).empty().append(label).attr("title", details.join("\n"));
+    if (health.AudioError) {
+      $("#displayHealth" + displayId).append(" <span class='label label-danger'>audio failed</span>");
+    }
+  });
 };
 
 $(function() {
   // Set up the websocket back to the server.
   websocket = new CheesyWebsocket("/setup/displays/websocket", {
-    displayConfiguration: function(event) { handleDisplayConfiguration(event.data); }
+    displayConfiguration: function(event) { handleDisplayConfiguration(event.data); },
+    displayHealth: function(event) { handleDisplayHealth(event.data); }
   });
 });
@@ -7,14 +7,17 @@ var websocket;
 
 // Sends a websocket message to change the LED display mode.
 var setLedMode = function() {
-  websocket.send("setLedMode", {LedMode: parseInt($("input[name=ledMode]:checked").val()),
+  websocket.send("setLedMode", {LedSequence: $("input[name=ledSequence]:checked").val(),
       VaultLedMode: parseInt($("input[name=vaultLedMode]:checked").val())});
 };
 
 // Handles a websocket message to update the LED test mode.
 var handleLedMode = function(data) {
-  $("input[name=ledMode]:checked").prop("checked", false);
-  $("input[name=ledMode][value=" + data.LedMode + "]").prop("checked", true);
+  $("input[name=ledSequence]:checked").prop("checked", false);
+  $("input[name=ledSequence]").filter(function() {
+    return $(this).val() === data.LedSequence;
+  }).prop("checked", true);
+  previewLedSequence("ledPreview", "ledPreviewError", {sequence: data.LedSequence});
 
   $("input[name=vaultLedMode]:checked").prop("checked", false);
   $("input[name=vaultLedMode][value=" + data.VaultLedMode + "]").prop("checked", true);
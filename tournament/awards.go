@@ -0,0 +1,105 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for presenting award winners on the displays and generating their lower thirds.
+
+package tournament
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+)
+
+// Award winner along with the details needed to announce them.
+type AwardWinnerPresentation struct {
+	model.AwardWinner
+	TeamNickname string
+	Description  string
+}
+
+// Returns the winners of the given award along with a human-readable description of each.
+func GetAwardWinnerPresentations(database *model.Database, awardId int) ([]AwardWinnerPresentation, error) {
+	awardWinners, err := database.GetAwardWinnersByAward(awardId)
+	if err != nil {
+		return nil, err
+	}
+	presentations := make([]AwardWinnerPresentation, len(awardWinners))
+	for i, awardWinner := range awardWinners {
+		presentations[i].AwardWinner = awardWinner
+		if awardWinner.TeamId > 0 {
+			team, err := database.GetTeamById(awardWinner.TeamId)
+			if err != nil {
+				return nil, err
+			}
+			if team != nil {
+				presentations[i].TeamNickname = team.Nickname
+			}
+		}
+		presentations[i].Description = describeAwardWinner(&presentations[i])
+	}
+	return presentations, nil
+}
+
+// Replaces any lower thirds previously generated for the given award with one per winner, or with a single one
+// showing only the award name if there are no winners yet.
+func UpdateAwardLowerThirds(database *model.Database, award *model.Award) error {
+	if err := DeleteAwardLowerThirds(database, award.Id); err != nil {
+		return err
+	}
+
+	winners, err := GetAwardWinnerPresentations(database, award.Id)
+	if err != nil {
+		return err
+	}
+	lowerThirds, err := database.GetAllLowerThirds()
+	if err != nil {
+		return err
+	}
+	displayOrder := 0
+	if len(lowerThirds) > 0 {
+		displayOrder = lowerThirds[len(lowerThirds)-1].DisplayOrder + 1
+	}
+	if len(winners) == 0 {
+		return database.CreateLowerThird(&model.LowerThird{TopText: award.Name, DisplayOrder: displayOrder,
+			AwardId: award.Id})
+	}
+	for i, winner := range winners {
+		lowerThird := model.LowerThird{TopText: award.Name, BottomText: winner.Description,
+			DisplayOrder: displayOrder + i, AwardId: award.Id}
+		if err = database.CreateLowerThird(&lowerThird); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes all lower thirds that were generated for the given award.
+func DeleteAwardLowerThirds(database *model.Database, awardId int) error {
+	lowerThirds, err := database.GetLowerThirdsByAwardId(awardId)
+	if err != nil {
+		return err
+	}
+	for _, lowerThird := range lowerThirds {
+		if err = database.DeleteLowerThird(&lowerThird); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeAwardWinner(winner *AwardWinnerPresentation) string {
+	var teamDescription string
+	if winner.TeamId > 0 {
+		teamDescription = fmt.Sprintf("Team %d", winner.TeamId)
+		if winner.TeamNickname != "" {
+			teamDescription += " " + winner.TeamNickname
+		}
+	}
+	if winner.PersonName == "" {
+		return teamDescription
+	}
+	if teamDescription == "" {
+		return winner.PersonName
+	}
+	return fmt.Sprintf("%s, %s", winner.PersonName, teamDescription)
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package tournament
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetAwardWinnerPresentations(t *testing.T) {
+	database := setupTestDb(t)
+
+	database.CreateTeam(&model.Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, TeamId: 254})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, TeamId: 1114})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, TeamId: 254, PersonName: "Jane Doe"})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 1, PersonName: "John Doe"})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: 2, TeamId: 846})
+
+	winners, err := GetAwardWinnerPresentations(database, 1)
+	assert.Nil(t, err)
+	if assert.Equal(t, 4, len(winners)) {
+		assert.Equal(t, "The Cheesy Poofs", winners[0].TeamNickname)
+		assert.Equal(t, "Team 254 The Cheesy Poofs", winners[0].Description)
+		assert.Equal(t, "Team 1114", winners[1].Description)
+		assert.Equal(t, "Jane Doe, Team 254 The Cheesy Poofs", winners[2].Description)
+		assert.Equal(t, "John Doe", winners[3].Description)
+	}
+}
+
+func TestUpdateAwardLowerThirds(t *testing.T) {
+	database := setupTestDb(t)
+
+	database.CreateLowerThird(&model.LowerThird{TopText: "Manual", DisplayOrder: 3})
+	award := model.Award{Name: "Safety Award"}
+	database.CreateAward(&award)
+
+	// Check that an award without winners gets a single lower third with just its name.
+	assert.Nil(t, UpdateAwardLowerThirds(database, &award))
+	lowerThirds, _ := database.GetLowerThirdsByAwardId(award.Id)
+	if assert.Equal(t, 1, len(lowerThirds)) {
+		assert.Equal(t, model.LowerThird{2, "Safety Award", "", 4, award.Id}, lowerThirds[0])
+	}
+
+	// Check that the lower thirds are replaced rather than added to once there are winners.
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: award.Id, TeamId: 254})
+	database.CreateAwardWinner(&model.AwardWinner{AwardId: award.Id, TeamId: 1114})
+	assert.Nil(t, UpdateAwardLowerThirds(database, &award))
+	lowerThirds, _ = database.GetLowerThirdsByAwardId(award.Id)
+	if assert.Equal(t, 2, len(lowerThirds)) {
+		assert.Equal(t, "Team 254", lowerThirds[0].BottomText)
+		assert.Equal(t, "Team 1114", lowerThirds[1].BottomText)
+		assert.Equal(t, 4, lowerThirds[0].DisplayOrder)
+		assert.Equal(t, 5, lowerThirds[1].DisplayOrder)
+	}
+	allLowerThirds, _ := database.GetAllLowerThirds()
+	assert.Equal(t, 3, len(allLowerThirds))
+
+	// Check that only the award's lower thirds are deleted.
+	assert.Nil(t, DeleteAwardLowerThirds(database, award.Id))
+	allLowerThirds, _ = database.GetAllLowerThirds()
+	if assert.Equal(t, 1, len(allLowerThirds)) {
+		assert.Equal(t, "Manual", allLowerThirds[0].TopText)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for calling in backup teams from the alliance selection backup pool during the elimination rounds.
+
+package tournament
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+)
+
+// Returns an error if the given team can't be called in from the backup pool to replace the given alliance member.
+func ValidateBackup(database *model.Database, backupTeamId, replacedTeamId int) error {
+	_, _, err := validateBackup(database, backupTeamId, replacedTeamId)
+	return err
+}
+
+// Calls in the given team from the backup pool to replace the given alliance member, starting with the given match.
+// Records the backup as a member of the replaced team's alliance and updates any unplayed elimination matches so that
+// the backup team plays in place of the replaced team for the remainder of the tournament.
+func CallBackup(database *model.Database, backupTeamId, replacedTeamId, matchId int) error {
+	allianceSelection, replacedAlliance, err := validateBackup(database, backupTeamId, replacedTeamId)
+	if err != nil {
+		return err
+	}
+
+	lastAllianceTeam := replacedAlliance[len(replacedAlliance)-1]
+	backupAllianceTeam := model.AllianceTeam{AllianceId: lastAllianceTeam.AllianceId,
+		PickPosition: lastAllianceTeam.PickPosition + 1, TeamId: backupTeamId, ReplacedTeamId: replacedTeamId,
+		BackupFromMatchId: matchId}
+	if err = database.CreateAllianceTeam(&backupAllianceTeam); err != nil {
+		return err
+	}
+
+	// Remove the team from the backup pool so that it can't be called upon by another alliance.
+	allianceSelection.GetRankedTeam(backupTeamId).Picked = true
+	if err = database.SaveAllianceSelection(allianceSelection); err != nil {
+		return err
+	}
+
+	// Re-position the teams in the unplayed matches without disturbing the schedule timing.
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return err
+	}
+	_, err = buildEliminationMatchSet(database, 1, 1, len(alliances), getBackupTeams(alliances))
+	return err
+}
+
+// Checks that the given team can be called in as a backup for the given alliance member, returning the alliance
+// selection state and the replaced team's alliance if so.
+func validateBackup(database *model.Database, backupTeamId,
+	replacedTeamId int) (*model.AllianceSelection, []model.AllianceTeam, error) {
+	allianceSelection, err := database.GetAllianceSelection()
+	if err != nil {
+		return nil, nil, err
+	}
+	if allianceSelection == nil {
+		return nil, nil, fmt.Errorf("Can't call a backup team without a backup pool from alliance selection.")
+	}
+	if rankedTeam := allianceSelection.GetRankedTeam(backupTeamId); rankedTeam != nil && rankedTeam.Declined {
+		return nil, nil, fmt.Errorf("Team %d declined an invitation during alliance selection and can't be a backup.",
+			backupTeamId)
+	}
+	isInBackupPool := false
+	for _, team := range allianceSelection.BackupTeams() {
+		if team.TeamId == backupTeamId {
+			isInBackupPool = true
+			break
+		}
+	}
+	if !isInBackupPool {
+		return nil, nil, fmt.Errorf("Team %d is not in the backup pool.", backupTeamId)
+	}
+
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return nil, nil, err
+	}
+	var replacedAlliance []model.AllianceTeam
+	for _, alliance := range alliances {
+		for _, allianceTeam := range alliance {
+			if allianceTeam.TeamId == backupTeamId {
+				return nil, nil, fmt.Errorf("Team %d is already a member of alliance %d.", backupTeamId,
+					allianceTeam.AllianceId)
+			}
+			if allianceTeam.TeamId == replacedTeamId {
+				replacedAlliance = alliance
+			}
+			if allianceTeam.ReplacedTeamId == replacedTeamId {
+				return nil, nil, fmt.Errorf("Team %d has already been replaced by backup team %d.", replacedTeamId,
+					allianceTeam.TeamId)
+			}
+		}
+	}
+	if replacedAlliance == nil {
+		return nil, nil, fmt.Errorf("Team %d is not a member of any alliance.", replacedTeamId)
+	}
+	return allianceSelection, replacedAlliance, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package tournament
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCallBackup(t *testing.T) {
+	database := setupTestDb(t)
+
+	CreateTestAlliances(database, 4)
+	err := CallBackup(database, 5, 10, 0)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Can't call a backup team without a backup pool from alliance selection.", err.Error())
+	}
+	allianceSelection := model.AllianceSelection{RankedTeams: []model.AllianceSelectionTeam{{1, 1, true, false},
+		{2, 5, false, false}, {3, 2, true, false}, {4, 6, false, true}, {5, 10, true, false}, {6, 8, false, false}}}
+	database.SaveAllianceSelection(&allianceSelection)
+	UpdateEliminationSchedule(database, time.Unix(1000, 0))
+	scoreMatch(database, "SF1-1", "R")
+	UpdateEliminationSchedule(database, time.Unix(1000, 0))
+	sf12, _ := database.GetMatchByName("elimination", "SF1-2")
+
+	err = CallBackup(database, 7, 10, sf12.Id)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Team 7 is not in the backup pool.", err.Error())
+	}
+	err = CallBackup(database, 6, 10, sf12.Id)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Team 6 declined an invitation during alliance selection and can't be a backup.", err.Error())
+	}
+	assert.Nil(t, ValidateBackup(database, 5, 10))
+	err = CallBackup(database, 5, 254, sf12.Id)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Team 254 is not a member of any alliance.", err.Error())
+	}
+	assert.Nil(t, CallBackup(database, 5, 10, sf12.Id))
+	err = CallBackup(database, 8, 10, sf12.Id)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Team 10 has already been replaced by backup team 5.", err.Error())
+	}
+	err = CallBackup(database, 5, 20, sf12.Id)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Team 5 is not in the backup pool.", err.Error())
+	}
+
+	// Check the alliance and backup pool records.
+	allianceTeams, _ := database.GetTeamsByAlliance(1)
+	if assert.Equal(t, 4, len(allianceTeams)) {
+		assert.Equal(t, model.AllianceTeam{allianceTeams[3].Id, 1, 3, 5, 10, sf12.Id}, allianceTeams[3])
+	}
+	allianceSelection2, _ := database.GetAllianceSelection()
+	assert.True(t, allianceSelection2.GetRankedTeam(5).Picked)
+
+	// Check that the backup is in the unplayed matches but not the completed one.
+	matches, _ := database.GetMatchesByType("elimination")
+	if assert.Equal(t, 6, len(matches)) {
+		assert.Equal(t, []int{10, 1, 100}, []int{matches[0].Red1, matches[0].Red2, matches[0].Red3})
+		assert.Equal(t, []int{5, 1, 100}, []int{matches[2].Red1, matches[2].Red2, matches[2].Red3})
+		assert.Equal(t, []int{5, 1, 100}, []int{matches[4].Red1, matches[4].Red2, matches[4].Red3})
+		assert.Equal(t, sf12.Time.Unix(), matches[2].Time.Unix())
+	}
+
+	// Check that the backup carries over into the next round.
+	scoreMatch(database, "SF1-2", "R")
+	scoreMatch(database, "SF2-1", "B")
+	scoreMatch(database, "SF2-2", "B")
+	UpdateEliminationSchedule(database, time.Unix(5000, 0))
+	final, _ := database.GetMatchByName("elimination", "F-1")
+	assert.Equal(t, []int{5, 1, 100}, []int{final.Red1, final.Red2, final.Red3})
+	assert.Equal(t, 3, final.Blue2)
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for building a read-only model of the elimination bracket for reports and displays.
+
+package tournament
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"sort"
+	"strconv"
+)
+
+var elimRoundLongNames = map[int]string{1: "Finals", 2: "Semifinals", 4: "Quarterfinals", 8: "Eighthfinals"}
+
+type Bracket struct {
+	Rounds           []BracketRound
+	WinnerAllianceId int
+}
+
+type BracketRound struct {
+	Round    int
+	Name     string
+	Matchups []*BracketMatchup
+}
+
+type BracketMatchup struct {
+	Round              int
+	Group              int
+	DisplayName        string
+	RedAllianceId      int // Zero if not yet known.
+	BlueAllianceId     int // Zero if not yet known.
+	RedAllianceSource  string
+	BlueAllianceSource string
+	RedAllianceTeams   []int
+	BlueAllianceTeams  []int
+	RedWins            int
+	BlueWins           int
+	NumTies            int
+	WinnerAllianceId   int // Zero if the series is not yet won.
+}
+
+// Returns true if either alliance in the matchup has won the series.
+func (matchup *BracketMatchup) IsComplete() bool {
+	return matchup.WinnerAllianceId > 0
+}
+
+// Returns a short description of the state of the series, e.g. "Red leads 1-0".
+func (matchup *BracketMatchup) SeriesStatus() string {
+	if matchup.RedWins > matchup.BlueWins {
+		if matchup.IsComplete() {
+			return fmt.Sprintf("Red wins %d-%d", matchup.RedWins, matchup.BlueWins)
+		}
+		return fmt.Sprintf("Red leads %d-%d", matchup.RedWins, matchup.BlueWins)
+	} else if matchup.BlueWins > matchup.RedWins {
+		if matchup.IsComplete() {
+			return fmt.Sprintf("Blue wins %d-%d", matchup.BlueWins, matchup.RedWins)
+		}
+		return fmt.Sprintf("Blue leads %d-%d", matchup.BlueWins, matchup.RedWins)
+	} else if matchup.RedWins > 0 {
+		return fmt.Sprintf("Series tied %d-%d", matchup.RedWins, matchup.BlueWins)
+	}
+	return ""
+}
+
+// Builds a model of the full elimination bracket from the alliance selection results and the elimination matches
+// played so far, including placeholders for series whose alliances are not yet known.
+func BuildBracket(database *model.Database) (*Bracket, error) {
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return nil, err
+	}
+	bracket := &Bracket{Rounds: []BracketRound{}}
+	if len(alliances) < 2 {
+		// Alliance selection hasn't happened yet.
+		return bracket, nil
+	}
+
+	allianceTeams := make(map[int][]int)
+	for _, alliance := range alliances {
+		for _, allianceTeam := range alliance {
+			allianceTeams[allianceTeam.AllianceId] = append(allianceTeams[allianceTeam.AllianceId],
+				allianceTeam.TeamId)
+		}
+	}
+
+	matchups := make(map[int][]*BracketMatchup)
+	bracket.WinnerAllianceId, err = buildBracketMatchup(database, 1, 1, len(alliances), allianceTeams, matchups)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order the rounds from the earliest to the finals, and the matchups within each round by group.
+	var rounds []int
+	for round := range matchups {
+		rounds = append(rounds, round)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(rounds)))
+	for _, round := range rounds {
+		roundMatchups := matchups[round]
+		sort.Slice(roundMatchups, func(i, j int) bool { return roundMatchups[i].Group < roundMatchups[j].Group })
+		bracket.Rounds = append(bracket.Rounds,
+			BracketRound{Round: round, Name: elimRoundLongNames[round], Matchups: roundMatchups})
+	}
+
+	return bracket, nil
+}
+
+// Recursively traverses the elimination bracket downwards in the same manner as buildEliminationMatchSet, adding a
+// matchup for each series to the given map. Returns the number of the alliance that won the series, or zero if the
+// series is not yet won.
+func buildBracketMatchup(database *model.Database, round int, group int, numAlliances int,
+	allianceTeams map[int][]int, matchups map[int][]*BracketMatchup) (int, error) {
+	roundName, ok := model.ElimRoundNames[round]
+	if !ok {
+		return 0, fmt.Errorf("Round of depth %d is not supported", round*2)
+	}
+	matchup := BracketMatchup{Round: round, Group: group, DisplayName: roundName}
+	if round != 1 {
+		matchup.DisplayName += strconv.Itoa(group)
+	}
+
+	var redFromSelection, blueFromSelection bool
+	if numAlliances < 4*round {
+		matchupNumbers := []int{1, 16, 8, 9, 4, 13, 5, 12, 2, 15, 7, 10, 3, 14, 6, 11}
+		factor := len(matchupNumbers) / round
+		redAllianceNumber := matchupNumbers[(group-1)*factor]
+		blueAllianceNumber := matchupNumbers[(group-1)*factor+factor/2]
+		numDirectAlliances := 4*round - numAlliances
+		if redAllianceNumber <= numDirectAlliances {
+			matchup.RedAllianceId = redAllianceNumber
+			redFromSelection = true
+		}
+		if blueAllianceNumber <= numDirectAlliances {
+			matchup.BlueAllianceId = blueAllianceNumber
+			blueFromSelection = true
+		}
+	}
+
+	var err error
+	if !redFromSelection {
+		matchup.RedAllianceId, err = buildBracketMatchup(database, round*2, group*2-1, numAlliances, allianceTeams,
+			matchups)
+		if err != nil {
+			return 0, err
+		}
+		matchup.RedAllianceSource = fmt.Sprintf("Winner of %s%d", model.ElimRoundNames[round*2], group*2-1)
+	}
+	if !blueFromSelection {
+		matchup.BlueAllianceId, err = buildBracketMatchup(database, round*2, group*2, numAlliances, allianceTeams,
+			matchups)
+		if err != nil {
+			return 0, err
+		}
+		matchup.BlueAllianceSource = fmt.Sprintf("Winner of %s%d", model.ElimRoundNames[round*2], group*2)
+	}
+	matchup.RedAllianceTeams = allianceTeams[matchup.RedAllianceId]
+	matchup.BlueAllianceTeams = allianceTeams[matchup.BlueAllianceId]
+
+	matches, err := database.GetMatchesByElimRoundGroup(round, group)
+	if err != nil {
+		return 0, err
+	}
+	for _, match := range matches {
+		if match.Status != "complete" {
+			continue
+		}
+		switch match.Winner {
+		case "R":
+			matchup.RedWins++
+		case "B":
+			matchup.BlueWins++
+		case "T":
+			matchup.NumTies++
+		}
+	}
+	if matchup.RedWins >= 2 {
+		matchup.WinnerAllianceId = matchup.RedAllianceId
+	} else if matchup.BlueWins >= 2 {
+		matchup.WinnerAllianceId = matchup.BlueAllianceId
+	}
+
+	matchups[round] = append(matchups[round], &matchup)
+	return matchup.WinnerAllianceId, nil
+}
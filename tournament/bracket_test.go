@@ -0,0 +1,94 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package tournament
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBracketBeforeAllianceSelection(t *testing.T) {
+	database := setupTestDb(t)
+
+	bracket, err := BuildBracket(database)
+	assert.Nil(t, err)
+	assert.Empty(t, bracket.Rounds)
+	assert.Equal(t, 0, bracket.WinnerAllianceId)
+}
+
+func TestBracketWithByes(t *testing.T) {
+	database := setupTestDb(t)
+
+	CreateTestAlliances(database, 5)
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	bracket, err := BuildBracket(database)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(bracket.Rounds)) {
+		assert.Equal(t, "Quarterfinals", bracket.Rounds[0].Name)
+		if assert.Equal(t, 1, len(bracket.Rounds[0].Matchups)) {
+			assertBracketMatchup(t, bracket.Rounds[0].Matchups[0], "QF2", 4, 5, 0)
+		}
+		assert.Equal(t, "Semifinals", bracket.Rounds[1].Name)
+		if assert.Equal(t, 2, len(bracket.Rounds[1].Matchups)) {
+			assertBracketMatchup(t, bracket.Rounds[1].Matchups[0], "SF1", 1, 0, 0)
+			assert.Equal(t, "", bracket.Rounds[1].Matchups[0].RedAllianceSource)
+			assert.Equal(t, "Winner of QF2", bracket.Rounds[1].Matchups[0].BlueAllianceSource)
+			assertBracketMatchup(t, bracket.Rounds[1].Matchups[1], "SF2", 2, 3, 0)
+		}
+		assert.Equal(t, "Finals", bracket.Rounds[2].Name)
+		if assert.Equal(t, 1, len(bracket.Rounds[2].Matchups)) {
+			assertBracketMatchup(t, bracket.Rounds[2].Matchups[0], "F", 0, 0, 0)
+			assert.Equal(t, "Winner of SF1", bracket.Rounds[2].Matchups[0].RedAllianceSource)
+			assert.Equal(t, "Winner of SF2", bracket.Rounds[2].Matchups[0].BlueAllianceSource)
+		}
+	}
+	assert.Equal(t, []int{1, 10, 100}, bracket.Rounds[1].Matchups[0].RedAllianceTeams)
+	assert.Nil(t, bracket.Rounds[1].Matchups[0].BlueAllianceTeams)
+}
+
+func TestBracketProgression(t *testing.T) {
+	database := setupTestDb(t)
+
+	CreateTestAlliances(database, 4)
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	scoreMatch(database, "SF1-1", "B")
+	scoreMatch(database, "SF2-1", "T")
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	bracket, _ := BuildBracket(database)
+	sf1 := bracket.Rounds[0].Matchups[0]
+	assert.Equal(t, 1, sf1.BlueWins)
+	assert.Equal(t, "Blue leads 1-0", sf1.SeriesStatus())
+	assert.Equal(t, 1, bracket.Rounds[0].Matchups[1].NumTies)
+	assert.Equal(t, "", bracket.Rounds[0].Matchups[1].SeriesStatus())
+
+	scoreMatch(database, "SF1-2", "B")
+	scoreMatch(database, "SF2-2", "R")
+	scoreMatch(database, "SF2-3", "B")
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	bracket, _ = BuildBracket(database)
+	assertBracketMatchup(t, bracket.Rounds[0].Matchups[0], "SF1", 1, 4, 4)
+	assert.Equal(t, "Blue wins 2-0", bracket.Rounds[0].Matchups[0].SeriesStatus())
+	assertBracketMatchup(t, bracket.Rounds[0].Matchups[1], "SF2", 2, 3, 0)
+	assert.Equal(t, "Series tied 1-1", bracket.Rounds[0].Matchups[1].SeriesStatus())
+	assertBracketMatchup(t, bracket.Rounds[1].Matchups[0], "F", 4, 0, 0)
+
+	scoreMatch(database, "SF2-4", "R")
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	scoreMatch(database, "F-1", "R")
+	scoreMatch(database, "F-2", "R")
+	UpdateEliminationSchedule(database, time.Unix(0, 0))
+	bracket, _ = BuildBracket(database)
+	assertBracketMatchup(t, bracket.Rounds[1].Matchups[0], "F", 4, 2, 4)
+	assert.True(t, bracket.Rounds[1].Matchups[0].IsComplete())
+	assert.Equal(t, 4, bracket.WinnerAllianceId)
+}
+
+func assertBracketMatchup(t *testing.T, matchup *BracketMatchup, displayName string, redAllianceId,
+	blueAllianceId, winnerAllianceId int) {
+	assert.Equal(t, displayName, matchup.DisplayName)
+	assert.Equal(t, redAllianceId, matchup.RedAllianceId)
+	assert.Equal(t, blueAllianceId, matchup.BlueAllianceId)
+	assert.Equal(t, winnerAllianceId, matchup.WinnerAllianceId)
+}
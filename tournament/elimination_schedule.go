@@ -21,7 +21,7 @@ func UpdateEliminationSchedule(database *model.Database, startTime time.Time) (b
 	if err != nil {
 		return false, err
 	}
-	winner, err := buildEliminationMatchSet(database, 1, 1, len(alliances))
+	winner, err := buildEliminationMatchSet(database, 1, 1, len(alliances), getBackupTeams(alliances))
 	if err != nil {
 		return false, err
 	}
@@ -45,8 +45,10 @@ func UpdateEliminationSchedule(database *model.Database, startTime time.Time) (b
 }
 
 // Recursively traverses the elimination bracket downwards, creating matches as necessary. Returns the winner
-// of the given round if known.
-func buildEliminationMatchSet(database *model.Database, round int, group int, numAlliances int) ([]int, error) {
+// of the given round if known. The given map of replaced team to backup team is used to keep any backup teams that
+// have been called in playing in place of the teams they replaced.
+func buildEliminationMatchSet(database *model.Database, round int, group int, numAlliances int,
+	backupTeams map[int]int) ([]int, error) {
 	if numAlliances < 2 {
 		return []int{}, fmt.Errorf("Must have at least 2 alliances")
 	}
@@ -83,6 +85,7 @@ func buildEliminationMatchSet(database *model.Database, round int, group int, nu
 				// Swap the teams around to match the positions dictated by the rules.
 				redAlliance[0], redAlliance[1], redAlliance[2] = redAlliance[1], redAlliance[0], redAlliance[2]
 			}
+			positionBackupTeams(redAlliance, backupTeams)
 		}
 		if blueAllianceNumber <= numDirectAlliances {
 			// The blue alliance has a bye or the number of alliances is a power of 2; get from alliance selection.
@@ -98,18 +101,19 @@ func buildEliminationMatchSet(database *model.Database, round int, group int, nu
 				// Swap the teams around to match the positions dictated by the rules.
 				blueAlliance[0], blueAlliance[1], blueAlliance[2] = blueAlliance[1], blueAlliance[0], blueAlliance[2]
 			}
+			positionBackupTeams(blueAlliance, backupTeams)
 		}
 	}
 
 	// If the alliances aren't known yet, get them from one round down in the bracket.
 	if len(redAlliance) == 0 {
-		redAlliance, err = buildEliminationMatchSet(database, round*2, group*2-1, numAlliances)
+		redAlliance, err = buildEliminationMatchSet(database, round*2, group*2-1, numAlliances, backupTeams)
 		if err != nil {
 			return []int{}, err
 		}
 	}
 	if len(blueAlliance) == 0 {
-		blueAlliance, err = buildEliminationMatchSet(database, round*2, group*2, numAlliances)
+		blueAlliance, err = buildEliminationMatchSet(database, round*2, group*2, numAlliances, backupTeams)
 		if err != nil {
 			return []int{}, err
 		}
@@ -156,6 +160,8 @@ func buildEliminationMatchSet(database *model.Database, round int, group int, nu
 		if err != nil {
 			return []int{}, err
 		}
+		positionBackupTeams(redAlliance, backupTeams)
+		positionBackupTeams(blueAlliance, backupTeams)
 
 		// Check who won.
 		switch match.Winner {
@@ -275,3 +281,33 @@ func reorderTeams(team1, team2, team3 int, alliance []int) error {
 
 	return nil
 }
+
+// Returns a map of replaced team to the backup team that was called in to replace it.
+func getBackupTeams(alliances [][]model.AllianceTeam) map[int]int {
+	backupTeams := make(map[int]int)
+	for _, alliance := range alliances {
+		for _, allianceTeam := range alliance {
+			if allianceTeam.IsBackup() {
+				backupTeams[allianceTeam.ReplacedTeamId] = allianceTeam.TeamId
+			}
+		}
+	}
+	return backupTeams
+}
+
+// Swaps any backup teams in the alliance into the positions of the teams they replaced, so that the replaced teams
+// don't play in any further matches.
+func positionBackupTeams(alliance []int, backupTeams map[int]int) {
+	for i, team := range alliance {
+		backupTeam, ok := backupTeams[team]
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < len(alliance); j++ {
+			if alliance[j] == backupTeam {
+				alliance[i], alliance[j] = alliance[j], alliance[i]
+				break
+			}
+		}
+	}
+}
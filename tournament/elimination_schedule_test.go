@@ -415,10 +415,10 @@ func TestEliminationScheduleErrors(t *testing.T) {
 	}
 	database.TruncateAllianceTeams()
 
-	database.CreateAllianceTeam(&model.AllianceTeam{0, 1, 0, 1})
-	database.CreateAllianceTeam(&model.AllianceTeam{0, 1, 1, 2})
-	database.CreateAllianceTeam(&model.AllianceTeam{0, 2, 0, 3})
-	database.CreateAllianceTeam(&model.AllianceTeam{0, 2, 1, 4})
+	database.CreateAllianceTeam(&model.AllianceTeam{0, 1, 0, 1, 0, 0})
+	database.CreateAllianceTeam(&model.AllianceTeam{0, 1, 1, 2, 0, 0})
+	database.CreateAllianceTeam(&model.AllianceTeam{0, 2, 0, 3, 0, 0})
+	database.CreateAllianceTeam(&model.AllianceTeam{0, 2, 1, 4, 0, 0})
 	_, err = UpdateEliminationSchedule(database, time.Unix(0, 0))
 	if assert.NotNil(t, err) {
 		assert.Equal(t, "Alliances must consist of at least 3 teams", err.Error())
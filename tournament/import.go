@@ -0,0 +1,384 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for importing team lists, schedules and alliances from files prepared outside of Cheesy Arena.
+
+package tournament
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time formats accepted in imported schedules, including the one used by the schedule CSV report.
+var importTimeFormats = []string{"2006-01-02 15:04:05 -0700 MST", time.RFC3339, "2006-01-02T15:04:05",
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "1/2/2006 15:04", "1/2/2006 3:04 PM"}
+
+var nonAlphanumericRe = regexp.MustCompile("[^a-z0-9]")
+
+// Problem with a single row of an imported file.
+type ImportError struct {
+	Row     int // Line number within the file, or zero if the problem isn't specific to one row.
+	Message string
+}
+
+// All of the problems found in an imported file, which is rejected as a whole if there are any.
+type ImportErrors []ImportError
+
+func (importError ImportError) Error() string {
+	if importError.Row == 0 {
+		return importError.Message
+	}
+	return fmt.Sprintf("Row %d: %s", importError.Row, importError.Message)
+}
+
+func (importErrors ImportErrors) Error() string {
+	messages := make([]string, len(importErrors))
+	for i, importError := range importErrors {
+		messages[i] = importError.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Parses a CSV file of teams with a header row naming the columns. Only the team number column is required; the
+// others may be omitted or given in any order.
+func ParseTeamsCsv(reader io.Reader) ([]model.Team, error) {
+	header, rows, err := readImportCsv(reader)
+	if err != nil {
+		return nil, err
+	}
+	numberColumn := header.find("number", "team", "teamnumber")
+	if numberColumn < 0 {
+		return nil, ImportErrors{{1, "Missing team number column."}}
+	}
+	wpaKeyColumn := header.find("wpakey")
+
+	var teams []model.Team
+	var importErrors ImportErrors
+	rowsByTeam := make(map[int]int)
+	for _, row := range rows {
+		rowNumber := row.number
+		teamId, err := strconv.Atoi(row.get(numberColumn))
+		if err != nil || teamId <= 0 {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Invalid team number '%s'.", row.get(numberColumn))})
+			continue
+		}
+		if previousRow, ok := rowsByTeam[teamId]; ok {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Team %d is already listed in row %d.", teamId, previousRow)})
+			continue
+		}
+		rowsByTeam[teamId] = rowNumber
+
+		team := model.Team{Id: teamId, Name: row.get(header.find("name")), Nickname: row.get(header.find("nickname")),
+			City: row.get(header.find("city")), StateProv: row.get(header.find("stateprov", "state")),
+			Country: row.get(header.find("country")), RobotName: row.get(header.find("robotname")),
+			WpaKey: row.get(wpaKeyColumn)}
+		if rookieYear := row.get(header.find("rookieyear")); rookieYear != "" {
+			if team.RookieYear, err = strconv.Atoi(rookieYear); err != nil {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Invalid rookie year '%s'.", rookieYear)})
+				continue
+			}
+		}
+		if team.WpaKey != "" && (len(team.WpaKey) < 8 || len(team.WpaKey) > 63) {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				"WPA key must be between 8 and 63 characters."})
+			continue
+		}
+		teams = append(teams, team)
+	}
+	if len(importErrors) > 0 {
+		return nil, importErrors
+	}
+	return teams, nil
+}
+
+// Parses a CSV file of matches of the given type in the format produced by the schedule report. The surrogate and
+// type columns are optional.
+func ParseScheduleCsv(reader io.Reader, database *model.Database, matchType string) ([]model.Match, error) {
+	header, rows, err := readImportCsv(reader)
+	if err != nil {
+		return nil, err
+	}
+	stations := []string{"red1", "red2", "red3", "blue1", "blue2", "blue3"}
+	requiredColumns := append([]string{"match", "time"}, stations...)
+	var importErrors ImportErrors
+	for _, column := range requiredColumns {
+		if header.find(column) < 0 {
+			importErrors = append(importErrors, ImportError{1, fmt.Sprintf("Missing %s column.", column)})
+		}
+	}
+	if len(importErrors) > 0 {
+		return nil, importErrors
+	}
+	typeColumn := header.find("type")
+
+	matches := make([]model.Match, len(rows))
+	rowNumbers := make([]int, len(rows))
+	for i, row := range rows {
+		rowNumbers[i] = row.number
+		rowNumber := row.number
+		match := &matches[i]
+		match.Type = matchType
+		match.DisplayName = row.get(header.find("match"))
+		if rowType := strings.ToLower(row.get(typeColumn)); rowType != "" && rowType != matchType {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Match type '%s' doesn't match the %s schedule being imported.", rowType, matchType)})
+		}
+		match.Time = parseImportTime(row.get(header.find("time")))
+
+		teams := []*int{&match.Red1, &match.Red2, &match.Red3, &match.Blue1, &match.Blue2, &match.Blue3}
+		surrogates := []*bool{&match.Red1IsSurrogate, &match.Red2IsSurrogate, &match.Red3IsSurrogate,
+			&match.Blue1IsSurrogate, &match.Blue2IsSurrogate, &match.Blue3IsSurrogate}
+		for j, station := range stations {
+			teamText := row.get(header.find(station))
+			*teams[j], err = strconv.Atoi(teamText)
+			if err != nil {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Invalid team number '%s' in %s column.", teamText, station)})
+			}
+			if surrogateText := row.get(header.find(station + "issurrogate")); surrogateText != "" {
+				*surrogates[j], err = strconv.ParseBool(surrogateText)
+				if err != nil {
+					importErrors = append(importErrors, ImportError{rowNumber,
+						fmt.Sprintf("Invalid surrogate value '%s' in %sIsSurrogate column.", surrogateText, station)})
+				}
+			}
+		}
+	}
+	if len(importErrors) > 0 {
+		return nil, importErrors
+	}
+	if err = validateImportedSchedule(database, matches, rowNumbers); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// Checks that the given imported matches are complete, uniquely named and contain only teams in the team list. The
+// first match is reported as being in the given row of the file.
+func ValidateImportedSchedule(database *model.Database, matches []model.Match, firstRow int) error {
+	rowNumbers := make([]int, len(matches))
+	for i := range matches {
+		rowNumbers[i] = firstRow + i
+	}
+	return validateImportedSchedule(database, matches, rowNumbers)
+}
+
+// Checks the given imported matches as above, reporting each match as being in the corresponding row of the file.
+func validateImportedSchedule(database *model.Database, matches []model.Match, rowNumbers []int) error {
+	var importErrors ImportErrors
+	if len(matches) == 0 {
+		return ImportErrors{{0, "The schedule doesn't contain any matches."}}
+	}
+	rowsByName := make(map[string]int)
+	teamExists := make(map[int]bool)
+	for i, match := range matches {
+		rowNumber := rowNumbers[i]
+		if match.DisplayName == "" {
+			importErrors = append(importErrors, ImportError{rowNumber, "Missing match name."})
+		} else if previousRow, ok := rowsByName[match.DisplayName]; ok {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Match %s is already listed in row %d.", match.DisplayName, previousRow)})
+		} else {
+			rowsByName[match.DisplayName] = rowNumber
+		}
+		if match.Time.IsZero() {
+			importErrors = append(importErrors, ImportError{rowNumber, "Missing or invalid match time."})
+		}
+
+		teamsInMatch := make(map[int]bool)
+		for _, teamId := range []int{match.Red1, match.Red2, match.Red3, match.Blue1, match.Blue2, match.Blue3} {
+			if teamId <= 0 {
+				importErrors = append(importErrors, ImportError{rowNumber, "All six team positions must be filled."})
+				continue
+			}
+			if teamsInMatch[teamId] {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Team %d appears more than once in the match.", teamId)})
+			}
+			teamsInMatch[teamId] = true
+			exists, err := checkTeamExists(database, teamExists, teamId)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Team %d isn't in the team list.", teamId)})
+			}
+		}
+	}
+	if len(importErrors) > 0 {
+		return importErrors
+	}
+	return nil
+}
+
+// Parses a CSV file of alliance selection results, with one row per alliance giving the alliance number followed by
+// its teams in pick order. Every alliance must be present and completely filled.
+func ParseAlliancesCsv(reader io.Reader, database *model.Database, numAlliances,
+	teamsPerAlliance int) ([][]model.AllianceTeam, error) {
+	_, rows, err := readImportCsv(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	alliances := make([][]model.AllianceTeam, numAlliances)
+	var importErrors ImportErrors
+	rowsByTeam := make(map[int]int)
+	teamExists := make(map[int]bool)
+	for _, row := range rows {
+		rowNumber := row.number
+		allianceId, err := strconv.Atoi(row.get(0))
+		if err != nil || allianceId < 1 || allianceId > numAlliances {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Invalid alliance number '%s'; must be between 1 and %d.", row.get(0), numAlliances)})
+			continue
+		}
+		if alliances[allianceId-1] != nil {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Alliance %d is listed more than once.", allianceId)})
+			continue
+		}
+		var teamColumns []string
+		for _, value := range row.fields[1:] {
+			if strings.TrimSpace(value) != "" {
+				teamColumns = append(teamColumns, strings.TrimSpace(value))
+			}
+		}
+		if len(teamColumns) != teamsPerAlliance {
+			importErrors = append(importErrors, ImportError{rowNumber,
+				fmt.Sprintf("Alliance %d has %d teams; expected %d.", allianceId, len(teamColumns), teamsPerAlliance)})
+			continue
+		}
+
+		alliance := make([]model.AllianceTeam, teamsPerAlliance)
+		for j, teamText := range teamColumns {
+			teamId, err := strconv.Atoi(teamText)
+			if err != nil || teamId <= 0 {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Invalid team number '%s'.", teamText)})
+				continue
+			}
+			if previousRow, ok := rowsByTeam[teamId]; ok {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Team %d is already on an alliance in row %d.", teamId, previousRow)})
+				continue
+			}
+			rowsByTeam[teamId] = rowNumber
+			exists, err := checkTeamExists(database, teamExists, teamId)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				importErrors = append(importErrors, ImportError{rowNumber,
+					fmt.Sprintf("Team %d isn't in the team list.", teamId)})
+				continue
+			}
+			alliance[j] = model.AllianceTeam{AllianceId: allianceId, PickPosition: j, TeamId: teamId}
+		}
+		alliances[allianceId-1] = alliance
+	}
+	for i, alliance := range alliances {
+		if alliance == nil {
+			importErrors = append(importErrors, ImportError{0, fmt.Sprintf("Alliance %d is missing.", i+1)})
+		}
+	}
+	if len(importErrors) > 0 {
+		return nil, importErrors
+	}
+	return alliances, nil
+}
+
+type importHeader []string
+
+type importRow struct {
+	number int // Line number within the file.
+	fields []string
+}
+
+// Reads the given CSV file and splits it into its header and the subsequent non-blank rows.
+func readImportCsv(reader io.Reader) (importHeader, []importRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	var header importHeader
+	var rows []importRow
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, ImportErrors{{0, fmt.Sprintf("Could not parse CSV file: %s", err.Error())}}
+		}
+		if header == nil {
+			header = make(importHeader, len(record))
+			for i, column := range record {
+				header[i] = nonAlphanumericRe.ReplaceAllString(strings.ToLower(column), "")
+			}
+			continue
+		}
+
+		// Record the line that the row started on, as blank lines are skipped and quoted values may span lines.
+		if strings.TrimSpace(strings.Join(record, "")) != "" {
+			lineNumber, _ := csvReader.FieldPos(0)
+			rows = append(rows, importRow{number: lineNumber, fields: record})
+		}
+	}
+	if header == nil {
+		return nil, nil, ImportErrors{{0, "The file is empty."}}
+	}
+	return header, rows, nil
+}
+
+// Returns the index of the first column having any of the given normalized names, or -1 if there is none.
+func (header importHeader) find(names ...string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if column == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Returns the trimmed value of the given column, or a blank string if the row doesn't have that column.
+func (row importRow) get(column int) string {
+	if column < 0 || column >= len(row.fields) {
+		return ""
+	}
+	return strings.TrimSpace(row.fields[column])
+}
+
+// Returns the time given in one of the accepted formats, or the zero time if it can't be parsed.
+func parseImportTime(value string) time.Time {
+	for _, format := range importTimeFormats {
+		if parsedTime, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return parsedTime
+		}
+	}
+	return time.Time{}
+}
+
+// Returns whether the given team is in the team list, caching the result in the given map.
+func checkTeamExists(database *model.Database, teamExists map[int]bool, teamId int) (bool, error) {
+	if exists, ok := teamExists[teamId]; ok {
+		return exists, nil
+	}
+	team, err := database.GetTeamById(teamId)
+	if err != nil {
+		return false, err
+	}
+	teamExists[teamId] = team != nil
+	return team != nil, nil
+}
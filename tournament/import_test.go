@@ -0,0 +1,168 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package tournament
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseTeamsCsv(t *testing.T) {
+	teams, err := ParseTeamsCsv(strings.NewReader("Number,Nickname,City,State Prov,Rookie Year,WPA Key,Unknown\n" +
+		"254,The Cheesy Poofs,San Jose,CA,1999,12345678,blorpy\n\n" +
+		"1114,\"Simbotics, Inc.\",,,,,\n"))
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(teams)) {
+		assert.Equal(t, model.Team{Id: 254, Nickname: "The Cheesy Poofs", City: "San Jose", StateProv: "CA",
+			RookieYear: 1999, WpaKey: "12345678"}, teams[0])
+		assert.Equal(t, model.Team{Id: 1114, Nickname: "Simbotics, Inc."}, teams[1])
+	}
+
+	// Check that a file exported from the team list report can be imported.
+	teams, err = ParseTeamsCsv(strings.NewReader("Number,Name,Nickname,City,StateProv,Country,RookieYear," +
+		"RobotName,HasConnected\n846,\"Lynbrook\",\"The Funky Monkeys\",\"San Jose\",\"CA\",\"USA\",2002,\"\",false"))
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(teams)) {
+		assert.Equal(t, "The Funky Monkeys", teams[0].Nickname)
+		assert.Equal(t, "USA", teams[0].Country)
+	}
+}
+
+func TestParseTeamsCsvErrors(t *testing.T) {
+	_, err := ParseTeamsCsv(strings.NewReader("Nickname\nThe Cheesy Poofs"))
+	assert.Equal(t, "Row 1: Missing team number column.", err.Error())
+
+	_, err = ParseTeamsCsv(strings.NewReader(""))
+	assert.Equal(t, "The file is empty.", err.Error())
+
+	_, err = ParseTeamsCsv(strings.NewReader("Team,RookieYear,WpaKey\n254,1999,\nfrc1114,,\n254,,\n" +
+		"846,old,\n1678,,short"))
+	if assert.NotNil(t, err) {
+		importErrors, ok := err.(ImportErrors)
+		if assert.True(t, ok) && assert.Equal(t, 4, len(importErrors)) {
+			assert.Equal(t, ImportError{3, "Invalid team number 'frc1114'."}, importErrors[0])
+			assert.Equal(t, ImportError{4, "Team 254 is already listed in row 2."}, importErrors[1])
+			assert.Equal(t, ImportError{5, "Invalid rookie year 'old'."}, importErrors[2])
+			assert.Equal(t, ImportError{6, "WPA key must be between 8 and 63 characters."}, importErrors[3])
+		}
+	}
+
+	// Check that skipped blank rows are still counted in the row numbers.
+	_, err = ParseTeamsCsv(strings.NewReader("Team,Name\n254,Poofs\n\n,\n\"1114\",\"Simbotics\nRobotics\"\n" +
+		"frc846,"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Row 7: Invalid team number 'frc846'.", err.Error())
+	}
+}
+
+func TestParseScheduleCsv(t *testing.T) {
+	database := setupTestDb(t)
+	for _, teamId := range []int{1, 2, 3, 4, 5, 6, 7} {
+		database.CreateTeam(&model.Team{Id: teamId})
+	}
+
+	matches, err := ParseScheduleCsv(strings.NewReader(
+		"Match,Type,Time,Red1,Red1IsSurrogate,Red2,Red2IsSurrogate,Red3,Red3IsSurrogate,Blue1,Blue1IsSurrogate,"+
+			"Blue2,Blue2IsSurrogate,Blue3,Blue3IsSurrogate\n"+
+			"1,qualification,2018-11-24 09:00:00 -0800 PST,1,false,2,false,3,false,4,false,5,false,6,false\n"+
+			"2,qualification,2018-11-24 09:07:00 -0800 PST,7,false,6,false,5,false,4,false,3,false,1,true\n"),
+		database, "qualification")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, "1", matches[0].DisplayName)
+		assert.Equal(t, "qualification", matches[0].Type)
+		assert.Equal(t, int64(1543078800), matches[0].Time.Unix())
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, []int{matches[0].Red1, matches[0].Red2, matches[0].Red3,
+			matches[0].Blue1, matches[0].Blue2, matches[0].Blue3})
+		assert.Equal(t, 7, matches[1].Red1)
+		assert.True(t, matches[1].Blue3IsSurrogate)
+		assert.False(t, matches[1].Red1IsSurrogate)
+	}
+
+	// Check a minimal file with the columns in a different order.
+	matches, err = ParseScheduleCsv(strings.NewReader("Blue1,Blue2,Blue3,Red1,Red2,Red3,Match,Time\n"+
+		"4,5,6,1,2,3,P1,2018-11-23 13:30\n"), database, "practice")
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(matches)) {
+		assert.Equal(t, "P1", matches[0].DisplayName)
+		assert.Equal(t, "practice", matches[0].Type)
+		assert.Equal(t, 13, matches[0].Time.Hour())
+		assert.Equal(t, 4, matches[0].Blue1)
+	}
+}
+
+func TestParseScheduleCsvErrors(t *testing.T) {
+	database := setupTestDb(t)
+	for _, teamId := range []int{1, 2, 3, 4, 5, 6} {
+		database.CreateTeam(&model.Team{Id: teamId})
+	}
+
+	_, err := ParseScheduleCsv(strings.NewReader("Match,Red1,Red2,Red3,Blue1,Blue2\n"), database, "practice")
+	assert.Equal(t, "Row 1: Missing time column.; Row 1: Missing blue3 column.", err.Error())
+
+	_, err = ParseScheduleCsv(strings.NewReader("Match,Type,Time,Red1,Red2,Red3,Blue1,Blue2,Blue3,Red1IsSurrogate\n"+
+		"1,practice,2018-11-23 13:30,1,2,3,4,5,6,false\n"+
+		"2,qualification,2018-11-23 13:37,1,2,3,4,5,x,maybe\n"), database, "practice")
+	if assert.NotNil(t, err) {
+		importErrors := err.(ImportErrors)
+		if assert.Equal(t, 3, len(importErrors)) {
+			assert.Equal(t, 3, importErrors[0].Row)
+			assert.Contains(t, importErrors[0].Message, "Match type 'qualification'")
+			assert.Equal(t, "Invalid surrogate value 'maybe' in red1IsSurrogate column.", importErrors[1].Message)
+			assert.Equal(t, "Invalid team number 'x' in blue3 column.", importErrors[2].Message)
+		}
+	}
+
+	_, err = ParseScheduleCsv(strings.NewReader("Match,Time,Red1,Red2,Red3,Blue1,Blue2,Blue3\n"+
+		"1,2018-11-23 13:30,1,2,3,4,5,6\n"+
+		"1,tomorrow,1,2,3,4,5,7\n"+
+		",2018-11-23 13:44,1,1,3,4,5,0\n"), database, "practice")
+	if assert.NotNil(t, err) {
+		importErrors := err.(ImportErrors)
+		if assert.Equal(t, 6, len(importErrors)) {
+			assert.Equal(t, ImportError{3, "Match 1 is already listed in row 2."}, importErrors[0])
+			assert.Equal(t, ImportError{3, "Missing or invalid match time."}, importErrors[1])
+			assert.Equal(t, ImportError{3, "Team 7 isn't in the team list."}, importErrors[2])
+			assert.Equal(t, ImportError{4, "Missing match name."}, importErrors[3])
+			assert.Equal(t, ImportError{4, "Team 1 appears more than once in the match."}, importErrors[4])
+			assert.Equal(t, ImportError{4, "All six team positions must be filled."}, importErrors[5])
+		}
+	}
+
+	_, err = ParseScheduleCsv(strings.NewReader("Match,Time,Red1,Red2,Red3,Blue1,Blue2,Blue3\n"), database,
+		"practice")
+	assert.Equal(t, "The schedule doesn't contain any matches.", err.Error())
+}
+
+func TestParseAlliancesCsv(t *testing.T) {
+	database := setupTestDb(t)
+	for _, teamId := range []int{1, 2, 3, 4, 5, 6, 7, 8} {
+		database.CreateTeam(&model.Team{Id: teamId})
+	}
+
+	alliances, err := ParseAlliancesCsv(strings.NewReader("Alliance,Captain,Pick 1,Pick 2\n2,4,5,6\n1,1,2,3\n"),
+		database, 2, 3)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(alliances)) {
+		assert.Equal(t, []model.AllianceTeam{{AllianceId: 1, PickPosition: 0, TeamId: 1},
+			{AllianceId: 1, PickPosition: 1, TeamId: 2}, {AllianceId: 1, PickPosition: 2, TeamId: 3}}, alliances[0])
+		assert.Equal(t, 6, alliances[1][2].TeamId)
+		assert.Equal(t, 2, alliances[1][2].AllianceId)
+	}
+
+	_, err = ParseAlliancesCsv(strings.NewReader("Alliance,Captain,Pick 1,Pick 2\n1,1,2\n1,1,2,3\nx,4,5,6\n"+
+		"2,4,1,9\n"), database, 3, 3)
+	if assert.NotNil(t, err) {
+		importErrors := err.(ImportErrors)
+		if assert.Equal(t, 5, len(importErrors)) {
+			assert.Equal(t, ImportError{2, "Alliance 1 has 2 teams; expected 3."}, importErrors[0])
+			assert.Equal(t, ImportError{4, "Invalid alliance number 'x'; must be between 1 and 3."}, importErrors[1])
+			assert.Equal(t, ImportError{5, "Team 1 is already on an alliance in row 3."}, importErrors[2])
+			assert.Equal(t, ImportError{5, "Team 9 isn't in the team list."}, importErrors[3])
+			assert.Equal(t, ImportError{0, "Alliance 3 is missing."}, importErrors[4])
+		}
+	}
+}
@@ -27,24 +27,8 @@ func CalculateRankings(database *model.Database) error {
 		if err != nil {
 			return err
 		}
-		if !match.Red1IsSurrogate {
-			addMatchResultToRankings(rankings, match.Red1, matchResult, true)
-		}
-		if !match.Red2IsSurrogate {
-			addMatchResultToRankings(rankings, match.Red2, matchResult, true)
-		}
-		if !match.Red3IsSurrogate {
-			addMatchResultToRankings(rankings, match.Red3, matchResult, true)
-		}
-		if !match.Blue1IsSurrogate {
-			addMatchResultToRankings(rankings, match.Blue1, matchResult, false)
-		}
-		if !match.Blue2IsSurrogate {
-			addMatchResultToRankings(rankings, match.Blue2, matchResult, false)
-		}
-		if !match.Blue3IsSurrogate {
-			addMatchResultToRankings(rankings, match.Blue3, matchResult, false)
-		}
+		addMatchToRankings(rankings, &match, matchResult.RedScoreSummary(), matchResult.BlueScoreSummary(),
+			matchResult.RedCards, matchResult.BlueCards)
 	}
 
 	sortedRankings := sortRankings(rankings)
@@ -110,30 +94,40 @@ func CalculateTeamCards(database *model.Database, matchType string) error {
 	return nil
 }
 
-// Incrementally accounts for the given match result in the set of rankings that are being built.
-func addMatchResultToRankings(rankings map[int]*game.Ranking, teamId int, matchResult *model.MatchResult, isRed bool) {
-	ranking := rankings[teamId]
-	if ranking == nil {
-		ranking = &game.Ranking{TeamId: teamId}
-		rankings[teamId] = ranking
-	}
-
-	// Determine whether the team was disqualified.
-	var cards map[string]string
-	if isRed {
-		cards = matchResult.RedCards
-	} else {
-		cards = matchResult.BlueCards
+// Incrementally accounts for the given match outcome in the set of rankings, skipping any surrogate teams.
+func addMatchToRankings(rankings map[int]*game.Ranking, match *model.Match, redSummary,
+	blueSummary *game.ScoreSummary, redCards, blueCards map[string]string) {
+	teams := []struct {
+		teamId      int
+		isSurrogate bool
+		isRed       bool
+	}{
+		{match.Red1, match.Red1IsSurrogate, true},
+		{match.Red2, match.Red2IsSurrogate, true},
+		{match.Red3, match.Red3IsSurrogate, true},
+		{match.Blue1, match.Blue1IsSurrogate, false},
+		{match.Blue2, match.Blue2IsSurrogate, false},
+		{match.Blue3, match.Blue3IsSurrogate, false},
 	}
-	disqualified := false
-	if card, ok := cards[strconv.Itoa(teamId)]; ok && card == "red" {
-		disqualified = true
-	}
-
-	if isRed {
-		ranking.AddScoreSummary(matchResult.RedScoreSummary(), matchResult.BlueScoreSummary(), disqualified)
-	} else {
-		ranking.AddScoreSummary(matchResult.BlueScoreSummary(), matchResult.RedScoreSummary(), disqualified)
+	for _, team := range teams {
+		if team.isSurrogate || team.teamId == 0 {
+			continue
+		}
+		ranking := rankings[team.teamId]
+		if ranking == nil {
+			ranking = &game.Ranking{TeamId: team.teamId}
+			rankings[team.teamId] = ranking
+		}
+		cards := blueCards
+		if team.isRed {
+			cards = redCards
+		}
+		disqualified := cards[strconv.Itoa(team.teamId)] == "red"
+		if team.isRed {
+			ranking.AddScoreSummary(redSummary, blueSummary, disqualified)
+		} else {
+			ranking.AddScoreSummary(blueSummary, redSummary, disqualified)
+		}
 	}
 }
 
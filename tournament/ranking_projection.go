@@ -0,0 +1,422 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for projecting the final qualification rankings by simulating the remaining matches.
+
+package tournament
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/model"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	DefaultProjectionIterations = 1000
+	MaxProjectionIterations     = 10000
+	oprRegularization           = 0.5
+	minScoreStdDev              = 10
+)
+
+// Estimated per-match contribution of a team to its alliance, calculated from the completed qualification matches
+// using an offensive power rating (OPR) least-squares fit.
+type TeamEstimate struct {
+	TeamId          int
+	Opr             float64
+	AutoOpr         float64
+	OwnershipOpr    float64
+	VaultOpr        float64
+	ParkClimbOpr    float64
+	AutoQuestRate   float64
+	FaceTheBossRate float64
+}
+
+// Projected final qualification ranking outcomes for a single team.
+type RankingProjection struct {
+	TeamId             int
+	CurrentRank        int // Zero if the team has not yet played.
+	Opr                float64
+	RankProbabilities  []float64 // The probability of finishing at rank i+1 is at index i.
+	MostLikelyRank     int
+	AverageRank        float64
+	CaptainProbability float64 // The probability of finishing within the alliance captain positions.
+}
+
+// A ranking resulting from applying hypothetical match outcomes to the current rankings.
+type ProjectedRanking struct {
+	game.Ranking
+	PreviousRank int // Zero if the team had not yet played.
+}
+
+type RankingProjections struct {
+	NumIterations        int
+	NumCaptains          int
+	NumRemainingMatches  int
+	Outcomes             map[int]string
+	HypotheticalRankings []ProjectedRanking
+	Teams                []RankingProjection
+}
+
+// Completed and remaining qualification match data used as the basis for a projection.
+type projectionData struct {
+	currentRankings  map[int]*game.Ranking
+	estimates        map[int]*TeamEstimate
+	scoreStdDev      float64
+	remainingMatches []model.Match
+}
+
+// Projects the final qualification rankings by running the given number of Monte Carlo simulations of the remaining
+// matches, using each team's estimated contribution calculated from the completed matches. The given map of match ID
+// to winner ("R", "B" or "T") forces the outcome of the corresponding unplayed matches, for answering "what if"
+// questions.
+func ProjectRankings(database *model.Database, outcomes map[int]string, numIterations, numCaptains int,
+	random *rand.Rand) (*RankingProjections, error) {
+	if numIterations < 1 || numIterations > MaxProjectionIterations {
+		return nil, fmt.Errorf("Number of iterations must be between 1 and %d.", MaxProjectionIterations)
+	}
+	data, err := loadProjectionData(database)
+	if err != nil {
+		return nil, err
+	}
+	for matchId, winner := range outcomes {
+		if winner != "R" && winner != "B" && winner != "T" {
+			return nil, fmt.Errorf("Invalid winner '%s'.", winner)
+		}
+		found := false
+		for _, match := range data.remainingMatches {
+			if match.Id == matchId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Match %d is not an unplayed qualification match.", matchId)
+		}
+	}
+
+	projections := RankingProjections{NumIterations: numIterations, NumCaptains: numCaptains,
+		NumRemainingMatches: len(data.remainingMatches), Outcomes: outcomes}
+
+	// Apply only the given outcomes, using the expected scores, to determine the immediate effect on the rankings.
+	var forcedMatches []model.Match
+	for _, match := range data.remainingMatches {
+		if _, ok := outcomes[match.Id]; ok {
+			forcedMatches = append(forcedMatches, match)
+		}
+	}
+	hypotheticalRankings := data.simulate(forcedMatches, outcomes, nil)
+	for _, ranking := range hypotheticalRankings {
+		projectedRanking := ProjectedRanking{Ranking: *ranking}
+		if currentRanking, ok := data.currentRankings[ranking.TeamId]; ok {
+			projectedRanking.PreviousRank = currentRanking.Rank
+		}
+		projections.HypotheticalRankings = append(projections.HypotheticalRankings, projectedRanking)
+	}
+
+	// Tally up the final ranks across all the simulations.
+	rankCounts := make(map[int][]int)
+	for i := 0; i < numIterations; i++ {
+		rankings := data.simulate(data.remainingMatches, outcomes, random)
+		for _, ranking := range rankings {
+			if _, ok := rankCounts[ranking.TeamId]; !ok {
+				rankCounts[ranking.TeamId] = make([]int, len(rankings))
+			}
+			rankCounts[ranking.TeamId][ranking.Rank-1]++
+		}
+	}
+	for teamId, counts := range rankCounts {
+		projection := RankingProjection{TeamId: teamId, RankProbabilities: make([]float64, len(counts))}
+		if currentRanking, ok := data.currentRankings[teamId]; ok {
+			projection.CurrentRank = currentRanking.Rank
+		}
+		if estimate, ok := data.estimates[teamId]; ok {
+			projection.Opr = estimate.Opr
+		}
+		mostLikelyCount := 0
+		for i, count := range counts {
+			probability := float64(count) / float64(numIterations)
+			projection.RankProbabilities[i] = probability
+			projection.AverageRank += float64(i+1) * probability
+			if i < numCaptains {
+				projection.CaptainProbability += probability
+			}
+			if count > mostLikelyCount {
+				mostLikelyCount = count
+				projection.MostLikelyRank = i + 1
+			}
+		}
+		projections.Teams = append(projections.Teams, projection)
+	}
+	sort.Slice(projections.Teams, func(i, j int) bool {
+		if projections.Teams[i].AverageRank == projections.Teams[j].AverageRank {
+			return projections.Teams[i].TeamId < projections.Teams[j].TeamId
+		}
+		return projections.Teams[i].AverageRank < projections.Teams[j].AverageRank
+	})
+
+	return &projections, nil
+}
+
+// Loads the qualification matches and results and builds the current rankings and team estimates from them.
+func loadProjectionData(database *model.Database) (*projectionData, error) {
+	matches, err := database.GetMatchesByType("qualification")
+	if err != nil {
+		return nil, err
+	}
+	data := projectionData{currentRankings: make(map[int]*game.Ranking)}
+	var completedMatches []model.Match
+	matchResults := make(map[int]*model.MatchResult)
+	for _, match := range matches {
+		if match.Status != "complete" {
+			data.remainingMatches = append(data.remainingMatches, match)
+			continue
+		}
+		matchResult, err := database.GetMatchResultForMatch(match.Id)
+		if err != nil {
+			return nil, err
+		}
+		if matchResult == nil {
+			continue
+		}
+		completedMatches = append(completedMatches, match)
+		matchResults[match.Id] = matchResult
+		addMatchToRankings(data.currentRankings, &match, matchResult.RedScoreSummary(),
+			matchResult.BlueScoreSummary(), matchResult.RedCards, matchResult.BlueCards)
+	}
+	for rank, ranking := range sortRankings(data.currentRankings) {
+		ranking.Rank = rank + 1
+	}
+	data.estimates, data.scoreStdDev = calculateTeamEstimates(matches, completedMatches, matchResults)
+	return &data, nil
+}
+
+// Runs a single simulation of the given matches on top of the current rankings and returns the resulting sorted
+// rankings. Scores are drawn from a normal distribution around the expected alliance score if a random source is
+// given, and otherwise are set to the expected score. Tiebreaker point totals are projected at their expected values
+// and follow the score when an outcome is forced, so the forced winner is credited with the winning alliance's totals.
+func (data *projectionData) simulate(matches []model.Match, outcomes map[int]string,
+	random *rand.Rand) game.Rankings {
+	rankings := make(map[int]*game.Ranking, len(data.currentRankings))
+	for teamId, ranking := range data.currentRankings {
+		rankingCopy := *ranking
+		rankings[teamId] = &rankingCopy
+	}
+
+	for i := range matches {
+		match := &matches[i]
+		redSummary := data.simulateAlliance([]int{match.Red1, match.Red2, match.Red3}, random)
+		blueSummary := data.simulateAlliance([]int{match.Blue1, match.Blue2, match.Blue3}, random)
+		switch outcomes[match.Id] {
+		case "R":
+			if redSummary.Score <= blueSummary.Score {
+				*redSummary, *blueSummary = *blueSummary, *redSummary
+				redSummary.Score = int(math.Max(float64(redSummary.Score), float64(blueSummary.Score+1)))
+			}
+		case "B":
+			if blueSummary.Score <= redSummary.Score {
+				*redSummary, *blueSummary = *blueSummary, *redSummary
+				blueSummary.Score = int(math.Max(float64(blueSummary.Score), float64(redSummary.Score+1)))
+			}
+		case "T":
+			*blueSummary = *redSummary
+		}
+		addMatchToRankings(rankings, match, redSummary, blueSummary, nil, nil)
+	}
+
+	sortedRankings := sortRankings(rankings)
+	for rank, ranking := range sortedRankings {
+		ranking.Rank = rank + 1
+	}
+	return sortedRankings
+}
+
+// Returns a score summary for the given alliance based on the estimated contributions of its teams.
+func (data *projectionData) simulateAlliance(teamIds []int, random *rand.Rand) *game.ScoreSummary {
+	var score, auto, ownership, vault, parkClimb, autoQuestRate, faceTheBossRate float64
+	for _, teamId := range teamIds {
+		if estimate, ok := data.estimates[teamId]; ok {
+			score += estimate.Opr
+			auto += estimate.AutoOpr
+			ownership += estimate.OwnershipOpr
+			vault += estimate.VaultOpr
+			parkClimb += estimate.ParkClimbOpr
+			autoQuestRate += estimate.AutoQuestRate / float64(len(teamIds))
+			faceTheBossRate += estimate.FaceTheBossRate / float64(len(teamIds))
+		}
+	}
+
+	summary := game.ScoreSummary{AutoPoints: roundPoints(auto), OwnershipPoints: roundPoints(ownership),
+		VaultPoints: roundPoints(vault), ParkClimbPoints: roundPoints(parkClimb)}
+	if random == nil {
+		summary.Score = roundPoints(score)
+		summary.AutoQuest = autoQuestRate >= 0.5
+		summary.FaceTheBoss = faceTheBossRate >= 0.5
+	} else {
+		summary.Score = roundPoints(score + random.NormFloat64()*data.scoreStdDev)
+		summary.AutoQuest = random.Float64() < autoQuestRate
+		summary.FaceTheBoss = random.Float64() < faceTheBossRate
+	}
+	return &summary
+}
+
+// Calculates each team's estimated contribution to its alliance using a regularized least-squares fit over all
+// alliance appearances in the completed matches. Teams with little or no data are pulled towards the average
+// contribution. Also returns the standard deviation of the actual alliance scores from the fitted values.
+func calculateTeamEstimates(allMatches, completedMatches []model.Match,
+	matchResults map[int]*model.MatchResult) (map[int]*TeamEstimate, float64) {
+	// Assign an index to every team that appears in the schedule.
+	teamIndices := make(map[int]int)
+	var teamIds []int
+	for _, match := range allMatches {
+		for _, teamId := range []int{match.Red1, match.Red2, match.Red3, match.Blue1, match.Blue2, match.Blue3} {
+			if _, ok := teamIndices[teamId]; !ok && teamId != 0 {
+				teamIndices[teamId] = len(teamIds)
+				teamIds = append(teamIds, teamId)
+			}
+		}
+	}
+	numTeams := len(teamIds)
+
+	// Build up the alliance appearances, with one observation column for each estimated quantity.
+	const numColumns = 5
+	var allianceTeams [][]int
+	var observations [][numColumns]float64
+	autoQuests := make([]float64, numTeams)
+	faceTheBosses := make([]float64, numTeams)
+	appearances := make([]float64, numTeams)
+	for _, match := range completedMatches {
+		matchResult := matchResults[match.Id]
+		alliances := []struct {
+			teams   []int
+			summary *game.ScoreSummary
+		}{
+			{[]int{match.Red1, match.Red2, match.Red3}, matchResult.RedScoreSummary()},
+			{[]int{match.Blue1, match.Blue2, match.Blue3}, matchResult.BlueScoreSummary()},
+		}
+		for _, alliance := range alliances {
+			var indices []int
+			for _, teamId := range alliance.teams {
+				if index, ok := teamIndices[teamId]; ok {
+					indices = append(indices, index)
+					appearances[index]++
+					if alliance.summary.AutoQuest {
+						autoQuests[index]++
+					}
+					if alliance.summary.FaceTheBoss {
+						faceTheBosses[index]++
+					}
+				}
+			}
+			if len(indices) == 0 {
+				continue
+			}
+			allianceTeams = append(allianceTeams, indices)
+			observations = append(observations, [numColumns]float64{float64(alliance.summary.Score),
+				float64(alliance.summary.AutoPoints), float64(alliance.summary.OwnershipPoints),
+				float64(alliance.summary.VaultPoints), float64(alliance.summary.ParkClimbPoints)})
+		}
+	}
+
+	// Determine the average contribution per team, to be used as the baseline for the fit.
+	var averages [numColumns]float64
+	for i, observation := range observations {
+		for column := range observation {
+			averages[column] += observation[column] / float64(len(allianceTeams[i])) / float64(len(observations))
+		}
+	}
+
+	// Solve the regularized normal equations (AᵀA + λI)x = Aᵀb for the deviations from the average.
+	matrix := make([][]float64, numTeams)
+	rhs := make([][]float64, numTeams)
+	for i := range matrix {
+		matrix[i] = make([]float64, numTeams)
+		matrix[i][i] = oprRegularization
+		rhs[i] = make([]float64, numColumns)
+	}
+	for i, indices := range allianceTeams {
+		for _, row := range indices {
+			for _, column := range indices {
+				matrix[row][column]++
+			}
+			for column := 0; column < numColumns; column++ {
+				rhs[row][column] += observations[i][column] - averages[column]*float64(len(indices))
+			}
+		}
+	}
+	solution := solveLinearSystem(matrix, rhs)
+
+	estimates := make(map[int]*TeamEstimate, numTeams)
+	for index, teamId := range teamIds {
+		estimate := TeamEstimate{TeamId: teamId, Opr: averages[0] + solution[index][0],
+			AutoOpr: averages[1] + solution[index][1], OwnershipOpr: averages[2] + solution[index][2],
+			VaultOpr: averages[3] + solution[index][3], ParkClimbOpr: averages[4] + solution[index][4]}
+		if appearances[index] > 0 {
+			estimate.AutoQuestRate = autoQuests[index] / appearances[index]
+			estimate.FaceTheBossRate = faceTheBosses[index] / appearances[index]
+		}
+		estimates[teamId] = &estimate
+	}
+
+	// Calculate the spread of the actual scores around the fitted ones.
+	var sumSquaredError float64
+	for i, indices := range allianceTeams {
+		predicted := 0.0
+		for _, index := range indices {
+			predicted += estimates[teamIds[index]].Opr
+		}
+		sumSquaredError += math.Pow(observations[i][0]-predicted, 2)
+	}
+	scoreStdDev := float64(minScoreStdDev)
+	if len(observations) > 0 {
+		scoreStdDev = math.Max(scoreStdDev, math.Sqrt(sumSquaredError/float64(len(observations))))
+	}
+
+	return estimates, scoreStdDev
+}
+
+// Solves the given linear system for each column of the right-hand side using Gaussian elimination with partial
+// pivoting. The matrix is assumed to be non-singular; both arguments are modified in place.
+func solveLinearSystem(matrix [][]float64, rhs [][]float64) [][]float64 {
+	size := len(matrix)
+	for pivot := 0; pivot < size; pivot++ {
+		maxRow := pivot
+		for row := pivot + 1; row < size; row++ {
+			if math.Abs(matrix[row][pivot]) > math.Abs(matrix[maxRow][pivot]) {
+				maxRow = row
+			}
+		}
+		matrix[pivot], matrix[maxRow] = matrix[maxRow], matrix[pivot]
+		rhs[pivot], rhs[maxRow] = rhs[maxRow], rhs[pivot]
+
+		for row := pivot + 1; row < size; row++ {
+			factor := matrix[row][pivot] / matrix[pivot][pivot]
+			for column := pivot; column < size; column++ {
+				matrix[row][column] -= factor * matrix[pivot][column]
+			}
+			for column := range rhs[row] {
+				rhs[row][column] -= factor * rhs[pivot][column]
+			}
+		}
+	}
+
+	solution := make([][]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		solution[row] = make([]float64, len(rhs[row]))
+		for column := range rhs[row] {
+			sum := rhs[row][column]
+			for k := row + 1; k < size; k++ {
+				sum -= matrix[row][k] * solution[k][column]
+			}
+			solution[row][column] = sum / matrix[row][row]
+		}
+	}
+	return solution
+}
+
+// Rounds the given projected point total to the nearest non-negative integer.
+func roundPoints(points float64) int {
+	return int(math.Max(0, math.Round(points)))
+}
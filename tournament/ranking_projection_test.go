@@ -0,0 +1,172 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package tournament
+
+import (
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestProjectRankings(t *testing.T) {
+	database := setupTestDb(t)
+
+	setupMatchResultsForRankings(database)
+	CalculateRankings(database)
+	projections, err := ProjectRankings(database, nil, 200, 8, rand.New(rand.NewSource(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, projections.NumIterations)
+	assert.Equal(t, 1, projections.NumRemainingMatches)
+	if assert.Equal(t, 12, len(projections.Teams)) {
+		for _, projection := range projections.Teams {
+			totalProbability := 0.0
+			for _, probability := range projection.RankProbabilities {
+				totalProbability += probability
+			}
+			assert.InDelta(t, 1.0, totalProbability, 0.0001)
+			assert.True(t, projection.CaptainProbability >= 0 && projection.CaptainProbability <= 1.0001)
+			assert.True(t, projection.MostLikelyRank >= 1 && projection.MostLikelyRank <= 12)
+		}
+	}
+
+	// Check that the current rankings are reported for the teams that have played.
+	rankings, _ := database.GetAllRankings()
+	for _, projection := range projections.Teams {
+		if projection.TeamId > 6 {
+			assert.Equal(t, 0, projection.CurrentRank)
+			continue
+		}
+		for _, ranking := range rankings {
+			if ranking.TeamId == projection.TeamId {
+				assert.Equal(t, ranking.Rank, projection.CurrentRank)
+			}
+		}
+	}
+
+	// Without any outcomes, the hypothetical rankings should be the same as the current ones.
+	if assert.Equal(t, 6, len(projections.HypotheticalRankings)) {
+		for i, ranking := range projections.HypotheticalRankings {
+			assert.Equal(t, rankings[i].TeamId, ranking.TeamId)
+			assert.Equal(t, ranking.Rank, ranking.PreviousRank)
+		}
+	}
+}
+
+func TestProjectRankingsWhatIf(t *testing.T) {
+	database := setupTestDb(t)
+
+	setupMatchResultsForRankings(database)
+	match, _ := database.GetMatchByName("qualification", "4")
+	projections, err := ProjectRankings(database, map[int]string{match.Id: "B"}, 100, 8,
+		rand.New(rand.NewSource(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{match.Id: "B"}, projections.Outcomes)
+	if assert.Equal(t, 12, len(projections.HypotheticalRankings)) {
+		for _, ranking := range projections.HypotheticalRankings {
+			if ranking.TeamId >= 7 && ranking.TeamId <= 9 {
+				assert.Equal(t, 1, ranking.Losses)
+				assert.Equal(t, 0, ranking.PreviousRank)
+			} else if ranking.TeamId >= 10 {
+				assert.Equal(t, 1, ranking.Wins)
+				assert.Equal(t, 0, ranking.PreviousRank)
+			} else {
+				assert.NotEqual(t, 0, ranking.PreviousRank)
+			}
+		}
+	}
+
+	// Check that the winners of the forced outcome always place above the losers.
+	averageRanks := make(map[int]float64)
+	for _, projection := range projections.Teams {
+		averageRanks[projection.TeamId] = projection.AverageRank
+	}
+	assert.True(t, averageRanks[10] < averageRanks[7])
+	assert.True(t, averageRanks[11] < averageRanks[8])
+}
+
+func TestSimulateForcedOutcome(t *testing.T) {
+	data := projectionData{currentRankings: map[int]*game.Ranking{}, estimates: map[int]*TeamEstimate{}}
+	for teamId := 1; teamId <= 3; teamId++ {
+		data.estimates[teamId] = &TeamEstimate{TeamId: teamId, Opr: 20, AutoOpr: 1, ParkClimbOpr: 4}
+		data.estimates[teamId+3] = &TeamEstimate{TeamId: teamId + 3, Opr: 10, AutoOpr: 5, ParkClimbOpr: 2}
+	}
+	matches := []model.Match{{Id: 1, Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6}}
+	rankingsByTeam := func(rankings game.Rankings) map[int]*game.Ranking {
+		rankingMap := make(map[int]*game.Ranking)
+		for _, ranking := range rankings {
+			rankingMap[ranking.TeamId] = ranking
+		}
+		return rankingMap
+	}
+
+	// Check that forcing an upset swaps the alliances' tiebreaker totals along with the scores.
+	rankings := rankingsByTeam(data.simulate(matches, map[int]string{1: "B"}, nil))
+	assert.Equal(t, 1, rankings[4].Wins)
+	assert.Equal(t, 3, rankings[4].AutoPoints)
+	assert.Equal(t, 12, rankings[4].ParkClimbPoints)
+	assert.Equal(t, 1, rankings[1].Losses)
+	assert.Equal(t, 15, rankings[1].AutoPoints)
+	assert.Equal(t, 6, rankings[1].ParkClimbPoints)
+
+	// Check that forcing a tie gives both alliances the same totals.
+	rankings = rankingsByTeam(data.simulate(matches, map[int]string{1: "T"}, nil))
+	assert.Equal(t, 1, rankings[4].Ties)
+	assert.Equal(t, rankings[1].AutoPoints, rankings[4].AutoPoints)
+	assert.Equal(t, rankings[1].ParkClimbPoints, rankings[4].ParkClimbPoints)
+}
+
+func TestProjectRankingsErrors(t *testing.T) {
+	database := setupTestDb(t)
+
+	setupMatchResultsForRankings(database)
+	_, err := ProjectRankings(database, nil, 0, 8, rand.New(rand.NewSource(0)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Number of iterations must be between 1 and 10000.", err.Error())
+	}
+	match, _ := database.GetMatchByName("qualification", "4")
+	_, err = ProjectRankings(database, map[int]string{match.Id: "X"}, 10, 8, rand.New(rand.NewSource(0)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid winner 'X'.", err.Error())
+	}
+	match, _ = database.GetMatchByName("qualification", "1")
+	_, err = ProjectRankings(database, map[int]string{match.Id: "R"}, 10, 8, rand.New(rand.NewSource(0)))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "is not an unplayed qualification match")
+	}
+}
+
+func TestCalculateTeamEstimates(t *testing.T) {
+	database := setupTestDb(t)
+
+	// With no completed matches, every team should get the same estimate.
+	match := model.Match{Type: "qualification", DisplayName: "1", Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5,
+		Blue3: 6}
+	estimates, scoreStdDev := calculateTeamEstimates([]model.Match{match}, nil, nil)
+	assert.Equal(t, 6, len(estimates))
+	assert.Equal(t, 0.0, estimates[1].Opr)
+	assert.Equal(t, 0.0, estimates[6].Opr)
+	assert.Equal(t, float64(minScoreStdDev), scoreStdDev)
+
+	// Teams that have not yet played should get the average estimate.
+	setupMatchResultsForRankings(database)
+	data, err := loadProjectionData(database)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, len(data.estimates))
+	assert.True(t, data.estimates[7].Opr > 0)
+	assert.Equal(t, data.estimates[7].Opr, data.estimates[12].Opr)
+	assert.NotEqual(t, data.estimates[7].Opr, data.estimates[1].Opr)
+	assert.True(t, data.scoreStdDev >= minScoreStdDev)
+}
+
+func TestSolveLinearSystem(t *testing.T) {
+	matrix := [][]float64{{0, 2}, {3, 1}}
+	rhs := [][]float64{{4, 2}, {5, 4}}
+	solution := solveLinearSystem(matrix, rhs)
+	assert.InDelta(t, 1.0, solution[0][0], 1e-9)
+	assert.InDelta(t, 2.0, solution[1][0], 1e-9)
+	assert.InDelta(t, 1.0, solution[0][1], 1e-9)
+	assert.InDelta(t, 1.0, solution[1][1], 1e-9)
+}
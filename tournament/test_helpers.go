@@ -12,9 +12,9 @@ import (
 
 func CreateTestAlliances(database *model.Database, allianceCount int) {
 	for i := 1; i <= allianceCount; i++ {
-		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 0, i})
-		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 1, 10 * i})
-		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 2, 100 * i})
+		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 0, i, 0, 0})
+		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 1, 10 * i, 0, 0})
+		database.CreateAllianceTeam(&model.AllianceTeam{0, i, 2, 100 * i, 0, 0})
 	}
 }
 
@@ -71,6 +71,13 @@ func (controller *Controller) SetAllModes(mode Mode) {
 	controller.SetBoostMode(mode)
 }
 
+// Returns a copy of the current pixel values, so that they can be shown without the physical LEDs.
+func (controller *Controller) GetPixels() [][3]byte {
+	pixels := make([][3]byte, numPixels)
+	copy(pixels, controller.pixels[:])
+	return pixels
+}
+
 // Sends a packet if necessary. Should be called from a timed loop.
 func (controller *Controller) Update() error {
 	if controller.conn == nil {
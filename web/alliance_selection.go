@@ -14,15 +14,6 @@ import (
 	"time"
 )
 
-type RankedTeam struct {
-	Rank   int
-	TeamId int
-	Picked bool
-}
-
-// Global var to hold the team rankings during the alliance selection.
-var cachedRankedTeams []*RankedTeam
-
 // Shows the alliance selection page.
 func (web *Web) allianceSelectionGetHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
@@ -32,7 +23,8 @@ func (web *Web) allianceSelectionGetHandler(w http.ResponseWriter, r *http.Reque
 	web.renderAllianceSelection(w, r, "")
 }
 
-// Updates the cache with the latest input from the client.
+// Updates the alliances with the latest input from the client, for making corrections outside of the normal
+// invitation flow.
 func (web *Web) allianceSelectionPostHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
 		return
@@ -42,49 +34,58 @@ func (web *Web) allianceSelectionPostHandler(w http.ResponseWriter, r *http.Requ
 		web.renderAllianceSelection(w, r, "Alliance selection has already been finalized.")
 		return
 	}
+	allianceSelection, ok := web.getAllianceSelectionInProgress(w, r)
+	if !ok {
+		return
+	}
+	prevRow, prevCol := web.determineNextCell(allianceSelection.Alliances)
 
 	// Reset picked state for each team in preparation for reconstructing it.
-	newRankedTeams := make([]*RankedTeam, len(cachedRankedTeams))
-	for i, team := range cachedRankedTeams {
-		newRankedTeams[i] = &RankedTeam{team.Rank, team.TeamId, false}
+	for i := range allianceSelection.RankedTeams {
+		allianceSelection.RankedTeams[i].Picked = false
 	}
 
 	// Iterate through all selections and update the alliances.
-	for i, alliance := range web.arena.AllianceSelectionAlliances {
+	for i, alliance := range allianceSelection.Alliances {
 		for j := range alliance {
 			teamString := r.PostFormValue(fmt.Sprintf("selection%d_%d", i, j))
 			if teamString == "" {
-				web.arena.AllianceSelectionAlliances[i][j].TeamId = 0
+				allianceSelection.Alliances[i][j].TeamId = 0
 			} else {
 				teamId, err := strconv.Atoi(teamString)
 				if err != nil {
 					web.renderAllianceSelection(w, r, fmt.Sprintf("Invalid team number value '%s'.", teamString))
 					return
 				}
-				found := false
-				for _, team := range newRankedTeams {
-					if team.TeamId == teamId {
-						if team.Picked {
-							web.renderAllianceSelection(w, r,
-								fmt.Sprintf("Team %d is already part of an alliance.", teamId))
-							return
-						}
-						found = true
-						team.Picked = true
-						web.arena.AllianceSelectionAlliances[i][j].TeamId = teamId
-						break
-					}
-				}
-				if !found {
+				team := allianceSelection.GetRankedTeam(teamId)
+				if team == nil {
 					web.renderAllianceSelection(w, r, fmt.Sprintf("Team %d is not present at this event.", teamId))
 					return
 				}
+				if team.Picked {
+					web.renderAllianceSelection(w, r, fmt.Sprintf("Team %d is already part of an alliance.", teamId))
+					return
+				}
+				if team.Declined && j > 0 {
+					web.renderAllianceSelection(w, r,
+						fmt.Sprintf("Team %d has declined an invitation and may not be picked.", teamId))
+					return
+				}
+				team.Picked = true
+				allianceSelection.Alliances[i][j].TeamId = teamId
 			}
 		}
 	}
-	cachedRankedTeams = newRankedTeams
 
-	web.arena.AllianceSelectionNotifier.Notify()
+	// Cancel any outstanding invitation and restart the timer if the edits changed whose turn it is to pick.
+	if nextRow, nextCol := web.determineNextCell(allianceSelection.Alliances); nextRow != prevRow ||
+		nextCol != prevCol {
+		web.advanceAllianceSelection(allianceSelection)
+	}
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
+
 	http.Redirect(w, r, "/alliance_selection", 303)
 }
 
@@ -94,25 +95,31 @@ func (web *Web) allianceSelectionStartHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if len(web.arena.AllianceSelectionAlliances) != 0 {
-		web.renderAllianceSelection(w, r, "Can't start alliance selection when it is already in progress.")
-		return
-	}
 	if !web.canModifyAllianceSelection() {
 		web.renderAllianceSelection(w, r, "Alliance selection has already been finalized.")
 		return
 	}
+	allianceSelection, err := web.arena.Database.GetAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if allianceSelection != nil {
+		web.renderAllianceSelection(w, r, "Can't start alliance selection when it is already in progress.")
+		return
+	}
 
 	// Create a blank alliance set matching the event configuration.
-	web.arena.AllianceSelectionAlliances = make([][]model.AllianceTeam, web.arena.EventSettings.NumElimAlliances)
+	allianceSelection = new(model.AllianceSelection)
+	allianceSelection.Alliances = make([][]model.AllianceTeam, web.arena.EventSettings.NumElimAlliances)
 	teamsPerAlliance := 3
 	if web.arena.EventSettings.SelectionRound3Order != "" {
 		teamsPerAlliance = 4
 	}
 	for i := 0; i < web.arena.EventSettings.NumElimAlliances; i++ {
-		web.arena.AllianceSelectionAlliances[i] = make([]model.AllianceTeam, teamsPerAlliance)
+		allianceSelection.Alliances[i] = make([]model.AllianceTeam, teamsPerAlliance)
 		for j := 0; j < teamsPerAlliance; j++ {
-			web.arena.AllianceSelectionAlliances[i][j] = model.AllianceTeam{AllianceId: i + 1, PickPosition: j}
+			allianceSelection.Alliances[i][j] = model.AllianceTeam{AllianceId: i + 1, PickPosition: j}
 		}
 	}
 
@@ -122,12 +129,79 @@ func (web *Web) allianceSelectionStartHandler(w http.ResponseWriter, r *http.Req
 		handleWebErr(w, err)
 		return
 	}
-	cachedRankedTeams = make([]*RankedTeam, len(rankings))
+	allianceSelection.RankedTeams = make([]model.AllianceSelectionTeam, len(rankings))
 	for i, ranking := range rankings {
-		cachedRankedTeams[i] = &RankedTeam{i + 1, ranking.TeamId, false}
+		allianceSelection.RankedTeams[i] = model.AllianceSelectionTeam{Rank: i + 1, TeamId: ranking.TeamId}
 	}
 
-	web.arena.AllianceSelectionNotifier.Notify()
+	web.advanceAllianceSelection(allianceSelection)
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
+	http.Redirect(w, r, "/alliance_selection", 303)
+}
+
+// Populates the alliances from an uploaded CSV file of results from an alliance selection conducted elsewhere, so that
+// they can be reviewed and finalized.
+func (web *Web) allianceSelectionImportHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if !web.canModifyAllianceSelection() {
+		web.renderAllianceSelection(w, r, "Alliance selection has already been finalized.")
+		return
+	}
+	allianceSelection, err := web.arena.Database.GetAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if allianceSelection != nil {
+		web.renderAllianceSelection(w, r, "Can't import alliances when alliance selection is already in progress.")
+		return
+	}
+
+	file, _, err := r.FormFile("alliancesFile")
+	if err != nil {
+		web.renderAllianceSelection(w, r, "No alliances file was specified.")
+		return
+	}
+	defer file.Close()
+	teamsPerAlliance := 3
+	if web.arena.EventSettings.SelectionRound3Order != "" {
+		teamsPerAlliance = 4
+	}
+	alliances, err := tournament.ParseAlliancesCsv(file, web.arena.Database,
+		web.arena.EventSettings.NumElimAlliances, teamsPerAlliance)
+	if err != nil {
+		web.renderAllianceSelection(w, r, fmt.Sprintf("Error importing alliances: %s", err.Error()))
+		return
+	}
+
+	// Populate the ranked list of teams, marking the imported alliance members as picked.
+	pickedTeams := make(map[int]bool)
+	for _, alliance := range alliances {
+		for _, team := range alliance {
+			pickedTeams[team.TeamId] = true
+		}
+	}
+	rankings, err := web.arena.Database.GetAllRankings()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	allianceSelection = &model.AllianceSelection{Alliances: alliances}
+	allianceSelection.RankedTeams = make([]model.AllianceSelectionTeam, len(rankings))
+	for i, ranking := range rankings {
+		allianceSelection.RankedTeams[i] = model.AllianceSelectionTeam{Rank: i + 1, TeamId: ranking.TeamId,
+			Picked: pickedTeams[ranking.TeamId]}
+	}
+
+	web.advanceAllianceSelection(allianceSelection)
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
 	http.Redirect(w, r, "/alliance_selection", 303)
 }
 
@@ -142,12 +216,89 @@ func (web *Web) allianceSelectionResetHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	web.arena.AllianceSelectionAlliances = [][]model.AllianceTeam{}
-	cachedRankedTeams = []*RankedTeam{}
+	err := web.arena.Database.TruncateAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
 	web.arena.AllianceSelectionNotifier.Notify()
 	http.Redirect(w, r, "/alliance_selection", 303)
 }
 
+// Invites the given team to join the alliance whose turn it is to pick, or seats it directly if the next open spot
+// is for an alliance captain.
+func (web *Web) allianceSelectionInviteHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if !web.canModifyAllianceSelection() {
+		web.renderAllianceSelection(w, r, "Alliance selection has already been finalized.")
+		return
+	}
+	allianceSelection, ok := web.getAllianceSelectionInProgress(w, r)
+	if !ok {
+		return
+	}
+	nextRow, nextCol := web.determineNextCell(allianceSelection.Alliances)
+	if nextRow == -1 {
+		web.renderAllianceSelection(w, r, "All alliance spots have already been filled.")
+		return
+	}
+	if allianceSelection.InvitedTeamId != 0 {
+		web.renderAllianceSelection(w, r,
+			fmt.Sprintf("Team %d has not yet responded to the outstanding invitation.",
+				allianceSelection.InvitedTeamId))
+		return
+	}
+
+	teamString := r.PostFormValue("teamId")
+	teamId, err := strconv.Atoi(teamString)
+	if err != nil {
+		web.renderAllianceSelection(w, r, fmt.Sprintf("Invalid team number value '%s'.", teamString))
+		return
+	}
+	team := allianceSelection.GetRankedTeam(teamId)
+	if team == nil {
+		web.renderAllianceSelection(w, r, fmt.Sprintf("Team %d is not present at this event.", teamId))
+		return
+	}
+	if team.Picked {
+		web.renderAllianceSelection(w, r, fmt.Sprintf("Team %d is already part of an alliance.", teamId))
+		return
+	}
+
+	if nextCol == 0 {
+		// Captains take their spot directly, even if they have previously declined an invitation.
+		team.Picked = true
+		allianceSelection.Alliances[nextRow][nextCol].TeamId = teamId
+		web.advanceAllianceSelection(allianceSelection)
+	} else {
+		if team.Declined {
+			web.renderAllianceSelection(w, r,
+				fmt.Sprintf("Team %d has declined an invitation and may not be picked.", teamId))
+			return
+		}
+		allianceSelection.InvitedTeamId = teamId
+		allianceSelection.PickTimerStartedAt = time.Time{}
+	}
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
+
+	http.Redirect(w, r, "/alliance_selection", 303)
+}
+
+// Records the invited team's acceptance and adds it to the alliance.
+func (web *Web) allianceSelectionAcceptHandler(w http.ResponseWriter, r *http.Request) {
+	web.respondToInvitation(w, r, true)
+}
+
+// Records the invited team's refusal, which makes it ineligible to be picked by any other alliance.
+func (web *Web) allianceSelectionDeclineHandler(w http.ResponseWriter, r *http.Request) {
+	web.respondToInvitation(w, r, false)
+}
+
 // Saves the selected alliances to the database and generates the first round of elimination matches.
 func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
@@ -159,6 +310,11 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	allianceSelection, ok := web.getAllianceSelectionInProgress(w, r)
+	if !ok {
+		return
+	}
+
 	location, _ := time.LoadLocation("Local")
 	startTime, err := time.ParseInLocation("2006-01-02 03:04:05 PM", r.PostFormValue("startTime"), location)
 	if err != nil {
@@ -167,7 +323,7 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 	}
 
 	// Check that all spots are filled.
-	for _, alliance := range web.arena.AllianceSelectionAlliances {
+	for _, alliance := range allianceSelection.Alliances {
 		for _, team := range alliance {
 			if team.TeamId <= 0 {
 				web.renderAllianceSelection(w, r, "Can't finalize alliance selection until all spots have been filled.")
@@ -177,7 +333,7 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 	}
 
 	// Save alliances to the database.
-	for _, alliance := range web.arena.AllianceSelectionAlliances {
+	for _, alliance := range allianceSelection.Alliances {
 		for _, team := range alliance {
 			err := web.arena.Database.CreateAllianceTeam(&team)
 			if err != nil {
@@ -193,6 +349,16 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 		handleWebErr(w, err)
 		return
 	}
+	web.arena.BracketNotifier.Notify()
+	web.arena.ScheduleNotifier.Notify()
+	web.arena.QueueWebhookEvent(model.WebhookAllianceSelectionFinalized, allianceSelection.Alliances)
+
+	// Keep the selection state around afterwards so that the backup pool remains available.
+	allianceSelection.InvitedTeamId = 0
+	allianceSelection.PickTimerStartedAt = time.Time{}
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
 
 	// Reset yellow cards.
 	err = tournament.CalculateTeamCards(web.arena.Database, "elimination")
@@ -209,15 +375,13 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 	}
 
 	if web.arena.EventSettings.TbaPublishingEnabled {
-		// Publish alliances and schedule to The Blue Alliance.
-		err = web.arena.TbaClient.PublishAlliances(web.arena.Database)
-		if err != nil {
-			web.renderAllianceSelection(w, r, fmt.Sprintf("Failed to publish alliances: %s", err.Error()))
+		// Queue the alliances and schedule to be published asynchronously to The Blue Alliance.
+		if err = web.arena.QueueTbaPublish(model.TbaPublishAlliances); err != nil {
+			handleWebErr(w, err)
 			return
 		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
-		if err != nil {
-			web.renderAllianceSelection(w, r, fmt.Sprintf("Failed to publish matches: %s", err.Error()))
+		if err = web.arena.QueueTbaPublish(model.TbaPublishMatches); err != nil {
+			handleWebErr(w, err)
 			return
 		}
 	}
@@ -231,22 +395,28 @@ func (web *Web) allianceSelectionPublishHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	err := web.arena.TbaClient.PublishAlliances(web.arena.Database)
-	if err != nil {
-		http.Error(w, "Failed to publish alliances: "+err.Error(), 500)
+	if err := web.arena.QueueTbaPublish(model.TbaPublishAlliances); err != nil {
+		handleWebErr(w, err)
 		return
 	}
 	http.Redirect(w, r, "/alliance_selection", 303)
 }
 
 func (web *Web) renderAllianceSelection(w http.ResponseWriter, r *http.Request, errorMessage string) {
-	if len(web.arena.AllianceSelectionAlliances) == 0 && !web.canModifyAllianceSelection() {
-		// The application was restarted since the alliance selection was conducted; reload the alliances from the DB.
-		var err error
-		web.arena.AllianceSelectionAlliances, err = web.arena.Database.GetAllAlliances()
-		if err != nil {
-			handleWebErr(w, err)
-			return
+	allianceSelection, err := web.arena.Database.GetAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if allianceSelection == nil {
+		allianceSelection = &model.AllianceSelection{Alliances: [][]model.AllianceTeam{}}
+		if !web.canModifyAllianceSelection() {
+			// The alliance selection was conducted without its state being saved; reload the alliances from the DB.
+			allianceSelection.Alliances, err = web.arena.Database.GetAllAlliances()
+			if err != nil {
+				handleWebErr(w, err)
+				return
+			}
 		}
 	}
 
@@ -255,15 +425,22 @@ func (web *Web) renderAllianceSelection(w http.ResponseWriter, r *http.Request,
 		handleWebErr(w, err)
 		return
 	}
-	nextRow, nextCol := web.determineNextCell()
+	nextRow, nextCol := web.determineNextCell(allianceSelection.Alliances)
 	data := struct {
 		*model.EventSettings
-		Alliances    [][]model.AllianceTeam
-		RankedTeams  []*RankedTeam
-		NextRow      int
-		NextCol      int
-		ErrorMessage string
-	}{web.arena.EventSettings, web.arena.AllianceSelectionAlliances, cachedRankedTeams, nextRow, nextCol, errorMessage}
+		Alliances            [][]model.AllianceTeam
+		RankedTeams          []model.AllianceSelectionTeam
+		BackupTeams          []model.AllianceSelectionTeam
+		InvitedTeamId        int
+		PickTimeRemainingSec int
+		PickTimerRunning     bool
+		NextRow              int
+		NextCol              int
+		ErrorMessage         string
+	}{web.arena.EventSettings, allianceSelection.Alliances, allianceSelection.RankedTeams,
+		allianceSelection.BackupTeams(), allianceSelection.InvitedTeamId,
+		allianceSelection.PickTimeRemainingSec(time.Now()), !allianceSelection.PickTimerStartedAt.IsZero(), nextRow,
+		nextCol, errorMessage}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -280,10 +457,10 @@ func (web *Web) canModifyAllianceSelection() bool {
 	return true
 }
 
-// Returns the row and column of the next alliance selection spot that should have keyboard autofocus.
-func (web *Web) determineNextCell() (int, int) {
+// Returns the row and column of the next alliance selection spot to be filled, or -1 and -1 if all are filled.
+func (web *Web) determineNextCell(alliances [][]model.AllianceTeam) (int, int) {
 	// Check the first two columns.
-	for i, alliance := range web.arena.AllianceSelectionAlliances {
+	for i, alliance := range alliances {
 		if alliance[0].TeamId == 0 {
 			return i, 0
 		}
@@ -294,14 +471,14 @@ func (web *Web) determineNextCell() (int, int) {
 
 	// Check the third column.
 	if web.arena.EventSettings.SelectionRound2Order == "F" {
-		for i, alliance := range web.arena.AllianceSelectionAlliances {
+		for i, alliance := range alliances {
 			if alliance[2].TeamId == 0 {
 				return i, 2
 			}
 		}
 	} else {
-		for i := len(web.arena.AllianceSelectionAlliances) - 1; i >= 0; i-- {
-			if web.arena.AllianceSelectionAlliances[i][2].TeamId == 0 {
+		for i := len(alliances) - 1; i >= 0; i-- {
+			if alliances[i][2].TeamId == 0 {
 				return i, 2
 			}
 		}
@@ -309,17 +486,89 @@ func (web *Web) determineNextCell() (int, int) {
 
 	// Check the fourth column.
 	if web.arena.EventSettings.SelectionRound3Order == "F" {
-		for i, alliance := range web.arena.AllianceSelectionAlliances {
+		for i, alliance := range alliances {
 			if alliance[3].TeamId == 0 {
 				return i, 3
 			}
 		}
 	} else if web.arena.EventSettings.SelectionRound3Order == "L" {
-		for i := len(web.arena.AllianceSelectionAlliances) - 1; i >= 0; i-- {
-			if web.arena.AllianceSelectionAlliances[i][3].TeamId == 0 {
+		for i := len(alliances) - 1; i >= 0; i-- {
+			if alliances[i][3].TeamId == 0 {
 				return i, 3
 			}
 		}
 	}
 	return -1, -1
 }
+
+// Loads the in-progress alliance selection state, rendering an error and returning false if there is none.
+func (web *Web) getAllianceSelectionInProgress(w http.ResponseWriter,
+	r *http.Request) (*model.AllianceSelection, bool) {
+	allianceSelection, err := web.arena.Database.GetAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return nil, false
+	}
+	if allianceSelection == nil {
+		web.renderAllianceSelection(w, r, "Alliance selection has not been started.")
+		return nil, false
+	}
+	return allianceSelection, true
+}
+
+// Persists the alliance selection state and pushes it out to the displays. Returns false if an error occurred.
+func (web *Web) saveAllianceSelection(w http.ResponseWriter, allianceSelection *model.AllianceSelection) bool {
+	err := web.arena.Database.SaveAllianceSelection(allianceSelection)
+	if err != nil {
+		handleWebErr(w, err)
+		return false
+	}
+	web.arena.AllianceSelectionNotifier.Notify()
+	return true
+}
+
+// Clears any outstanding invitation and restarts the pick timer if the next open spot is for a pick rather than
+// a captain.
+func (web *Web) advanceAllianceSelection(allianceSelection *model.AllianceSelection) {
+	allianceSelection.InvitedTeamId = 0
+	if _, nextCol := web.determineNextCell(allianceSelection.Alliances); nextCol > 0 {
+		allianceSelection.PickTimerStartedAt = time.Now()
+	} else {
+		allianceSelection.PickTimerStartedAt = time.Time{}
+	}
+}
+
+// Resolves the outstanding invitation according to the invited team's response.
+func (web *Web) respondToInvitation(w http.ResponseWriter, r *http.Request, accepted bool) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if !web.canModifyAllianceSelection() {
+		web.renderAllianceSelection(w, r, "Alliance selection has already been finalized.")
+		return
+	}
+	allianceSelection, ok := web.getAllianceSelectionInProgress(w, r)
+	if !ok {
+		return
+	}
+	team := allianceSelection.GetRankedTeam(allianceSelection.InvitedTeamId)
+	nextRow, nextCol := web.determineNextCell(allianceSelection.Alliances)
+	if team == nil || nextRow == -1 {
+		web.renderAllianceSelection(w, r, "There is no outstanding invitation.")
+		return
+	}
+
+	if accepted {
+		team.Picked = true
+		allianceSelection.Alliances[nextRow][nextCol].TeamId = team.TeamId
+	} else {
+		team.Declined = true
+	}
+	web.advanceAllianceSelection(allianceSelection)
+	if !web.saveAllianceSelection(w, allianceSelection) {
+		return
+	}
+
+	http.Redirect(w, r, "/alliance_selection", 303)
+}
@@ -4,6 +4,7 @@
 package web
 
 import (
+	"bytes"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/stretchr/testify/assert"
@@ -13,8 +14,6 @@ import (
 func TestAllianceSelection(t *testing.T) {
 	web := setupTestWeb(t)
 
-	web.arena.AllianceSelectionAlliances = [][]model.AllianceTeam{}
-	cachedRankedTeams = []*RankedTeam{}
 	web.arena.EventSettings.NumElimAlliances = 15
 	web.arena.EventSettings.SelectionRound3Order = "L"
 	for i := 1; i <= 10; i++ {
@@ -30,8 +29,9 @@ func TestAllianceSelection(t *testing.T) {
 	// Start the alliance selection.
 	recorder = web.postHttpResponse("/alliance_selection/start", "")
 	assert.Equal(t, 303, recorder.Code)
-	if assert.Equal(t, 15, len(web.arena.AllianceSelectionAlliances)) {
-		assert.Equal(t, 4, len(web.arena.AllianceSelectionAlliances[0]))
+	allianceSelection, _ := web.arena.Database.GetAllianceSelection()
+	if assert.Equal(t, 15, len(allianceSelection.Alliances)) {
+		assert.Equal(t, 4, len(allianceSelection.Alliances[0]))
 	}
 	recorder = web.getHttpResponse("/alliance_selection")
 	assert.Contains(t, recorder.Body.String(), "Captain")
@@ -46,14 +46,16 @@ func TestAllianceSelection(t *testing.T) {
 	web.arena.EventSettings.SelectionRound3Order = ""
 	recorder = web.postHttpResponse("/alliance_selection/start", "")
 	assert.Equal(t, 303, recorder.Code)
-	if assert.Equal(t, 3, len(web.arena.AllianceSelectionAlliances)) {
-		assert.Equal(t, 3, len(web.arena.AllianceSelectionAlliances[0]))
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	if assert.Equal(t, 3, len(allianceSelection.Alliances)) {
+		assert.Equal(t, 3, len(allianceSelection.Alliances[0]))
 	}
 
 	// Update one team at a time.
 	recorder = web.postHttpResponse("/alliance_selection", "selection0_0=110")
 	assert.Equal(t, 303, recorder.Code)
-	assert.Equal(t, 110, web.arena.AllianceSelectionAlliances[0][0].TeamId)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 110, allianceSelection.Alliances[0][0].TeamId)
 	recorder = web.getHttpResponse("/alliance_selection")
 	assert.Contains(t, recorder.Body.String(), "\"110\"")
 	assert.NotContains(t, recorder.Body.String(), ">110<")
@@ -61,9 +63,10 @@ func TestAllianceSelection(t *testing.T) {
 	// Update multiple teams at a time.
 	recorder = web.postHttpResponse("/alliance_selection", "selection0_0=101&selection0_1=102&selection1_0=103")
 	assert.Equal(t, 303, recorder.Code)
-	assert.Equal(t, 101, web.arena.AllianceSelectionAlliances[0][0].TeamId)
-	assert.Equal(t, 102, web.arena.AllianceSelectionAlliances[0][1].TeamId)
-	assert.Equal(t, 103, web.arena.AllianceSelectionAlliances[1][0].TeamId)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 101, allianceSelection.Alliances[0][0].TeamId)
+	assert.Equal(t, 102, allianceSelection.Alliances[0][1].TeamId)
+	assert.Equal(t, 103, allianceSelection.Alliances[1][0].TeamId)
 	recorder = web.getHttpResponse("/alliance_selection")
 	assert.Contains(t, recorder.Body.String(), ">110<")
 
@@ -95,8 +98,6 @@ func TestAllianceSelection(t *testing.T) {
 func TestAllianceSelectionErrors(t *testing.T) {
 	web := setupTestWeb(t)
 
-	web.arena.AllianceSelectionAlliances = [][]model.AllianceTeam{}
-	cachedRankedTeams = []*RankedTeam{}
 	web.arena.EventSettings.NumElimAlliances = 2
 	for i := 1; i <= 6; i++ {
 		web.arena.Database.CreateRanking(&game.Ranking{TeamId: 100 + i, Rank: i})
@@ -132,12 +133,14 @@ func TestAllianceSelectionErrors(t *testing.T) {
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "valid start time")
 
-	// Finalize for real and check that TBA publishing is triggered.
-	web.arena.TbaClient.BaseUrl = "fakeurl"
+	// Finalize for real and check that TBA publishing is queued.
 	web.arena.EventSettings.TbaPublishingEnabled = true
 	recorder = web.postHttpResponse("/alliance_selection/finalize", "startTime=2014-01-01 01:00:00 PM")
-	assert.Equal(t, 200, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "Failed to publish alliances")
+	assert.Equal(t, 303, recorder.Code)
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAlliances)
+	assert.NotNil(t, item)
+	item, _ = web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishMatches)
+	assert.NotNil(t, item)
 
 	// Do other things after finalization.
 	recorder = web.postHttpResponse("/alliance_selection/finalize", "startTime=2014-01-01 01:00:00 PM")
@@ -149,18 +152,118 @@ func TestAllianceSelectionErrors(t *testing.T) {
 	recorder = web.postHttpResponse("/alliance_selection", "selection0_0=asdf")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "already been finalized")
-	web.arena.AllianceSelectionAlliances = [][]model.AllianceTeam{}
-	cachedRankedTeams = []*RankedTeam{}
 	recorder = web.postHttpResponse("/alliance_selection/start", "")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "already been finalized")
 }
 
-func TestAllianceSelectionAutofocus(t *testing.T) {
+func TestAllianceSelectionInvitations(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.EventSettings.NumElimAlliances = 2
+	for i := 1; i <= 12; i++ {
+		web.arena.Database.CreateRanking(&game.Ranking{TeamId: 100 + i, Rank: i})
+	}
+
+	// Respond to an invitation before alliance selection has started.
+	recorder := web.postHttpResponse("/alliance_selection/invite", "teamId=101")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "has not been started")
+
+	// Seat the first captain directly; the pick timer should start once it's time for the captain to pick.
+	recorder = web.postHttpResponse("/alliance_selection/start", "")
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ := web.arena.Database.GetAllianceSelection()
+	assert.True(t, allianceSelection.PickTimerStartedAt.IsZero())
+	recorder = web.postHttpResponse("/alliance_selection/accept", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no outstanding invitation")
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=101")
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 101, allianceSelection.Alliances[0][0].TeamId)
+	assert.Equal(t, 0, allianceSelection.InvitedTeamId)
+	assert.False(t, allianceSelection.PickTimerStartedAt.IsZero())
+
+	// Have the invited team decline.
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=102")
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 102, allianceSelection.InvitedTeamId)
+	assert.True(t, allianceSelection.PickTimerStartedAt.IsZero())
+	recorder = web.getHttpResponse("/alliance_selection")
+	assert.Contains(t, recorder.Body.String(), "Awaiting response from team <b>102</b>")
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=103")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "has not yet responded")
+	recorder = web.postHttpResponse("/alliance_selection/decline", "")
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 0, allianceSelection.Alliances[0][1].TeamId)
+	assert.Equal(t, 0, allianceSelection.InvitedTeamId)
+	assert.True(t, allianceSelection.GetRankedTeam(102).Declined)
+	assert.False(t, allianceSelection.PickTimerStartedAt.IsZero())
+	recorder = web.getHttpResponse("/alliance_selection")
+	assert.Contains(t, recorder.Body.String(), "102 (declined)")
+
+	// Check that the declining team can't be picked but can still be a captain.
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=102")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "may not be picked")
+	recorder = web.postHttpResponse("/alliance_selection", "selection0_0=101&selection0_1=102")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "may not be picked")
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=101")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already part of an alliance")
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=103")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/alliance_selection/accept", "")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=102")
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.Equal(t, 103, allianceSelection.Alliances[0][1].TeamId)
+	assert.Equal(t, 102, allianceSelection.Alliances[1][0].TeamId)
+
+	// Check the backup pool.
+	backupTeams := allianceSelection.BackupTeams()
+	if assert.Equal(t, 8, len(backupTeams)) {
+		assert.Equal(t, 104, backupTeams[0].TeamId)
+		assert.Equal(t, 111, backupTeams[7].TeamId)
+	}
+
+	// Fill the remaining spots and check that the state survives finalization.
+	for _, teamId := range []string{"104", "105", "106"} {
+		recorder = web.postHttpResponse("/alliance_selection/invite", "teamId="+teamId)
+		assert.Equal(t, 303, recorder.Code)
+		recorder = web.postHttpResponse("/alliance_selection/accept", "")
+		assert.Equal(t, 303, recorder.Code)
+	}
+	recorder = web.postHttpResponse("/alliance_selection/invite", "teamId=107")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already been filled")
+	recorder = web.postHttpResponse("/alliance_selection/finalize", "startTime=2014-01-01 01:00:00 PM")
+	assert.Equal(t, 303, recorder.Code)
+	alliances, _ := web.arena.Database.GetAllAlliances()
+	if assert.Equal(t, 2, len(alliances)) {
+		assert.Equal(t, 101, alliances[0][0].TeamId)
+		assert.Equal(t, 103, alliances[0][1].TeamId)
+		assert.Equal(t, 106, alliances[0][2].TeamId)
+		assert.Equal(t, 102, alliances[1][0].TeamId)
+		assert.Equal(t, 104, alliances[1][1].TeamId)
+		assert.Equal(t, 105, alliances[1][2].TeamId)
+	}
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	assert.True(t, allianceSelection.PickTimerStartedAt.IsZero())
+	if assert.Equal(t, 6, len(allianceSelection.BackupTeams())) {
+		assert.Equal(t, 107, allianceSelection.BackupTeams()[0].TeamId)
+	}
+}
+
+func TestAllianceSelectionNextCell(t *testing.T) {
 	web := setupTestWeb(t)
 
-	web.arena.AllianceSelectionAlliances = [][]model.AllianceTeam{}
-	cachedRankedTeams = []*RankedTeam{}
 	web.arena.EventSettings.NumElimAlliances = 2
 
 	// Straight draft.
@@ -168,39 +271,41 @@ func TestAllianceSelectionAutofocus(t *testing.T) {
 	web.arena.EventSettings.SelectionRound3Order = "F"
 	recorder := web.postHttpResponse("/alliance_selection/start", "")
 	assert.Equal(t, 303, recorder.Code)
-	i, j := web.determineNextCell()
+	allianceSelection, _ := web.arena.Database.GetAllianceSelection()
+	alliances := allianceSelection.Alliances
+	i, j := web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 0, j)
-	web.arena.AllianceSelectionAlliances[0][0].TeamId = 1
-	i, j = web.determineNextCell()
+	alliances[0][0].TeamId = 1
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 1, j)
-	web.arena.AllianceSelectionAlliances[0][1].TeamId = 2
-	i, j = web.determineNextCell()
+	alliances[0][1].TeamId = 2
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 0, j)
-	web.arena.AllianceSelectionAlliances[1][0].TeamId = 3
-	i, j = web.determineNextCell()
+	alliances[1][0].TeamId = 3
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 1, j)
-	web.arena.AllianceSelectionAlliances[1][1].TeamId = 4
-	i, j = web.determineNextCell()
+	alliances[1][1].TeamId = 4
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 2, j)
-	web.arena.AllianceSelectionAlliances[0][2].TeamId = 5
-	i, j = web.determineNextCell()
+	alliances[0][2].TeamId = 5
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 2, j)
-	web.arena.AllianceSelectionAlliances[1][2].TeamId = 6
-	i, j = web.determineNextCell()
+	alliances[1][2].TeamId = 6
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 3, j)
-	web.arena.AllianceSelectionAlliances[0][3].TeamId = 7
-	i, j = web.determineNextCell()
+	alliances[0][3].TeamId = 7
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 3, j)
-	web.arena.AllianceSelectionAlliances[1][3].TeamId = 8
-	i, j = web.determineNextCell()
+	alliances[1][3].TeamId = 8
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, -1, i)
 	assert.Equal(t, -1, j)
 
@@ -211,39 +316,41 @@ func TestAllianceSelectionAutofocus(t *testing.T) {
 	assert.Equal(t, 303, recorder.Code)
 	recorder = web.postHttpResponse("/alliance_selection/start", "")
 	assert.Equal(t, 303, recorder.Code)
-	i, j = web.determineNextCell()
+	allianceSelection, _ = web.arena.Database.GetAllianceSelection()
+	alliances = allianceSelection.Alliances
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 0, j)
-	web.arena.AllianceSelectionAlliances[0][0].TeamId = 1
-	i, j = web.determineNextCell()
+	alliances[0][0].TeamId = 1
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 1, j)
-	web.arena.AllianceSelectionAlliances[0][1].TeamId = 2
-	i, j = web.determineNextCell()
+	alliances[0][1].TeamId = 2
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 0, j)
-	web.arena.AllianceSelectionAlliances[1][0].TeamId = 3
-	i, j = web.determineNextCell()
+	alliances[1][0].TeamId = 3
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 1, j)
-	web.arena.AllianceSelectionAlliances[1][1].TeamId = 4
-	i, j = web.determineNextCell()
+	alliances[1][1].TeamId = 4
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 2, j)
-	web.arena.AllianceSelectionAlliances[1][2].TeamId = 5
-	i, j = web.determineNextCell()
+	alliances[1][2].TeamId = 5
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 2, j)
-	web.arena.AllianceSelectionAlliances[0][2].TeamId = 6
-	i, j = web.determineNextCell()
+	alliances[0][2].TeamId = 6
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 1, i)
 	assert.Equal(t, 3, j)
-	web.arena.AllianceSelectionAlliances[1][3].TeamId = 7
-	i, j = web.determineNextCell()
+	alliances[1][3].TeamId = 7
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, 0, i)
 	assert.Equal(t, 3, j)
-	web.arena.AllianceSelectionAlliances[0][3].TeamId = 8
-	i, j = web.determineNextCell()
+	alliances[0][3].TeamId = 8
+	i, j = web.determineNextCell(alliances)
 	assert.Equal(t, -1, i)
 	assert.Equal(t, -1, j)
 }
@@ -251,10 +358,51 @@ func TestAllianceSelectionAutofocus(t *testing.T) {
 func TestAllianceSelectionPublish(t *testing.T) {
 	web := setupTestWeb(t)
 
-	web.arena.TbaClient.BaseUrl = "fakeurl"
 	web.arena.EventSettings.TbaPublishingEnabled = true
 
 	recorder := web.postHttpResponse("/alliance_selection/publish", "")
-	assert.Equal(t, 500, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "Failed to publish alliances")
+	assert.Equal(t, 303, recorder.Code)
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAlliances)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, model.TbaOutboxPending, item.Status)
+	}
+}
+
+func TestAllianceSelectionImport(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.EventSettings.NumElimAlliances = 2
+	for i := 1; i <= 7; i++ {
+		web.arena.Database.CreateTeam(&model.Team{Id: i})
+	}
+	web.arena.Database.CreateRanking(&game.Ranking{TeamId: 7, Rank: 1})
+	web.arena.Database.CreateRanking(&game.Ranking{TeamId: 1, Rank: 2})
+
+	recorder := web.postFileHttpResponse("/alliance_selection/import", "alliancesFile",
+		bytes.NewBufferString("Alliance,Captain,Pick 1,Pick 2\n1,1,2,3\n"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Alliance 2 is missing.")
+
+	recorder = web.postFileHttpResponse("/alliance_selection/import", "alliancesFile",
+		bytes.NewBufferString("Alliance,Captain,Pick 1,Pick 2\n1,1,2,3\n2,4,5,6\n"))
+	assert.Equal(t, 303, recorder.Code)
+	allianceSelection, _ := web.arena.Database.GetAllianceSelection()
+	if assert.NotNil(t, allianceSelection) {
+		assert.Equal(t, 2, len(allianceSelection.Alliances))
+		assert.Equal(t, 6, allianceSelection.Alliances[1][2].TeamId)
+		if assert.Equal(t, 2, len(allianceSelection.RankedTeams)) {
+			assert.False(t, allianceSelection.RankedTeams[0].Picked)
+			assert.True(t, allianceSelection.RankedTeams[1].Picked)
+		}
+	}
+
+	// Check that the imported alliances can be finalized as usual.
+	recorder = web.postHttpResponse("/alliance_selection/import", "")
+	assert.Contains(t, recorder.Body.String(), "already in progress")
+	recorder = web.postHttpResponse("/alliance_selection/finalize", "startTime=2018-11-25 01:00:00 PM")
+	assert.Equal(t, 303, recorder.Code)
+	alliances, _ := web.arena.Database.GetAllAlliances()
+	assert.Equal(t, 2, len(alliances))
+	matches, _ := web.arena.Database.GetMatchesByType("elimination")
+	assert.NotEmpty(t, matches)
 }
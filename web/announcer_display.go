@@ -57,8 +57,11 @@ func (web *Web) announcerDisplayWebsocketHandler(w http.ResponseWriter, r *http.
 	}
 	defer ws.Close()
 
-	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier,
+	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
+	go ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier,
 		web.arena.RealtimeScoreNotifier, web.arena.ScorePostedNotifier, web.arena.AudienceDisplayModeNotifier,
-		web.arena.DisplayConfigurationNotifier, web.arena.ReloadDisplaysNotifier)
+		web.arena.AwardNotifier, web.arena.DisplayConfigurationNotifier, web.arena.ReloadDisplaysNotifier)
+
+	// Loop, waiting for heartbeats from the display, until the client closes the connection.
+	web.handleDisplayWebsocketMessages(ws, display)
 }
@@ -35,6 +35,7 @@ func TestAnnouncerDisplayWebsocket(t *testing.T) {
 	readWebsocketType(t, ws, "realtimeScore")
 	readWebsocketType(t, ws, "scorePosted")
 	readWebsocketType(t, ws, "audienceDisplayMode")
+	readWebsocketType(t, ws, "award")
 	readWebsocketType(t, ws, "displayConfiguration")
 
 	web.arena.MatchLoadNotifier.Notify()
@@ -56,4 +57,6 @@ func TestAnnouncerDisplayWebsocket(t *testing.T) {
 	readWebsocketType(t, ws, "realtimeScore")
 	web.arena.ScorePostedNotifier.Notify()
 	readWebsocketType(t, ws, "scorePosted")
+	web.arena.AwardNotifier.Notify()
+	readWebsocketType(t, ws, "award")
 }
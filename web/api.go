@@ -7,10 +7,16 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/gorilla/mux"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type MatchResultWithSummary struct {
@@ -35,8 +41,11 @@ func (web *Web) matchesApiHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vars := mux.Vars(r)
-	matches, err := web.arena.Database.GetMatchesByType(vars["type"])
+	web.writeMatchesJson(w, mux.Vars(r)["type"])
+}
+
+func (web *Web) writeMatchesJson(w http.ResponseWriter, matchType string) {
+	matches, err := web.arena.Database.GetMatchesByType(matchType)
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -73,6 +82,78 @@ func (web *Web) matchesApiHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Generates a JSON dump of the projected final qualification rankings, based on simulating the remaining matches.
+func (web *Web) rankingsProjectionApiHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsReader(w, r) {
+		return
+	}
+
+	web.writeRankingProjections(w, r, map[int]string{})
+}
+
+// Generates a JSON dump of the projected qualification rankings given hypothetical outcomes for one or more unplayed
+// matches, specified as repeated "outcome" parameters of the form "<match>:<R|B|T>" (e.g. "outcome=58:R").
+func (web *Web) rankingsWhatIfApiHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsReader(w, r) {
+		return
+	}
+
+	outcomes := make(map[int]string)
+	for _, outcome := range r.URL.Query()["outcome"] {
+		outcomeParts := strings.Split(outcome, ":")
+		if len(outcomeParts) != 2 {
+			handleWebErr(w, fmt.Errorf("Invalid outcome '%s'.", outcome))
+			return
+		}
+		match, err := web.arena.Database.GetMatchByName("qualification", outcomeParts[0])
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if match == nil {
+			handleWebErr(w, fmt.Errorf("Invalid qualification match '%s'.", outcomeParts[0]))
+			return
+		}
+		outcomes[match.Id] = strings.ToUpper(outcomeParts[1])
+	}
+	if len(outcomes) == 0 {
+		handleWebErr(w, fmt.Errorf("At least one outcome must be specified."))
+		return
+	}
+
+	web.writeRankingProjections(w, r, outcomes)
+}
+
+func (web *Web) writeRankingProjections(w http.ResponseWriter, r *http.Request, outcomes map[int]string) {
+	numIterations := tournament.DefaultProjectionIterations
+	if iterations := r.URL.Query().Get("iterations"); iterations != "" {
+		var err error
+		numIterations, err = strconv.Atoi(iterations)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	projections, err := tournament.ProjectRankings(web.arena.Database, outcomes, numIterations,
+		web.arena.EventSettings.NumElimAlliances, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	jsonData, err := json.MarshalIndent(projections, "", "  ")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
 // Generates a JSON dump of the sponsor slides for use by the audience display.
 func (web *Web) sponsorSlidesApiHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsReader(w, r) {
@@ -109,6 +190,10 @@ func (web *Web) rankingsApiHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	web.writeRankingsJson(w)
+}
+
+func (web *Web) writeRankingsJson(w http.ResponseWriter) {
 	rankings, err := web.arena.Database.GetAllRankings()
 	if err != nil {
 		handleWebErr(w, err)
@@ -173,6 +258,10 @@ func (web *Web) alliancesApiHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	web.writeAlliancesJson(w)
+}
+
+func (web *Web) writeAlliancesJson(w http.ResponseWriter) {
 	alliances, err := web.arena.Database.GetAllAlliances()
 	if err != nil {
 		handleWebErr(w, err)
@@ -192,3 +281,29 @@ func (web *Web) alliancesApiHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// Generates a JSON dump of the elimination bracket, primarily for use by the bracket displays.
+func (web *Web) bracketApiHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsReader(w, r) {
+		return
+	}
+
+	bracket, err := tournament.BuildBracket(web.arena.Database)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(bracket, "", "  ")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -79,6 +80,67 @@ func TestRankingsApi(t *testing.T) {
 	assert.Equal(t, "29", rankingsData.HighestPlayedMatch)
 }
 
+func TestRankingsProjectionApi(t *testing.T) {
+	web := setupTestWeb(t)
+
+	match1 := model.Match{Type: "qualification", DisplayName: "1", Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5,
+		Blue3: 6, Status: "complete"}
+	web.arena.Database.CreateMatch(&match1)
+	web.arena.Database.CreateMatchResult(model.BuildTestMatchResult(match1.Id, 1))
+	match2 := model.Match{Type: "qualification", DisplayName: "2", Red1: 1, Red2: 3, Red3: 5, Blue1: 2, Blue2: 4,
+		Blue3: 6}
+	web.arena.Database.CreateMatch(&match2)
+
+	recorder := web.getHttpResponse("/api/rankings/projection?iterations=50")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.HeaderMap["Content-Type"][0])
+	var projections tournament.RankingProjections
+	err := json.Unmarshal([]byte(recorder.Body.String()), &projections)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, projections.NumIterations)
+	assert.Equal(t, 8, projections.NumCaptains)
+	assert.Equal(t, 1, projections.NumRemainingMatches)
+	assert.Equal(t, 6, len(projections.Teams))
+
+	recorder = web.getHttpResponse("/api/rankings/projection?iterations=0")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Number of iterations must be between")
+}
+
+func TestRankingsWhatIfApi(t *testing.T) {
+	web := setupTestWeb(t)
+
+	match := model.Match{Type: "qualification", DisplayName: "58", Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5,
+		Blue3: 6}
+	web.arena.Database.CreateMatch(&match)
+
+	recorder := web.getHttpResponse("/api/rankings/what_if?outcome=58:r&iterations=10")
+	assert.Equal(t, 200, recorder.Code)
+	var projections tournament.RankingProjections
+	err := json.Unmarshal([]byte(recorder.Body.String()), &projections)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{match.Id: "R"}, projections.Outcomes)
+	if assert.Equal(t, 6, len(projections.HypotheticalRankings)) {
+		for _, ranking := range projections.HypotheticalRankings {
+			if ranking.TeamId <= 3 {
+				assert.Equal(t, 1, ranking.Wins)
+			} else {
+				assert.Equal(t, 1, ranking.Losses)
+			}
+		}
+	}
+
+	recorder = web.getHttpResponse("/api/rankings/what_if")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "At least one outcome must be specified.")
+	recorder = web.getHttpResponse("/api/rankings/what_if?outcome=58")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Invalid outcome '58'.")
+	recorder = web.getHttpResponse("/api/rankings/what_if?outcome=59:B")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Invalid qualification match '59'.")
+}
+
 func TestSponsorSlidesApi(t *testing.T) {
 	web := setupTestWeb(t)
 
@@ -123,3 +185,28 @@ func TestAlliancesApi(t *testing.T) {
 		}
 	}
 }
+
+func TestBracketApi(t *testing.T) {
+	web := setupTestWeb(t)
+
+	tournament.CreateTestAlliances(web.arena.Database, 4)
+	tournament.UpdateEliminationSchedule(web.arena.Database, time.Unix(0, 0))
+
+	recorder := web.getHttpResponse("/api/bracket")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.HeaderMap["Content-Type"][0])
+	var bracket tournament.Bracket
+	err := json.Unmarshal([]byte(recorder.Body.String()), &bracket)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(bracket.Rounds)) {
+		if assert.Equal(t, 2, len(bracket.Rounds[0].Matchups)) {
+			assert.Equal(t, "SF1", bracket.Rounds[0].Matchups[0].DisplayName)
+			assert.Equal(t, 1, bracket.Rounds[0].Matchups[0].RedAllianceId)
+			assert.Equal(t, 4, bracket.Rounds[0].Matchups[0].BlueAllianceId)
+			assert.Equal(t, []int{4, 40, 400}, bracket.Rounds[0].Matchups[0].BlueAllianceTeams)
+		}
+		if assert.Equal(t, 1, len(bracket.Rounds[1].Matchups)) {
+			assert.Equal(t, "Winner of SF2", bracket.Rounds[1].Matchups[0].BlueAllianceSource)
+		}
+	}
+}
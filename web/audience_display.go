@@ -17,7 +17,8 @@ func (web *Web) audienceDisplayHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !web.enforceDisplayConfiguration(w, r, map[string]string{"background": "#0f0", "reversed": "false"}) {
+	if !web.enforceDisplayConfiguration(w, r,
+		map[string]string{"background": "#0f0", "reversed": "false", "audio": "true", "volume": "100"}) {
 		return
 	}
 
@@ -29,7 +30,8 @@ func (web *Web) audienceDisplayHandler(w http.ResponseWriter, r *http.Request) {
 
 	data := struct {
 		*model.EventSettings
-	}{web.arena.EventSettings}
+		SoundUrls map[string]string
+	}{web.arena.EventSettings, web.arena.GetSoundUrls()}
 	err = template.ExecuteTemplate(w, "audience_display.html", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -57,9 +59,13 @@ func (web *Web) audienceDisplayWebsocketHandler(w http.ResponseWriter, r *http.R
 	}
 	defer ws.Close()
 
-	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.AudienceDisplayModeNotifier,
+	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
+	go ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.AudienceDisplayModeNotifier,
 		web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier, web.arena.RealtimeScoreNotifier,
 		web.arena.PlaySoundNotifier, web.arena.ScorePostedNotifier, web.arena.AllianceSelectionNotifier,
-		web.arena.LowerThirdNotifier, web.arena.DisplayConfigurationNotifier, web.arena.ReloadDisplaysNotifier)
+		web.arena.LowerThirdNotifier, web.arena.BracketNotifier, web.arena.AwardNotifier,
+		web.arena.DisplayConfigurationNotifier, web.arena.ReloadDisplaysNotifier)
+
+	// Loop, waiting for heartbeats from the display, until the client closes the connection.
+	web.handleDisplayWebsocketMessages(ws, display)
 }
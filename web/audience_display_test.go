@@ -18,10 +18,14 @@ func TestAudienceDisplay(t *testing.T) {
 	assert.Contains(t, recorder.Header().Get("Location"), "displayId=874")
 	assert.Contains(t, recorder.Header().Get("Location"), "background=%230f0")
 	assert.Contains(t, recorder.Header().Get("Location"), "reversed=false")
+	assert.Contains(t, recorder.Header().Get("Location"), "audio=true")
+	assert.Contains(t, recorder.Header().Get("Location"), "volume=100")
 
-	recorder = web.getHttpResponse("/displays/audience?displayId=1&background=%23000&reversed=false")
+	recorder = web.getHttpResponse("/displays/audience?displayId=1&background=%23000&reversed=false&audio=true&" +
+		"volume=50")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "Audience Display - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), "<audio id=\"match-start\" src=\"/static/audio/match_start.wav\"")
 }
 
 func TestAudienceDisplayWebsocket(t *testing.T) {
@@ -43,6 +47,8 @@ func TestAudienceDisplayWebsocket(t *testing.T) {
 	readWebsocketType(t, ws, "scorePosted")
 	readWebsocketType(t, ws, "allianceSelection")
 	readWebsocketType(t, ws, "lowerThird")
+	readWebsocketType(t, ws, "bracket")
+	readWebsocketType(t, ws, "award")
 	readWebsocketType(t, ws, "displayConfiguration")
 
 	// Run through a match cycle.
@@ -63,7 +69,8 @@ func TestAudienceDisplayWebsocket(t *testing.T) {
 	}
 	sound, ok := messages["playSound"]
 	if assert.True(t, ok) {
-		assert.Equal(t, "match-warmup", sound)
+		assert.Equal(t, map[string]interface{}{"Name": "match-warmup", "Url": "/static/audio/match_warmup.wav"},
+			sound)
 	}
 	_, ok = messages["matchTime"]
 	assert.True(t, ok)
@@ -77,4 +84,8 @@ func TestAudienceDisplayWebsocket(t *testing.T) {
 	readWebsocketType(t, ws, "allianceSelection")
 	web.arena.LowerThirdNotifier.Notify()
 	readWebsocketType(t, ws, "lowerThird")
+	web.arena.BracketNotifier.Notify()
+	readWebsocketType(t, ws, "bracket")
+	web.arena.AwardNotifier.Notify()
+	readWebsocketType(t, ws, "award")
 }
@@ -6,12 +6,22 @@
 package web
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/websocket"
+	"github.com/mitchellh/mapstructure"
+	"io"
+	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Returns true if the given required parameters are present; otherwise redirects to the defaults and returns false.
@@ -66,6 +76,73 @@ func (web *Web) registerDisplay(r *http.Request) (*field.Display, error) {
 		display.IpAddress = regexp.MustCompile("(.*):\\d+$").FindStringSubmatch(r.RemoteAddr)[1]
 	}
 
-	web.arena.RegisterDisplay(display)
+	if err = web.arena.RegisterDisplay(display); err != nil {
+		return nil, err
+	}
 	return display, nil
 }
+
+// Reads the heartbeats sent by the given display and records its health until the client closes the connection. Each
+// heartbeat is answered with a probe that the client echoes back, so that the round-trip latency can be measured.
+func (web *Web) handleDisplayWebsocketMessages(ws *websocket.Websocket, display *field.Display) {
+	probeId := 0
+	var probeSentTime time.Time
+	for {
+		messageType, data, err := ws.Read()
+		if err != nil {
+			if err != io.EOF {
+				log.Println(err)
+			}
+			return
+		}
+
+		switch messageType {
+		case "displayHeartbeat":
+			var heartbeat field.DisplayHeartbeat
+			if err = mapstructure.Decode(data, &heartbeat); err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
+			web.arena.RecordDisplayHeartbeat(display.Id, &heartbeat)
+			probeId++
+			probeSentTime = time.Now()
+			if err = ws.Write("displayLatencyProbe", probeId); err != nil {
+				log.Println(err)
+				return
+			}
+		case "displayLatencyProbe":
+			if echoedProbeId, ok := data.(float64); ok && int(echoedProbeId) == probeId {
+				web.arena.RecordDisplayLatency(display.Id, time.Since(probeSentTime))
+			}
+		case "displayAudioStatus":
+			audioError, _ := data.(string)
+			web.arena.RecordDisplayAudioStatus(display.Id, audioError)
+		default:
+			ws.WriteError(fmt.Sprintf("Invalid message type '%s'.", messageType))
+		}
+	}
+}
+
+// Returns a short hash of the templates and scripts that make up the display pages, which changes whenever the software
+// is updated in a way that could affect them.
+func computeDisplayPageVersion() (string, error) {
+	hash := sha256.New()
+	for _, dir := range []string{"templates", "static/js"} {
+		err := filepath.Walk(filepath.Join(model.BaseDir, dir), func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			io.WriteString(hash, path)
+			hash.Write(contents)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))[:12], nil
+}
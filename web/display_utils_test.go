@@ -0,0 +1,57 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/websocket"
+	gorillawebsocket "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDisplayWebsocketHeartbeat(t *testing.T) {
+	web := setupTestWeb(t)
+	assert.NotEqual(t, "", web.arena.DisplayPageVersion)
+	recorder := web.getHttpResponse("/displays/pit?displayId=1&scrollMsPerRow=700")
+	assert.Contains(t, recorder.Body.String(), web.arena.DisplayPageVersion)
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/displays/pit/websocket?displayId=1", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+	readWebsocketType(t, ws, "displayConfiguration")
+
+	ws.Write("displayHeartbeat", map[string]interface{}{"RenderTime": 1000, "SentTime": 1100,
+		"PageVersion": web.arena.DisplayPageVersion, "ScreenWidth": 1920, "ScreenHeight": 1080})
+	probeId := readWebsocketType(t, ws, "displayLatencyProbe")
+	ws.Write("displayLatencyProbe", probeId)
+	time.Sleep(time.Millisecond * 10)
+
+	// Check that a stale probe is ignored.
+	ws.Write("displayLatencyProbe", 12345)
+	ws.Write("displayAudioStatus", "NotAllowedError: play() failed")
+	time.Sleep(time.Millisecond * 10)
+
+	// Use the setup websocket to check the health that was recorded.
+	setupConn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/setup/displays/websocket", nil)
+	assert.Nil(t, err)
+	defer setupConn.Close()
+	setupWs := websocket.NewTestWebsocket(setupConn)
+	readWebsocketType(t, setupWs, "displayConfiguration")
+	message := readWebsocketType(t, setupWs, "displayHealth")
+	if health, ok := message.(map[string]interface{})["1"].(map[string]interface{}); assert.True(t, ok) {
+		assert.Equal(t, field.DisplayHealthy, health["Status"])
+		assert.Equal(t, web.arena.DisplayPageVersion, health["PageVersion"])
+		assert.Equal(t, 1920.0, health["ScreenWidth"])
+		assert.Equal(t, 100.0, health["RenderLagMs"])
+		assert.Equal(t, "NotAllowedError: play() failed", health["AudioError"])
+	}
+
+	ws.Write("invalid", nil)
+	readWebsocketType(t, ws, "error")
+}
@@ -7,6 +7,8 @@ package web
 
 import (
 	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/Team254/cheesy-arena/websocket"
@@ -70,6 +72,17 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	isReplay := matchResult != nil
+	var backupTeams []model.AllianceSelectionTeam
+	if web.arena.CurrentMatch.Type == "elimination" {
+		allianceSelection, err := web.arena.Database.GetAllianceSelection()
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if allianceSelection != nil {
+			backupTeams = allianceSelection.BackupTeams()
+		}
+	}
 	data := struct {
 		*model.EventSettings
 		MatchesByType     map[string]MatchPlayList
@@ -77,8 +90,9 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 		Match             *model.Match
 		AllowSubstitution bool
 		IsReplay          bool
+		BackupTeams       []model.AllianceSelectionTeam
 	}{web.arena.EventSettings, matchesByType, currentMatchType, web.arena.CurrentMatch, allowSubstitution,
-		isReplay}
+		isReplay, backupTeams}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -197,6 +211,27 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(err.Error())
 				continue
 			}
+		case "callBackup":
+			args := struct {
+				Team     int
+				Position string
+			}{}
+			err = mapstructure.Decode(data, &args)
+			if err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
+			err = web.callBackup(args.Team, args.Position)
+			if err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
+			err = ws.WriteNotifier(web.arena.ReloadDisplaysNotifier)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			continue // Skip sending the status update, as the client is about to terminate and reload.
 		case "toggleBypass":
 			station, ok := data.(string)
 			if !ok {
@@ -357,7 +392,11 @@ func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchRes
 		}
 	}
 
-	// Update and save the match record to the database.
+	// Update and save the match record to the database, noting its previous winner if it is being edited.
+	previousWinner := ""
+	if match.Status == "complete" {
+		previousWinner = match.Winner
+	}
 	match.Status = "complete"
 	match.ScoreCommittedAt = time.Now()
 	redScore := matchResult.RedScoreSummary()
@@ -369,11 +408,36 @@ func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchRes
 	} else {
 		match.Winner = "T"
 	}
+	match.TiebreakCriterion = ""
+	if match.Type == "elimination" && match.Winner == "T" {
+		// Apply the configured tiebreakers in order to avoid having to replay the match.
+		tiebreakers, err := game.ParseElimTiebreakers(web.arena.EventSettings.ElimTiebreakers)
+		if err != nil {
+			return err
+		}
+		winner, tiebreaker := game.BreakElimTie(redScore, blueScore, tiebreakers)
+		if winner == game.RedAlliance {
+			match.Winner = "R"
+		} else if winner == game.BlueAlliance {
+			match.Winner = "B"
+		}
+		if tiebreaker != nil {
+			match.TiebreakCriterion = tiebreaker.Description
+		}
+	}
 	err := web.arena.Database.SaveMatch(match)
 	if err != nil {
 		return err
 	}
 
+	web.arena.QueueWebhookEvent(model.WebhookMatchCommitted, struct {
+		Match      field.WebhookMatch
+		RedScore   int
+		BlueScore  int
+		Winner     string
+		PlayNumber int
+	}{field.NewWebhookMatch(match), redScore.Score, blueScore.Score, match.Winner, matchResult.PlayNumber})
+
 	if match.Type != "practice" {
 		// Regenerate the residual yellow cards that teams may carry.
 		tournament.CalculateTeamCards(web.arena.Database, match.Type)
@@ -385,30 +449,34 @@ func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchRes
 		if err != nil {
 			return err
 		}
+		web.arena.RankingsNotifier.Notify()
+		web.arena.QueueWebhookEvent(model.WebhookRankingsUpdated, nil)
 	}
 
 	if match.Type == "elimination" {
+		if err = web.queueElimSeriesWonWebhook(match, previousWinner); err != nil {
+			return err
+		}
+
 		// Generate any subsequent elimination matches.
 		_, err = tournament.UpdateEliminationSchedule(web.arena.Database, time.Now().Add(time.Second*tournament.ElimMatchSpacingSec))
 		if err != nil {
 			return err
 		}
+		web.arena.BracketNotifier.Notify()
+		web.arena.ScheduleNotifier.Notify()
 	}
 
 	if web.arena.EventSettings.TbaPublishingEnabled && match.Type != "practice" {
-		// Publish asynchronously to The Blue Alliance.
-		go func() {
-			err = web.arena.TbaClient.PublishMatches(web.arena.Database)
-			if err != nil {
-				log.Printf("Failed to publish matches: %s", err.Error())
-			}
-			if match.Type == "qualification" {
-				err = web.arena.TbaClient.PublishRankings(web.arena.Database)
-				if err != nil {
-					log.Printf("Failed to publish rankings: %s", err.Error())
-				}
+		// Queue the results to be published asynchronously to The Blue Alliance.
+		if err = web.arena.QueueTbaPublish(model.TbaPublishMatches); err != nil {
+			return err
+		}
+		if match.Type == "qualification" {
+			if err = web.arena.QueueTbaPublish(model.TbaPublishRankings); err != nil {
+				return err
 			}
-		}()
+		}
 	}
 
 	// Back up the database, but don't error out if it fails.
@@ -420,6 +488,100 @@ func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchRes
 	return nil
 }
 
+// Queues a webhook event if the given just-committed elimination match gives its winner the series, which it doesn't
+// if the series had already been won or if the match is being edited without changing its winner.
+func (web *Web) queueElimSeriesWonWebhook(match *model.Match, previousWinner string) error {
+	if match.Winner != "R" && match.Winner != "B" {
+		return nil
+	}
+	matches, err := web.arena.Database.GetMatchesByElimRoundGroup(match.ElimRound, match.ElimGroup)
+	if err != nil {
+		return err
+	}
+	wins := 0
+	for _, seriesMatch := range matches {
+		if seriesMatch.Status == "complete" && seriesMatch.Winner == match.Winner {
+			wins++
+		}
+	}
+	previousWins := wins
+	if previousWinner != match.Winner {
+		previousWins--
+	}
+	if wins < 2 || previousWins >= 2 {
+		return nil
+	}
+
+	winningTeams := []int{match.Red1, match.Red2, match.Red3}
+	if match.Winner == "B" {
+		winningTeams = []int{match.Blue1, match.Blue2, match.Blue3}
+	}
+	web.arena.QueueWebhookEvent(model.WebhookElimSeriesWon, struct {
+		Round        string
+		Group        int
+		Winner       string
+		WinningTeams []int
+	}{model.ElimRoundNames[match.ElimRound], match.ElimGroup, match.Winner, winningTeams})
+	return nil
+}
+
+// Calls in the given team from the backup pool to replace the team in the given station of the current elimination
+// match and all subsequent matches played by the same alliance.
+func (web *Web) callBackup(teamId int, station string) error {
+	if web.arena.CurrentMatch.Type != "elimination" {
+		return fmt.Errorf("Backup teams can only be called in elimination matches.")
+	}
+	if web.arena.MatchState != field.PreMatch {
+		return fmt.Errorf("Backup teams can only be called before the match has started.")
+	}
+	var replacedTeamId int
+	switch station {
+	case "R1":
+		replacedTeamId = web.arena.CurrentMatch.Red1
+	case "R2":
+		replacedTeamId = web.arena.CurrentMatch.Red2
+	case "R3":
+		replacedTeamId = web.arena.CurrentMatch.Red3
+	case "B1":
+		replacedTeamId = web.arena.CurrentMatch.Blue1
+	case "B2":
+		replacedTeamId = web.arena.CurrentMatch.Blue2
+	case "B3":
+		replacedTeamId = web.arena.CurrentMatch.Blue3
+	default:
+		return fmt.Errorf("Invalid alliance station '%s'.", station)
+	}
+
+	// Substitute the team into the loaded match before recording the backup, so that a failed substitution doesn't
+	// leave the database and the field disagreeing about who is playing.
+	err := tournament.ValidateBackup(web.arena.Database, teamId, replacedTeamId)
+	if err != nil {
+		return err
+	}
+	err = web.arena.SubstituteTeam(teamId, station)
+	if err != nil {
+		return err
+	}
+	err = tournament.CallBackup(web.arena.Database, teamId, replacedTeamId, web.arena.CurrentMatch.Id)
+	if err != nil {
+		if rollbackErr := web.arena.SubstituteTeam(replacedTeamId, station); rollbackErr != nil {
+			log.Printf("Failed to restore team %d after calling backup failed: %v", replacedTeamId, rollbackErr)
+		}
+		return err
+	}
+	web.arena.BracketNotifier.Notify()
+	web.arena.ScheduleNotifier.Notify()
+
+	if web.arena.EventSettings.TbaPublishingEnabled {
+		// Queue the updated alliances and matches to be published asynchronously to The Blue Alliance.
+		if err = web.arena.QueueTbaPublish(model.TbaPublishAlliances); err != nil {
+			return err
+		}
+		return web.arena.QueueTbaPublish(model.TbaPublishMatches)
+	}
+	return nil
+}
+
 func (web *Web) getCurrentMatchResult() *model.MatchResult {
 	return &model.MatchResult{MatchId: web.arena.CurrentMatch.Id, MatchType: web.arena.CurrentMatch.Type,
 		RedScore: &web.arena.RedRealtimeScore.CurrentScore, BlueScore: &web.arena.BlueRealtimeScore.CurrentScore,
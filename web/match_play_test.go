@@ -4,7 +4,6 @@
 package web
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/Team254/cheesy-arena/field"
 	"github.com/Team254/cheesy-arena/game"
@@ -14,7 +13,6 @@ import (
 	gorillawebsocket "github.com/gorilla/websocket"
 	"github.com/mitchellh/mapstructure"
 	"github.com/stretchr/testify/assert"
-	"log"
 	"testing"
 	"time"
 )
@@ -157,16 +155,15 @@ func TestCommitMatch(t *testing.T) {
 	match, _ = web.arena.Database.GetMatchById(1)
 	assert.Equal(t, "T", match.Winner)
 
-	// Verify TBA publishing by checking the log for the expected failure messages.
-	web.arena.TbaClient.BaseUrl = "fakeUrl"
+	// Verify TBA publishing by checking that the expected publishes have been queued.
 	web.arena.EventSettings.TbaPublishingEnabled = true
-	var writer bytes.Buffer
-	log.SetOutput(&writer)
 	err = web.commitMatchScore(match, matchResult, false)
 	assert.Nil(t, err)
-	time.Sleep(time.Millisecond * 10) // Allow some time for the asynchronous publishing to happen.
-	assert.Contains(t, writer.String(), "Failed to publish matches")
-	assert.Contains(t, writer.String(), "Failed to publish rankings")
+	items, _ := web.arena.Database.GetAllTbaOutboxItems()
+	if assert.Equal(t, 2, len(items)) {
+		assert.Equal(t, model.TbaPublishMatches, items[0].Action)
+		assert.Equal(t, model.TbaPublishRankings, items[1].Action)
+	}
 }
 
 func TestCommitEliminationTie(t *testing.T) {
@@ -184,7 +181,65 @@ func TestCommitEliminationTie(t *testing.T) {
 	web.arena.Database.SaveMatch(match)
 	web.commitMatchScore(match, matchResult, false)
 	match, _ = web.arena.Database.GetMatchById(1)
-	assert.Equal(t, "T", match.Winner) // No elimination tiebreakers.
+	assert.Equal(t, "B", match.Winner)
+	assert.Equal(t, "Fewer Fouls", match.TiebreakCriterion)
+
+	// Check that a tie stands if it can't be broken by any of the configured tiebreakers.
+	matchResult = &model.MatchResult{MatchId: match.Id, RedScore: &game.Score{Parks: 1}, BlueScore: &game.Score{Parks: 1}}
+	web.commitMatchScore(match, matchResult, false)
+	match, _ = web.arena.Database.GetMatchById(1)
+	assert.Equal(t, "T", match.Winner)
+	assert.Equal(t, "", match.TiebreakCriterion)
+
+	// Check that tiebreakers are not applied if disabled in the settings.
+	web.arena.EventSettings.ElimTiebreakers = ""
+	matchResult = &model.MatchResult{MatchId: match.Id, RedScore: &game.Score{ForceCubes: 1, Fouls: []game.Foul{{}}},
+		BlueScore: &game.Score{}}
+	web.commitMatchScore(match, matchResult, false)
+	match, _ = web.arena.Database.GetMatchById(1)
+	assert.Equal(t, "T", match.Winner)
+	assert.Equal(t, "", match.TiebreakCriterion)
+}
+
+func TestCommitMatchWebhooks(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateWebhook(&model.Webhook{Name: "Hook", Url: "http://localhost", Enabled: true,
+		Events: model.WebhookMatchCommitted + "," + model.WebhookElimSeriesWon})
+	tournament.CreateTestAlliances(web.arena.Database, 2)
+	tournament.UpdateEliminationSchedule(web.arena.Database, time.Unix(0, 0))
+	match1, _ := web.arena.Database.GetMatchByName("elimination", "F-1")
+	match2, _ := web.arena.Database.GetMatchByName("elimination", "F-2")
+	redWin := func(match *model.Match) *model.MatchResult {
+		return &model.MatchResult{MatchId: match.Id, RedScore: &game.Score{AutoRuns: 1}, BlueScore: &game.Score{}}
+	}
+
+	assert.Nil(t, web.commitMatchScore(match1, redWin(match1), false))
+	field.ProcessTestWebhookQueue(web.arena)
+	deliveries, _ := web.arena.Database.GetPendingWebhookDeliveries()
+	if assert.Equal(t, 1, len(deliveries)) {
+		assert.Equal(t, model.WebhookMatchCommitted, deliveries[0].Event)
+		assert.Contains(t, deliveries[0].Payload, "\"RedScore\":5")
+	}
+
+	// Check that the series is announced once the red alliance has won two matches.
+	assert.Nil(t, web.commitMatchScore(match2, redWin(match2), false))
+	field.ProcessTestWebhookQueue(web.arena)
+	deliveries, _ = web.arena.Database.GetPendingWebhookDeliveries()
+	if assert.Equal(t, 3, len(deliveries)) {
+		assert.Equal(t, model.WebhookMatchCommitted, deliveries[1].Event)
+		assert.Equal(t, model.WebhookElimSeriesWon, deliveries[2].Event)
+		assert.Contains(t, deliveries[2].Payload, "\"Round\":\"F\"")
+	}
+
+	// Check that editing a match without changing the series outcome doesn't announce it again.
+	match2, _ = web.arena.Database.GetMatchByName("elimination", "F-2")
+	assert.Nil(t, web.commitMatchScore(match2, redWin(match2), false))
+	field.ProcessTestWebhookQueue(web.arena)
+	deliveries, _ = web.arena.Database.GetPendingWebhookDeliveries()
+	if assert.Equal(t, 4, len(deliveries)) {
+		assert.Equal(t, model.WebhookMatchCommitted, deliveries[3].Event)
+	}
 }
 
 func TestCommitCards(t *testing.T) {
@@ -321,6 +376,61 @@ func TestMatchPlayWebsocketCommands(t *testing.T) {
 	assert.Equal(t, "logo", web.arena.AllianceStationDisplayMode)
 }
 
+func TestMatchPlayCallBackup(t *testing.T) {
+	web := setupTestWeb(t)
+
+	tournament.CreateTestAlliances(web.arena.Database, 2)
+	allianceSelection := model.AllianceSelection{RankedTeams: []model.AllianceSelectionTeam{{1, 1, true, false},
+		{2, 254, false, false}, {3, 2, true, false}, {4, 148, false, true}}}
+	web.arena.Database.SaveAllianceSelection(&allianceSelection)
+	tournament.UpdateEliminationSchedule(web.arena.Database, time.Unix(0, 0))
+	match, _ := web.arena.Database.GetMatchByName("elimination", "F-1")
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/match_play/websocket", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+	readWebsocketMultiple(t, ws, 7)
+
+	ws.Write("callBackup", map[string]interface{}{"team": 254, "position": "R2"})
+	assert.Contains(t, readWebsocketError(t, ws), "Backup teams can only be called in elimination matches.")
+
+	conn.Close()
+	assert.Nil(t, web.arena.LoadMatch(match))
+	conn, _, err = gorillawebsocket.DefaultDialer.Dial(wsUrl+"/match_play/websocket", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws = websocket.NewTestWebsocket(conn)
+	readWebsocketMultiple(t, ws, 7)
+	ws.Write("callBackup", map[string]interface{}{"team": 254, "position": "R4"})
+	assert.Contains(t, readWebsocketError(t, ws), "Invalid alliance station 'R4'.")
+	ws.Write("callBackup", map[string]interface{}{"team": 1503, "position": "R2"})
+	assert.Contains(t, readWebsocketError(t, ws), "Team 1503 is not in the backup pool.")
+	ws.Write("callBackup", map[string]interface{}{"team": 148, "position": "R2"})
+	assert.Contains(t, readWebsocketError(t, ws), "Team 148 declined an invitation")
+	assert.Equal(t, 1, web.arena.CurrentMatch.Red2)
+	ws.Write("callBackup", map[string]interface{}{"team": 254, "position": "R2"})
+	readWebsocketType(t, ws, "reload")
+	assert.Equal(t, 254, web.arena.CurrentMatch.Red2)
+	assert.Equal(t, 254, web.arena.AllianceStations["R2"].Team.Id)
+
+	// Check that the backup has been recorded and carried into the subsequent matches.
+	allianceTeams, _ := web.arena.Database.GetTeamsByAlliance(1)
+	if assert.Equal(t, 4, len(allianceTeams)) {
+		assert.Equal(t, 254, allianceTeams[3].TeamId)
+		assert.Equal(t, 1, allianceTeams[3].ReplacedTeamId)
+		assert.Equal(t, match.Id, allianceTeams[3].BackupFromMatchId)
+	}
+	match, _ = web.arena.Database.GetMatchByName("elimination", "F-3")
+	assert.Equal(t, 254, match.Red2)
+
+	// Check that the backup can't be substituted into the other alliance.
+	ws.Write("substituteTeam", map[string]interface{}{"team": 254, "position": "B1"})
+	assert.Contains(t, readWebsocketError(t, ws), "Team 254 is a member of alliance 1 and can't play for alliance 2.")
+}
+
 func TestMatchPlayWebsocketNotifications(t *testing.T) {
 	web := setupTestWeb(t)
 
@@ -0,0 +1,140 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Versioned, read-only public API for third-party applications, authenticated with API keys rather than cookies.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/websocket"
+	"github.com/gorilla/mux"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const apiRateLimitWindow = time.Minute
+
+// Tracks the recent requests made with each API key so that its rate limit can be enforced over a sliding window.
+type apiRateLimiter struct {
+	requestTimes map[int][]time.Time
+	mutex        sync.Mutex
+}
+
+// Shows the documentation for the public API.
+func (web *Web) apiDocsHandler(w http.ResponseWriter, r *http.Request) {
+	template, err := web.parseFiles("templates/api_docs.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		RateLimitWindowSec int
+	}{web.arena.EventSettings, int(apiRateLimitWindow.Seconds())}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Generates a JSON dump of the matches and results of the given type.
+func (web *Web) apiMatchesHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.apiKeyIsValid(w, r) {
+		return
+	}
+
+	web.writeMatchesJson(w, mux.Vars(r)["type"])
+}
+
+// Generates a JSON dump of the qualification rankings.
+func (web *Web) apiRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.apiKeyIsValid(w, r) {
+		return
+	}
+
+	web.writeRankingsJson(w)
+}
+
+// Generates a JSON dump of the alliances.
+func (web *Web) apiAlliancesHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.apiKeyIsValid(w, r) {
+		return
+	}
+
+	web.writeAlliancesJson(w)
+}
+
+// Streams the match state, realtime score, posted scores, rankings and schedule as Server-Sent Events until the client
+// disconnects.
+func (web *Web) apiStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.apiKeyIsValid(w, r) {
+		return
+	}
+
+	websocket.HandleEventStream(w, r, web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier,
+		web.arena.RealtimeScoreNotifier, web.arena.ScorePostedNotifier, web.arena.RankingsNotifier,
+		web.arena.ScheduleNotifier)
+}
+
+// Returns true if the request carries a valid API key that hasn't exceeded its rate limit. Otherwise writes an error
+// response and returns false.
+func (web *Web) apiKeyIsValid(w http.ResponseWriter, r *http.Request) bool {
+	key := r.Header.Get("X-Api-Key")
+	if key == "" {
+		// Allow the key to be given in the query string since browsers can't set headers on an EventSource.
+		key = r.URL.Query().Get("apiKey")
+	}
+	if key == "" {
+		http.Error(w, "Error: an API key is required.", 401)
+		return false
+	}
+	apiKey, err := web.arena.Database.GetApiKeyByKey(key)
+	if err != nil {
+		handleWebErr(w, err)
+		return false
+	}
+	if apiKey == nil {
+		http.Error(w, "Error: invalid API key.", 401)
+		return false
+	}
+	if allowed, retryAfter := web.apiRateLimiter.allow(apiKey, time.Now()); !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		http.Error(w, fmt.Sprintf("Error: the rate limit of %d requests per minute has been exceeded.",
+			apiKey.RequestsPerMinute), 429)
+		return false
+	}
+	return true
+}
+
+func newApiRateLimiter() *apiRateLimiter {
+	return &apiRateLimiter{requestTimes: make(map[int][]time.Time)}
+}
+
+// Records a request made with the given key at the given time and returns whether it is within the key's rate limit,
+// along with how long to wait before retrying if it isn't.
+func (limiter *apiRateLimiter) allow(apiKey *model.ApiKey, now time.Time) (bool, time.Duration) {
+	if apiKey.RequestsPerMinute <= 0 {
+		return true, 0
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	// Forget the requests that have fallen out of the window.
+	requestTimes := limiter.requestTimes[apiKey.Id]
+	for len(requestTimes) > 0 && now.Sub(requestTimes[0]) >= apiRateLimitWindow {
+		requestTimes = requestTimes[1:]
+	}
+	if len(requestTimes) >= apiKey.RequestsPerMinute {
+		limiter.requestTimes[apiKey.Id] = requestTimes
+		return false, requestTimes[0].Add(apiRateLimitWindow).Sub(now)
+	}
+	limiter.requestTimes[apiKey.Id] = append(requestTimes, now)
+	return true, 0
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApiDocs(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/api/v1")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Public API - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), "/api/v1/stream")
+}
+
+func TestApiKeyAuthentication(t *testing.T) {
+	web := setupTestWeb(t)
+	apiKey := model.ApiKey{Name: "Scouting App", Key: "abcd1234", RequestsPerMinute: 2}
+	web.arena.Database.CreateApiKey(&apiKey)
+	match := model.Match{Type: "qualification", DisplayName: "1"}
+	web.arena.Database.CreateMatch(&match)
+
+	recorder := web.getHttpResponse("/api/v1/matches/qualification")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "an API key is required")
+	recorder = web.getHttpResponse("/api/v1/matches/qualification?apiKey=blorpy")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid API key")
+
+	recorder = web.getHttpResponseWithHeaders("/api/v1/matches/qualification",
+		map[string]string{"X-Api-Key": "abcd1234"})
+	assert.Equal(t, 200, recorder.Code)
+	var matchesData []MatchWithResult
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &matchesData))
+	if assert.Equal(t, 1, len(matchesData)) {
+		assert.Equal(t, match.Id, matchesData[0].Id)
+	}
+	recorder = web.getHttpResponse("/api/v1/alliances?apiKey=abcd1234")
+	assert.Equal(t, 200, recorder.Code)
+
+	// Check that the key's rate limit is enforced.
+	recorder = web.getHttpResponse("/api/v1/rankings?apiKey=abcd1234")
+	assert.Equal(t, 429, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "rate limit of 2 requests per minute")
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	apiKey.RequestsPerMinute = 0
+	web.arena.Database.SaveApiKey(&apiKey)
+	recorder = web.getHttpResponse("/api/v1/rankings?apiKey=abcd1234")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "HighestPlayedMatch")
+}
+
+func TestApiRateLimiter(t *testing.T) {
+	limiter := newApiRateLimiter()
+	apiKey := &model.ApiKey{Id: 1, RequestsPerMinute: 2}
+	otherApiKey := &model.ApiKey{Id: 2, RequestsPerMinute: 2}
+	startTime := time.Unix(1000, 0)
+
+	allowed, _ := limiter.allow(apiKey, startTime)
+	assert.True(t, allowed)
+	allowed, _ = limiter.allow(apiKey, startTime.Add(20*time.Second))
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.allow(apiKey, startTime.Add(30*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 30*time.Second, retryAfter)
+	allowed, _ = limiter.allow(otherApiKey, startTime.Add(30*time.Second))
+	assert.True(t, allowed)
+	allowed, _ = limiter.allow(apiKey, startTime.Add(60*time.Second))
+	assert.True(t, allowed)
+	allowed, retryAfter = limiter.allow(apiKey, startTime.Add(61*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 19*time.Second, retryAfter)
+}
+
+func TestApiStream(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateApiKey(&model.ApiKey{Name: "Stream Overlay", Key: "abcd1234"})
+
+	server, _ := web.startTestServer()
+	defer server.Close()
+	response, err := http.Get(server.URL + "/api/v1/stream?apiKey=blorpy")
+	if assert.Nil(t, err) {
+		assert.Equal(t, 401, response.StatusCode)
+		response.Body.Close()
+	}
+	response, err = http.Get(server.URL + "/api/v1/stream?apiKey=abcd1234")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+
+	// Should get the current state of everything right after connection.
+	for _, eventType := range []string{"matchLoad", "matchTime", "realtimeScore", "scorePosted", "rankings",
+		"schedule"} {
+		readEventType(t, reader, eventType)
+	}
+
+	// Check that rankings updates are streamed.
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", Red1: 254, Red2: 1114,
+		Red3: 2056, Blue1: 148, Blue2: 118, Blue3: 1678, Status: "complete"})
+	matchResult := model.BuildTestMatchResult(1, 1)
+	web.arena.Database.CreateMatchResult(matchResult)
+	tournament.CalculateRankings(web.arena.Database)
+	web.arena.RankingsNotifier.Notify()
+	var rankings []map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(readEventType(t, reader, "rankings")), &rankings))
+	assert.Equal(t, 6, len(rankings))
+}
+
+// Reads lines from the given event stream until the next event, asserts that it is of the given type and returns its
+// data.
+func readEventType(t *testing.T, reader *bufio.Reader, expectedEventType string) string {
+	var eventType, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return ""
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" && eventType != "" {
+			break
+		}
+		if strings.HasPrefix(line, "event: ") {
+			eventType = strings.TrimPrefix(line, "event: ")
+		} else if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	assert.Equal(t, expectedEventType, eventType)
+	return data
+}
@@ -12,8 +12,75 @@ import (
 	"github.com/jung-kurt/gofpdf"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
+// Generates a PDF-formatted report of the elimination bracket.
+func (web *Web) bracketPdfReportHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsReader(w, r) {
+		return
+	}
+
+	bracket, err := tournament.BuildBracket(web.arena.Database)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// The widths of the table columns in mm, stored here so that they can be referenced for each row.
+	colWidths := map[string]float64{"Series": 20, "Red": 62, "Blue": 62, "Status": 51}
+	rowHeight := 6.5
+
+	pdf := gofpdf.New("P", "mm", "Letter", "font")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(220, 220, 220)
+	pdf.CellFormat(195, rowHeight, "Elimination Bracket - "+web.arena.EventSettings.Name, "", 1, "C", false, 0, "")
+	if len(bracket.Rounds) == 0 {
+		pdf.SetFont("Arial", "", 10)
+		pdf.CellFormat(195, rowHeight, "Alliance selection has not yet been finalized.", "", 1, "C", false, 0, "")
+	}
+	for _, round := range bracket.Rounds {
+		// Render round header rows.
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(195, rowHeight, "", "", 1, "C", false, 0, "")
+		pdf.CellFormat(195, rowHeight, round.Name, "", 1, "L", false, 0, "")
+		pdf.CellFormat(colWidths["Series"], rowHeight, "Series", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths["Red"], rowHeight, "Red Alliance", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths["Blue"], rowHeight, "Blue Alliance", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths["Status"], rowHeight, "Status", "1", 1, "C", true, 0, "")
+		for _, matchup := range round.Matchups {
+			// Render matchup info row.
+			pdf.SetFont("Arial", "B", 10)
+			pdf.CellFormat(colWidths["Series"], rowHeight, matchup.DisplayName, "1", 0, "C", false, 0, "")
+			pdf.SetFont("Arial", "", 10)
+			pdf.CellFormat(colWidths["Red"], rowHeight, bracketAllianceText(matchup.RedAllianceId,
+				matchup.RedAllianceTeams, matchup.RedAllianceSource), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths["Blue"], rowHeight, bracketAllianceText(matchup.BlueAllianceId,
+				matchup.BlueAllianceTeams, matchup.BlueAllianceSource), "1", 0, "C", false, 0, "")
+			status := matchup.SeriesStatus()
+			if matchup.IsComplete() {
+				status = fmt.Sprintf("Alliance %d (%s)", matchup.WinnerAllianceId, status)
+			}
+			pdf.CellFormat(colWidths["Status"], rowHeight, status, "1", 1, "C", false, 0, "")
+		}
+	}
+	if bracket.WinnerAllianceId > 0 {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(195, rowHeight, "", "", 1, "C", false, 0, "")
+		pdf.CellFormat(195, rowHeight, fmt.Sprintf("Event Winner: Alliance %d", bracket.WinnerAllianceId), "", 1,
+			"C", false, 0, "")
+	}
+
+	// Write out the PDF file as the HTTP response.
+	w.Header().Set("Content-Type", "application/pdf")
+	err = pdf.Output(w)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
 // Generates a CSV-formatted report of the qualification rankings.
 func (web *Web) rankingsCsvReportHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsReader(w, r) {
@@ -352,6 +419,18 @@ func (web *Web) wpaKeysCsvReportHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// Returns the text to display for one side of an elimination bracket matchup.
+func bracketAllianceText(allianceId int, teams []int, source string) string {
+	if allianceId == 0 {
+		return source
+	}
+	teamStrings := make([]string, len(teams))
+	for i, team := range teams {
+		teamStrings[i] = strconv.Itoa(team)
+	}
+	return fmt.Sprintf("Alliance %d (%s)", allianceId, strings.Join(teamStrings, ", "))
+}
+
 // Returns the text to display if a team is a surrogate.
 func surrogateText(isSurrogate bool) string {
 	if isSurrogate {
@@ -6,11 +6,24 @@ package web
 import (
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
 )
 
+func TestBracketPdfReport(t *testing.T) {
+	web := setupTestWeb(t)
+
+	tournament.CreateTestAlliances(web.arena.Database, 6)
+	tournament.UpdateEliminationSchedule(web.arena.Database, time.Unix(0, 0))
+
+	// Can't really parse the PDF content and check it, so just check that what's sent back is a PDF.
+	recorder := web.getHttpResponse("/reports/pdf/bracket")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/pdf", recorder.HeaderMap["Content-Type"][0])
+}
+
 func TestRankingsCsvReport(t *testing.T) {
 	web := setupTestWeb(t)
 
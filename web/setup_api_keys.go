@@ -0,0 +1,98 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for managing the keys that grant third-party applications access to the public API.
+
+package web
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Shows the API key management page.
+func (web *Web) apiKeysGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderApiKeys(w, r, "")
+}
+
+// Creates, saves or deletes an API key.
+func (web *Web) apiKeysPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	apiKeyId, _ := strconv.Atoi(r.PostFormValue("id"))
+	apiKey, err := web.arena.Database.GetApiKeyById(apiKeyId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if apiKey != nil {
+			err = web.arena.Database.DeleteApiKey(apiKey)
+		}
+	} else {
+		name := strings.TrimSpace(r.PostFormValue("name"))
+		if name == "" {
+			web.renderApiKeys(w, r, "The API key name can't be blank.")
+			return
+		}
+		requestsPerMinute, _ := strconv.Atoi(r.PostFormValue("requestsPerMinute"))
+		if requestsPerMinute < 0 {
+			web.renderApiKeys(w, r, "The rate limit can't be negative.")
+			return
+		}
+		if apiKey == nil {
+			var key string
+			if key, err = model.GenerateApiKey(); err != nil {
+				handleWebErr(w, err)
+				return
+			}
+			apiKey = &model.ApiKey{Name: name, Key: key, RequestsPerMinute: requestsPerMinute}
+			err = web.arena.Database.CreateApiKey(apiKey)
+		} else {
+			apiKey.Name = name
+			apiKey.RequestsPerMinute = requestsPerMinute
+			err = web.arena.Database.SaveApiKey(apiKey)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/api_keys", 303)
+}
+
+func (web *Web) renderApiKeys(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_api_keys.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	apiKeys, err := web.arena.Database.GetAllApiKeys()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Append a blank entry to the end that can be used to add a new one.
+	apiKeys = append(apiKeys, model.ApiKey{RequestsPerMinute: 60})
+
+	data := struct {
+		*model.EventSettings
+		ApiKeys      []model.ApiKey
+		ErrorMessage string
+	}{web.arena.EventSettings, apiKeys, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
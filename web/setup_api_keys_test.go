@@ -0,0 +1,48 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupApiKeys(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/api_keys")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "API Keys - Untitled Event - Cheesy Arena")
+
+	recorder = web.postHttpResponse("/setup/api_keys", "name=&requestsPerMinute=60")
+	assert.Contains(t, recorder.Body.String(), "The API key name can't be blank.")
+	recorder = web.postHttpResponse("/setup/api_keys", "name=Scouting+App&requestsPerMinute=-1")
+	assert.Contains(t, recorder.Body.String(), "The rate limit can't be negative.")
+	recorder = web.postHttpResponse("/setup/api_keys", "name=Scouting+App&requestsPerMinute=60")
+	assert.Equal(t, 303, recorder.Code)
+	apiKeys, _ := web.arena.Database.GetAllApiKeys()
+	if !assert.Equal(t, 1, len(apiKeys)) {
+		return
+	}
+	assert.Equal(t, "Scouting App", apiKeys[0].Name)
+	assert.Equal(t, 60, apiKeys[0].RequestsPerMinute)
+	assert.NotEqual(t, "", apiKeys[0].Key)
+	recorder = web.getHttpResponse("/setup/api_keys")
+	assert.Contains(t, recorder.Body.String(), apiKeys[0].Key)
+
+	// Check that saving an existing key keeps its key string.
+	recorder = web.postHttpResponse("/setup/api_keys",
+		fmt.Sprintf("id=%d&name=Stream+Overlay&requestsPerMinute=0", apiKeys[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	apiKey, _ := web.arena.Database.GetApiKeyById(apiKeys[0].Id)
+	assert.Equal(t, "Stream Overlay", apiKey.Name)
+	assert.Equal(t, 0, apiKey.RequestsPerMinute)
+	assert.Equal(t, apiKeys[0].Key, apiKey.Key)
+
+	recorder = web.postHttpResponse("/setup/api_keys", fmt.Sprintf("id=%d&action=delete", apiKeys[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	apiKeys, _ = web.arena.Database.GetAllApiKeys()
+	assert.Empty(t, apiKeys)
+}
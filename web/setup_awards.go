@@ -0,0 +1,347 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for managing the award catalog and winners and for running the award ceremony.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type awardListItem struct {
+	model.Award
+	Winners []tournament.AwardWinnerPresentation
+}
+
+// Shows the award configuration page.
+func (web *Web) awardsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderAwards(w, r, "")
+}
+
+// Saves the new or modified award to the database.
+func (web *Web) awardsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	awardId, _ := strconv.Atoi(r.PostFormValue("id"))
+	award, err := web.arena.Database.GetAwardById(awardId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if award != nil {
+			if err = web.deleteAward(award); err != nil {
+				handleWebErr(w, err)
+				return
+			}
+		}
+	} else {
+		name := strings.TrimSpace(r.PostFormValue("name"))
+		if name == "" {
+			web.renderAwards(w, r, "The award name can't be blank.")
+			return
+		}
+		displayOrder, _ := strconv.Atoi(r.PostFormValue("displayOrder"))
+		if award == nil {
+			award = &model.Award{Name: name, PresenterScript: r.PostFormValue("presenterScript"),
+				DisplayOrder: displayOrder}
+			err = web.arena.Database.CreateAward(award)
+		} else {
+			award.Name = name
+			award.PresenterScript = r.PostFormValue("presenterScript")
+			award.DisplayOrder = displayOrder
+			err = web.arena.Database.SaveAward(award)
+		}
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if err = web.handleAwardChanged(award); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+// Adds any of the standard awards that aren't already in the catalog.
+func (web *Web) awardsLoadStandardPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	awards, err := web.arena.Database.GetAllAwards()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	existingNames := make(map[string]bool)
+	displayOrder := 0
+	for _, award := range awards {
+		existingNames[award.Name] = true
+		if award.DisplayOrder >= displayOrder {
+			displayOrder = award.DisplayOrder + 1
+		}
+	}
+	for _, name := range model.StandardAwardNames {
+		if existingNames[name] {
+			continue
+		}
+		award := model.Award{Name: name, DisplayOrder: displayOrder}
+		if err = web.arena.Database.CreateAward(&award); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		displayOrder++
+	}
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+// Adds a winner to the given award.
+func (web *Web) awardWinnerAddPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	award, ok := web.getAward(w, r)
+	if !ok {
+		return
+	}
+	teamId, _ := strconv.Atoi(r.PostFormValue("teamId"))
+	personName := strings.TrimSpace(r.PostFormValue("personName"))
+	if teamId == 0 && personName == "" {
+		web.renderAwards(w, r, "An award winner must have a team, a person's name, or both.")
+		return
+	}
+	if teamId != 0 {
+		team, err := web.arena.Database.GetTeamById(teamId)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if team == nil {
+			web.renderAwards(w, r, fmt.Sprintf("Team %d is not present at the event.", teamId))
+			return
+		}
+	}
+	awardWinner := model.AwardWinner{AwardId: award.Id, TeamId: teamId, PersonName: personName}
+	if err := web.arena.Database.CreateAwardWinner(&awardWinner); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if err := web.handleAwardWinnersChanged(award); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+// Removes the given winner from their award.
+func (web *Web) awardWinnerDeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	awardWinnerId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	awardWinner, err := web.arena.Database.GetAwardWinnerById(awardWinnerId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if awardWinner == nil {
+		http.Error(w, fmt.Sprintf("Error: No such award winner: %d", awardWinnerId), 400)
+		return
+	}
+	if err = web.arena.Database.DeleteAwardWinner(awardWinner); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	award, err := web.arena.Database.GetAwardById(awardWinner.AwardId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if award != nil {
+		if err = web.handleAwardWinnersChanged(award); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+// Shows the given award on the audience display, or reveals its winners if it is already being shown.
+func (web *Web) awardPresentPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	award, ok := web.getAward(w, r)
+	if !ok {
+		return
+	}
+	switch r.PostFormValue("action") {
+	case "show":
+		web.arena.Award = award
+		web.arena.AwardWinnersRevealed = false
+	case "reveal":
+		web.arena.Award = award
+		web.arena.AwardWinnersRevealed = true
+	default:
+		handleWebErr(w, fmt.Errorf("Invalid award presentation action '%s'.", r.PostFormValue("action")))
+		return
+	}
+	web.arena.AwardNotifier.Notify()
+	if web.arena.AudienceDisplayMode != "award" {
+		web.arena.AudienceDisplayMode = "award"
+		web.arena.AudienceDisplayModeNotifier.Notify()
+	}
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+// Takes the award presentation off the audience display.
+func (web *Web) awardsHidePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if web.arena.AudienceDisplayMode == "award" {
+		web.arena.AudienceDisplayMode = "blank"
+		web.arena.AudienceDisplayModeNotifier.Notify()
+	}
+	web.arena.Award = nil
+	web.arena.AwardWinnersRevealed = false
+	web.arena.AwardNotifier.Notify()
+
+	http.Redirect(w, r, "/setup/awards", 303)
+}
+
+func (web *Web) renderAwards(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_awards.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	awards, err := web.arena.Database.GetAllAwards()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	awardListItems := make([]awardListItem, len(awards))
+	for i, award := range awards {
+		awardListItems[i].Award = award
+		awardListItems[i].Winners, err = tournament.GetAwardWinnerPresentations(web.arena.Database, award.Id)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	nextDisplayOrder := 0
+	if len(awards) > 0 {
+		nextDisplayOrder = awards[len(awards)-1].DisplayOrder + 1
+	}
+	data := struct {
+		*model.EventSettings
+		Awards               []awardListItem
+		Teams                []model.Team
+		NextDisplayOrder     int
+		CurrentAward         *model.Award
+		AwardWinnersRevealed bool
+		ErrorMessage         string
+	}{web.arena.EventSettings, awardListItems, teams, nextDisplayOrder, web.arena.Award,
+		web.arena.AwardWinnersRevealed, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Loads the award given in the request URL, writing an error response and returning false if it doesn't exist.
+func (web *Web) getAward(w http.ResponseWriter, r *http.Request) (*model.Award, bool) {
+	awardId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	award, err := web.arena.Database.GetAwardById(awardId)
+	if err != nil {
+		handleWebErr(w, err)
+		return nil, false
+	}
+	if award == nil {
+		http.Error(w, fmt.Sprintf("Error: No such award: %d", awardId), 400)
+		return nil, false
+	}
+	return award, true
+}
+
+// Deletes the given award along with its winners and generated lower thirds.
+func (web *Web) deleteAward(award *model.Award) error {
+	awardWinners, err := web.arena.Database.GetAwardWinnersByAward(award.Id)
+	if err != nil {
+		return err
+	}
+	for _, awardWinner := range awardWinners {
+		if err = web.arena.Database.DeleteAwardWinner(&awardWinner); err != nil {
+			return err
+		}
+	}
+	if err = tournament.DeleteAwardLowerThirds(web.arena.Database, award.Id); err != nil {
+		return err
+	}
+	if err = web.arena.Database.DeleteAward(award); err != nil {
+		return err
+	}
+	if web.arena.Award != nil && web.arena.Award.Id == award.Id {
+		web.arena.Award = nil
+		web.arena.AwardWinnersRevealed = false
+		web.arena.AwardNotifier.Notify()
+	}
+	if len(awardWinners) > 0 && web.arena.EventSettings.TbaPublishingEnabled {
+		return web.arena.QueueTbaPublish(model.TbaPublishAwards)
+	}
+	return nil
+}
+
+// Regenerates the award's lower thirds and refreshes any display currently presenting it.
+func (web *Web) handleAwardChanged(award *model.Award) error {
+	if err := tournament.UpdateAwardLowerThirds(web.arena.Database, award); err != nil {
+		return err
+	}
+	if web.arena.Award != nil && web.arena.Award.Id == award.Id {
+		web.arena.Award = award
+		web.arena.AwardNotifier.Notify()
+	}
+	return nil
+}
+
+// Updates everything that depends on the award's list of winners, including publishing them to TBA.
+func (web *Web) handleAwardWinnersChanged(award *model.Award) error {
+	if err := web.handleAwardChanged(award); err != nil {
+		return err
+	}
+	if web.arena.EventSettings.TbaPublishingEnabled {
+		return web.arena.QueueTbaPublish(model.TbaPublishAwards)
+	}
+	return nil
+}
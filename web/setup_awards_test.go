@@ -0,0 +1,107 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupAwards(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	web.arena.EventSettings.TbaPublishingEnabled = true
+
+	recorder := web.postHttpResponse("/setup/awards", "id=0&name=Safety+Award&presenterScript=Stay+safe&displayOrder=0")
+	assert.Equal(t, 303, recorder.Code)
+	award, _ := web.arena.Database.GetAwardById(1)
+	if assert.NotNil(t, award) {
+		assert.Equal(t, "Safety Award", award.Name)
+		assert.Equal(t, "Stay safe", award.PresenterScript)
+	}
+	lowerThirds, _ := web.arena.Database.GetLowerThirdsByAwardId(1)
+	if assert.Equal(t, 1, len(lowerThirds)) {
+		assert.Equal(t, "Safety Award", lowerThirds[0].TopText)
+	}
+
+	recorder = web.postHttpResponse("/setup/awards", "id=0&name=+&displayOrder=1")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "The award name can't be blank.")
+
+	// Add winners and check that the lower thirds and TBA are updated.
+	recorder = web.postHttpResponse("/setup/awards/1/winners", "teamId=254&personName=")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/setup/awards/1/winners", "teamId=0&personName=Jane+Doe")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/setup/awards/1/winners", "teamId=1114&personName=")
+	assert.Contains(t, recorder.Body.String(), "Team 1114 is not present at the event.")
+	recorder = web.postHttpResponse("/setup/awards/1/winners", "teamId=0&personName=")
+	assert.Contains(t, recorder.Body.String(), "An award winner must have a team")
+	recorder = web.postHttpResponse("/setup/awards/2/winners", "teamId=254")
+	assert.Equal(t, 400, recorder.Code)
+	awardWinners, _ := web.arena.Database.GetAwardWinnersByAward(1)
+	assert.Equal(t, 2, len(awardWinners))
+	lowerThirds, _ = web.arena.Database.GetLowerThirdsByAwardId(1)
+	if assert.Equal(t, 2, len(lowerThirds)) {
+		assert.Equal(t, "Team 254 The Cheesy Poofs", lowerThirds[0].BottomText)
+		assert.Equal(t, "Jane Doe", lowerThirds[1].BottomText)
+	}
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishAwards)
+	assert.NotNil(t, item)
+
+	recorder = web.getHttpResponse("/setup/awards")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Safety Award")
+	assert.Contains(t, recorder.Body.String(), "Team 254 The Cheesy Poofs")
+
+	// Present the award.
+	recorder = web.postHttpResponse("/setup/awards/1/present", "action=show")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "award", web.arena.AudienceDisplayMode)
+	assert.Equal(t, award.Id, web.arena.Award.Id)
+	assert.False(t, web.arena.AwardWinnersRevealed)
+	recorder = web.postHttpResponse("/setup/awards/1/present", "action=reveal")
+	assert.Equal(t, 303, recorder.Code)
+	assert.True(t, web.arena.AwardWinnersRevealed)
+	recorder = web.postHttpResponse("/setup/awards/1/present", "action=blorpy")
+	assert.Equal(t, 500, recorder.Code)
+	recorder = web.postHttpResponse("/setup/awards/hide", "")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "blank", web.arena.AudienceDisplayMode)
+	assert.Nil(t, web.arena.Award)
+
+	// Remove a winner.
+	recorder = web.postHttpResponse("/setup/awards/winners/1/delete", "")
+	assert.Equal(t, 303, recorder.Code)
+	awardWinners, _ = web.arena.Database.GetAwardWinnersByAward(1)
+	assert.Equal(t, 1, len(awardWinners))
+	lowerThirds, _ = web.arena.Database.GetLowerThirdsByAwardId(1)
+	assert.Equal(t, 1, len(lowerThirds))
+
+	// Delete the award along with its winners and lower thirds.
+	recorder = web.postHttpResponse("/setup/awards", "id=1&action=delete")
+	assert.Equal(t, 303, recorder.Code)
+	award, _ = web.arena.Database.GetAwardById(1)
+	assert.Nil(t, award)
+	awardWinners, _ = web.arena.Database.GetAllAwardWinners()
+	assert.Empty(t, awardWinners)
+	lowerThirds, _ = web.arena.Database.GetAllLowerThirds()
+	assert.Empty(t, lowerThirds)
+}
+
+func TestSetupAwardsLoadStandard(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateAward(&model.Award{Name: "Winner", DisplayOrder: 5})
+	recorder := web.postHttpResponse("/setup/awards/load_standard", "")
+	assert.Equal(t, 303, recorder.Code)
+	awards, _ := web.arena.Database.GetAllAwards()
+	if assert.Equal(t, len(model.StandardAwardNames), len(awards)) {
+		assert.Equal(t, "Winner", awards[0].Name)
+		assert.Equal(t, "Finalist", awards[1].Name)
+		assert.Equal(t, 6, awards[1].DisplayOrder)
+	}
+}
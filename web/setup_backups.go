@@ -0,0 +1,186 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for browsing, verifying, pruning and restoring the automatic database backups.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/gorilla/mux"
+	"net/http"
+	"os"
+)
+
+// Shows the list of database backups.
+func (web *Web) backupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderBackups(w, r, "", nil)
+}
+
+// Checks every backup for corruption and shows the results.
+func (web *Web) backupsVerifyPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	backups, err := model.ListBackups()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	verifyErrors := make(map[string]string)
+	for _, backup := range backups {
+		if err = model.VerifyBackup(backup.Filename); err != nil {
+			verifyErrors[backup.Filename] = err.Error()
+		} else {
+			verifyErrors[backup.Filename] = ""
+		}
+	}
+
+	web.renderBackups(w, r, "", verifyErrors)
+}
+
+// Deletes old backups according to the retention policy.
+func (web *Web) backupsPrunePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if web.arena.EventSettings.BackupRetentionCount == 0 {
+		web.renderBackups(w, r, "Backup pruning is disabled; set the number of backups to keep in the settings.", nil)
+		return
+	}
+	if _, err := model.PruneBackups(web.arena.EventSettings.BackupRetentionCount); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/backups", 303)
+}
+
+// Shows a summary of what would change if the given backup were restored.
+func (web *Web) backupGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	filename := mux.Vars(r)["filename"]
+	if _, err := model.GetBackupPath(filename); err != nil {
+		web.renderBackups(w, r, err.Error(), nil)
+		return
+	}
+	verifyError := ""
+	if err := model.VerifyBackup(filename); err != nil {
+		verifyError = err.Error()
+	}
+	var diffs []model.BackupMatchDiff
+	var schemaVersion int64
+	if verifyError == "" {
+		var err error
+		if diffs, err = web.arena.Database.DiffBackup(filename); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		backupPath, _ := model.GetBackupPath(filename)
+		if schemaVersion, err = model.GetDatabaseSchemaVersion(backupPath); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	latestSchemaVersion, err := model.GetLatestSchemaVersion()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	template, err := web.parseFiles("templates/setup_backup.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Filename            string
+		VerifyError         string
+		Diffs               []model.BackupMatchDiff
+		SchemaVersion       int64
+		LatestSchemaVersion int64
+	}{web.arena.EventSettings, filename, verifyError, diffs, schemaVersion, latestSchemaVersion}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Replaces the current database with the given backup.
+func (web *Web) backupRestorePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	filename := mux.Vars(r)["filename"]
+	backupPath, err := model.GetBackupPath(filename)
+	if err != nil {
+		web.renderBackups(w, r, err.Error(), nil)
+		return
+	}
+	if err = model.VerifyBackup(filename); err != nil {
+		web.renderBackups(w, r, fmt.Sprintf("Can't restore corrupt backup: %s", err.Error()), nil)
+		return
+	}
+
+	// Copy the backup to a temporary location since the original is kept and the copy is moved into place.
+	tempFilePath, err := copyToTempFile(backupPath, "restored-db-")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer os.Remove(tempFilePath)
+	message, err := checkDbSchemaVersion(tempFilePath, r.PostFormValue("confirmUpgrade") == "on")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if message != "" {
+		web.renderBackups(w, r, message, nil)
+		return
+	}
+
+	if err = web.replaceDb(tempFilePath); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/backups", 303)
+}
+
+func (web *Web) renderBackups(w http.ResponseWriter, r *http.Request, errorMessage string,
+	verifyErrors map[string]string) {
+	template, err := web.parseFiles("templates/setup_backups.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	backups, err := model.ListBackups()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Backups      []model.BackupFile
+		VerifyErrors map[string]string // Nil if the backups haven't been verified.
+		ErrorMessage string
+	}{web.arena.EventSettings, backups, verifyErrors, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
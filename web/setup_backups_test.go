@@ -0,0 +1,65 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestSetupBackups(t *testing.T) {
+	web := setupTestWeb(t)
+
+	match := model.Match{Type: "qualification", DisplayName: "1"}
+	web.arena.Database.CreateMatch(&match)
+	web.arena.Database.Backup("Backup Test", "post_qualification_match_1")
+	backups, _ := model.ListBackups()
+	var filename string
+	for _, backup := range backups {
+		if backup.EventName == "Backup Test" {
+			filename = backup.Filename
+		}
+	}
+	if !assert.NotEqual(t, "", filename) {
+		return
+	}
+	backupPath, _ := model.GetBackupPath(filename)
+	defer os.Remove(backupPath)
+
+	recorder := web.getHttpResponse("/setup/backups")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), filename)
+	assert.Contains(t, recorder.Body.String(), "qualification 1")
+
+	recorder = web.postHttpResponse("/setup/backups/verify", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Integrity")
+
+	// Change the match and check that the restore page shows what would be undone.
+	match.Status = "complete"
+	web.arena.Database.SaveMatch(&match)
+	recorder = web.getHttpResponse("/setup/backups/" + filename)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Would change")
+	recorder = web.getHttpResponse("/setup/backups/nonexistent.db")
+	assert.Contains(t, recorder.Body.String(), "Invalid backup filename")
+
+	recorder = web.postHttpResponse("/setup/backups/"+filename+"/restore", "")
+	assert.Equal(t, 303, recorder.Code)
+	restoredMatch, _ := web.arena.Database.GetMatchById(match.Id)
+	if assert.NotNil(t, restoredMatch) {
+		assert.Equal(t, "", restoredMatch.Status)
+	}
+	_, err := os.Stat(backupPath)
+	assert.Nil(t, err)
+
+	web.arena.EventSettings.BackupRetentionCount = 0
+	recorder = web.postHttpResponse("/setup/backups/prune", "")
+	assert.Contains(t, recorder.Body.String(), "Backup pruning is disabled")
+	web.arena.EventSettings.BackupRetentionCount = 100
+	recorder = web.postHttpResponse("/setup/backups/prune", "")
+	assert.Equal(t, 303, recorder.Code)
+}
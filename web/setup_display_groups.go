@@ -0,0 +1,216 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for managing display groups and the playlists that they rotate through.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Shows the display groups and playlists configuration page.
+func (web *Web) displayGroupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderDisplayGroups(w, r, "")
+}
+
+// Saves, deletes or applies a display group.
+func (web *Web) displayGroupsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	groupId, _ := strconv.Atoi(r.PostFormValue("id"))
+	group, err := web.arena.Database.GetDisplayGroupById(groupId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	switch r.PostFormValue("action") {
+	case "delete":
+		if group != nil {
+			err = web.arena.DeleteDisplayGroup(group)
+		}
+	case "apply":
+		err = web.arena.ApplyDisplayGroup(groupId)
+		if err != nil {
+			web.renderDisplayGroups(w, r, err.Error())
+			return
+		}
+	default:
+		name := strings.TrimSpace(r.PostFormValue("name"))
+		if name == "" {
+			web.renderDisplayGroups(w, r, "The display group name can't be blank.")
+			return
+		}
+		var configuration map[string]string
+		configuration, err = parseDisplayConfiguration(r.PostFormValue("configuration"))
+		if err != nil {
+			web.renderDisplayGroups(w, r, err.Error())
+			return
+		}
+		displayType, _ := strconv.Atoi(r.PostFormValue("type"))
+		playlistId, _ := strconv.Atoi(r.PostFormValue("playlistId"))
+		if group == nil {
+			group = &model.DisplayGroup{Name: name, Type: displayType, Configuration: configuration,
+				PlaylistId: playlistId}
+			err = web.arena.Database.CreateDisplayGroup(group)
+		} else {
+			group.Name = name
+			group.Type = displayType
+			group.Configuration = configuration
+			group.PlaylistId = playlistId
+			err = web.arena.Database.SaveDisplayGroup(group)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/display_groups", 303)
+}
+
+// Saves or deletes a display playlist.
+func (web *Web) displayPlaylistsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	playlistId, _ := strconv.Atoi(r.PostFormValue("id"))
+	playlist, err := web.arena.Database.GetDisplayPlaylistById(playlistId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if playlist != nil {
+			err = web.arena.Database.DeleteDisplayPlaylist(playlist)
+		}
+	} else {
+		name := strings.TrimSpace(r.PostFormValue("name"))
+		if name == "" {
+			web.renderDisplayGroups(w, r, "The playlist name can't be blank.")
+			return
+		}
+
+		// Each step is submitted as a row of like-named fields; rows without a type are dropped.
+		var steps []model.DisplayPlaylistStep
+		stepTypes := r.PostForm["stepType"]
+		stepConfigurations := r.PostForm["stepConfiguration"]
+		stepDwellTimes := r.PostForm["stepDwellTimeSec"]
+		if len(stepConfigurations) != len(stepTypes) || len(stepDwellTimes) != len(stepTypes) {
+			web.renderDisplayGroups(w, r, "Invalid playlist steps.")
+			return
+		}
+		for i := range stepTypes {
+			displayType, _ := strconv.Atoi(stepTypes[i])
+			if displayType == 0 {
+				continue
+			}
+			configuration, err := parseDisplayConfiguration(stepConfigurations[i])
+			if err != nil {
+				web.renderDisplayGroups(w, r, err.Error())
+				return
+			}
+			dwellTimeSec, _ := strconv.Atoi(stepDwellTimes[i])
+			if dwellTimeSec < 1 {
+				web.renderDisplayGroups(w, r, "Each playlist step must be shown for at least one second.")
+				return
+			}
+			steps = append(steps, model.DisplayPlaylistStep{Type: displayType, Configuration: configuration,
+				DwellTimeSec: dwellTimeSec})
+		}
+
+		if playlist == nil {
+			playlist = &model.DisplayPlaylist{Name: name, Steps: steps}
+			err = web.arena.Database.CreateDisplayPlaylist(playlist)
+		} else {
+			playlist.Name = name
+			playlist.Steps = steps
+			err = web.arena.Database.SaveDisplayPlaylist(playlist)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/display_groups", 303)
+}
+
+func (web *Web) renderDisplayGroups(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_display_groups.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	groups, err := web.arena.Database.GetAllDisplayGroups()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	playlists, err := web.arena.Database.GetAllDisplayPlaylists()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Append blank entries to the end that can be used to add new ones.
+	groups = append(groups, model.DisplayGroup{Type: int(field.PlaceholderDisplay)})
+	for i := range playlists {
+		playlists[i].Steps = append(playlists[i].Steps, model.DisplayPlaylistStep{DwellTimeSec: 30})
+	}
+	playlists = append(playlists, model.DisplayPlaylist{Steps: []model.DisplayPlaylistStep{{DwellTimeSec: 30}}})
+
+	data := struct {
+		*model.EventSettings
+		DisplayTypeNames map[field.DisplayType]string
+		DisplayGroups    []model.DisplayGroup
+		DisplayPlaylists []model.DisplayPlaylist
+		ErrorMessage     string
+	}{web.arena.EventSettings, field.DisplayTypeNames, groups, playlists, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Parses a display configuration given in the same "key1=value1&key2=value2" form used on the display configuration
+// page.
+func parseDisplayConfiguration(configurationString string) (map[string]string, error) {
+	configuration := make(map[string]string)
+	for _, param := range strings.Split(configurationString, "&") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		keyValuePair := strings.SplitN(param, "=", 2)
+		if len(keyValuePair) != 2 || keyValuePair[0] == "" {
+			return nil, fmt.Errorf("Invalid display configuration parameter '%s'.", param)
+		}
+		configuration[keyValuePair[0]] = keyValuePair[1]
+	}
+	return configuration, nil
+}
+
+// Formats a display configuration in the "key1=value1&key2=value2" form, with the keys sorted.
+func formatDisplayConfiguration(configuration map[string]string) string {
+	var params []string
+	for key, value := range configuration {
+		params = append(params, key+"="+value)
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
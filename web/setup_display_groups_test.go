@@ -0,0 +1,80 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupDisplayGroups(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/display_groups")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Display Groups and Playlists - Untitled Event - Cheesy Arena")
+
+	// Add a playlist.
+	recorder = web.postHttpResponse("/setup/display_playlists", "name=&stepType=6&stepConfiguration=&"+
+		"stepDwellTimeSec=60")
+	assert.Contains(t, recorder.Body.String(), "The playlist name can't be blank.")
+	recorder = web.postHttpResponse("/setup/display_playlists", "name=Pit+Rotation&stepType=6&"+
+		"stepConfiguration=&stepDwellTimeSec=0")
+	assert.Contains(t, recorder.Body.String(), "at least one second")
+	recorder = web.postHttpResponse("/setup/display_playlists", "name=Pit+Rotation&stepType=6&"+
+		"stepConfiguration=scrollMsPerRow%3D1000&stepDwellTimeSec=60&stepType=9&stepConfiguration=&"+
+		"stepDwellTimeSec=30&stepType=0&stepConfiguration=&stepDwellTimeSec=30")
+	assert.Equal(t, 303, recorder.Code)
+	playlists, _ := web.arena.Database.GetAllDisplayPlaylists()
+	if assert.Equal(t, 1, len(playlists)) && assert.Equal(t, 2, len(playlists[0].Steps)) {
+		assert.Equal(t, "Pit Rotation", playlists[0].Name)
+		assert.Equal(t, int(field.PitDisplay), playlists[0].Steps[0].Type)
+		assert.Equal(t, "1000", playlists[0].Steps[0].Configuration["scrollMsPerRow"])
+		assert.Equal(t, 60, playlists[0].Steps[0].DwellTimeSec)
+		assert.Equal(t, int(field.BracketDisplay), playlists[0].Steps[1].Type)
+	}
+
+	// Add a group and put a display in it.
+	recorder = web.postHttpResponse("/setup/display_groups", "name=Stands&type=4&configuration=background")
+	assert.Contains(t, recorder.Body.String(), "Invalid display configuration parameter 'background'.")
+	recorder = web.postHttpResponse("/setup/display_groups", "name=Stands&type=4&configuration=background%3D%2300f")
+	assert.Equal(t, 303, recorder.Code)
+	groups, _ := web.arena.Database.GetAllDisplayGroups()
+	if !assert.Equal(t, 1, len(groups)) {
+		return
+	}
+	assert.Equal(t, "#00f", groups[0].Configuration["background"])
+	web.arena.RegisterDisplay(&field.Display{Id: "100", Type: field.PlaceholderDisplay,
+		Configuration: map[string]string{}})
+	web.arena.UpdateDisplay(&field.Display{Id: "100", Type: field.PlaceholderDisplay, GroupId: groups[0].Id,
+		Configuration: map[string]string{}})
+	recorder = web.getHttpResponse("/setup/display_groups")
+	assert.Contains(t, recorder.Body.String(), "Stands")
+	assert.Contains(t, recorder.Body.String(), "background=#00f")
+
+	recorder = web.postHttpResponse("/setup/display_groups", fmt.Sprintf("id=%d&action=apply", groups[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, field.AudienceDisplay, web.arena.Displays["100"].Type)
+	assert.Equal(t, "#00f", web.arena.Displays["100"].Configuration["background"])
+
+	// Put the group on the playlist and check that applying it starts the playlist.
+	recorder = web.postHttpResponse("/setup/display_groups", fmt.Sprintf("id=%d&name=Stands&type=4&playlistId=%d",
+		groups[0].Id, playlists[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	web.postHttpResponse("/setup/display_groups", fmt.Sprintf("id=%d&action=apply", groups[0].Id))
+	assert.Equal(t, field.PitDisplay, web.arena.Displays["100"].Type)
+
+	recorder = web.postHttpResponse("/setup/display_groups", fmt.Sprintf("id=%d&action=delete", groups[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	groups, _ = web.arena.Database.GetAllDisplayGroups()
+	assert.Empty(t, groups)
+	assert.Equal(t, 0, web.arena.Displays["100"].GroupId)
+	recorder = web.postHttpResponse("/setup/display_playlists", fmt.Sprintf("id=%d&action=delete",
+		playlists[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	playlists, _ = web.arena.Database.GetAllDisplayPlaylists()
+	assert.Empty(t, playlists)
+}
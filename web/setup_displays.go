@@ -27,10 +27,16 @@ func (web *Web) displaysGetHandler(w http.ResponseWriter, r *http.Request) {
 		handleWebErr(w, err)
 		return
 	}
+	displayGroups, err := web.arena.Database.GetAllDisplayGroups()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
 	data := struct {
 		*model.EventSettings
 		DisplayTypeNames map[field.DisplayType]string
-	}{web.arena.EventSettings, field.DisplayTypeNames}
+		DisplayGroups    []model.DisplayGroup
+	}{web.arena.EventSettings, field.DisplayTypeNames, displayGroups}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -52,7 +58,7 @@ func (web *Web) displaysWebsocketHandler(w http.ResponseWriter, r *http.Request)
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
-	go ws.HandleNotifiers(web.arena.DisplayConfigurationNotifier)
+	go ws.HandleNotifiers(web.arena.DisplayConfigurationNotifier, web.arena.DisplayHealthNotifier)
 
 	// Loop, waiting for commands and responding to them, until the client closes the connection.
 	for {
@@ -68,16 +74,34 @@ func (web *Web) displaysWebsocketHandler(w http.ResponseWriter, r *http.Request)
 
 		switch messageType {
 		case "configureDisplay":
-			var display field.Display
-			err = mapstructure.Decode(data, &display)
+			var args struct {
+				Id            string
+				Nickname      string
+				Type          field.DisplayType
+				Configuration map[string]string
+				GroupId       int
+			}
+			err = mapstructure.Decode(data, &args)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
+			display := field.Display{Id: args.Id, Nickname: args.Nickname, Type: args.Type,
+				Configuration: args.Configuration, GroupId: args.GroupId}
 			if err = web.arena.UpdateDisplay(&display); err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
+		case "deleteDisplay":
+			displayId, ok := data.(string)
+			if !ok {
+				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
+				continue
+			}
+			if err = web.arena.DeleteDisplay(displayId); err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
 		case "reloadDisplay":
 			displayId, ok := data.(string)
 			if !ok {
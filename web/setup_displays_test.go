@@ -4,12 +4,13 @@
 package web
 
 import (
+	"encoding/json"
 	"github.com/Team254/cheesy-arena/field"
 	"github.com/Team254/cheesy-arena/websocket"
 	gorillawebsocket "github.com/gorilla/websocket"
-	"github.com/mitchellh/mapstructure"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestSetupDisplays(t *testing.T) {
@@ -34,6 +35,7 @@ func TestSetupDisplaysWebsocket(t *testing.T) {
 	message := readDisplayConfiguration(t, ws)
 	assert.Empty(t, message.Displays)
 	assert.Empty(t, message.DisplayUrls)
+	assert.Empty(t, readWebsocketType(t, ws, "displayHealth"))
 
 	// Connect a couple of displays and verify the resulting configuration messages.
 	displayConn1, _, _ := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/display/websocket?displayId=1", nil)
@@ -63,6 +65,20 @@ func TestSetupDisplaysWebsocket(t *testing.T) {
 	message = readDisplayConfiguration(t, ws)
 	assert.Equal(t, expectedDisplay1, message.Displays["1"])
 	assert.Equal(t, expectedDisplay1.ToUrl(), message.DisplayUrls["1"])
+
+	// Disconnect a display and check that it is remembered until it is explicitly forgotten.
+	web.arena.MarkDisplayDisconnected(&field.Display{Id: "2"})
+	message = readDisplayConfiguration(t, ws)
+	if assert.Contains(t, message.Displays, "2") {
+		assert.Equal(t, 0, message.Displays["2"].ConnectionCount)
+	}
+	ws.Write("deleteDisplay", "1")
+	assert.Contains(t, readWebsocketType(t, ws, "error"), "can't be removed while it is connected")
+	ws.Write("deleteDisplay", "2")
+	message = readDisplayConfiguration(t, ws)
+	assert.NotContains(t, message.Displays, "2")
+	savedDisplay, _ := web.arena.Database.GetDisplayById("2")
+	assert.Nil(t, savedDisplay)
 }
 
 func TestSetupDisplaysWebsocketReloadDisplays(t *testing.T) {
@@ -77,6 +93,7 @@ func TestSetupDisplaysWebsocketReloadDisplays(t *testing.T) {
 
 	// Should get a few status updates right after connection.
 	readDisplayConfiguration(t, ws)
+	readWebsocketType(t, ws, "displayHealth")
 
 	// Connect a display and verify the resulting configuration messages.
 	displayConn, _, _ := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/display/websocket?displayId=1", nil)
@@ -95,7 +112,14 @@ func TestSetupDisplaysWebsocketReloadDisplays(t *testing.T) {
 func readDisplayConfiguration(t *testing.T, ws *websocket.Websocket) *field.DisplayConfigurationMessage {
 	message := readWebsocketType(t, ws, "displayConfiguration")
 	var displayConfigurationMessage field.DisplayConfigurationMessage
-	err := mapstructure.Decode(message, &displayConfigurationMessage)
+	messageJson, _ := json.Marshal(message)
+	err := json.Unmarshal(messageJson, &displayConfigurationMessage)
 	assert.Nil(t, err)
+
+	// Clear the last seen times since they aren't deterministic.
+	for _, display := range displayConfigurationMessage.Displays {
+		assert.False(t, display.LastSeenTime.IsZero())
+		display.LastSeenTime = time.Time{}
+	}
 	return &displayConfigurationMessage
 }
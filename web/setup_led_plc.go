@@ -8,7 +8,6 @@ package web
 import (
 	"fmt"
 	"github.com/Team254/cheesy-arena/field"
-	"github.com/Team254/cheesy-arena/led"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/Team254/cheesy-arena/vaultled"
 	"github.com/Team254/cheesy-arena/websocket"
@@ -35,10 +34,10 @@ func (web *Web) ledPlcGetHandler(w http.ResponseWriter, r *http.Request) {
 		InputNames        []string
 		RegisterNames     []string
 		CoilNames         []string
-		LedModeNames      map[led.Mode]string
+		LedSequenceNames  []string
 		VaultLedModeNames map[vaultled.Mode]string
-	}{web.arena.EventSettings, plc.GetInputNames(), plc.GetRegisterNames(), plc.GetCoilNames(), led.ModeNames,
-		vaultled.ModeNames}
+	}{web.arena.EventSettings, plc.GetInputNames(), plc.GetRegisterNames(), plc.GetCoilNames(),
+		web.arena.GetLedSequenceNames(), vaultled.ModeNames}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -88,9 +87,15 @@ func (web *Web) ledPlcWebsocketHandler(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
-			web.arena.ScaleLeds.SetMode(modeMessage.LedMode, modeMessage.LedMode)
-			web.arena.RedSwitchLeds.SetMode(modeMessage.LedMode, modeMessage.LedMode)
-			web.arena.BlueSwitchLeds.SetMode(modeMessage.LedMode, modeMessage.LedMode)
+			sequence := web.arena.GetLedSequence(modeMessage.LedSequence)
+			if sequence == nil {
+				ws.WriteError(fmt.Sprintf("Unknown LED sequence '%s'.", modeMessage.LedSequence))
+				continue
+			}
+
+			web.arena.ScaleLeds.SetSequence(sequence, sequence)
+			web.arena.RedSwitchLeds.SetSequence(sequence, sequence)
+			web.arena.BlueSwitchLeds.SetSequence(sequence, sequence)
 			web.arena.RedVaultLeds.SetAllModes(modeMessage.VaultLedMode)
 			web.arena.BlueVaultLeds.SetAllModes(modeMessage.VaultLedMode)
 			web.arena.LedModeNotifier.Notify()
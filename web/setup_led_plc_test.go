@@ -27,15 +27,18 @@ func TestSetupLedPlcWebsocket(t *testing.T) {
 
 	// Should get a few status updates right after connection.
 	ledModeMessage := readLedModes(t, ws)
-	assert.Equal(t, led.OffMode, ledModeMessage.LedMode)
+	assert.Equal(t, led.OffSequence, ledModeMessage.LedSequence)
 	assert.Equal(t, vaultled.OffMode, ledModeMessage.VaultLedMode)
 	readWebsocketType(t, ws, "plcIoChange")
 
 	// Change the LED modes and verify that the new modes are broadcast back.
-	ws.Write("setLedMode", field.LedModeMessage{LedMode: led.RandomMode, VaultLedMode: vaultled.BluePlayedMode})
+	ws.Write("setLedMode", field.LedModeMessage{LedSequence: "Random", VaultLedMode: vaultled.BluePlayedMode})
 	ledModeMessage = readLedModes(t, ws)
-	assert.Equal(t, led.RandomMode, ledModeMessage.LedMode)
+	assert.Equal(t, "Random", ledModeMessage.LedSequence)
 	assert.Equal(t, vaultled.BluePlayedMode, ledModeMessage.VaultLedMode)
+
+	ws.Write("setLedMode", field.LedModeMessage{LedSequence: "Blorpy"})
+	assert.Contains(t, readWebsocketError(t, ws), "Unknown LED sequence 'Blorpy'.")
 }
 
 func readLedModes(t *testing.T, ws *websocket.Websocket) *field.LedModeMessage {
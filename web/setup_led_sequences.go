@@ -0,0 +1,262 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for configuring the field LED controllers, editing custom LED sequences and mapping events to them.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/led"
+	"github.com/Team254/cheesy-arena/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ledPreviewFrameIntervalMs = 20
+
+// Shows the LED sequence configuration page.
+func (web *Web) ledSequencesGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderLedSequences(w, r, "")
+}
+
+// Creates, saves or deletes a custom LED sequence.
+func (web *Web) ledSequencesPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	sequenceId, _ := strconv.Atoi(r.PostFormValue("id"))
+	ledSequence, err := web.arena.Database.GetLedSequenceById(sequenceId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if ledSequence != nil {
+			if events := web.arena.GetLedSequenceEvents(ledSequence.Name); len(events) > 0 {
+				web.renderLedSequences(w, r, fmt.Sprintf("Sequence '%s' can't be deleted while it is mapped to "+
+					"events: %s.", ledSequence.Name, strings.Join(events, ", ")))
+				return
+			}
+			err = web.arena.Database.DeleteLedSequence(ledSequence)
+		}
+	} else {
+		var newLedSequence *model.LedSequence
+		if newLedSequence, err = parseLedSequence(r, ""); err != nil {
+			web.renderLedSequences(w, r, err.Error())
+			return
+		}
+		var customSequences []model.LedSequence
+		if customSequences, err = web.arena.Database.GetAllLedSequences(); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		for _, customSequence := range customSequences {
+			if customSequence.Name == newLedSequence.Name && customSequence.Id != sequenceId {
+				web.renderLedSequences(w, r, fmt.Sprintf("A sequence named '%s' already exists.",
+					newLedSequence.Name))
+				return
+			}
+		}
+
+		if ledSequence == nil {
+			err = web.arena.Database.CreateLedSequence(newLedSequence)
+		} else {
+			newLedSequence.Id = ledSequence.Id
+			if err = web.arena.Database.SaveLedSequence(newLedSequence); err == nil &&
+				newLedSequence.Name != ledSequence.Name {
+				// Keep the events that show the sequence pointed at it under its new name.
+				err = web.arena.RenameLedEventSequence(ledSequence.Name, newLedSequence.Name)
+			}
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if err = web.arena.ReloadLedSettings(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/led_sequences", 303)
+}
+
+// Saves the LED controller configuration and the mapping of events to sequences.
+func (web *Web) ledSettingsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	ledSettings := model.LedSettings{Controllers: make(map[string]model.LedControllerSettings),
+		EventSequences: make(map[string]string)}
+	for _, name := range field.LedControllers {
+		numPixels, _ := strconv.Atoi(r.PostFormValue(name + "NumPixels"))
+		nearUniverse, _ := strconv.Atoi(r.PostFormValue(name + "NearUniverse"))
+		farUniverse, _ := strconv.Atoi(r.PostFormValue(name + "FarUniverse"))
+		var controller led.Controller
+		if err := controller.Configure(numPixels, nearUniverse, farUniverse); err != nil {
+			web.renderLedSequences(w, r, fmt.Sprintf("Controller '%s': %s", name, err.Error()))
+			return
+		}
+		ledSettings.Controllers[name] = model.LedControllerSettings{NumPixels: numPixels,
+			NearUniverse: nearUniverse, FarUniverse: farUniverse}
+	}
+	for _, event := range field.LedEvents {
+		var names []string
+		for _, name := range strings.Split(r.PostFormValue(event), ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if web.arena.GetLedSequence(name) == nil {
+				web.renderLedSequences(w, r, fmt.Sprintf("Event '%s' refers to unknown sequence '%s'.", event,
+					name))
+				return
+			}
+			names = append(names, name)
+		}
+		ledSettings.EventSequences[event] = strings.Join(names, ",")
+	}
+
+	if err := web.arena.Database.SaveLedSettings(&ledSettings); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if err := web.arena.ReloadLedSettings(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/led_sequences", 303)
+}
+
+// Renders the frames of either the named sequence or the one given in the request, for animating in the browser.
+func (web *Web) ledSequencePreviewPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	var sequence *led.Sequence
+	if name := r.PostFormValue("sequence"); name != "" {
+		if sequence = web.arena.GetLedSequence(name); sequence == nil {
+			http.Error(w, fmt.Sprintf("Error: No such LED sequence: %s", name), 400)
+			return
+		}
+	} else {
+		// Allow previewing a sequence that hasn't been named yet.
+		ledSequence, err := parseLedSequence(r, "Preview")
+		if err != nil {
+			http.Error(w, "Error: "+err.Error(), 400)
+			return
+		}
+		sequence = ledSequence.Sequence()
+	}
+	numPixels := web.arena.GetLedControllerSettings(field.ScaleLedController).NumPixels
+	isRed := r.PostFormValue("alliance") != "blue"
+
+	frameInterval := ledPreviewFrameIntervalMs * time.Millisecond
+	preview := struct {
+		FrameIntervalMs int
+		Frames          [][]string
+	}{ledPreviewFrameIntervalMs, sequence.Preview(numPixels, isRed, frameInterval, sequence.PreviewLength())}
+	jsonData, err := json.Marshal(preview)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Builds and validates a sequence from the name, loop and JSON keyframes fields of the request, using the given
+// default name if none is given.
+func parseLedSequence(r *http.Request, defaultName string) (*model.LedSequence, error) {
+	ledSequence := model.LedSequence{Name: strings.TrimSpace(r.PostFormValue("name")),
+		Loop: r.PostFormValue("loop") == "on"}
+	if ledSequence.Name == "" {
+		ledSequence.Name = defaultName
+	}
+	if err := json.Unmarshal([]byte(r.PostFormValue("keyframes")), &ledSequence.Keyframes); err != nil {
+		return nil, fmt.Errorf("The keyframes are not valid JSON: %s", err.Error())
+	}
+	if err := ledSequence.Sequence().Validate(); err != nil {
+		return nil, err
+	}
+	return &ledSequence, nil
+}
+
+func (web *Web) renderLedSequences(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_led_sequences.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	customSequences, err := web.arena.Database.GetAllLedSequences()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	type sequenceForm struct {
+		model.LedSequence
+		KeyframesJson string
+	}
+	var sequenceForms []sequenceForm
+	for _, customSequence := range customSequences {
+		keyframesJson, err := json.MarshalIndent(customSequence.Keyframes, "", "  ")
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		sequenceForms = append(sequenceForms, sequenceForm{customSequence, string(keyframesJson)})
+	}
+
+	// Append a blank entry to the end that can be used to add a new one.
+	sequenceForms = append(sequenceForms, sequenceForm{KeyframesJson: `[
+  {"Effect": "solid", "Colors": ["alliance"]}
+]`})
+
+	controllers := make(map[string]model.LedControllerSettings)
+	for _, name := range field.LedControllers {
+		controllers[name] = web.arena.GetLedControllerSettings(name)
+	}
+	eventSequences := make(map[string]string)
+	for _, event := range field.LedEvents {
+		eventSequences[event] = strings.Replace(web.arena.GetLedEventSequenceNames(event), ",", ", ", -1)
+	}
+	var builtInSequences []string
+	for _, sequence := range led.DefaultSequences {
+		builtInSequences = append(builtInSequences, sequence.Name)
+	}
+
+	data := struct {
+		*model.EventSettings
+		ControllerNames  []string
+		Controllers      map[string]model.LedControllerSettings
+		Events           []string
+		EventSequences   map[string]string
+		Sequences        []sequenceForm
+		BuiltInSequences []string
+		Effects          []string
+		ErrorMessage     string
+	}{web.arena.EventSettings, field.LedControllers, controllers, field.LedEvents, eventSequences, sequenceForms,
+		builtInSequences, led.Effects, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
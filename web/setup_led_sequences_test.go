@@ -0,0 +1,118 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestSetupLedSequences(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/led_sequences")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "LED Sequences - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), "Warmup Gradient")
+
+	keyframes := url.QueryEscape(`[{"Effect": "blink", "Colors": ["white"], "DurationMs": 1000, "PeriodMs": 100}]`)
+	recorder = web.postHttpResponse("/setup/led_sequences", "name=&keyframes="+keyframes)
+	assert.Contains(t, recorder.Body.String(), "The sequence name can't be blank.")
+	recorder = web.postHttpResponse("/setup/led_sequences", "name=Sparkle&keyframes=[")
+	assert.Contains(t, recorder.Body.String(), "The keyframes are not valid JSON")
+	recorder = web.postHttpResponse("/setup/led_sequences", "name=Sparkle&keyframes="+
+		url.QueryEscape(`[{"Effect": "blink", "Colors": ["mauve"], "PeriodMs": 100}]`))
+	assert.Contains(t, recorder.Body.String(), "unknown color 'mauve'.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences", "name=Sparkle&loop=on&keyframes="+keyframes)
+	assert.Equal(t, 303, recorder.Code)
+	ledSequences, _ := web.arena.Database.GetAllLedSequences()
+	if !assert.Equal(t, 1, len(ledSequences)) {
+		return
+	}
+	assert.Equal(t, "Sparkle", ledSequences[0].Name)
+	assert.True(t, ledSequences[0].Loop)
+	assert.NotNil(t, web.arena.GetLedSequence("Sparkle"))
+
+	recorder = web.postHttpResponse("/setup/led_sequences", "name=Sparkle&keyframes="+keyframes)
+	assert.Contains(t, recorder.Body.String(), "A sequence named 'Sparkle' already exists.")
+
+	// Check that renaming the sequence keeps the events mapped to it.
+	settingsForm := ""
+	for _, name := range field.LedControllers {
+		settingsForm += fmt.Sprintf("%sNumPixels=60&%sNearUniverse=3&%sFarUniverse=4&", name, name, name)
+	}
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences/settings", settingsForm+"warmup=Sparkle,%20Warmup")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences",
+		fmt.Sprintf("id=%d&name=Glitter&keyframes=%s", ledSequences[0].Id, keyframes))
+	assert.Equal(t, 303, recorder.Code)
+	assert.Nil(t, web.arena.GetLedSequence("Sparkle"))
+	assert.NotNil(t, web.arena.GetLedSequence("Glitter"))
+	assert.Equal(t, "Glitter,Warmup", web.arena.GetLedEventSequenceNames(field.LedEventWarmup))
+	ledSettings, _ := web.arena.Database.GetLedSettings()
+	assert.Equal(t, "Glitter,Warmup", ledSettings.EventSequences[field.LedEventWarmup])
+
+	// Check that the sequence can't be deleted while it is mapped to an event.
+	recorder = web.postHttpResponse("/setup/led_sequences", fmt.Sprintf("id=%d&action=delete", ledSequences[0].Id))
+	assert.Contains(t, recorder.Body.String(), "Sequence 'Glitter' can't be deleted while it is mapped to events: warmup.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences/settings", settingsForm+"warmup=Warmup")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences",
+		fmt.Sprintf("id=%d&action=delete", ledSequences[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	ledSequences, _ = web.arena.Database.GetAllLedSequences()
+	assert.Empty(t, ledSequences)
+	assert.Nil(t, web.arena.GetLedSequence("Glitter"))
+}
+
+func TestSetupLedSettings(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postHttpResponse("/setup/led_sequences/settings", "scaleNumPixels=500")
+	assert.Contains(t, recorder.Body.String(), "Controller 'scale'")
+	form := "pause=Green"
+	for _, name := range field.LedControllers {
+		form += fmt.Sprintf("&%sNumPixels=60&%sNearUniverse=3&%sFarUniverse=4", name, name, name)
+	}
+	recorder = web.postHttpResponse("/setup/led_sequences/settings", form+"&warmup=Warmup,%20Bogus")
+	assert.Contains(t, recorder.Body.String(), "refers to unknown sequence 'Bogus'.")
+
+	recorder = web.postHttpResponseWithArenaLoop("/setup/led_sequences/settings", form+"&warmup=Warmup,%20Purple")
+	assert.Equal(t, 303, recorder.Code)
+	ledSettings, _ := web.arena.Database.GetLedSettings()
+	assert.Equal(t, 60, ledSettings.Controllers[field.ScaleLedController].NumPixels)
+	assert.Equal(t, "Warmup,Purple", ledSettings.EventSequences[field.LedEventWarmup])
+	assert.Equal(t, "Green", web.arena.GetLedEventSequenceNames(field.LedEventPause))
+	assert.Equal(t, "", ledSettings.EventSequences[field.LedEventPostMatch])
+}
+
+func TestSetupLedSequencePreview(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postHttpResponse("/setup/led_sequences/preview", "sequence=Bogus")
+	assert.Equal(t, 400, recorder.Code)
+	recorder = web.postHttpResponse("/setup/led_sequences/preview", "sequence=Red")
+	assert.Equal(t, 200, recorder.Code)
+	var preview struct {
+		FrameIntervalMs int
+		Frames          [][]string
+	}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &preview))
+	assert.Equal(t, 20, preview.FrameIntervalMs)
+	if assert.NotEmpty(t, preview.Frames) {
+		assert.Equal(t, "#ff0000", preview.Frames[0][0])
+	}
+
+	recorder = web.postHttpResponse("/setup/led_sequences/preview", "alliance=blue&keyframes="+
+		url.QueryEscape(`[{"Effect": "solid", "Colors": ["alliance"]}]`))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &preview))
+	assert.Equal(t, "#0000ff", preview.Frames[0][0])
+	recorder = web.postHttpResponse("/setup/led_sequences/preview", "keyframes=[]")
+	assert.Equal(t, 400, recorder.Code)
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for configuring the venue lighting fixtures and cues, and for firing cues by hand.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Shows the lighting configuration page.
+func (web *Web) lightingGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderLighting(w, r, "")
+}
+
+// Creates, saves or deletes a lighting fixture.
+func (web *Web) lightingFixturesPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	fixtureId, _ := strconv.Atoi(r.PostFormValue("id"))
+	fixture, err := web.arena.Database.GetLightingFixtureById(fixtureId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if fixture != nil {
+			err = web.arena.Database.DeleteLightingFixture(fixture)
+		}
+	} else {
+		newFixture := model.LightingFixture{Name: strings.TrimSpace(r.PostFormValue("name")),
+			Group: strings.TrimSpace(r.PostFormValue("group")), Profile: r.PostFormValue("profile")}
+		newFixture.Universe, _ = strconv.Atoi(r.PostFormValue("universe"))
+		newFixture.Address, _ = strconv.Atoi(r.PostFormValue("address"))
+		if newFixture.Profile == lighting.CustomProfile {
+			for _, channel := range strings.Split(r.PostFormValue("channels"), ",") {
+				if channel = strings.TrimSpace(channel); channel != "" {
+					newFixture.Channels = append(newFixture.Channels, channel)
+				}
+			}
+		}
+		if errorMessage := web.checkLightingFixture(&newFixture, fixtureId); errorMessage != "" {
+			web.renderLighting(w, r, errorMessage)
+			return
+		}
+
+		if fixture == nil {
+			err = web.arena.Database.CreateLightingFixture(&newFixture)
+		} else {
+			newFixture.Id = fixture.Id
+			err = web.arena.Database.SaveLightingFixture(&newFixture)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if err = web.arena.ReloadLighting(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/lighting", 303)
+}
+
+// Creates, saves or deletes a lighting cue.
+func (web *Web) lightingCuesPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	cueId, _ := strconv.Atoi(r.PostFormValue("id"))
+	cue, err := web.arena.Database.GetLightingCueById(cueId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if cue != nil {
+			err = web.arena.Database.DeleteLightingCue(cue)
+		}
+	} else {
+		newCue := model.LightingCue{Name: strings.TrimSpace(r.PostFormValue("name")),
+			Trigger: r.PostFormValue("trigger")}
+		newCue.FadeMs, _ = strconv.Atoi(r.PostFormValue("fadeMs"))
+		if err = json.Unmarshal([]byte(r.PostFormValue("looks")), &newCue.Looks); err != nil {
+			web.renderLighting(w, r, fmt.Sprintf("The looks are not valid JSON: %s", err.Error()))
+			return
+		}
+		if errorMessage := web.checkLightingCue(&newCue, cueId); errorMessage != "" {
+			web.renderLighting(w, r, errorMessage)
+			return
+		}
+
+		if cue == nil {
+			err = web.arena.Database.CreateLightingCue(&newCue)
+		} else {
+			newCue.Id = cue.Id
+			err = web.arena.Database.SaveLightingCue(&newCue)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if err = web.arena.ReloadLighting(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/lighting", 303)
+}
+
+// Fires the given lighting cue immediately.
+func (web *Web) lightingCueFirePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	cueId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	cue, err := web.arena.Database.GetLightingCueById(cueId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if cue == nil {
+		handleWebErr(w, fmt.Errorf("Lighting cue %d does not exist.", cueId))
+		return
+	}
+	if err = web.arena.FireLightingCue(cue.Name); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/lighting", 303)
+}
+
+// Returns a message describing the first problem with the given fixture, or a blank string if there is none.
+func (web *Web) checkLightingFixture(fixture *model.LightingFixture, fixtureId int) string {
+	lightingFixture := fixture.Fixture()
+	if err := lightingFixture.Validate(); err != nil {
+		return err.Error()
+	}
+	maxUniverse := lighting.MaxUniverse(web.arena.EventSettings.LightingProtocol)
+	if fixture.Universe > maxUniverse {
+		return fmt.Sprintf("Fixture '%s' must have a universe between 1 and %d.", fixture.Name, maxUniverse)
+	}
+	fixtures, err := web.arena.Database.GetAllLightingFixtures()
+	if err != nil {
+		return err.Error()
+	}
+	endAddress := fixture.Address + len(lightingFixture.GetChannels()) - 1
+	for _, otherFixture := range fixtures {
+		if otherFixture.Id == fixtureId {
+			continue
+		}
+		if otherFixture.Name == fixture.Name {
+			return fmt.Sprintf("A fixture named '%s' already exists.", fixture.Name)
+		}
+		otherLightingFixture := otherFixture.Fixture()
+		otherEndAddress := otherFixture.Address + len(otherLightingFixture.GetChannels()) - 1
+		if otherFixture.Universe == fixture.Universe && fixture.Address <= otherEndAddress &&
+			otherFixture.Address <= endAddress {
+			return fmt.Sprintf("Fixture '%s' overlaps the channels of fixture '%s'.", fixture.Name,
+				otherFixture.Name)
+		}
+	}
+	return ""
+}
+
+// Returns a message describing the first problem with the given cue, or a blank string if there is none.
+func (web *Web) checkLightingCue(cue *model.LightingCue, cueId int) string {
+	if err := cue.Cue().Validate(); err != nil {
+		return err.Error()
+	}
+	if cue.Trigger != "" {
+		validTrigger := false
+		for _, trigger := range field.LightingTriggers {
+			validTrigger = validTrigger || cue.Trigger == trigger
+		}
+		if !validTrigger {
+			return fmt.Sprintf("Unknown lighting trigger '%s'.", cue.Trigger)
+		}
+	}
+	cues, err := web.arena.Database.GetAllLightingCues()
+	if err != nil {
+		return err.Error()
+	}
+	for _, otherCue := range cues {
+		if otherCue.Id == cueId {
+			continue
+		}
+		if otherCue.Name == cue.Name {
+			return fmt.Sprintf("A cue named '%s' already exists.", cue.Name)
+		}
+		if cue.Trigger != "" && otherCue.Trigger == cue.Trigger {
+			return fmt.Sprintf("Cue '%s' is already fired by the %s trigger.", otherCue.Name, cue.Trigger)
+		}
+	}
+	return ""
+}
+
+// Returns an error if any existing fixture uses a universe that the given protocol can't address.
+func (web *Web) checkLightingFixtureUniverses(protocol string) error {
+	fixtures, err := web.arena.Database.GetAllLightingFixtures()
+	if err != nil {
+		return err
+	}
+	maxUniverse := lighting.MaxUniverse(protocol)
+	for _, fixture := range fixtures {
+		if fixture.Universe > maxUniverse {
+			return fmt.Errorf("Fixture '%s' is in universe %d, which is beyond the maximum of %d for %s.",
+				fixture.Name, fixture.Universe, maxUniverse, protocol)
+		}
+	}
+	return nil
+}
+
+func (web *Web) renderLighting(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_lighting.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	fixtures, err := web.arena.Database.GetAllLightingFixtures()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	cues, err := web.arena.Database.GetAllLightingCues()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Append a blank entry to the end of each list that can be used to add a new one.
+	fixtures = append(fixtures, model.LightingFixture{Profile: lighting.FixtureProfiles[0].Name, Universe: 1,
+		Address: 1})
+	type cueForm struct {
+		model.LightingCue
+		LooksJson string
+	}
+	var cueForms []cueForm
+	for _, cue := range cues {
+		looksJson, err := json.MarshalIndent(cue.Looks, "", "  ")
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		cueForms = append(cueForms, cueForm{cue, string(looksJson)})
+	}
+	cueForms = append(cueForms, cueForm{LooksJson: `[
+  {"Target": "all", "Color": "white", "Dimmer": 100}
+]`})
+
+	data := struct {
+		*model.EventSettings
+		Fixtures     []model.LightingFixture
+		Cues         []cueForm
+		Profiles     []lighting.FixtureProfile
+		Channels     []string
+		Triggers     []string
+		CurrentCue   string
+		ErrorMessage string
+	}{web.arena.EventSettings, fixtures, cueForms, lighting.FixtureProfiles, lighting.Channels,
+		field.LightingTriggers, web.arena.Lighting.GetCurrentCue(), errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/lighting"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestSetupLightingFixtures(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/lighting")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Venue Lighting - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), "Moving Head")
+
+	recorder = web.postHttpResponse("/setup/lighting/fixtures", "name=&profile=RGB+Par&universe=1&address=1")
+	assert.Contains(t, recorder.Body.String(), "The fixture name can't be blank.")
+	recorder = web.postHttpResponse("/setup/lighting/fixtures", "name=Wash&profile=Custom&channels=dimmer,+fog&"+
+		"universe=1&address=1")
+	assert.Contains(t, recorder.Body.String(), "has unknown channel type 'fog'.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/fixtures", "name=Wash&group=Stage&profile=Custom&"+
+		"channels=dimmer,+red&universe=1&address=1")
+	assert.Equal(t, 303, recorder.Code)
+	fixtures, _ := web.arena.Database.GetAllLightingFixtures()
+	if !assert.Equal(t, 1, len(fixtures)) {
+		return
+	}
+	assert.Equal(t, model.LightingFixture{Id: fixtures[0].Id, Name: "Wash", Group: "Stage", Profile: "Custom",
+		Channels: []string{"dimmer", "red"}, Universe: 1, Address: 1}, fixtures[0])
+
+	recorder = web.postHttpResponse("/setup/lighting/fixtures", "name=Wash&profile=RGB+Par&universe=2&address=1")
+	assert.Contains(t, recorder.Body.String(), "A fixture named 'Wash' already exists.")
+	recorder = web.postHttpResponse("/setup/lighting/fixtures", "name=Mover&profile=Moving+Head&universe=1&"+
+		"address=2")
+	assert.Contains(t, recorder.Body.String(), "Fixture 'Mover' overlaps the channels of fixture 'Wash'.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/fixtures", "name=Mover&profile=Moving+Head&universe=1&"+
+		"address=3")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/fixtures",
+		"name=Far&profile=RGB+Par&universe=40000&address=1")
+	assert.Equal(t, 303, recorder.Code)
+
+	// Check that the protocol can't be changed to one that can't address all the fixtures.
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&lightingProtocol=artnet")
+	assert.Contains(t, recorder.Body.String(), "Fixture 'Far' is in universe 40000")
+
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/fixtures",
+		fmt.Sprintf("id=%d&name=Wash&profile=RGBW+Par&universe=3&address=10", fixtures[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	fixture, _ := web.arena.Database.GetLightingFixtureById(fixtures[0].Id)
+	assert.Equal(t, "RGBW Par", fixture.Profile)
+	assert.Empty(t, fixture.Channels)
+
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/fixtures",
+		fmt.Sprintf("id=%d&action=delete", fixture.Id))
+	assert.Equal(t, 303, recorder.Code)
+	fixtures, _ = web.arena.Database.GetAllLightingFixtures()
+	assert.Equal(t, 2, len(fixtures))
+}
+
+func TestSetupLightingCues(t *testing.T) {
+	web := setupTestWeb(t)
+
+	looks := url.QueryEscape(`[{"Target": "all", "Color": "winner", "Dimmer": 100}]`)
+	recorder := web.postHttpResponse("/setup/lighting/cues", "name=Final+Score&trigger=finalScore&looks=[")
+	assert.Contains(t, recorder.Body.String(), "The looks are not valid JSON")
+	recorder = web.postHttpResponse("/setup/lighting/cues", "name=Final+Score&trigger=finalScore&looks="+
+		url.QueryEscape(`[{"Target": "all", "Color": "mauve"}]`))
+	assert.Contains(t, recorder.Body.String(), "unknown color 'mauve'.")
+	recorder = web.postHttpResponse("/setup/lighting/cues", "name=Final+Score&trigger=confetti&looks="+looks)
+	assert.Contains(t, recorder.Body.String(), "Unknown lighting trigger 'confetti'.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/cues", "name=Final+Score&trigger=finalScore&fadeMs=500&"+
+		"looks="+looks)
+	assert.Equal(t, 303, recorder.Code)
+	cues, _ := web.arena.Database.GetAllLightingCues()
+	if !assert.Equal(t, 1, len(cues)) {
+		return
+	}
+	assert.Equal(t, "finalScore", cues[0].Trigger)
+	assert.Equal(t, 500, cues[0].FadeMs)
+	assert.Equal(t, lighting.WinnerColor, cues[0].Looks[0].Color)
+
+	recorder = web.postHttpResponse("/setup/lighting/cues", "name=Final+Score&looks="+looks)
+	assert.Contains(t, recorder.Body.String(), "A cue named 'Final Score' already exists.")
+	recorder = web.postHttpResponse("/setup/lighting/cues", "name=Celebration&trigger=finalScore&looks="+looks)
+	assert.Contains(t, recorder.Body.String(), "Cue 'Final Score' is already fired by the finalScore trigger.")
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/cues", "name=Blackout&looks="+
+		url.QueryEscape(`[{"Target": "all", "Dimmer": 0}]`))
+	assert.Equal(t, 303, recorder.Code)
+	cues, _ = web.arena.Database.GetAllLightingCues()
+	assert.Equal(t, 2, len(cues))
+
+	// Check that a cue can be fired by hand.
+	recorder = web.postHttpResponseWithArenaLoop(fmt.Sprintf("/setup/lighting/cues/%d/fire", cues[0].Id), "")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "Blackout", web.arena.Lighting.GetCurrentCue())
+	recorder = web.getHttpResponse("/setup/lighting")
+	assert.Contains(t, recorder.Body.String(), "Current cue: <b>Blackout</b>")
+	recorder = web.postHttpResponse("/setup/lighting/cues/1114/fire", "")
+	assert.Equal(t, 500, recorder.Code)
+
+	recorder = web.postHttpResponseWithArenaLoop("/setup/lighting/cues",
+		fmt.Sprintf("id=%d&action=delete", cues[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	cues, _ = web.arena.Database.GetAllLightingCues()
+	assert.Equal(t, 1, len(cues))
+}
+
+func TestSetupSettingsLighting(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postHttpResponse("/setup/settings", "numElimAlliances=8&lightingProtocol=artnet&"+
+		"lightingAddress=multicast")
+	assert.Contains(t, recorder.Body.String(), "Art-Net doesn't support multicast")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&lightingProtocol=e131&"+
+		"lightingPriority=250")
+	assert.Contains(t, recorder.Body.String(), "The lighting priority must be between 0 and 200.")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&lightingProtocol=artnet&"+
+		"lightingAddress=127.0.0.1:6454&lightingPriority=100&lightingSyncUniverse=5")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "artnet", web.arena.EventSettings.LightingProtocol)
+	assert.Equal(t, "127.0.0.1:6454", web.arena.EventSettings.LightingAddress)
+	assert.Equal(t, 5, web.arena.EventSettings.LightingSyncUniverse)
+}
@@ -6,11 +6,15 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/partner"
 	"github.com/Team254/cheesy-arena/tournament"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -85,6 +89,104 @@ func (web *Web) scheduleGeneratePostHandler(w http.ResponseWriter, r *http.Reque
 		web.renderSchedule(w, r, fmt.Sprintf("Error generating schedule: %s.", err.Error()))
 		return
 	}
+	cacheSchedule(matchType, matches)
+
+	http.Redirect(w, r, "/setup/schedule?matchType="+matchType, 303)
+}
+
+// Imports the official schedule from the partner data sources and presents it for review without saving it.
+func (web *Web) scheduleImportPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	matchType := getMatchType(r)
+	if matchType != "qualification" {
+		web.renderSchedule(w, r, "Only the qualification schedule can be imported.")
+		return
+	}
+	matches, err := web.arena.PartnerSource.GetEventSchedule(matchType)
+	if err != nil {
+		web.renderSchedule(w, r, fmt.Sprintf("Error importing schedule: %s", err.Error()))
+		return
+	}
+	if len(matches) == 0 {
+		web.renderSchedule(w, r, "No official schedule has been published for the event yet.")
+		return
+	}
+
+	// Check that every team in the schedule is in the team list, so that they can be assigned to the field.
+	var missingTeams []int
+	checkedTeams := make(map[int]bool)
+	for _, match := range matches {
+		for _, teamId := range []int{match.Red1, match.Red2, match.Red3, match.Blue1, match.Blue2, match.Blue3} {
+			if teamId == 0 || checkedTeams[teamId] {
+				continue
+			}
+			checkedTeams[teamId] = true
+			team, err := web.arena.Database.GetTeamById(teamId)
+			if err != nil {
+				handleWebErr(w, err)
+				return
+			}
+			if team == nil {
+				missingTeams = append(missingTeams, teamId)
+			}
+		}
+	}
+	if len(missingTeams) > 0 {
+		web.renderSchedule(w, r, fmt.Sprintf("The official schedule contains teams that aren't in the team list: %v",
+			missingTeams))
+		return
+	}
+	cacheSchedule(matchType, matches)
+
+	http.Redirect(w, r, "/setup/schedule?matchType="+matchType, 303)
+}
+
+// Imports a schedule from an uploaded CSV or FRC Events API JSON file and presents it for review without saving it.
+func (web *Web) scheduleImportFilePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	matchType := getMatchType(r)
+	if matchType != "practice" && matchType != "qualification" {
+		handleWebErr(w, fmt.Errorf("Invalid match type '%s'.", matchType))
+		return
+	}
+	file, _, err := r.FormFile("scheduleFile")
+	if err != nil {
+		web.renderSchedule(w, r, "No schedule file was specified.")
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	var matches []model.Match
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		matches, err = partner.ParseFrcEventsSchedule(data, matchType)
+		if err == nil {
+			err = tournament.ValidateImportedSchedule(web.arena.Database, matches, 1)
+		}
+	} else {
+		matches, err = tournament.ParseScheduleCsv(bytes.NewReader(data), web.arena.Database, matchType)
+	}
+	if err != nil {
+		web.renderSchedule(w, r, fmt.Sprintf("Error importing schedule: %s", err.Error()))
+		return
+	}
+	cacheSchedule(matchType, matches)
+
+	http.Redirect(w, r, "/setup/schedule?matchType="+matchType, 303)
+}
+
+// Holds the given schedule for review until it is saved, along with the first match of each team in it.
+func cacheSchedule(matchType string, matches []model.Match) {
 	cachedMatches[matchType] = matches
 
 	// Determine each team's first match.
@@ -104,22 +206,14 @@ func (web *Web) scheduleGeneratePostHandler(w http.ResponseWriter, r *http.Reque
 		checkTeam(match.Blue3)
 	}
 	cachedTeamFirstMatches[matchType] = teamFirstMatches
-
-	http.Redirect(w, r, "/setup/schedule?matchType="+matchType, 303)
 }
 
 // Publishes the schedule in the database to TBA
 func (web *Web) scheduleRepublishPostHandler(w http.ResponseWriter, r *http.Request) {
 	if web.arena.EventSettings.TbaPublishingEnabled {
-		// Publish schedule to The Blue Alliance.
-		err := web.arena.TbaClient.DeletePublishedMatches()
-		if err != nil {
-			http.Error(w, "Failed to delete published matches: "+err.Error(), 500)
-			return
-		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
-		if err != nil {
-			http.Error(w, "Failed to publish matches: "+err.Error(), 500)
+		// Queue the schedule to be republished asynchronously to The Blue Alliance.
+		if err := web.arena.QueueTbaPublish(model.TbaRepublishSchedule); err != nil {
+			handleWebErr(w, err)
 			return
 		}
 	} else {
@@ -155,6 +249,7 @@ func (web *Web) scheduleSavePostHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	}
+	web.arena.ScheduleNotifier.Notify()
 
 	// Back up the database.
 	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "post_scheduling")
@@ -164,15 +259,9 @@ func (web *Web) scheduleSavePostHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if web.arena.EventSettings.TbaPublishingEnabled && matchType != "practice" {
-		// Publish schedule to The Blue Alliance.
-		err = web.arena.TbaClient.DeletePublishedMatches()
-		if err != nil {
-			http.Error(w, "Failed to delete published matches: "+err.Error(), 500)
-			return
-		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
-		if err != nil {
-			http.Error(w, "Failed to publish matches: "+err.Error(), 500)
+		// Queue the schedule to be republished asynchronously to The Blue Alliance.
+		if err = web.arena.QueueTbaPublish(model.TbaRepublishSchedule); err != nil {
+			handleWebErr(w, err)
 			return
 		}
 	}
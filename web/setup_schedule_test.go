@@ -4,8 +4,12 @@
 package web
 
 import (
+	"bytes"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/partner"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -34,13 +38,13 @@ func TestSetupSchedule(t *testing.T) {
 	assert.Contains(t, recorder.Body.String(), "2014-01-02 11:48:00") // Last match of second block.
 	assert.Contains(t, recorder.Body.String(), "2014-01-03 16:54:00") // Last match of third block.
 
-	// Save schedule and check that it is published to TBA.
-	web.arena.TbaClient.BaseUrl = "fakeUrl"
+	// Save schedule and check that it is queued to be published to TBA.
 	web.arena.EventSettings.TbaPublishingEnabled = true
 	recorder = web.postHttpResponse("/setup/schedule/save?matchType=qualification", "")
 	matches, err := web.arena.Database.GetMatchesByType("qualification")
-	assert.Equal(t, 500, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "Failed to delete published matches")
+	assert.Equal(t, 303, recorder.Code)
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaRepublishSchedule)
+	assert.NotNil(t, item)
 	assert.Nil(t, err)
 	assert.Equal(t, 64, len(matches))
 	location, _ := time.LoadLocation("Local")
@@ -98,3 +102,96 @@ func TestSetupScheduleErrors(t *testing.T) {
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "schedule of 2 practice matches already exists")
 }
+
+func TestSetupScheduleImport(t *testing.T) {
+	web := setupTestWeb(t)
+
+	// Mock the FRC Events API server to import the schedule from.
+	frcEventsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2.0/2018/schedule/CASJ", r.URL.Path)
+		w.Write([]byte(`{"Schedule": [{"matchNumber": 1, "startTime": "2018-03-02T09:00:00", "teams": [
+			{"teamNumber": 101, "station": "Red1"}, {"teamNumber": 102, "station": "Red2"},
+			{"teamNumber": 103, "station": "Red3"}, {"teamNumber": 104, "station": "Blue1"},
+			{"teamNumber": 105, "station": "Blue2"}, {"teamNumber": 106, "station": "Blue3"}]}]}`))
+	}))
+	defer frcEventsServer.Close()
+	frcEventsClient := partner.NewFrcEventsClient("user", "token", "CASJ", 2018)
+	frcEventsClient.BaseUrl = frcEventsServer.URL
+	web.arena.PartnerSource = partner.NewFallbackSource(frcEventsClient)
+
+	recorder := web.postHttpResponse("/setup/schedule/import", "matchType=practice")
+	assert.Contains(t, recorder.Body.String(), "Only the qualification schedule can be imported.")
+
+	// Check that the schedule is rejected if it references teams that aren't in the team list.
+	recorder = web.postHttpResponse("/setup/schedule/import", "matchType=qualification")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "contains teams that aren't in the team list: [101 102 103 104 "+
+		"105 106]")
+
+	for i := 101; i <= 106; i++ {
+		web.arena.Database.CreateTeam(&model.Team{Id: i})
+	}
+	recorder = web.postHttpResponse("/setup/schedule/import", "matchType=qualification")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.getHttpResponse("/setup/schedule?matchType=qualification")
+	assert.Contains(t, recorder.Body.String(), "2018-03-02 09:00:00")
+	recorder = web.postHttpResponse("/setup/schedule/save?matchType=qualification", "")
+	assert.Equal(t, 303, recorder.Code)
+	matches, _ := web.arena.Database.GetMatchesByType("qualification")
+	if assert.Equal(t, 1, len(matches)) {
+		assert.Equal(t, 101, matches[0].Red1)
+		assert.Equal(t, 106, matches[0].Blue3)
+	}
+
+	// Check that errors from the partner source are shown.
+	web.arena.PartnerSource = partner.NewFallbackSource()
+	recorder = web.postHttpResponse("/setup/schedule/import", "matchType=qualification")
+	assert.Contains(t, recorder.Body.String(), "Error importing schedule: No data sources are configured.")
+}
+
+func TestSetupScheduleImportFile(t *testing.T) {
+	web := setupTestWeb(t)
+
+	for i := 1; i <= 6; i++ {
+		web.arena.Database.CreateTeam(&model.Team{Id: i})
+	}
+
+	recorder := web.postFileHttpResponse("/setup/schedule/import_file?matchType=practice", "scheduleFile",
+		bytes.NewBufferString("Match,Time,Red1,Red2,Red3,Blue1,Blue2,Blue3\nP1,2018-11-23 13:30,1,2,3,4,5,6\n"+
+			"P2,2018-11-23 13:37,1,2,3,4,5,7\n"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Row 3: Team 7 isn")
+
+	recorder = web.postFileHttpResponse("/setup/schedule/import_file?matchType=practice", "scheduleFile",
+		bytes.NewBufferString("Match,Time,Red1,Red2,Red3,Blue1,Blue2,Blue3,Blue3IsSurrogate\n"+
+			"P1,2018-11-23 13:30,1,2,3,4,5,6,true\nP2,2018-11-23 13:37,6,5,4,3,2,1,false\n"))
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/setup/schedule/save?matchType=practice", "")
+	assert.Equal(t, 303, recorder.Code)
+	matches, _ := web.arena.Database.GetMatchesByType("practice")
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, "P1", matches[0].DisplayName)
+		assert.True(t, matches[0].Blue3IsSurrogate)
+		assert.Equal(t, 37, matches[1].Time.Minute())
+		assert.Equal(t, 6, matches[1].Red1)
+	}
+
+	// Check importing a file in the FRC Events API format.
+	recorder = web.postFileHttpResponse("/setup/schedule/import_file?matchType=qualification", "scheduleFile",
+		bytes.NewBufferString(`{"Schedule": [{"matchNumber": 1, "startTime": "2018-11-24T09:00:00", "teams": [
+			{"teamNumber": 1, "station": "Red1"}, {"teamNumber": 2, "station": "Red2"},
+			{"teamNumber": 3, "station": "Red3"}, {"teamNumber": 4, "station": "Blue1"},
+			{"teamNumber": 5, "station": "Blue2"}, {"teamNumber": 6, "station": "Blue3"}]},
+			{"matchNumber": 2, "startTime": "2018-11-24T09:07:00", "teams": []}]}`))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Row 2: All six team positions must be filled.")
+
+	recorder = web.postFileHttpResponse("/setup/schedule/import_file?matchType=qualification", "scheduleFile",
+		bytes.NewBufferString(`{"Schedule": [{"matchNumber": 1, "startTime": "2018-11-24T09:00:00", "teams": [
+			{"teamNumber": 1, "station": "Red1"}, {"teamNumber": 2, "station": "Red2"},
+			{"teamNumber": 3, "station": "Red3"}, {"teamNumber": 4, "station": "Blue1"},
+			{"teamNumber": 5, "station": "Blue2"}, {"teamNumber": 6, "station": "Blue3"}]}]}`))
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.getHttpResponse("/setup/schedule?matchType=qualification")
+	assert.Contains(t, recorder.Body.String(), "2018-11-24 09:00:00")
+}
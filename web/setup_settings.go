@@ -6,12 +6,17 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/game"
+	"github.com/Team254/cheesy-arena/lighting"
 	"github.com/Team254/cheesy-arena/model"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -40,14 +45,65 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	backupRetentionCount, _ := strconv.Atoi(r.PostFormValue("backupRetentionCount"))
+	if backupRetentionCount < 0 {
+		web.renderSettings(w, r, "Number of backups to keep can't be negative.")
+		return
+	}
+
+	elimTiebreakers := r.PostFormValue("elimTiebreakers")
+	if _, err := game.ParseElimTiebreakers(elimTiebreakers); err != nil {
+		web.renderSettings(w, r, err.Error())
+		return
+	}
+
+	if r.PostFormValue("tbaPublishingEnabled") == "on" && r.PostFormValue("tbaMirrorEnabled") == "on" {
+		web.renderSettings(w, r, "Can't publish to and mirror from The Blue Alliance at the same time.")
+		return
+	}
+
+	if r.PostFormValue("tbaMirrorEnabled") == "on" && eventSettings.StandbyEnabled {
+		web.renderSettings(w, r, "Can't mirror from The Blue Alliance while in standby mode.")
+		return
+	}
+
+	lightingProtocol := r.PostFormValue("lightingProtocol")
+	if lightingProtocol == "" {
+		lightingProtocol = lighting.E131Protocol
+	}
+	lightingAddress := strings.TrimSpace(r.PostFormValue("lightingAddress"))
+	lightingPriority, _ := strconv.Atoi(r.PostFormValue("lightingPriority"))
+	lightingSyncUniverse, _ := strconv.Atoi(r.PostFormValue("lightingSyncUniverse"))
+	if err := lighting.ValidateOutputSettings(lightingProtocol, lightingAddress, lightingPriority,
+		lightingSyncUniverse); err != nil {
+		web.renderSettings(w, r, err.Error())
+		return
+	}
+	if err := web.checkLightingFixtureUniverses(lightingProtocol); err != nil {
+		web.renderSettings(w, r, err.Error())
+		return
+	}
+
 	eventSettings.NumElimAlliances = numAlliances
 	eventSettings.SelectionRound2Order = r.PostFormValue("selectionRound2Order")
 	eventSettings.SelectionRound3Order = r.PostFormValue("selectionRound3Order")
+	eventSettings.ElimTiebreakers = elimTiebreakers
 	eventSettings.TBADownloadEnabled = r.PostFormValue("TBADownloadEnabled") == "on"
 	eventSettings.TbaPublishingEnabled = r.PostFormValue("tbaPublishingEnabled") == "on"
-	eventSettings.TbaEventCode = r.PostFormValue("tbaEventCode")
+	eventSettings.TbaMirrorEnabled = r.PostFormValue("tbaMirrorEnabled") == "on"
+	if tbaEventCode := r.PostFormValue("tbaEventCode"); tbaEventCode != eventSettings.TbaEventCode {
+		// Forget what was published to the previous event so that every match is sent to the new one.
+		if err := web.arena.Database.TruncateTbaPublishedMatches(); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		eventSettings.TbaEventCode = tbaEventCode
+	}
 	eventSettings.TbaSecretId = r.PostFormValue("tbaSecretId")
 	eventSettings.TbaSecret = r.PostFormValue("tbaSecret")
+	eventSettings.FrcEventsUsername = r.PostFormValue("frcEventsUsername")
+	eventSettings.FrcEventsAuthToken = r.PostFormValue("frcEventsAuthToken")
+	eventSettings.FrcEventsEventCode = r.PostFormValue("frcEventsEventCode")
 	eventSettings.NetworkSecurityEnabled = r.PostFormValue("networkSecurityEnabled") == "on"
 	eventSettings.ApAddress = r.PostFormValue("apAddress")
 	eventSettings.ApUsername = r.PostFormValue("apUsername")
@@ -65,6 +121,11 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 	eventSettings.BlueSwitchLedAddress = r.PostFormValue("blueSwitchLedAddress")
 	eventSettings.RedVaultLedAddress = r.PostFormValue("redVaultLedAddress")
 	eventSettings.BlueVaultLedAddress = r.PostFormValue("blueVaultLedAddress")
+	eventSettings.BackupRetentionCount = backupRetentionCount
+	eventSettings.LightingProtocol = lightingProtocol
+	eventSettings.LightingAddress = lightingAddress
+	eventSettings.LightingPriority = lightingPriority
+	eventSettings.LightingSyncUniverse = lightingSyncUniverse
 
 	err := web.arena.Database.SaveEventSettings(eventSettings)
 	if err != nil {
@@ -82,20 +143,50 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/setup/settings", 303)
 }
 
-// Sends a copy of the event database file to the client as a download.
+// Sends a copy of the event database file to the client as a download, optionally migrated down to an older schema
+// version so that it can be loaded by an older version of the software.
 func (web *Web) saveDbHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
 		return
 	}
 
-	dbFile, err := os.Open(web.arena.Database.Path)
-	defer dbFile.Close()
+	schemaVersion, err := web.arena.Database.GetSchemaVersion()
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	filename := fmt.Sprintf("%s-%s.db", strings.Replace(web.arena.EventSettings.Name, " ", "_", -1),
-		time.Now().Format("20060102150405"))
+	dbPath := web.arena.Database.Path
+	if versionParam := r.URL.Query().Get("schemaVersion"); versionParam != "" {
+		targetVersion, err := strconv.ParseInt(versionParam, 10, 64)
+		if err != nil {
+			web.renderSettings(w, r, fmt.Sprintf("Invalid schema version '%s'.", versionParam))
+			return
+		}
+		if targetVersion != schemaVersion {
+			// Migrate a copy of the database so that the current one is left untouched.
+			tempFilePath, err := copyToTempFile(dbPath, "migrated-db-")
+			if err != nil {
+				handleWebErr(w, err)
+				return
+			}
+			defer os.Remove(tempFilePath)
+			if err = model.MigrateDatabase(tempFilePath, targetVersion); err != nil {
+				web.renderSettings(w, r, fmt.Sprintf("Could not migrate the database: %v", err))
+				return
+			}
+			dbPath = tempFilePath
+			schemaVersion = targetVersion
+		}
+	}
+
+	dbFile, err := os.Open(dbPath)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer dbFile.Close()
+	filename := fmt.Sprintf("%s-%s-schema%d.db", strings.Replace(web.arena.EventSettings.Name, " ", "_", -1),
+		time.Now().Format("20060102150405"), schemaVersion)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 	http.ServeContent(w, r, "", time.Now(), dbFile)
 }
@@ -127,6 +218,20 @@ func (web *Web) restoreDbHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tempFile.Close()
+	if _, err = model.GetDatabaseSchemaVersion(tempFilePath); err != nil {
+		web.renderSettings(w, r, "Could not read uploaded database backup file. Please verify that it a valid "+
+			"database file.")
+		return
+	}
+	message, err := checkDbSchemaVersion(tempFilePath, r.PostFormValue("confirmUpgrade") == "on")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if message != "" {
+		web.renderSettings(w, r, message)
+		return
+	}
 	tempDb, err := model.OpenDatabase(tempFilePath)
 	if err != nil {
 		web.renderSettings(w, r, "Could not read uploaded database backup file. Please verify that it a valid "+
@@ -135,30 +240,134 @@ func (web *Web) restoreDbHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	tempDb.Close()
 
-	// Back up the current database.
-	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_restore")
+	err = web.replaceDb(tempFilePath)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
 
+	http.Redirect(w, r, "/setup/settings", 303)
+}
+
+// Backs up the current database and then replaces it with the already-verified database file at the given path.
+func (web *Web) replaceDb(newDbPath string) error {
+	// Back up the current database.
+	err := web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_restore")
+	if err != nil {
+		return err
+	}
+
 	// Replace the current database with the new one.
 	web.arena.Database.Close()
 	err = os.Remove(web.arena.Database.Path)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(newDbPath, web.arena.Database.Path)
+	if err != nil {
+		return err
+	}
+	web.arena.Database, err = model.OpenDatabase(web.arena.Database.Path)
+	if err != nil {
+		return err
+	}
+	return web.arena.LoadSettings()
+}
+
+// Returns a message explaining why the database file at the given path shouldn't be loaded in place of the current
+// one, or a blank string if it can be. A database from a newer version of the software is always refused, and one
+// from an older version is only loaded once the user has confirmed that it may be upgraded.
+func checkDbSchemaVersion(path string, upgradeConfirmed bool) (string, error) {
+	schemaVersion, err := model.GetDatabaseSchemaVersion(path)
+	if err != nil {
+		return "", err
+	}
+	latestVersion, err := model.GetLatestSchemaVersion()
+	if err != nil {
+		return "", err
+	}
+	if schemaVersion > latestVersion {
+		return fmt.Sprintf("The database has schema version %d, which is newer than the latest version %d supported "+
+			"by this version of the software. Save a copy of it at schema version %d from the newer version instead.",
+			schemaVersion, latestVersion, latestVersion), nil
+	}
+	if schemaVersion < latestVersion && !upgradeConfirmed {
+		return fmt.Sprintf("The database has schema version %d and needs to be upgraded to version %d, after which "+
+			"older versions of the software won't be able to load it. Confirm the upgrade to load it.",
+			schemaVersion, latestVersion), nil
+	}
+	return "", nil
+}
+
+// Copies the given file to a new temporary file in the current directory and returns its path.
+func copyToTempFile(path, prefix string) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	tempFile, err := ioutil.TempFile(".", prefix)
+	if err != nil {
+		return "", err
+	}
+	defer tempFile.Close()
+	if _, err = io.Copy(tempFile, source); err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
+
+// Sends a portable bundle of all the event's data and team match logs, for archiving or moving to another machine.
+func (web *Web) exportEventBundleHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	var buffer bytes.Buffer
+	err := web.arena.Database.ExportEventBundle(&buffer, filepath.Join(model.BaseDir, field.LogsDir))
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	err = os.Rename(tempFilePath, web.arena.Database.Path)
+	filename := fmt.Sprintf("%s-%s.zip", strings.Replace(web.arena.EventSettings.Name, " ", "_", -1),
+		time.Now().Format("20060102150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Write(buffer.Bytes())
+}
+
+// Accepts an event bundle as an upload and replaces all of the event's data with its contents.
+func (web *Web) importEventBundleHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	file, _, err := r.FormFile("bundleFile")
+	if err != nil {
+		web.renderSettings(w, r, "No event bundle file was specified.")
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	web.arena.Database, err = model.OpenDatabase(web.arena.Database.Path)
+
+	// Back up the current database.
+	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_bundle_import")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
+
+	err = web.arena.Database.ImportEventBundle(bytes.NewReader(data), int64(len(data)),
+		filepath.Join(model.BaseDir, field.LogsDir))
+	if err != nil {
+		web.renderSettings(w, r, fmt.Sprintf("Could not import the event bundle: %v", err))
+		return
+	}
 	err = web.arena.LoadSettings()
 	if err != nil {
 		handleWebErr(w, err)
@@ -201,6 +410,13 @@ func (web *Web) clearDbHandler(w http.ResponseWriter, r *http.Request) {
 		handleWebErr(w, err)
 		return
 	}
+	err = web.arena.Database.TruncateAllianceSelection()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	web.arena.RankingsNotifier.Notify()
+	web.arena.ScheduleNotifier.Notify()
 	http.Redirect(w, r, "/setup/settings", 303)
 }
 
@@ -210,10 +426,23 @@ func (web *Web) renderSettings(w http.ResponseWriter, r *http.Request, errorMess
 		handleWebErr(w, err)
 		return
 	}
+	schemaVersion, err := web.arena.Database.GetSchemaVersion()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	schemaVersions, err := model.GetSchemaVersions()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
 	data := struct {
 		*model.EventSettings
-		ErrorMessage string
-	}{web.arena.EventSettings, errorMessage}
+		ElimTiebreakerOptions []game.ElimTiebreaker
+		SchemaVersion         int64
+		SchemaVersions        []int64
+		ErrorMessage          string
+	}{web.arena.EventSettings, game.ElimTiebreakers, schemaVersion, schemaVersions, errorMessage}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
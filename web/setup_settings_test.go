@@ -5,6 +5,7 @@ package web
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/Team254/cheesy-arena/game"
 	"github.com/Team254/cheesy-arena/model"
 	"github.com/Team254/cheesy-arena/tournament"
@@ -28,7 +29,8 @@ func TestSetupSettings(t *testing.T) {
 
 	// Change the settings and check the response.
 	recorder = web.postHttpResponse("/setup/settings", "name=Chezy Champs&code=CC&numElimAlliances=16&"+
-		"tbaPublishingEnabled=on&tbaEventCode=2014cc&tbaSecretId=secretId&tbaSecret=tbasec")
+		"tbaPublishingEnabled=on&tbaEventCode=2014cc&tbaSecretId=secretId&tbaSecret=tbasec&"+
+		"elimTiebreakers=parkclimb,fouls")
 	assert.Equal(t, 303, recorder.Code)
 	recorder = web.getHttpResponse("/setup/settings")
 	assert.Contains(t, recorder.Body.String(), "Chezy Champs")
@@ -37,6 +39,7 @@ func TestSetupSettings(t *testing.T) {
 	assert.Contains(t, recorder.Body.String(), "2014cc")
 	assert.Contains(t, recorder.Body.String(), "secretId")
 	assert.Contains(t, recorder.Body.String(), "tbasec")
+	assert.Contains(t, recorder.Body.String(), "parkclimb,fouls")
 }
 
 func TestSetupSettingsInvalidValues(t *testing.T) {
@@ -45,6 +48,17 @@ func TestSetupSettingsInvalidValues(t *testing.T) {
 	// Invalid number of alliances.
 	recorder := web.postHttpResponse("/setup/settings", "numAlliances=1")
 	assert.Contains(t, recorder.Body.String(), "must be between 2 and 16")
+
+	// Invalid elimination tiebreaker.
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&elimTiebreakers=auto,blorpy")
+	assert.Contains(t, recorder.Body.String(), "Invalid elimination tiebreaker 'blorpy'")
+	assert.Equal(t, "fouls,auto,ownership,parkclimb", web.arena.EventSettings.ElimTiebreakers)
+
+	// Publishing and mirroring enabled at the same time.
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&tbaPublishingEnabled=on&"+
+		"tbaMirrorEnabled=on")
+	assert.Contains(t, recorder.Body.String(), "Can't publish to and mirror from The Blue Alliance at the same time.")
+	assert.False(t, web.arena.EventSettings.TbaMirrorEnabled)
 }
 
 func TestSetupSettingsClearDb(t *testing.T) {
@@ -101,14 +115,87 @@ func TestSetupSettingsBackupRestoreDb(t *testing.T) {
 	// Check restoring with the backup retrieved before.
 	recorder = web.postFileHttpResponse("/setup/db/restore", "databaseFile", backupBody)
 	assert.Equal(t, "Chezy Champs", web.arena.EventSettings.Name)
+}
 
+func TestSetupSettingsDbSchemaVersion(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.Name = "Chezy Champs"
+	web.arena.Database.SaveEventSettings(web.arena.EventSettings)
+	schemaVersions, _ := model.GetSchemaVersions()
+	latestVersion := schemaVersions[len(schemaVersions)-1]
+	olderVersion := schemaVersions[len(schemaVersions)-2]
+
+	recorder := web.getHttpResponse("/setup/settings")
+	assert.Contains(t, recorder.Body.String(), fmt.Sprintf("Schema version %d", latestVersion))
+	recorder = web.getHttpResponse("/setup/db/save")
+	assert.Contains(t, recorder.HeaderMap["Content-Disposition"][0], fmt.Sprintf("schema%d.db", latestVersion))
+
+	// Save a copy for an older version of the software and check that the current database is untouched.
+	recorder = web.getHttpResponse("/setup/db/save?schemaVersion=12345")
+	assert.Contains(t, recorder.Body.String(), "Unknown schema version 12345")
+	recorder = web.getHttpResponse(fmt.Sprintf("/setup/db/save?schemaVersion=%d", olderVersion))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.HeaderMap["Content-Disposition"][0], fmt.Sprintf("schema%d.db", olderVersion))
+	olderBody := recorder.Body
+	schemaVersion, _ := web.arena.Database.GetSchemaVersion()
+	assert.Equal(t, latestVersion, schemaVersion)
+
+	// Check that loading the older database requires confirmation of the upgrade.
+	web = setupTestWeb(t)
+	olderBytes := olderBody.Bytes()
+	recorder = web.postFileHttpResponse("/setup/db/restore", "databaseFile", bytes.NewBuffer(olderBytes))
+	assert.Contains(t, recorder.Body.String(), "Confirm the upgrade to load it")
+	assert.NotEqual(t, "Chezy Champs", web.arena.EventSettings.Name)
+	recorder = web.postFileAndFieldsHttpResponse("/setup/db/restore", "databaseFile", bytes.NewBuffer(olderBytes),
+		map[string]string{"confirmUpgrade": "on"})
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "Chezy Champs", web.arena.EventSettings.Name)
+	schemaVersion, _ = web.arena.Database.GetSchemaVersion()
+	assert.Equal(t, latestVersion, schemaVersion)
+}
+
+func TestSetupSettingsExportImportEventBundle(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.EventSettings.Name = "Chezy Champs"
+	web.arena.Database.SaveEventSettings(web.arena.EventSettings)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+
+	recorder := web.getHttpResponse("/setup/db/export_bundle")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/zip", recorder.HeaderMap["Content-Type"][0])
+	bundleBody := recorder.Body
+
+	web = setupTestWeb(t)
+	assert.NotEqual(t, "Chezy Champs", web.arena.EventSettings.Name)
+
+	recorder = web.postHttpResponse("/setup/db/import_bundle", "")
+	assert.Contains(t, recorder.Body.String(), "No event bundle file was specified")
+
+	recorder = web.postFileHttpResponse("/setup/db/import_bundle", "bundleFile", bytes.NewBufferString("invalid"))
+	assert.Contains(t, recorder.Body.String(), "Could not import the event bundle")
+	assert.NotEqual(t, "Chezy Champs", web.arena.EventSettings.Name)
+
+	recorder = web.postFileHttpResponse("/setup/db/import_bundle", "bundleFile", bundleBody)
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "Chezy Champs", web.arena.EventSettings.Name)
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.NotNil(t, team)
 }
 
 func (web *Web) postFileHttpResponse(path string, paramName string, file *bytes.Buffer) *httptest.ResponseRecorder {
+	return web.postFileAndFieldsHttpResponse(path, paramName, file, nil)
+}
+
+func (web *Web) postFileAndFieldsHttpResponse(path string, paramName string, file *bytes.Buffer,
+	fields map[string]string) *httptest.ResponseRecorder {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
 	part, _ := writer.CreateFormFile(paramName, "file.ext")
 	io.Copy(part, file)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
 	writer.Close()
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", path, body)
@@ -0,0 +1,244 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for managing the sound packs and server-side playback of the sounds played on arena events.
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/sound"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shows the sound configuration page.
+func (web *Web) soundsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderSounds(w, r, "")
+}
+
+// Saves the choice of sound pack and the server-side playback settings.
+func (web *Web) soundSettingsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	soundPackId, _ := strconv.Atoi(r.PostFormValue("soundPackId"))
+	if soundPackId != 0 {
+		soundPack, err := web.arena.Database.GetSoundPackById(soundPackId)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if soundPack == nil {
+			web.renderSounds(w, r, fmt.Sprintf("Sound pack %d does not exist.", soundPackId))
+			return
+		}
+	}
+	soundPlayerCommand := strings.TrimSpace(r.PostFormValue("soundPlayerCommand"))
+	if err := sound.ValidateCommand(soundPlayerCommand); err != nil {
+		web.renderSounds(w, r, err.Error())
+		return
+	}
+
+	eventSettings := web.arena.EventSettings
+	eventSettings.SoundPackId = soundPackId
+	eventSettings.SoundPlayerCommand = soundPlayerCommand
+	if err := web.saveSoundSettings(eventSettings); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/sounds", 303)
+}
+
+// Accepts a zip file of sounds as an upload and creates a sound pack from it.
+func (web *Web) soundPacksPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	soundPack := model.SoundPack{Name: strings.TrimSpace(r.PostFormValue("name"))}
+	soundPack.Season, _ = strconv.Atoi(r.PostFormValue("season"))
+	if soundPack.Name == "" {
+		web.renderSounds(w, r, "The sound pack name can't be blank.")
+		return
+	}
+	soundPacks, err := web.arena.Database.GetAllSoundPacks()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	for _, otherSoundPack := range soundPacks {
+		if otherSoundPack.Name == soundPack.Name && otherSoundPack.Season == soundPack.Season {
+			web.renderSounds(w, r, fmt.Sprintf("A %d sound pack named '%s' already exists.", soundPack.Season,
+				soundPack.Name))
+			return
+		}
+	}
+	file, _, err := r.FormFile("soundPackFile")
+	if err != nil {
+		web.renderSounds(w, r, "No sound pack file was specified.")
+		return
+	}
+	zipData, err := ioutil.ReadAll(file)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	soundPackFiles, err := field.SoundPackFilesFromZip(zipData)
+	if err != nil {
+		web.renderSounds(w, r, err.Error())
+		return
+	}
+
+	if err = web.arena.Database.CreateSoundPack(&soundPack); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	for _, soundPackFile := range soundPackFiles {
+		soundPackFile.SoundPackId = soundPack.Id
+		if err = web.arena.Database.CreateSoundPackFile(&soundPackFile); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/setup/sounds", 303)
+}
+
+// Deletes the given sound pack, reverting to the built-in sounds if it was in use.
+func (web *Web) soundPackDeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	soundPackId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	soundPack, err := web.arena.Database.GetSoundPackById(soundPackId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if soundPack == nil {
+		handleWebErr(w, fmt.Errorf("Sound pack %d does not exist.", soundPackId))
+		return
+	}
+	if err = web.arena.Database.DeleteSoundPack(soundPack); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if web.arena.EventSettings.SoundPackId == soundPack.Id {
+		eventSettings := web.arena.EventSettings
+		eventSettings.SoundPackId = 0
+		if err = web.saveSoundSettings(eventSettings); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/setup/sounds", 303)
+}
+
+// Plays the given sound cue immediately, regardless of whether match sounds are muted, to test it.
+func (web *Web) soundPlayPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	cue := field.GetSoundCue(mux.Vars(r)["name"])
+	if cue == nil {
+		handleWebErr(w, fmt.Errorf("Sound cue '%s' does not exist.", mux.Vars(r)["name"]))
+		return
+	}
+	web.arena.PlaySound(cue.Name)
+
+	http.Redirect(w, r, "/setup/sounds", 303)
+}
+
+// Serves the file that the given sound pack plays for the given cue, for the displays to fetch.
+func (web *Web) soundPackFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsReader(w, r) {
+		return
+	}
+
+	soundPackId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	soundPackFile, err := web.arena.Database.GetSoundPackFile(soundPackId, mux.Vars(r)["cue"])
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if soundPackFile == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Use ServeContent so that range requests are supported, which some browsers require in order to play audio.
+	http.ServeContent(w, r, soundPackFile.Filename, time.Time{}, bytes.NewReader(soundPackFile.Data))
+}
+
+// Saves the given event settings and reloads the sounds to reflect them.
+func (web *Web) saveSoundSettings(eventSettings *model.EventSettings) error {
+	if err := web.arena.Database.SaveEventSettings(eventSettings); err != nil {
+		return err
+	}
+	return web.arena.LoadSounds()
+}
+
+func (web *Web) renderSounds(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_sounds.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	soundPacks, err := web.arena.Database.GetAllSoundPacks()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// List the cues that each sound pack includes, so that the ones falling back to the built-in sounds are clear.
+	type soundPackSummary struct {
+		model.SoundPack
+		Cues []string
+	}
+	var soundPackSummaries []soundPackSummary
+	for _, soundPack := range soundPacks {
+		soundPackFiles, err := web.arena.Database.GetSoundPackFiles(soundPack.Id)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		summary := soundPackSummary{SoundPack: soundPack}
+		for _, soundPackFile := range soundPackFiles {
+			summary.Cues = append(summary.Cues, soundPackFile.Cue)
+		}
+		soundPackSummaries = append(soundPackSummaries, summary)
+	}
+
+	data := struct {
+		*model.EventSettings
+		SoundCues        []field.SoundCue
+		SoundUrls        map[string]string
+		SoundPacks       []soundPackSummary
+		SoundPlayerError string
+		CurrentSeason    int
+		ErrorMessage     string
+	}{web.arena.EventSettings, field.SoundCues, web.arena.GetSoundUrls(), soundPackSummaries,
+		web.arena.SoundPlayer.GetLastError(), time.Now().Year(), errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/Team254/cheesy-arena/websocket"
+	gorillawebsocket "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupSounds(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/sounds")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "match-endgame")
+	assert.Contains(t, recorder.Body.String(), "/static/audio/match_endgame.wav")
+
+	// Upload a sound pack and check that it is listed.
+	recorder = web.postFileAndFieldsHttpResponse("/setup/sounds/packs", "soundPackFile",
+		createTestSoundPackZip(t, map[string]string{"match_endgame.mp3": "horn", "match_start.wav": "charge"}),
+		map[string]string{"name": "Power Up", "season": "2018"})
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	soundPacks, _ := web.arena.Database.GetAllSoundPacks()
+	if assert.Equal(t, 1, len(soundPacks)) {
+		assert.Equal(t, "Power Up", soundPacks[0].Name)
+		assert.Equal(t, 2018, soundPacks[0].Season)
+	}
+	recorder = web.getHttpResponse("/setup/sounds")
+	assert.Contains(t, recorder.Body.String(), "match-endgame, match-start")
+
+	// Check that the sound pack is used once it is chosen.
+	recorder = web.postHttpResponse("/setup/sounds/settings", "soundPackId=1&soundPlayerCommand=")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	assert.Equal(t, 1, web.arena.EventSettings.SoundPackId)
+	recorder = web.getHttpResponse("/setup/sounds")
+	assert.Contains(t, recorder.Body.String(), "/sound_packs/1/match-endgame")
+	assert.Contains(t, recorder.Body.String(), "/static/audio/match_end.wav")
+	recorder = web.getHttpResponse("/sound_packs/1/match-endgame")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "horn", recorder.Body.String())
+	assert.Equal(t, "audio/mpeg", recorder.Header().Get("Content-Type"))
+	recorder = web.getHttpResponse("/sound_packs/1/match-end")
+	assert.Equal(t, 404, recorder.Code)
+
+	// Check that deleting the sound pack in use reverts to the built-in sounds.
+	recorder = web.postHttpResponse("/setup/sounds/packs/1/delete", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	assert.Equal(t, 0, web.arena.EventSettings.SoundPackId)
+	assert.Equal(t, "/static/audio/match_endgame.wav", web.arena.GetSoundUrls()["match-endgame"])
+	soundPacks, _ = web.arena.Database.GetAllSoundPacks()
+	assert.Empty(t, soundPacks)
+}
+
+func TestSetupSoundsErrors(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postFileAndFieldsHttpResponse("/setup/sounds/packs", "soundPackFile",
+		createTestSoundPackZip(t, map[string]string{"match_begin.wav": "charge"}), map[string]string{"name": "Blorpy"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Sound file 'match_begin.wav' isn't named after any sound cue.")
+	recorder = web.postFileAndFieldsHttpResponse("/setup/sounds/packs", "soundPackFile",
+		createTestSoundPackZip(t, map[string]string{"match_start.wav": "charge"}), map[string]string{"name": " "})
+	assert.Contains(t, recorder.Body.String(), "The sound pack name can't be blank.")
+	recorder = web.postHttpResponse("/setup/sounds/packs", "name=Blorpy")
+	assert.Contains(t, recorder.Body.String(), "No sound pack file was specified.")
+	soundPacks, _ := web.arena.Database.GetAllSoundPacks()
+	assert.Empty(t, soundPacks)
+
+	recorder = web.postHttpResponse("/setup/sounds/settings", "soundPackId=5&soundPlayerCommand=")
+	assert.Contains(t, recorder.Body.String(), "Sound pack 5 does not exist.")
+	recorder = web.postHttpResponse("/setup/sounds/settings", "soundPackId=0&soundPlayerCommand=blorpy-player+-q")
+	assert.Contains(t, recorder.Body.String(), "The sound player program 'blorpy-player' can't be found.")
+	assert.Equal(t, "", web.arena.EventSettings.SoundPlayerCommand)
+
+	recorder = web.postHttpResponse("/setup/sounds/packs/5/delete", "")
+	assert.Equal(t, 500, recorder.Code)
+	recorder = web.postHttpResponse("/setup/sounds/match-blorpy/play", "")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Sound cue 'match-blorpy' does not exist.")
+}
+
+func TestSetupSoundsPlay(t *testing.T) {
+	web := setupTestWeb(t)
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/displays/audience/websocket?displayId=1", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+	readWebsocketMultiple(t, ws, 11)
+
+	// Check that the test button plays the sound even when match sounds are muted.
+	web.arena.MuteMatchSounds = true
+	recorder := web.postHttpResponse("/setup/sounds/match-abort/play", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	message := readWebsocketType(t, ws, "playSound")
+	assert.Equal(t, map[string]interface{}{"Name": "match-abort", "Url": "/static/audio/match_abort.mp3"}, message)
+}
+
+func createTestSoundPackZip(t *testing.T, files map[string]string) *bytes.Buffer {
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	for name, contents := range files {
+		file, err := writer.Create(name)
+		assert.Nil(t, err)
+		file.Write([]byte(contents))
+	}
+	assert.Nil(t, writer.Close())
+	return buffer
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for replicating the event to a hot-standby instance and for promoting the standby to primary.
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"net/http"
+	"strings"
+)
+
+// Shows the standby configuration and replication status.
+func (web *Web) standbyGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderStandby(w, r, "")
+}
+
+// Saves the standby configuration.
+func (web *Web) standbyPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	standbyEnabled := r.PostFormValue("standbyEnabled") == "on"
+	primaryAddress := strings.TrimSpace(r.PostFormValue("standbyPrimaryAddress"))
+	if standbyEnabled && primaryAddress == "" {
+		web.renderStandby(w, r, "The primary's address must be given to enable standby mode.")
+		return
+	}
+	if standbyEnabled && web.arena.EventSettings.TbaMirrorEnabled {
+		web.renderStandby(w, r, "Can't be a standby while mirroring from The Blue Alliance.")
+		return
+	}
+
+	if standbyEnabled && !web.arena.EventSettings.StandbyEnabled {
+		// Back up the database since it will be overwritten by the first sync from the primary.
+		err := web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_standby")
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	err := web.arena.ConfigureStandby(standbyEnabled, primaryAddress, r.PostFormValue("standbyPrimaryPassword"))
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/standby", 303)
+}
+
+// Promotes this standby instance to be the primary.
+func (web *Web) standbyPromotePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if err := web.arena.PromoteStandby(); err != nil {
+		web.renderStandby(w, r, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/match_play", 303)
+}
+
+// Serves the event data to a standby instance, or an empty response if it hasn't changed since the given revision.
+func (web *Web) standbySnapshotApiHandler(w http.ResponseWriter, r *http.Request) {
+	if adminPassword := web.arena.EventSettings.AdminPassword; adminPassword != "" {
+		if username, password, ok := r.BasicAuth(); !ok || username != adminUser || password != adminPassword {
+			http.Error(w, "Error: the admin password is required to replicate the event.", 401)
+			return
+		}
+	}
+
+	// Get the revision before reading the changes so that any made in between are picked up by the next request.
+	revision, err := web.arena.Database.GetRevision()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	tables, err := web.arena.Database.GetChangedTables(r.URL.Query().Get("revision"))
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if len(tables) == 0 {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	bundle, err := web.arena.Database.BuildEventBundleTables(tables)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data, err := json.Marshal(field.StandbySnapshot{Revision: revision, Tables: tables, Bundle: bundle})
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (web *Web) renderStandby(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_standby.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		StandbyStatus field.StandbyStatus
+		ErrorMessage  string
+	}{web.arena.EventSettings, web.arena.StandbyStatus, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSetupStandby(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/standby")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "This instance is the primary.")
+
+	recorder = web.postHttpResponse("/setup/standby", "standbyEnabled=on&standbyPrimaryAddress=+")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "The primary's address must be given")
+
+	field.RunWithTestArenaLoop(web.arena, func() error {
+		recorder = web.postHttpResponse("/setup/standby",
+			"standbyEnabled=on&standbyPrimaryAddress=10.0.100.5:8080&standbyPrimaryPassword=secret")
+		return nil
+	})
+	assert.Equal(t, 303, recorder.Code)
+	assert.True(t, web.arena.EventSettings.StandbyEnabled)
+	assert.Equal(t, "10.0.100.5:8080", web.arena.EventSettings.StandbyPrimaryAddress)
+	assert.Equal(t, "secret", web.arena.EventSettings.StandbyPrimaryPassword)
+	recorder = web.getHttpResponse("/setup/standby")
+	assert.Contains(t, recorder.Body.String(), "Promote to Primary")
+
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&tbaMirrorEnabled=on")
+	assert.Contains(t, recorder.Body.String(), "Can't mirror from The Blue Alliance while in standby mode.")
+
+	// Promote the standby even though the primary is unreachable.
+	web.arena.EventSettings.StandbyPrimaryAddress = "127.0.0.1:1"
+	field.RunWithTestArenaLoop(web.arena, func() error {
+		recorder = web.postHttpResponse("/setup/standby/promote", "")
+		return nil
+	})
+	assert.Equal(t, 303, recorder.Code)
+	assert.False(t, web.arena.EventSettings.StandbyEnabled)
+	recorder = web.postHttpResponse("/setup/standby/promote", "")
+	assert.Contains(t, recorder.Body.String(), "This instance is not in standby mode.")
+}
+
+func TestStandbySnapshotApi(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+	recorder := web.getHttpResponse("/api/standby/snapshot")
+	assert.Equal(t, 200, recorder.Code)
+	var snapshot field.StandbySnapshot
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &snapshot))
+	if assert.NotNil(t, snapshot.Bundle) && assert.Equal(t, 1, len(snapshot.Bundle.Teams)) {
+		assert.Equal(t, 254, snapshot.Bundle.Teams[0].Id)
+	}
+
+	recorder = web.getHttpResponse("/api/standby/snapshot?revision=" + url.QueryEscape(snapshot.Revision))
+	assert.Equal(t, 304, recorder.Code)
+	web.arena.Database.CreateTeam(&model.Team{Id: 1114})
+	recorder = web.getHttpResponse("/api/standby/snapshot?revision=" + url.QueryEscape(snapshot.Revision))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &snapshot))
+	assert.Equal(t, []string{"teams"}, snapshot.Tables)
+	if assert.NotNil(t, snapshot.Bundle) {
+		assert.Equal(t, 2, len(snapshot.Bundle.Teams))
+		assert.Equal(t, 0, len(snapshot.Bundle.Matches))
+	}
+
+	// Check that the admin password is required once one is set.
+	web.arena.EventSettings.AdminPassword = "secret"
+	recorder = web.getHttpResponse("/api/standby/snapshot")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/standby/snapshot", nil)
+	request.SetBasicAuth("admin", "secret")
+	web.newHandler().ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for monitoring and managing the queue of data to publish to The Blue Alliance.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+// Shows the pending and failed publishes to The Blue Alliance.
+func (web *Web) tbaOutboxGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	items, err := web.arena.Database.GetAllTbaOutboxItems()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	template, err := web.parseFiles("templates/setup_tba_outbox.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Items       []model.TbaOutboxItem
+		MaxAttempts int
+		Actions     []string
+	}{web.arena.EventSettings, items, field.TbaOutboxMaxAttempts, []string{model.TbaPublishTeams,
+		model.TbaPublishMatches, model.TbaRepublishSchedule, model.TbaPublishRankings, model.TbaPublishAlliances,
+		model.TbaPublishAwards}}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Queues a publish of the given type of data to The Blue Alliance.
+func (web *Web) tbaOutboxQueuePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	action := r.PostFormValue("action")
+	switch action {
+	case model.TbaPublishAlliances, model.TbaPublishAwards, model.TbaPublishMatches, model.TbaPublishRankings,
+		model.TbaPublishTeams, model.TbaRepublishSchedule:
+	default:
+		handleWebErr(w, fmt.Errorf("Invalid TBA publish action '%s'.", action))
+		return
+	}
+	if err := web.arena.QueueTbaPublish(action); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/tba_outbox", 303)
+}
+
+// Retries the given failed publish immediately.
+func (web *Web) tbaOutboxRetryPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	item, ok := web.getTbaOutboxItem(w, r)
+	if !ok {
+		return
+	}
+	if err := web.arena.QueueTbaPublish(item.Action); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/tba_outbox", 303)
+}
+
+// Removes the given publish from the queue without sending it.
+func (web *Web) tbaOutboxDeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	item, ok := web.getTbaOutboxItem(w, r)
+	if !ok {
+		return
+	}
+	if err := web.arena.Database.DeleteTbaOutboxItem(item); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/tba_outbox", 303)
+}
+
+// Loads the outbox item given in the request URL, writing an error response and returning false if it doesn't exist.
+func (web *Web) getTbaOutboxItem(w http.ResponseWriter, r *http.Request) (*model.TbaOutboxItem, bool) {
+	itemId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	item, err := web.arena.Database.GetTbaOutboxItemById(itemId)
+	if err != nil {
+		handleWebErr(w, err)
+		return nil, false
+	}
+	if item == nil {
+		http.Error(w, fmt.Sprintf("Error: No such TBA outbox item: %d", itemId), 400)
+		return nil, false
+	}
+	return item, true
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupTbaOutbox(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/tba_outbox")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "All data has been published.")
+	assert.Contains(t, recorder.Body.String(), "Publishing to The Blue Alliance is disabled")
+
+	recorder = web.postHttpResponse("/setup/tba_outbox/queue", "action=matches")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/setup/tba_outbox/queue", "action=blorpy")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Invalid TBA publish action 'blorpy'.")
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishMatches)
+	if assert.NotNil(t, item) {
+		item.Status = model.TbaOutboxFailed
+		item.Attempts = field.TbaOutboxMaxAttempts
+		item.LastError = "Got status code 500 from TBA"
+		web.arena.Database.SaveTbaOutboxItem(item)
+	}
+	recorder = web.getHttpResponse("/setup/tba_outbox")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Got status code 500 from TBA")
+	assert.Contains(t, recorder.Body.String(), "failed")
+
+	recorder = web.postHttpResponse("/setup/tba_outbox/1/retry", "")
+	assert.Equal(t, 303, recorder.Code)
+	item, _ = web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishMatches)
+	assert.Equal(t, model.TbaOutboxPending, item.Status)
+	assert.Equal(t, 0, item.Attempts)
+
+	recorder = web.postHttpResponse("/setup/tba_outbox/1/delete", "")
+	assert.Equal(t, 303, recorder.Code)
+	items, _ := web.arena.Database.GetAllTbaOutboxItems()
+	assert.Empty(t, items)
+	recorder = web.postHttpResponse("/setup/tba_outbox/1/retry", "")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No such TBA outbox item")
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/tournament"
 	"github.com/dchest/uniuri"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -19,13 +20,16 @@ import (
 
 const wpaKeyLength = 8
 
+const teamListLockedMessage = "You can't modify the team list once the qualification schedule has been generated. If " +
+	"you need to change the team list, clear all other data first on the Settings page."
+
 // Shows the team list.
 func (web *Web) teamsGetHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
 		return
 	}
 
-	web.renderTeams(w, r, false)
+	web.renderTeams(w, r, "")
 }
 
 // Adds teams to the team list.
@@ -35,7 +39,7 @@ func (web *Web) teamsPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !web.canModifyTeamList() {
-		web.renderTeams(w, r, true)
+		web.renderTeams(w, r, teamListLockedMessage)
 		return
 	}
 
@@ -63,6 +67,64 @@ func (web *Web) teamsPostHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
+// Adds or updates teams from an uploaded CSV file.
+func (web *Web) teamsImportPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	if !web.canModifyTeamList() {
+		web.renderTeams(w, r, teamListLockedMessage)
+		return
+	}
+
+	file, _, err := r.FormFile("teamsFile")
+	if err != nil {
+		web.renderTeams(w, r, "No team list file was specified.")
+		return
+	}
+	defer file.Close()
+	teams, err := tournament.ParseTeamsCsv(file)
+	if err != nil {
+		web.renderTeams(w, r, fmt.Sprintf("Error importing team list: %s", err.Error()))
+		return
+	}
+
+	for _, team := range teams {
+		existingTeam, err := web.arena.Database.GetTeamById(team.Id)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if existingTeam == nil {
+			err = web.arena.Database.CreateTeam(&team)
+		} else {
+			// Only overwrite the fields given in the file, so that other local edits are preserved.
+			replaceIfSet := func(target *string, value string) {
+				if value != "" {
+					*target = value
+				}
+			}
+			replaceIfSet(&existingTeam.Name, team.Name)
+			replaceIfSet(&existingTeam.Nickname, team.Nickname)
+			replaceIfSet(&existingTeam.City, team.City)
+			replaceIfSet(&existingTeam.StateProv, team.StateProv)
+			replaceIfSet(&existingTeam.Country, team.Country)
+			replaceIfSet(&existingTeam.RobotName, team.RobotName)
+			replaceIfSet(&existingTeam.WpaKey, team.WpaKey)
+			if team.RookieYear != 0 {
+				existingTeam.RookieYear = team.RookieYear
+			}
+			err = web.arena.Database.SaveTeam(existingTeam)
+		}
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	http.Redirect(w, r, "/setup/teams", 303)
+}
+
 // Re-downloads the data for all teams from TBA and overwrites any local edits.
 func (web *Web) teamsRefreshHandler(w http.ResponseWriter, r *http.Request) {
 	if !web.userIsAdmin(w, r) {
@@ -96,7 +158,7 @@ func (web *Web) teamsClearHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !web.canModifyTeamList() {
-		web.renderTeams(w, r, true)
+		web.renderTeams(w, r, teamListLockedMessage)
 		return
 	}
 
@@ -191,7 +253,7 @@ func (web *Web) teamDeletePostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !web.canModifyTeamList() {
-		web.renderTeams(w, r, true)
+		web.renderTeams(w, r, teamListLockedMessage)
 		return
 	}
 
@@ -220,9 +282,8 @@ func (web *Web) teamsPublishHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := web.arena.TbaClient.PublishTeams(web.arena.Database)
-	if err != nil {
-		http.Error(w, "Failed to publish teams: "+err.Error(), 500)
+	if err := web.arena.QueueTbaPublish(model.TbaPublishTeams); err != nil {
+		handleWebErr(w, err)
 		return
 	}
 	http.Redirect(w, r, "/setup/teams", 303)
@@ -254,7 +315,7 @@ func (web *Web) teamsGenerateWpaKeysHandler(w http.ResponseWriter, r *http.Reque
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
-func (web *Web) renderTeams(w http.ResponseWriter, r *http.Request, showErrorMessage bool) {
+func (web *Web) renderTeams(w http.ResponseWriter, r *http.Request, errorMessage string) {
 	teams, err := web.arena.Database.GetAllTeams()
 	if err != nil {
 		handleWebErr(w, err)
@@ -268,9 +329,9 @@ func (web *Web) renderTeams(w http.ResponseWriter, r *http.Request, showErrorMes
 	}
 	data := struct {
 		*model.EventSettings
-		Teams            []model.Team
-		ShowErrorMessage bool
-	}{web.arena.EventSettings, teams, showErrorMessage}
+		Teams        []model.Team
+		ErrorMessage string
+	}{web.arena.EventSettings, teams, errorMessage}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -289,29 +350,26 @@ func (web *Web) canModifyTeamList() bool {
 
 // Returns the data for the given team number.
 func (web *Web) populateOfficialTeamInfo(team *model.Team) error {
-	tbaTeam, err := web.arena.TbaClient.GetTeam(team.Id)
+	teamInfo, err := web.arena.PartnerSource.GetTeamInfo(team.Id, time.Now().Year())
 	if err != nil {
 		return err
 	}
 
 	// Check if the result is valid. If a team is not found, it will just not have its detail fields filled out.
-	if tbaTeam.TeamNumber == 0 {
+	if teamInfo == nil {
 		return nil
 	}
 
-	team.Name = tbaTeam.Name
-	team.Nickname = tbaTeam.Nickname
-	team.City = tbaTeam.City
-	team.StateProv = tbaTeam.StateProv
-	team.Country = tbaTeam.Country
-	team.RookieYear = tbaTeam.RookieYear
-	team.RobotName, err = web.arena.TbaClient.GetRobotName(team.Id, time.Now().Year())
-	if err != nil {
-		return err
-	}
+	team.Name = teamInfo.Name
+	team.Nickname = teamInfo.Nickname
+	team.City = teamInfo.City
+	team.StateProv = teamInfo.StateProv
+	team.Country = teamInfo.Country
+	team.RookieYear = teamInfo.RookieYear
+	team.RobotName = teamInfo.RobotName
 
 	// Generate string of recent awards in reverse chronological order.
-	recentAwards, err := web.arena.TbaClient.GetTeamAwards(team.Id)
+	recentAwards, err := web.arena.PartnerSource.GetRecentAwards(team.Id)
 	if err != nil {
 		return err
 	}
@@ -326,7 +384,7 @@ func (web *Web) populateOfficialTeamInfo(team *model.Team) error {
 	team.Accomplishments = accomplishmentsBuffer.String()
 
 	// Download and store the team's avatar; if there isn't one, ignore the error.
-	web.arena.TbaClient.DownloadTeamAvatar(team.Id, time.Now().Year())
+	web.arena.PartnerSource.DownloadTeamAvatar(team.Id, time.Now().Year())
 
 	return nil
 }
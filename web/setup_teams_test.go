@@ -4,8 +4,10 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/Team254/cheesy-arena/model"
+	"github.com/Team254/cheesy-arena/partner"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -209,10 +211,76 @@ func TestSetupTeamsWpaKeys(t *testing.T) {
 func TestSetupTeamsPublish(t *testing.T) {
 	web := setupTestWeb(t)
 
-	web.arena.TbaClient.BaseUrl = "fakeurl"
 	web.arena.EventSettings.TbaPublishingEnabled = true
 
 	recorder := web.postHttpResponse("/setup/teams/publish", "")
-	assert.Equal(t, 500, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "Failed to publish teams")
+	assert.Equal(t, 303, recorder.Code)
+	item, _ := web.arena.Database.GetTbaOutboxItemByAction(model.TbaPublishTeams)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, model.TbaOutboxPending, item.Status)
+	}
+}
+
+func TestSetupTeamsFallbackSource(t *testing.T) {
+	web := setupTestWeb(t)
+
+	// Mock a TBA server that has incomplete info for a rookie team and an FRC Events API server that fills it in.
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/team/frc7777") {
+			fmt.Fprintln(w, `{"team_number": 7777, "name": "Rookie High School", "nickname": "", "rookie_year": 2018}`)
+		} else {
+			fmt.Fprintln(w, "[]")
+		}
+	}))
+	defer tbaServer.Close()
+	frcEventsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/teams") {
+			fmt.Fprintln(w, `{"teams": [{"teamNumber": 7777, "nameFull": "Other Name",
+				"nameShort": "Rookie Robotics", "city": "San Jose"}]}`)
+		} else {
+			http.Error(w, "Not found", 404)
+		}
+	}))
+	defer frcEventsServer.Close()
+	web.arena.TbaClient.BaseUrl = tbaServer.URL
+	frcEventsClient := partner.NewFrcEventsClient("user", "token", "CASJ", 2018)
+	frcEventsClient.BaseUrl = frcEventsServer.URL
+	web.arena.PartnerSource = partner.NewFallbackSource(web.arena.TbaClient, frcEventsClient)
+
+	recorder := web.postHttpResponse("/setup/teams", "teamNumbers=7777")
+	assert.Equal(t, 303, recorder.Code)
+	team, _ := web.arena.Database.GetTeamById(7777)
+	if assert.NotNil(t, team) {
+		assert.Equal(t, "Rookie High School", team.Name)
+		assert.Equal(t, "Rookie Robotics", team.Nickname)
+		assert.Equal(t, "San Jose", team.City)
+		assert.Equal(t, 2018, team.RookieYear)
+	}
+}
+
+func TestSetupTeamsImport(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "Poofs", RobotName: "Lockdown"})
+	recorder := web.postFileHttpResponse("/setup/teams/import", "teamsFile", bytes.NewBufferString(
+		"Number,Nickname,City,WpaKey\n254,The Cheesy Poofs,San Jose,\n1114,Simbotics,,abcdefgh\n"))
+	assert.Equal(t, 303, recorder.Code)
+	teams, _ := web.arena.Database.GetAllTeams()
+	if assert.Equal(t, 2, len(teams)) {
+		assert.Equal(t, model.Team{Id: 254, Nickname: "The Cheesy Poofs", City: "San Jose", RobotName: "Lockdown"},
+			teams[0])
+		assert.Equal(t, model.Team{Id: 1114, Nickname: "Simbotics", WpaKey: "abcdefgh"}, teams[1])
+	}
+
+	// Check that nothing is imported if any row has an error.
+	recorder = web.postFileHttpResponse("/setup/teams/import", "teamsFile",
+		bytes.NewBufferString("Number\n846\nfrc1678\n"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Row 3: Invalid team number")
+	teams, _ = web.arena.Database.GetAllTeams()
+	assert.Equal(t, 2, len(teams))
+
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification"})
+	recorder = web.postFileHttpResponse("/setup/teams/import", "teamsFile", bytes.NewBufferString("Number\n846\n"))
+	assert.Contains(t, recorder.Body.String(), "can't modify")
 }
@@ -0,0 +1,166 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Web routes for configuring the webhooks notified of event lifecycle changes and viewing their delivery log.
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const webhookDeliveryLogSize = 50
+
+// Shows the webhook configuration page and the recent delivery log.
+func (web *Web) webhooksGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	web.renderWebhooks(w, r, "")
+}
+
+// Creates, saves or deletes a webhook.
+func (web *Web) webhooksPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	webhookId, _ := strconv.Atoi(r.PostFormValue("id"))
+	webhook, err := web.arena.Database.GetWebhookById(webhookId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if r.PostFormValue("action") == "delete" {
+		if webhook != nil {
+			err = web.arena.Database.DeleteWebhook(webhook)
+		}
+	} else {
+		name := strings.TrimSpace(r.PostFormValue("name"))
+		if name == "" {
+			web.renderWebhooks(w, r, "The webhook name can't be blank.")
+			return
+		}
+		webhookUrl := strings.TrimSpace(r.PostFormValue("url"))
+		if parsedUrl, err := url.Parse(webhookUrl); err != nil ||
+			(parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+			web.renderWebhooks(w, r, fmt.Sprintf("'%s' is not a valid HTTP or HTTPS URL.", webhookUrl))
+			return
+		}
+		secret := strings.TrimSpace(r.PostFormValue("secret"))
+		if secret == "" {
+			// Generate a random secret if none was given; an API key has the right properties for one.
+			if secret, err = model.GenerateApiKey(); err != nil {
+				handleWebErr(w, err)
+				return
+			}
+		}
+		var events []string
+		for _, event := range r.Form["events"] {
+			if isValidWebhookEvent(event) {
+				events = append(events, event)
+			}
+		}
+
+		if webhook == nil {
+			webhook = &model.Webhook{}
+		}
+		webhook.Name = name
+		webhook.Url = webhookUrl
+		webhook.Secret = secret
+		webhook.Events = strings.Join(events, ",")
+		webhook.Enabled = r.PostFormValue("enabled") == "on"
+		if webhook.Id == 0 {
+			err = web.arena.Database.CreateWebhook(webhook)
+		} else {
+			err = web.arena.Database.SaveWebhook(webhook)
+		}
+	}
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/webhooks", 303)
+}
+
+// Attempts the given delivery again from scratch.
+func (web *Web) webhookDeliveryRetryPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userIsAdmin(w, r) {
+		return
+	}
+
+	deliveryId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	delivery, err := web.arena.Database.GetWebhookDeliveryById(deliveryId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if delivery == nil {
+		http.Error(w, fmt.Sprintf("Error: No such webhook delivery: %d", deliveryId), 400)
+		return
+	}
+	if err = web.arena.RetryWebhookDelivery(delivery); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/webhooks", 303)
+}
+
+func (web *Web) renderWebhooks(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_webhooks.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	webhooks, err := web.arena.Database.GetAllWebhooks()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	deliveries, err := web.arena.Database.GetRecentWebhookDeliveries(webhookDeliveryLogSize)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	webhookNames := make(map[int]string)
+	for _, webhook := range webhooks {
+		webhookNames[webhook.Id] = webhook.Name
+	}
+
+	// Append a blank entry to the end that can be used to add a new one.
+	webhooks = append(webhooks, model.Webhook{Enabled: true})
+
+	data := struct {
+		*model.EventSettings
+		Webhooks     []model.Webhook
+		Events       []string
+		Deliveries   []model.WebhookDelivery
+		WebhookNames map[int]string
+		MaxAttempts  int
+		ErrorMessage string
+	}{web.arena.EventSettings, webhooks, model.WebhookEvents, deliveries, webhookNames, field.WebhookMaxAttempts,
+		errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+func isValidWebhookEvent(event string) bool {
+	for _, validEvent := range model.WebhookEvents {
+		if event == validEvent {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package web
+
+import (
+	"fmt"
+	"github.com/Team254/cheesy-arena/field"
+	"github.com/Team254/cheesy-arena/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupWebhooks(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/webhooks")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Webhooks - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), model.WebhookElimSeriesWon)
+
+	recorder = web.postHttpResponse("/setup/webhooks", "name=&url=http://localhost/hook")
+	assert.Contains(t, recorder.Body.String(), "The webhook name can't be blank.")
+	recorder = web.postHttpResponse("/setup/webhooks", "name=Hook&url=ftp://localhost/hook")
+	assert.Contains(t, recorder.Body.String(), "is not a valid HTTP or HTTPS URL.")
+	recorder = web.postHttpResponse("/setup/webhooks", "name=Hook&url=http://localhost/hook&enabled=on&"+
+		"events=match_loaded&events=bogus&events=match_committed")
+	assert.Equal(t, 303, recorder.Code)
+	webhooks, _ := web.arena.Database.GetAllWebhooks()
+	if !assert.Equal(t, 1, len(webhooks)) {
+		return
+	}
+	assert.Equal(t, "Hook", webhooks[0].Name)
+	assert.Equal(t, "match_loaded,match_committed", webhooks[0].Events)
+	assert.True(t, webhooks[0].Enabled)
+	assert.NotEqual(t, "", webhooks[0].Secret)
+
+	recorder = web.postHttpResponse("/setup/webhooks",
+		fmt.Sprintf("id=%d&name=Hook&url=https://localhost/hook&secret=shh", webhooks[0].Id))
+	assert.Equal(t, 303, recorder.Code)
+	webhook, _ := web.arena.Database.GetWebhookById(webhooks[0].Id)
+	assert.Equal(t, "https://localhost/hook", webhook.Url)
+	assert.Equal(t, "shh", webhook.Secret)
+	assert.Equal(t, "", webhook.Events)
+	assert.False(t, webhook.Enabled)
+
+	// Check that a failed delivery shows up in the log and can be retried.
+	delivery := model.WebhookDelivery{WebhookId: webhook.Id, Event: model.WebhookMatchLoaded,
+		Status: model.WebhookDeliveryFailed, Attempts: 10, LastError: "Got status code 500."}
+	web.arena.Database.CreateWebhookDelivery(&delivery)
+	recorder = web.getHttpResponse("/setup/webhooks")
+	assert.Contains(t, recorder.Body.String(), "Got status code 500.")
+	recorder = web.postHttpResponse(fmt.Sprintf("/setup/webhooks/deliveries/%d/retry", delivery.Id), "")
+	assert.Equal(t, 303, recorder.Code)
+	field.ProcessTestWebhookQueue(web.arena)
+	delivery2, _ := web.arena.Database.GetWebhookDeliveryById(delivery.Id)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery2.Status)
+	assert.Equal(t, 0, delivery2.Attempts)
+	recorder = web.postHttpResponse("/setup/webhooks/deliveries/1114/retry", "")
+	assert.Equal(t, 400, recorder.Code)
+
+	recorder = web.postHttpResponse("/setup/webhooks", fmt.Sprintf("id=%d&action=delete", webhook.Id))
+	assert.Equal(t, 303, recorder.Code)
+	webhooks, _ = web.arena.Database.GetAllWebhooks()
+	assert.Empty(t, webhooks)
+}
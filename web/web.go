@@ -26,10 +26,11 @@ type Web struct {
 	arena           *field.Arena
 	cookieAuth      *httpauth.Cookie
 	templateHelpers template.FuncMap
+	apiRateLimiter  *apiRateLimiter
 }
 
 func NewWeb(arena *field.Arena) *Web {
-	web := &Web{arena: arena}
+	web := &Web{arena: arena, apiRateLimiter: newApiRateLimiter()}
 	web.cookieAuth = httpauth.NewCookie("Cheesy Arena", "", web.checkAuthPassword)
 
 	// Helper functions that can be used inside templates.
@@ -49,7 +50,19 @@ func NewWeb(arena *field.Arena) *Web {
 			}
 			return dict, nil
 		},
+		// Formats a display configuration map for editing.
+		"displayConfiguration": formatDisplayConfiguration,
+		// Identifies the version of the display pages so that the clients can report it in their heartbeats.
+		"displayPageVersion": func() string {
+			return web.arena.DisplayPageVersion
+		},
+	}
+
+	displayPageVersion, err := computeDisplayPageVersion()
+	if err != nil {
+		log.Printf("Failed to compute display page version: %v", err)
 	}
+	arena.DisplayPageVersion = displayPageVersion
 
 	return web
 }
@@ -126,14 +139,27 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/", web.indexHandler).Methods("GET")
 	router.HandleFunc("/alliance_selection", web.allianceSelectionGetHandler).Methods("GET")
 	router.HandleFunc("/alliance_selection", web.allianceSelectionPostHandler).Methods("POST")
+	router.HandleFunc("/alliance_selection/accept", web.allianceSelectionAcceptHandler).Methods("POST")
+	router.HandleFunc("/alliance_selection/decline", web.allianceSelectionDeclineHandler).Methods("POST")
 	router.HandleFunc("/alliance_selection/finalize", web.allianceSelectionFinalizeHandler).Methods("POST")
+	router.HandleFunc("/alliance_selection/import", web.allianceSelectionImportHandler).Methods("POST")
+	router.HandleFunc("/alliance_selection/invite", web.allianceSelectionInviteHandler).Methods("POST")
 	router.HandleFunc("/alliance_selection/publish", web.allianceSelectionPublishHandler).Methods("POST")
 	router.HandleFunc("/alliance_selection/reset", web.allianceSelectionResetHandler).Methods("POST")
 	router.HandleFunc("/alliance_selection/start", web.allianceSelectionStartHandler).Methods("POST")
 	router.HandleFunc("/api/alliances", web.alliancesApiHandler).Methods("GET")
+	router.HandleFunc("/api/bracket", web.bracketApiHandler).Methods("GET")
 	router.HandleFunc("/api/matches/{type}", web.matchesApiHandler).Methods("GET")
 	router.HandleFunc("/api/rankings", web.rankingsApiHandler).Methods("GET")
+	router.HandleFunc("/api/rankings/projection", web.rankingsProjectionApiHandler).Methods("GET")
+	router.HandleFunc("/api/rankings/what_if", web.rankingsWhatIfApiHandler).Methods("GET")
 	router.HandleFunc("/api/sponsor_slides", web.sponsorSlidesApiHandler).Methods("GET")
+	router.HandleFunc("/api/standby/snapshot", web.standbySnapshotApiHandler).Methods("GET")
+	router.HandleFunc("/api/v1", web.apiDocsHandler).Methods("GET")
+	router.HandleFunc("/api/v1/alliances", web.apiAlliancesHandler).Methods("GET")
+	router.HandleFunc("/api/v1/matches/{type}", web.apiMatchesHandler).Methods("GET")
+	router.HandleFunc("/api/v1/rankings", web.apiRankingsHandler).Methods("GET")
+	router.HandleFunc("/api/v1/stream", web.apiStreamHandler).Methods("GET")
 	router.HandleFunc("/display", web.placeholderDisplayHandler).Methods("GET")
 	router.HandleFunc("/display/websocket", web.placeholderDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/alliance_station", web.allianceStationDisplayHandler).Methods("GET")
@@ -142,8 +168,12 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/displays/announcer/websocket", web.announcerDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/audience", web.audienceDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/audience/websocket", web.audienceDisplayWebsocketHandler).Methods("GET")
+	router.HandleFunc("/displays/bracket", web.bracketDisplayHandler).Methods("GET")
+	router.HandleFunc("/displays/bracket/websocket", web.bracketDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/field_monitor", web.fieldMonitorDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/field_monitor/websocket", web.fieldMonitorDisplayWebsocketHandler).Methods("GET")
+	router.HandleFunc("/displays/field_visualizer", web.fieldVisualizerDisplayHandler).Methods("GET")
+	router.HandleFunc("/displays/field_visualizer/websocket", web.fieldVisualizerDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/pit", web.pitDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/pit/websocket", web.pitDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/queueing", web.queueingDisplayHandler).Methods("GET")
@@ -163,6 +193,7 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/panels/scoring/{alliance}/websocket", web.scoringPanelWebsocketHandler).Methods("GET")
 	router.HandleFunc("/panels/referee", web.refereePanelHandler).Methods("GET")
 	router.HandleFunc("/panels/referee/websocket", web.refereePanelWebsocketHandler).Methods("GET")
+	router.HandleFunc("/reports/pdf/bracket", web.bracketPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/csv/rankings", web.rankingsCsvReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/rankings", web.rankingsPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/csv/schedule/{type}", web.scheduleCsvReportHandler).Methods("GET")
@@ -170,23 +201,64 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/reports/csv/teams", web.teamsCsvReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/teams", web.teamsPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/csv/wpa_keys", web.wpaKeysCsvReportHandler).Methods("GET")
+	router.HandleFunc("/setup/api_keys", web.apiKeysGetHandler).Methods("GET")
+	router.HandleFunc("/setup/api_keys", web.apiKeysPostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards", web.awardsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/awards", web.awardsPostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards/{id}/present", web.awardPresentPostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards/{id}/winners", web.awardWinnerAddPostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards/hide", web.awardsHidePostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards/load_standard", web.awardsLoadStandardPostHandler).Methods("POST")
+	router.HandleFunc("/setup/awards/winners/{id}/delete", web.awardWinnerDeletePostHandler).Methods("POST")
+	router.HandleFunc("/setup/backups", web.backupsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/backups/prune", web.backupsPrunePostHandler).Methods("POST")
+	router.HandleFunc("/setup/backups/verify", web.backupsVerifyPostHandler).Methods("POST")
+	router.HandleFunc("/setup/backups/{filename}", web.backupGetHandler).Methods("GET")
+	router.HandleFunc("/setup/backups/{filename}/restore", web.backupRestorePostHandler).Methods("POST")
 	router.HandleFunc("/setup/db/clear", web.clearDbHandler).Methods("POST")
+	router.HandleFunc("/setup/db/export_bundle", web.exportEventBundleHandler).Methods("GET")
+	router.HandleFunc("/setup/db/import_bundle", web.importEventBundleHandler).Methods("POST")
 	router.HandleFunc("/setup/db/restore", web.restoreDbHandler).Methods("POST")
 	router.HandleFunc("/setup/db/save", web.saveDbHandler).Methods("GET")
+	router.HandleFunc("/setup/display_groups", web.displayGroupsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/display_groups", web.displayGroupsPostHandler).Methods("POST")
+	router.HandleFunc("/setup/display_playlists", web.displayPlaylistsPostHandler).Methods("POST")
 	router.HandleFunc("/setup/displays", web.displaysGetHandler).Methods("GET")
 	router.HandleFunc("/setup/displays/websocket", web.displaysWebsocketHandler).Methods("GET")
 	router.HandleFunc("/setup/led_plc", web.ledPlcGetHandler).Methods("GET")
 	router.HandleFunc("/setup/led_plc/websocket", web.ledPlcWebsocketHandler).Methods("GET")
+	router.HandleFunc("/setup/led_sequences", web.ledSequencesGetHandler).Methods("GET")
+	router.HandleFunc("/setup/led_sequences", web.ledSequencesPostHandler).Methods("POST")
+	router.HandleFunc("/setup/led_sequences/preview", web.ledSequencePreviewPostHandler).Methods("POST")
+	router.HandleFunc("/setup/led_sequences/settings", web.ledSettingsPostHandler).Methods("POST")
+	router.HandleFunc("/setup/lighting", web.lightingGetHandler).Methods("GET")
+	router.HandleFunc("/setup/lighting/cues", web.lightingCuesPostHandler).Methods("POST")
+	router.HandleFunc("/setup/lighting/cues/{id}/fire", web.lightingCueFirePostHandler).Methods("POST")
+	router.HandleFunc("/setup/lighting/fixtures", web.lightingFixturesPostHandler).Methods("POST")
 	router.HandleFunc("/setup/lower_thirds", web.lowerThirdsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/lower_thirds/websocket", web.lowerThirdsWebsocketHandler).Methods("GET")
 	router.HandleFunc("/setup/schedule", web.scheduleGetHandler).Methods("GET")
 	router.HandleFunc("/setup/schedule/generate", web.scheduleGeneratePostHandler).Methods("POST")
+	router.HandleFunc("/setup/schedule/import", web.scheduleImportPostHandler).Methods("POST")
+	router.HandleFunc("/setup/schedule/import_file", web.scheduleImportFilePostHandler).Methods("POST")
 	router.HandleFunc("/setup/schedule/republish", web.scheduleRepublishPostHandler).Methods("POST")
 	router.HandleFunc("/setup/schedule/save", web.scheduleSavePostHandler).Methods("POST")
 	router.HandleFunc("/setup/settings", web.settingsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/settings", web.settingsPostHandler).Methods("POST")
+	router.HandleFunc("/setup/sounds", web.soundsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/sounds/packs", web.soundPacksPostHandler).Methods("POST")
+	router.HandleFunc("/setup/sounds/packs/{id}/delete", web.soundPackDeletePostHandler).Methods("POST")
+	router.HandleFunc("/setup/sounds/settings", web.soundSettingsPostHandler).Methods("POST")
+	router.HandleFunc("/setup/sounds/{name}/play", web.soundPlayPostHandler).Methods("POST")
 	router.HandleFunc("/setup/sponsor_slides", web.sponsorSlidesGetHandler).Methods("GET")
 	router.HandleFunc("/setup/sponsor_slides", web.sponsorSlidesPostHandler).Methods("POST")
+	router.HandleFunc("/setup/standby", web.standbyGetHandler).Methods("GET")
+	router.HandleFunc("/setup/standby", web.standbyPostHandler).Methods("POST")
+	router.HandleFunc("/setup/standby/promote", web.standbyPromotePostHandler).Methods("POST")
+	router.HandleFunc("/setup/tba_outbox", web.tbaOutboxGetHandler).Methods("GET")
+	router.HandleFunc("/setup/tba_outbox/queue", web.tbaOutboxQueuePostHandler).Methods("POST")
+	router.HandleFunc("/setup/tba_outbox/{id}/delete", web.tbaOutboxDeletePostHandler).Methods("POST")
+	router.HandleFunc("/setup/tba_outbox/{id}/retry", web.tbaOutboxRetryPostHandler).Methods("POST")
 	router.HandleFunc("/setup/teams", web.teamsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/teams", web.teamsPostHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/{id}/delete", web.teamDeletePostHandler).Methods("POST")
@@ -194,8 +266,13 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/setup/teams/{id}/edit", web.teamEditPostHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/clear", web.teamsClearHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/generate_wpa_keys", web.teamsGenerateWpaKeysHandler).Methods("GET")
+	router.HandleFunc("/setup/teams/import", web.teamsImportPostHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/publish", web.teamsPublishHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/refresh", web.teamsRefreshHandler).Methods("GET")
+	router.HandleFunc("/setup/webhooks", web.webhooksGetHandler).Methods("GET")
+	router.HandleFunc("/setup/webhooks", web.webhooksPostHandler).Methods("POST")
+	router.HandleFunc("/setup/webhooks/deliveries/{id}/retry", web.webhookDeliveryRetryPostHandler).Methods("POST")
+	router.HandleFunc("/sound_packs/{id}/{cue}", web.soundPackFileHandler).Methods("GET")
 	return router
 }
 
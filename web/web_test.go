@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -47,12 +48,41 @@ func (web *Web) postHttpResponse(path string, body string) *httptest.ResponseRec
 	return recorder
 }
 
+// Posts the given request while running the tasks that its handler posts to the arena loop, in place of the real loop.
+func (web *Web) postHttpResponseWithArenaLoop(path string, body string) *httptest.ResponseRecorder {
+	var recorder *httptest.ResponseRecorder
+	field.RunWithTestArenaLoop(web.arena, func() error {
+		recorder = web.postHttpResponse(path, body)
+		return nil
+	})
+	return recorder
+}
+
+// Wraps a real local HTTP server so that closing it also waits for any websocket handlers to finish.
+type testServer struct {
+	*httptest.Server
+	handlers sync.WaitGroup
+}
+
 // Starts a real local HTTP server that can be used by more sophisticated tests.
-func (web *Web) startTestServer() (*httptest.Server, string) {
-	server := httptest.NewServer(web.newHandler())
+func (web *Web) startTestServer() (*testServer, string) {
+	server := new(testServer)
+	handler := web.newHandler()
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.handlers.Add(1)
+		defer server.handlers.Done()
+		handler.ServeHTTP(w, r)
+	}))
 	return server, "ws" + server.URL[len("http"):]
 }
 
+// Shuts down the server and blocks until all of its handlers have returned, including those for websockets that have
+// been hijacked from it, so that they don't write to the database after the next test has replaced it.
+func (server *testServer) Close() {
+	server.Server.Close()
+	server.handlers.Wait()
+}
+
 // Receives the next websocket message and asserts that it is an error.
 func readWebsocketError(t *testing.T, ws *websocket.Websocket) string {
 	messageType, data, err := ws.Read()
@@ -0,0 +1,93 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+//
+// Functions for streaming notifications over plain HTTP as Server-Sent Events, for clients that can't use websockets.
+
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// Creates listeners for the given notifiers and loops until the client disconnects to pass their output through to the
+// given HTTP response as Server-Sent Events. Each event is named after the notifier's message type and carries the
+// notification's sequence number as its ID. The current state of each notifier is sent upon connection.
+func HandleEventStream(w http.ResponseWriter, r *http.Request, notifiers ...*Notifier) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported.", 500)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// Use reflection to dynamically build a select/case structure for all the notifiers.
+	listeners := make([]reflect.SelectCase, len(notifiers))
+	for i, notifier := range notifiers {
+		listener := notifier.listen()
+		defer close(listener)
+		listeners[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(listener)}
+
+		// Send each notifier's respective data immediately upon connection to bootstrap the client state.
+		if notifier.messageProducer != nil {
+			if err := writeEvent(w, notifier.snapshot()); err != nil {
+				log.Printf("Event stream error writing initial value for notifier %v: %v", notifier, err)
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	// Add additional cases to detect when the client has gone away and to periodically send a comment to keep any
+	// proxies in between from timing out the connection.
+	doneIndex := len(listeners)
+	listeners = append(listeners,
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(r.Context().Done())})
+	pingIndex := len(listeners)
+	listeners = append(listeners,
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(time.Tick(pingInterval))})
+
+	for {
+		// Block until a message is available on any of the channels.
+		chosenIndex, value, ok := reflect.Select(listeners)
+		if chosenIndex == doneIndex {
+			return
+		}
+		var err error
+		if chosenIndex == pingIndex {
+			_, err = io.WriteString(w, ": ping\n\n")
+		} else {
+			if !ok {
+				log.Printf("Channel for notifier %v closed unexpectedly.", notifiers[chosenIndex])
+				return
+			}
+			message, ok := value.Interface().(messageEnvelope)
+			if !ok {
+				log.Printf("Channel for notifier %v sent unexpected value %v.", notifiers[chosenIndex], value)
+				continue
+			}
+			err = writeEvent(w, message)
+		}
+		if err != nil {
+			// The client has probably closed the connection; bail out of the loop.
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// Writes the given message in the Server-Sent Events format.
+func writeEvent(w io.Writer, message messageEnvelope) error {
+	data, err := json.Marshal(message.messageBody)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", message.messageType, message.sequence, data)
+	return err
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Team 254. All Rights Reserved.
+// Author: pat@patfairbank.com (Patrick Fairbank)
+
+package websocket
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEventStream(t *testing.T) {
+	notifier1 := NewNotifier("messageType1", func() interface{} { return "test message" })
+	notifier2 := NewNotifier("messageType2", nil)
+	notifier1.Notify()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleEventStream(w, r, notifier1, notifier2)
+	}))
+	defer server.Close()
+	response, err := http.Get(server.URL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+
+	// Ensure the initial state is sent upon connection.
+	assertEvent(t, reader, "event: messageType1\nid: 1\ndata: \"test message\"\n\n")
+
+	notifier2.NotifyWithMessage(map[string]int{"Value": 254})
+	assertEvent(t, reader, "event: messageType2\nid: 1\ndata: {\"Value\":254}\n\n")
+	notifier1.NotifyWithMessage("test message 2")
+	assertEvent(t, reader, "event: messageType1\nid: 2\ndata: \"test message 2\"\n\n")
+
+	// Check that the listeners are cleaned up once the client disconnects.
+	response.Body.Close()
+	server.Close()
+	notifier1.Notify()
+	assert.Equal(t, 0, len(notifier1.listeners))
+}
+
+func assertEvent(t *testing.T, reader *bufio.Reader, expectedEvent string) {
+	var event string
+	for !strings.HasSuffix(event, "\n\n") {
+		line, err := reader.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return
+		}
+		event += line
+	}
+	assert.Equal(t, expectedEvent, event)
+}
@@ -13,16 +13,21 @@ import (
 // Allow the listeners to buffer a small number of notifications to streamline delivery.
 const notifyBufferSize = 5
 
+// Notifiers that have a messageProducer describe the latest state of something, so if a listener falls behind its
+// oldest unread notification is discarded in favor of the new one. Notifiers without one carry discrete events, which
+// are dropped instead. Either way, the sequence numbers let the client detect the gap and ask to be resynchronized.
 type Notifier struct {
 	messageType     string
 	messageProducer func() interface{}
 	listeners       map[chan messageEnvelope]struct{} // The map is essentially a set; the value is ignored.
+	sequence        int64                             // Number of the most recent notification sent.
 	mutex           sync.Mutex
 }
 
 type messageEnvelope struct {
 	messageType string
 	messageBody interface{}
+	sequence    int64
 }
 
 func NewNotifier(messageType string, messageProducer func() interface{}) *Notifier {
@@ -43,7 +48,8 @@ func (notifier *Notifier) NotifyWithMessage(messageBody interface{}) {
 	notifier.mutex.Lock()
 	defer notifier.mutex.Unlock()
 
-	message := messageEnvelope{messageType: notifier.messageType, messageBody: messageBody}
+	notifier.sequence++
+	message := messageEnvelope{messageType: notifier.messageType, messageBody: messageBody, sequence: notifier.sequence}
 	for listener := range notifier.listeners {
 		notifier.notifyListener(listener, message)
 	}
@@ -62,9 +68,23 @@ func (notifier *Notifier) notifyListener(listener chan messageEnvelope, message
 	select {
 	case listener <- message:
 		// The notification was sent and received successfully.
+		return
 	default:
-		log.Println("Failed to send a notification due to blocked listener.")
 	}
+
+	if notifier.messageProducer != nil {
+		// Make room by discarding the oldest unread notification, which the new one supersedes.
+		select {
+		case <-listener:
+		default:
+		}
+		select {
+		case listener <- message:
+			return
+		default:
+		}
+	}
+	log.Println("Failed to send a notification due to blocked listener.")
 }
 
 // Registers and returns a channel that can be read from to receive notification messages. The caller is
@@ -78,6 +98,17 @@ func (notifier *Notifier) listen() chan messageEnvelope {
 	return listener
 }
 
+// Returns a message containing the current output of the message producer, numbered with the sequence of the latest
+// notification so that the client can tell which notifications it supersedes.
+func (notifier *Notifier) snapshot() messageEnvelope {
+	// Read the sequence before invoking the producer, since the producer may need locks held by callers of Notify().
+	notifier.mutex.Lock()
+	sequence := notifier.sequence
+	notifier.mutex.Unlock()
+	return messageEnvelope{messageType: notifier.messageType, messageBody: notifier.getMessageBody(),
+		sequence: sequence}
+}
+
 // Invokes the message producer to get the message, or returns nil if no producer is defined.
 func (notifier *Notifier) getMessageBody() interface{} {
 	if notifier.messageProducer == nil {
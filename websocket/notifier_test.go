@@ -34,24 +34,39 @@ func TestNotifier(t *testing.T) {
 	assert.Equal(t, "message2", (<-listener).messageBody)
 	assert.Equal(t, "test message", (<-listener).messageBody)
 
+	// Should number the notifications sequentially.
+	notifier.NotifyWithMessage("message3")
+	assert.Equal(t, int64(9), (<-listener).sequence)
+	assert.Equal(t, int64(9), notifier.snapshot().sequence)
+	assert.Equal(t, "test message", notifier.snapshot().messageBody)
+
+	// Should discard the oldest unread messages from a notifier with a producer once the buffer is full.
+	for i := 0; i < 20; i++ {
+		notifier.NotifyWithMessage(i)
+	}
+	for i := 20 - notifyBufferSize; i < 20; i++ {
+		message = <-listener
+		assert.Equal(t, i, message.messageBody)
+		assert.Equal(t, int64(10+i), message.sequence)
+	}
+}
+
+func TestNotifierDropsEvents(t *testing.T) {
+	notifier := NewNotifier("testEvent", nil)
+	listener := notifier.listen()
+
 	// Should stop sending messages and not block once the buffer is full.
 	log.SetOutput(ioutil.Discard) // Silence noisy log output.
 	for i := 0; i < 20; i++ {
 		notifier.NotifyWithMessage(i)
 	}
-	var value messageEnvelope
-	var lastValue interface{}
-	for lastValue == nil {
-		select {
-		case value = <-listener:
-		default:
-			lastValue = value.messageBody
-			return
-		}
+	for i := 0; i < notifyBufferSize; i++ {
+		assert.Equal(t, i, (<-listener).messageBody)
 	}
 	notifier.NotifyWithMessage("next message")
-	assert.True(t, lastValue.(int) < 10)
-	assert.Equal(t, "next message", (<-listener).messageBody)
+	message := <-listener
+	assert.Equal(t, "next message", message.messageBody)
+	assert.Equal(t, int64(21), message.sequence)
 }
 
 func TestNotifyMultipleListeners(t *testing.T) {
@@ -22,13 +22,16 @@ const pingInterval = time.Second * 10
 
 // Wraps the Gorilla Websocket module so that we can define additional functions on it.
 type Websocket struct {
-	conn       *websocket.Conn
-	writeMutex *sync.Mutex
+	conn           *websocket.Conn
+	writeMutex     *sync.Mutex
+	notifiers      []*Notifier // The notifiers whose state is resent when the client asks to be resynchronized.
+	notifiersMutex *sync.Mutex
 }
 
 type Message struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+	Seq  int64       `json:"seq,omitempty"` // Sequence number within the notifier that the message came from, if any.
 }
 
 var websocketUpgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 2014}
@@ -39,32 +42,45 @@ func NewWebsocket(w http.ResponseWriter, r *http.Request) (*Websocket, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Websocket{conn, new(sync.Mutex)}, nil
+	return &Websocket{conn: conn, writeMutex: new(sync.Mutex), notifiersMutex: new(sync.Mutex)}, nil
 }
 
 func NewTestWebsocket(conn *websocket.Conn) *Websocket {
-	return &Websocket{conn, new(sync.Mutex)}
+	return &Websocket{conn: conn, writeMutex: new(sync.Mutex), notifiersMutex: new(sync.Mutex)}
 }
 
 func (ws *Websocket) Close() error {
 	return ws.conn.Close()
 }
 
+// Reads the next message from the client. Requests to resynchronize the notifiers are handled transparently.
 func (ws *Websocket) Read() (string, interface{}, error) {
-	var message Message
-	err := ws.conn.ReadJSON(&message)
-	if websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseAbnormalClosure) {
-		// This error indicates that the browser terminated the connection normally; rewrite it so that clients don't
-		// log it.
-		return "", nil, io.EOF
-	}
-	if err != nil {
-		// Include the caller of this method in the error message.
-		_, file, line, _ := runtime.Caller(1)
-		filePathParts := strings.Split(file, "/")
-		return "", nil, fmt.Errorf("[%s:%d] Websocket read error: %v", filePathParts[len(filePathParts)-1], line, err)
+	for {
+		var message Message
+		err := ws.conn.ReadJSON(&message)
+		if websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseAbnormalClosure) {
+			// This error indicates that the browser terminated the connection normally; rewrite it so that clients
+			// don't log it.
+			return "", nil, io.EOF
+		}
+		if err != nil {
+			// Include the caller of this method in the error message.
+			_, file, line, _ := runtime.Caller(1)
+			filePathParts := strings.Split(file, "/")
+			return "", nil, fmt.Errorf("[%s:%d] Websocket read error: %v", filePathParts[len(filePathParts)-1], line,
+				err)
+		}
+
+		if message.Type == "resync" {
+			// The client has detected a gap in the notifications it received; bring it back up to date by resending
+			// the current state of everything it is subscribed to.
+			if err = ws.writeNotifierSnapshots(); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+		return message.Type, message.Data, nil
 	}
-	return message.Type, message.Data, nil
 }
 
 func (ws *Websocket) ReadWithTimeout(timeout time.Duration) (string, interface{}, error) {
@@ -88,9 +104,7 @@ func (ws *Websocket) ReadWithTimeout(timeout time.Duration) (string, interface{}
 }
 
 func (ws *Websocket) Write(messageType string, data interface{}) error {
-	ws.writeMutex.Lock()
-	defer ws.writeMutex.Unlock()
-	err := ws.conn.WriteJSON(Message{messageType, data})
+	err := ws.writeMessage(Message{Type: messageType, Data: data})
 	if err != nil {
 		// Include the caller of this method in the error message.
 		_, file, line, _ := runtime.Caller(1)
@@ -100,8 +114,10 @@ func (ws *Websocket) Write(messageType string, data interface{}) error {
 	return nil
 }
 
+// Writes the current state of the given notifier, numbered so that the client can discard any older notifications from
+// it that are still in flight.
 func (ws *Websocket) WriteNotifier(notifier *Notifier) error {
-	return ws.Write(notifier.messageType, notifier.getMessageBody())
+	return ws.writeEnvelope(notifier.snapshot())
 }
 
 func (ws *Websocket) WriteError(errorMessage string) error {
@@ -110,6 +126,10 @@ func (ws *Websocket) WriteError(errorMessage string) error {
 
 // Creates listeners for the given notifiers and loops forever to pass their output directly through to the websocket.
 func (ws *Websocket) HandleNotifiers(notifiers ...*Notifier) {
+	ws.notifiersMutex.Lock()
+	ws.notifiers = notifiers
+	ws.notifiersMutex.Unlock()
+
 	// Use reflection to dynamically build a select/case structure for all the notifiers.
 	listeners := make([]reflect.SelectCase, len(notifiers))
 	for i, notifier := range notifiers {
@@ -154,10 +174,36 @@ func (ws *Websocket) HandleNotifiers(notifiers ...*Notifier) {
 		}
 
 		// Forward the message verbatim on to the websocket.
-		err := ws.Write(message.messageType, message.messageBody)
+		err := ws.writeEnvelope(message)
 		if err != nil {
 			// The client has probably closed the connection; bail out of the loop.
 			return
 		}
 	}
 }
+
+// Writes the current state of each of the notifiers that the websocket is subscribed to.
+func (ws *Websocket) writeNotifierSnapshots() error {
+	ws.notifiersMutex.Lock()
+	notifiers := ws.notifiers
+	ws.notifiersMutex.Unlock()
+
+	for _, notifier := range notifiers {
+		if notifier.messageProducer != nil {
+			if err := ws.WriteNotifier(notifier); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ws *Websocket) writeEnvelope(message messageEnvelope) error {
+	return ws.writeMessage(Message{Type: message.messageType, Data: message.messageBody, Seq: message.sequence})
+}
+
+func (ws *Websocket) writeMessage(message Message) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+	return ws.conn.WriteJSON(message)
+}
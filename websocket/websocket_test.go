@@ -92,6 +92,21 @@ func TestWebsocket(t *testing.T) {
 	ws.Write("sendError", nil)
 	assertMessage(t, ws, "error", "error message")
 
+	// Check that notifications are numbered and that a resync request resends the state of every notifier.
+	notifier3.Notify()
+	var message Message
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, Message{Type: "messageType3", Data: changingValue, Seq: 4}, message)
+	notifier2.Notify()
+	assertMessage(t, ws, "messageType2", nil)
+	ws.Write("resync", nil)
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, Message{Type: "messageType3", Data: changingValue, Seq: 4}, message)
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, Message{Type: "messageType1", Data: "test message", Seq: 2}, message)
+	ws.Write("messageType4", "test message 5")
+	assertMessage(t, ws, "messageType4", "test message 5")
+
 	// Ensure the read times out if there is nothing to read.
 	_, _, err = ws.ReadWithTimeout(time.Millisecond)
 	if assert.NotNil(t, err) {